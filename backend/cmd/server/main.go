@@ -9,11 +9,17 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // Embed the timezone database for recurrence rules on minimal images
 
 	_ "todo-backend/docs" // Import generated docs for swagger
 	"todo-backend/internal/config"
 	"todo-backend/internal/database"
+	"todo-backend/internal/models"
+	"todo-backend/internal/notifier"
+	"todo-backend/internal/repository"
 	"todo-backend/internal/router"
+	"todo-backend/internal/scheduler"
+	"todo-backend/internal/service"
 	"todo-backend/pkg/logger"
 
 	"github.com/gin-gonic/gin"
@@ -66,8 +72,55 @@ func main() {
 		log.Fatal().Err(err).Msg("Failed to run migrations")
 	}
 
+	// Notifiers for reminders and account exports
+	notifiers := notifier.Registry{
+		models.ReminderChannelLog: notifier.NewLogNotifier(log),
+	}
+	if cfg.WebhookURL != "" {
+		notifiers[models.ReminderChannelWebhook] = notifier.NewWebhookNotifier(cfg.WebhookURL, cfg.WebhookSecret)
+	}
+	if cfg.SMTPHost != "" {
+		notifiers[models.ReminderChannelEmail] = notifier.NewEmailNotifier(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPFrom)
+	}
+
+	// Background worker for large imports and account exports
+	worker := scheduler.NewWorker(cfg.BackgroundWorkers, log)
+
+	// Blob storage and account service, shared by the API and the purgers
+	blobStore, err := router.NewBlobStore(cfg)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize blob store")
+	}
+	accountService, err := router.NewAccountService(db, cfg, blobStore, notifiers, worker)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize account service")
+	}
+
 	// Initialize router
-	r := router.SetupRouter(db, cfg)
+	r, err := router.SetupRouter(db, cfg, blobStore, accountService, worker)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize router")
+	}
+
+	worker.Start(context.Background())
+
+	// Start the reminder scheduler
+	reminderScheduler := scheduler.NewReminderScheduler(repository.NewReminderRepository(db), notifiers, cfg.ReminderPollInterval, cfg.ReminderBatchSize, log)
+	reminderScheduler.Start(context.Background())
+
+	// Start purging expired trash
+	var trashPurger *scheduler.TrashPurger
+	if cfg.TrashRetentionDays > 0 {
+		todoRepo := repository.NewTodoRepository(db)
+		attachmentService := service.NewAttachmentService(repository.NewAttachmentRepository(db), todoRepo, blobStore, cfg)
+		retention := time.Duration(cfg.TrashRetentionDays) * 24 * time.Hour
+		trashPurger = scheduler.NewTrashPurger(todoRepo, attachmentService, retention, cfg.TrashPurgeInterval, log)
+		trashPurger.Start(context.Background())
+	}
+
+	// Start purging deleted accounts and expired account exports
+	accountPurger := scheduler.NewAccountPurger(accountService, cfg.AccountPurgeInterval, log)
+	accountPurger.Start(context.Background())
 
 	// Setup server
 	srv := &http.Server{
@@ -97,5 +150,21 @@ func main() {
 		log.Fatal().Err(err).Msg("Server forced to shutdown")
 	}
 
+	if err := worker.Stop(ctx); err != nil {
+		log.Error().Err(err).Msg("Background worker did not stop in time")
+	}
+	if err := reminderScheduler.Stop(ctx); err != nil {
+		log.Error().Err(err).Msg("Reminder scheduler did not stop in time")
+	}
+	if trashPurger != nil {
+		if err := trashPurger.Stop(ctx); err != nil {
+			log.Error().Err(err).Msg("Trash purger did not stop in time")
+		}
+	}
+
+	if err := accountPurger.Stop(ctx); err != nil {
+		log.Error().Err(err).Msg("Account purger did not stop in time")
+	}
+
 	log.Info().Msg("Server exited")
 }
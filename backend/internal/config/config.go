@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/viper"
@@ -21,6 +22,55 @@ type Config struct {
 	AppleKeyID      string `mapstructure:"APPLE_KEY_ID"`
 	AppleKeyPath    string `mapstructure:"APPLE_KEY_PATH"`
 	AppleRedirectURL string `mapstructure:"APPLE_REDIRECT_URL"`
+
+	// Reminder scheduler
+	ReminderPollInterval time.Duration `mapstructure:"REMINDER_POLL_INTERVAL"`
+	ReminderBatchSize    int           `mapstructure:"REMINDER_BATCH_SIZE"`
+	WebhookURL           string        `mapstructure:"REMINDER_WEBHOOK_URL"`
+	WebhookSecret        string        `mapstructure:"REMINDER_WEBHOOK_SECRET"`
+
+	// SMTP for email notifications
+	SMTPHost     string `mapstructure:"SMTP_HOST"`
+	SMTPPort     string `mapstructure:"SMTP_PORT"`
+	SMTPUsername string `mapstructure:"SMTP_USERNAME"`
+	SMTPPassword string `mapstructure:"SMTP_PASSWORD"`
+	SMTPFrom     string `mapstructure:"SMTP_FROM"`
+
+	// Attachments
+	AttachmentMaxSize      int64         `mapstructure:"ATTACHMENT_MAX_SIZE"`
+	AttachmentAllowedTypes []string      `mapstructure:"ATTACHMENT_ALLOWED_TYPES"`
+	AttachmentURLTTL       time.Duration `mapstructure:"ATTACHMENT_URL_TTL"`
+	AttachmentSigningKey   string        `mapstructure:"ATTACHMENT_SIGNING_KEY"`
+	PublicBaseURL          string        `mapstructure:"PUBLIC_BASE_URL"`
+
+	// Blob storage: "local" or "s3"
+	BlobStore      string `mapstructure:"BLOB_STORE"`
+	BlobLocalDir   string `mapstructure:"BLOB_LOCAL_DIR"`
+	S3Endpoint     string `mapstructure:"S3_ENDPOINT"`
+	S3Region       string `mapstructure:"S3_REGION"`
+	S3Bucket       string `mapstructure:"S3_BUCKET"`
+	S3AccessKey    string `mapstructure:"S3_ACCESS_KEY"`
+	S3SecretKey    string `mapstructure:"S3_SECRET_KEY"`
+	S3UsePathStyle bool   `mapstructure:"S3_USE_PATH_STYLE"`
+
+	// Full-text search: "postgres" or "memory"
+	SearchBackend string `mapstructure:"SEARCH_BACKEND"`
+
+	// Background jobs (large imports and account exports): BACKGROUND_WORKERS
+	// run at a time and jobs not updated for BACKGROUND_JOB_TIMEOUT are
+	// reported as failed
+	BackgroundWorkers    int           `mapstructure:"BACKGROUND_WORKERS"`
+	BackgroundJobTimeout time.Duration `mapstructure:"BACKGROUND_JOB_TIMEOUT"`
+
+	// Trash: deleted todos are purged after TRASH_RETENTION_DAYS (0 keeps them)
+	TrashRetentionDays int           `mapstructure:"TRASH_RETENTION_DAYS"`
+	TrashPurgeInterval time.Duration `mapstructure:"TRASH_PURGE_INTERVAL"`
+
+	// Accounts: data exports can be downloaded for ACCOUNT_EXPORT_TTL and
+	// deleted accounts are purged after ACCOUNT_DELETION_GRACE_DAYS
+	AccountExportTTL         time.Duration `mapstructure:"ACCOUNT_EXPORT_TTL"`
+	AccountDeletionGraceDays int           `mapstructure:"ACCOUNT_DELETION_GRACE_DAYS"`
+	AccountPurgeInterval     time.Duration `mapstructure:"ACCOUNT_PURGE_INTERVAL"`
 }
 
 func Load() (*Config, error) {
@@ -42,6 +92,48 @@ func Load() (*Config, error) {
 	viper.SetDefault("APPLE_KEY_PATH", "")
 	viper.SetDefault("APPLE_REDIRECT_URL", "http://localhost:8080/api/v1/auth/apple/callback")
 
+	// Reminder defaults (webhook and email channels are disabled until configured)
+	viper.SetDefault("REMINDER_POLL_INTERVAL", "30s")
+	viper.SetDefault("REMINDER_BATCH_SIZE", 50)
+	viper.SetDefault("REMINDER_WEBHOOK_URL", "")
+	viper.SetDefault("REMINDER_WEBHOOK_SECRET", "")
+	viper.SetDefault("SMTP_HOST", "")
+	viper.SetDefault("SMTP_PORT", "587")
+	viper.SetDefault("SMTP_USERNAME", "")
+	viper.SetDefault("SMTP_PASSWORD", "")
+	viper.SetDefault("SMTP_FROM", "")
+
+	// Attachment defaults
+	viper.SetDefault("ATTACHMENT_MAX_SIZE", 10<<20)
+	viper.SetDefault("ATTACHMENT_ALLOWED_TYPES", "image/png,image/jpeg,image/gif,image/webp,application/pdf,text/plain,text/csv")
+	viper.SetDefault("ATTACHMENT_URL_TTL", "15m")
+	viper.SetDefault("ATTACHMENT_SIGNING_KEY", "")
+	viper.SetDefault("PUBLIC_BASE_URL", "http://localhost:8080")
+	viper.SetDefault("BLOB_STORE", "local")
+	viper.SetDefault("BLOB_LOCAL_DIR", "./data/attachments")
+	viper.SetDefault("S3_ENDPOINT", "")
+	viper.SetDefault("S3_REGION", "us-east-1")
+	viper.SetDefault("S3_BUCKET", "")
+	viper.SetDefault("S3_ACCESS_KEY", "")
+	viper.SetDefault("S3_SECRET_KEY", "")
+	viper.SetDefault("S3_USE_PATH_STYLE", true)
+
+	// Search defaults
+	viper.SetDefault("SEARCH_BACKEND", "postgres")
+
+	// Background job defaults
+	viper.SetDefault("BACKGROUND_WORKERS", 4)
+	viper.SetDefault("BACKGROUND_JOB_TIMEOUT", "30m")
+
+	// Trash defaults
+	viper.SetDefault("TRASH_RETENTION_DAYS", 30)
+	viper.SetDefault("TRASH_PURGE_INTERVAL", "1h")
+
+	// Account defaults
+	viper.SetDefault("ACCOUNT_EXPORT_TTL", "168h")
+	viper.SetDefault("ACCOUNT_DELETION_GRACE_DAYS", 30)
+	viper.SetDefault("ACCOUNT_PURGE_INTERVAL", "1h")
+
 	// Bind environment variables
 	viper.AutomaticEnv()
 
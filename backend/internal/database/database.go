@@ -2,6 +2,7 @@ package database
 
 import (
 	"todo-backend/internal/models"
+	"todo-backend/internal/search"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -32,8 +33,33 @@ func Connect(databaseURL string) (*gorm.DB, error) {
 }
 
 func Migrate(db *gorm.DB) error {
-	return db.AutoMigrate(
+	err := db.AutoMigrate(
 		&models.User{},
+		&models.List{},
+		&models.Tag{},
 		&models.Todo{},
+		&models.Reminder{},
+		&models.Comment{},
+		&models.CommentMention{},
+		&models.Attachment{},
+		&models.TodoDependency{},
+		&models.SavedView{},
+		&models.Operation{},
+		&models.TodoChange{},
+		&models.WorkflowState{},
+		&models.TimeEntry{},
+		&models.Template{},
+		&models.AppPassword{},
+		&models.ImportJob{},
+		&models.AccountExport{},
 	)
+	if err != nil {
+		return err
+	}
+
+	// Full-text search column, index and trigger
+	if db.Dialector.Name() == "postgres" {
+		return search.Migrate(db)
+	}
+	return nil
 } 
\ No newline at end of file
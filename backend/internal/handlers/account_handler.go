@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"todo-backend/internal/models"
+	"todo-backend/internal/service"
+	"todo-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AccountHandler struct {
+	accountService service.AccountService
+}
+
+func NewAccountHandler(accountService service.AccountService) *AccountHandler {
+	return &AccountHandler{
+		accountService: accountService,
+	}
+}
+
+// RequestExport godoc
+// @Summary Export all account data
+// @Description Start building a ZIP archive with the profile, todos, comments, attachments and sessions (app passwords) of the account. The archive is built in the background; poll the export or wait for the notification with the download link.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 202 {object} utils.Response{data=models.AccountExportResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Failure 503 {object} utils.ErrorResponse
+// @Router /api/v1/auth/account/export [post]
+func (h *AccountHandler) RequestExport(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	export, err := h.accountService.RequestExport(userID)
+	if err != nil {
+		sendAccountError(c, err, "Failed to start account export")
+		return
+	}
+
+	c.Header("Location", "/api/v1/auth/account/export/"+export.ID.String())
+	utils.SuccessResponse(c, http.StatusAccepted, "Account export started", export.ToResponse())
+}
+
+// GetExport godoc
+// @Summary Get an account export
+// @Description Poll an account export; completed exports carry a download link until they expire
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Account export ID"
+// @Success 200 {object} utils.Response{data=models.AccountExportResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/auth/account/export/{id} [get]
+func (h *AccountHandler) GetExport(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid account export ID", err.Error())
+		return
+	}
+
+	export, err := h.accountService.GetExport(id, userID)
+	if err != nil {
+		sendAccountError(c, err, "Failed to get account export")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Account export retrieved successfully", export)
+}
+
+// DownloadExport godoc
+// @Summary Download an account export
+// @Description Stream the ZIP archive of an account export using the signed link from the export or its notification
+// @Tags auth
+// @Produce application/zip
+// @Param id path string true "Account export ID"
+// @Param expires query int true "Link expiry (Unix time)"
+// @Param signature query string true "Link signature"
+// @Success 200 {file} file
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /api/v1/auth/account/export/{id}/download [get]
+func (h *AccountHandler) DownloadExport(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid account export ID", err.Error())
+		return
+	}
+
+	expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid download link", "expires must be a Unix timestamp")
+		return
+	}
+
+	export, content, err := h.accountService.OpenSignedExport(c.Request.Context(), id, expires, c.Query("signature"))
+	if err != nil {
+		sendAccountError(c, err, "Failed to download account export")
+		return
+	}
+	defer content.Close()
+
+	fileName := fmt.Sprintf("account-export-%s.zip", export.CompletedAt.UTC().Format("2006-01-02"))
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Length", strconv.FormatInt(export.Size, 10))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Status(http.StatusOK)
+	io.Copy(c.Writer, content)
+}
+
+// DeleteAccount godoc
+// @Summary Delete the account
+// @Description Delete the account and all of its data. Requires the password of an email account or a fresh Apple authorization code. The account is purged when the grace period is over and can be kept until then; without a grace period it is purged right away.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.AccountDeleteRequest true "Reauthentication"
+// @Success 200 {object} utils.Response{data=models.AccountDeletionResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/auth/account [delete]
+func (h *AccountHandler) DeleteAccount(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	var req models.AccountDeleteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	deletion, err := h.accountService.Delete(c.Request.Context(), userID, &req)
+	if err != nil {
+		sendAccountError(c, err, "Failed to delete account")
+		return
+	}
+
+	if deletion.DeletionScheduledAt == nil {
+		utils.SuccessResponse(c, http.StatusOK, "Account deleted successfully", deletion)
+		return
+	}
+	utils.SuccessResponse(c, http.StatusOK, "Account deletion scheduled", deletion)
+}
+
+// CancelDeletion godoc
+// @Summary Keep the account
+// @Description Cancel a scheduled account deletion during its grace period
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=models.UserResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/auth/account/restore [post]
+func (h *AccountHandler) CancelDeletion(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	user, err := h.accountService.CancelDeletion(userID)
+	if err != nil {
+		sendAccountError(c, err, "Failed to cancel account deletion")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Account deletion cancelled", user.ToResponse())
+}
+
+func sendAccountError(c *gin.Context, err error, message string) {
+	msg := err.Error()
+	switch {
+	case msg == "user not found":
+		utils.SendErrorResponse(c, http.StatusNotFound, "User not found", msg)
+	case msg == "account export not found":
+		utils.SendErrorResponse(c, http.StatusNotFound, "Account export not found", msg)
+	case strings.HasPrefix(msg, "unauthorized"):
+		utils.SendErrorResponse(c, http.StatusForbidden, "Forbidden", msg)
+	case msg == "invalid or expired download link":
+		utils.SendErrorResponse(c, http.StatusForbidden, "Invalid download link", msg)
+	case msg == "an account export is already in progress", msg == "account deletion is not scheduled":
+		utils.SendErrorResponse(c, http.StatusConflict, message, msg)
+	case msg == "too many background jobs, try again later":
+		utils.SendErrorResponse(c, http.StatusServiceUnavailable, message, msg)
+	case msg == "invalid credentials":
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Reauthentication failed", msg)
+	case strings.HasPrefix(msg, "reauthentication required"):
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Reauthentication required", msg)
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, message, msg)
+	}
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"net/http"
+	"todo-backend/internal/models"
+	"todo-backend/internal/service"
+	"todo-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AppPasswordHandler struct {
+	passwordService service.AppPasswordService
+}
+
+func NewAppPasswordHandler(passwordService service.AppPasswordService) *AppPasswordHandler {
+	return &AppPasswordHandler{
+		passwordService: passwordService,
+	}
+}
+
+// CreateAppPassword godoc
+// @Summary Create an app password
+// @Description Create a personal access token for apps such as CalDAV clients. Use it as the password with your email as the user name, or as a bearer token. The password is only shown in this response.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param password body models.AppPasswordCreateRequest true "App password name"
+// @Success 201 {object} utils.Response{data=models.AppPasswordCreatedResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 422 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/auth/app-passwords [post]
+func (h *AppPasswordHandler) CreateAppPassword(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	var req models.AppPasswordCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	password, plain, err := h.passwordService.Create(userID, &req)
+	if err != nil {
+		sendAppPasswordError(c, err, "Failed to create app password")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "App password created successfully", models.AppPasswordCreatedResponse{
+		AppPasswordResponse: password.ToResponse(),
+		Password:            plain,
+	})
+}
+
+// GetAppPasswords godoc
+// @Summary Get app passwords
+// @Description Get the app passwords of the authenticated user, newest first, without the passwords themselves
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=[]models.AppPasswordResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/auth/app-passwords [get]
+func (h *AppPasswordHandler) GetAppPasswords(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	passwords, err := h.passwordService.GetByUserID(userID)
+	if err != nil {
+		sendAppPasswordError(c, err, "Failed to get app passwords")
+		return
+	}
+
+	responses := make([]models.AppPasswordResponse, len(passwords))
+	for i := range passwords {
+		responses[i] = passwords[i].ToResponse()
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "App passwords retrieved successfully", responses)
+}
+
+// DeleteAppPassword godoc
+// @Summary Revoke an app password
+// @Description Delete an app password; apps using it are signed out
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "App password ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/auth/app-passwords/{id} [delete]
+func (h *AppPasswordHandler) DeleteAppPassword(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid app password ID", "App password ID must be a valid UUID")
+		return
+	}
+
+	if err := h.passwordService.Delete(id, userID); err != nil {
+		sendAppPasswordError(c, err, "Failed to delete app password")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "App password deleted successfully", nil)
+}
+
+func sendAppPasswordError(c *gin.Context, err error, message string) {
+	msg := err.Error()
+	switch msg {
+	case "app password not found":
+		utils.SendErrorResponse(c, http.StatusNotFound, "App password not found", msg)
+	case "unauthorized to delete this app password":
+		utils.SendErrorResponse(c, http.StatusForbidden, "Forbidden", msg)
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, message, msg)
+	}
+}
@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"todo-backend/internal/models"
+	"todo-backend/internal/service"
+	"todo-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// multipartOverhead is the room left for multipart headers on top of the file size limit
+const multipartOverhead = 1 << 20
+
+type AttachmentHandler struct {
+	attachmentService service.AttachmentService
+	maxSize           int64
+}
+
+func NewAttachmentHandler(attachmentService service.AttachmentService, maxSize int64) *AttachmentHandler {
+	return &AttachmentHandler{
+		attachmentService: attachmentService,
+		maxSize:           maxSize,
+	}
+}
+
+// UploadAttachment godoc
+// @Summary Attach a file to a todo
+// @Description Upload a file as multipart/form-data in the "file" field. Size and type limits are configurable.
+// @Tags attachments
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Todo ID"
+// @Param file formData file true "File to attach"
+// @Success 201 {object} utils.Response{data=models.AttachmentResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 413 {object} utils.ErrorResponse
+// @Failure 415 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/todos/{id}/attachments [post]
+func (h *AttachmentHandler) UploadAttachment(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	todoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid todo ID", err.Error())
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxSize+multipartOverhead)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		if strings.Contains(err.Error(), "request body too large") {
+			utils.SendErrorResponse(c, http.StatusRequestEntityTooLarge, "File too large", fmt.Sprintf("file exceeds the maximum size of %d bytes", h.maxSize))
+			return
+		}
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid upload", err.Error())
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid upload", err.Error())
+		return
+	}
+	defer file.Close()
+
+	attachment, err := h.attachmentService.Upload(c.Request.Context(), todoID, userID, fileHeader.Filename, fileHeader.Size, file)
+	if err != nil {
+		sendAttachmentError(c, err, "Failed to upload attachment")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Attachment uploaded successfully", attachment.ToResponse())
+}
+
+// GetAttachments godoc
+// @Summary List the attachments of a todo
+// @Description Get metadata of all files attached to a todo
+// @Tags attachments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Todo ID"
+// @Success 200 {object} utils.Response{data=[]models.AttachmentResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/todos/{id}/attachments [get]
+func (h *AttachmentHandler) GetAttachments(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	todoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid todo ID", err.Error())
+		return
+	}
+
+	attachments, err := h.attachmentService.GetByTodoID(todoID, userID)
+	if err != nil {
+		sendAttachmentError(c, err, "Failed to get attachments")
+		return
+	}
+
+	responses := make([]models.AttachmentResponse, 0, len(attachments))
+	for _, attachment := range attachments {
+		responses = append(responses, attachment.ToResponse())
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Attachments retrieved successfully", responses)
+}
+
+// GetAttachmentURL godoc
+// @Summary Get a download link for an attachment
+// @Description Get a signed URL for downloading an attachment that expires after a configurable time
+// @Tags attachments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Todo ID"
+// @Param attachment_id path string true "Attachment ID"
+// @Success 200 {object} utils.Response{data=models.AttachmentDownloadResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/todos/{id}/attachments/{attachment_id}/url [get]
+func (h *AttachmentHandler) GetAttachmentURL(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	todoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid todo ID", err.Error())
+		return
+	}
+
+	attachmentID, err := uuid.Parse(c.Param("attachment_id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid attachment ID", err.Error())
+		return
+	}
+
+	download, err := h.attachmentService.GetDownloadURL(attachmentID, todoID, userID)
+	if err != nil {
+		sendAttachmentError(c, err, "Failed to create download link")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Download link created successfully", download)
+}
+
+// DownloadAttachment godoc
+// @Summary Download an attachment
+// @Description Stream an attachment using a signed link from the download URL endpoint. No bearer token is needed.
+// @Tags attachments
+// @Produce octet-stream
+// @Param attachment_id path string true "Attachment ID"
+// @Param expires query int true "Expiry as a Unix timestamp"
+// @Param signature query string true "Link signature"
+// @Success 200 {file} file
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/attachments/{attachment_id}/download [get]
+func (h *AttachmentHandler) DownloadAttachment(c *gin.Context) {
+	attachmentID, err := uuid.Parse(c.Param("attachment_id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid attachment ID", err.Error())
+		return
+	}
+
+	expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid download link", "expires must be a Unix timestamp")
+		return
+	}
+
+	attachment, content, err := h.attachmentService.OpenSignedDownload(c.Request.Context(), attachmentID, expires, c.Query("signature"))
+	if err != nil {
+		sendAttachmentError(c, err, "Failed to download attachment")
+		return
+	}
+	defer content.Close()
+
+	c.Header("Content-Type", attachment.ContentType)
+	c.Header("Content-Length", strconv.FormatInt(attachment.Size, 10))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", attachment.FileName))
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Status(http.StatusOK)
+	io.Copy(c.Writer, content)
+}
+
+// DeleteAttachment godoc
+// @Summary Delete an attachment
+// @Description Remove an attachment and its stored file
+// @Tags attachments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Todo ID"
+// @Param attachment_id path string true "Attachment ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/todos/{id}/attachments/{attachment_id} [delete]
+func (h *AttachmentHandler) DeleteAttachment(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	todoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid todo ID", err.Error())
+		return
+	}
+
+	attachmentID, err := uuid.Parse(c.Param("attachment_id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid attachment ID", err.Error())
+		return
+	}
+
+	if err := h.attachmentService.Delete(c.Request.Context(), attachmentID, todoID, userID); err != nil {
+		sendAttachmentError(c, err, "Failed to delete attachment")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Attachment deleted successfully", nil)
+}
+
+func sendAttachmentError(c *gin.Context, err error, message string) {
+	msg := err.Error()
+	switch {
+	case msg == "todo not found":
+		utils.SendErrorResponse(c, http.StatusNotFound, "Todo not found", msg)
+	case msg == "attachment not found":
+		utils.SendErrorResponse(c, http.StatusNotFound, "Attachment not found", msg)
+	case msg == "unauthorized to access this todo":
+		utils.SendErrorResponse(c, http.StatusForbidden, "Forbidden", msg)
+	case msg == "invalid or expired download link":
+		utils.SendErrorResponse(c, http.StatusForbidden, "Invalid download link", msg)
+	case strings.HasPrefix(msg, "file exceeds"):
+		utils.SendErrorResponse(c, http.StatusRequestEntityTooLarge, "File too large", msg)
+	case strings.HasPrefix(msg, "file type"):
+		utils.SendErrorResponse(c, http.StatusUnsupportedMediaType, "Unsupported file type", msg)
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, message, msg)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"net/http"
+	"strings"
 	"time"
 	"todo-backend/internal/models"
 	"todo-backend/internal/service"
@@ -232,23 +233,66 @@ func (h *AuthHandler) GetUserProfile(c *gin.Context) {
 		return
 	}
 
-	// Get user details (this would use userRepository, but for now we can get it from context)
-	// In a real implementation, you'd fetch fresh user data from the database
-	email, exists := c.Get("email")
-	if !exists {
-		utils.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get user info", "email not found in context")
+	user, err := h.authService.GetUser(userID)
+	if err != nil {
+		if err.Error() == "user not found" {
+			utils.SendErrorResponse(c, http.StatusNotFound, "User not found", err.Error())
+			return
+		}
+		utils.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get user info", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "User profile retrieved", user.ToResponse())
+}
+
+// UpdatePreferences godoc
+// @Summary Update user preferences
+// @Description Set the timezone (IANA name) and first day of the week used for date buckets such as Today and Upcoming
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.UserPreferencesRequest true "Preferences"
+// @Success 200 {object} utils.Response{data=models.UserResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 422 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/auth/user/preferences [put]
+func (h *AuthHandler) UpdatePreferences(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	var req models.UserPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request body", err.Error())
 		return
 	}
 
-	// For now, return basic info from token
-	// TODO: Fetch complete user data from repository
-	response := models.UserResponse{
-		ID:    userID,
-		Email: email.(string),
-		// Add other fields as needed
+	if err := utils.ValidateStruct(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	user, err := h.authService.UpdatePreferences(userID, &req)
+	if err != nil {
+		switch {
+		case err.Error() == "user not found":
+			utils.SendErrorResponse(c, http.StatusNotFound, "User not found", err.Error())
+		case strings.HasPrefix(err.Error(), "invalid timezone"):
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid preferences", err.Error())
+		default:
+			utils.SendErrorResponse(c, http.StatusInternalServerError, "Failed to update preferences", err.Error())
+		}
+		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "User profile retrieved", response)
+	utils.SuccessResponse(c, http.StatusOK, "Preferences updated successfully", user.ToResponse())
 }
 
 // Traditional Auth Handlers (for future use)
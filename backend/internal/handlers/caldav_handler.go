@@ -0,0 +1,526 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"strings"
+	"todo-backend/internal/models"
+	"todo-backend/internal/service"
+	"todo-backend/pkg/utils"
+	"todo-backend/pkg/webdav"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CalDAVPrefix is the path the CalDAV server is mounted at
+const CalDAVPrefix = "/dav"
+
+// maxCalDAVBody limits request bodies of PUT and REPORT
+const maxCalDAVBody = 1 << 20
+
+// Kinds of resources on the CalDAV server
+const (
+	davRoot = iota
+	davPrincipal
+	davHome
+	davCalendar
+	davObject
+)
+
+// davResource is a resource addressed by a path below CalDAVPrefix:
+//
+//	/                                     root
+//	/principals/<user>/                   principal
+//	/calendars/<user>/                    calendar home
+//	/calendars/<user>/<calendar>/         a list, or the inbox
+//	/calendars/<user>/<calendar>/<name>   a todo
+type davResource struct {
+	kind       int
+	calendarID string
+	name       string
+}
+
+func davName(local string) xml.Name {
+	return xml.Name{Space: webdav.NamespaceDAV, Local: local}
+}
+
+func caldavName(local string) xml.Name {
+	return xml.Name{Space: webdav.NamespaceCalDAV, Local: local}
+}
+
+var (
+	propResourceType       = davName("resourcetype")
+	propDisplayName        = davName("displayname")
+	propCurrentUser        = davName("current-user-principal")
+	propPrincipalURL       = davName("principal-URL")
+	propPrivileges         = davName("current-user-privilege-set")
+	propSupportedReports   = davName("supported-report-set")
+	propETag               = davName("getetag")
+	propContentType        = davName("getcontenttype")
+	propCalendarHome       = caldavName("calendar-home-set")
+	propCalendarUser       = caldavName("calendar-user-address-set")
+	propSupportedComponent = caldavName("supported-calendar-component-set")
+	propCalendarData       = caldavName("calendar-data")
+	propCTag               = xml.Name{Space: webdav.NamespaceCalendar, Local: "getctag"}
+)
+
+// privileges are the same on every resource: users own all of theirs
+const privileges = "<privilege><read/></privilege><privilege><write/></privilege>" +
+	"<privilege><write-content/></privilege><privilege><bind/></privilege><privilege><unbind/></privilege>"
+
+const objectContentType = "text/calendar; charset=utf-8; component=VTODO"
+
+type CalDAVHandler struct {
+	caldavService service.CalDAVService
+}
+
+func NewCalDAVHandler(caldavService service.CalDAVService) *CalDAVHandler {
+	return &CalDAVHandler{
+		caldavService: caldavService,
+	}
+}
+
+// WellKnown redirects CalDAV service discovery (RFC 6764) to the server root
+func (h *CalDAVHandler) WellKnown(c *gin.Context) {
+	c.Redirect(http.StatusMovedPermanently, CalDAVPrefix+"/")
+}
+
+// Options advertises the CalDAV capabilities; it needs no authentication
+func (h *CalDAVHandler) Options(c *gin.Context) {
+	c.Header("DAV", "1, 3, calendar-access")
+	c.Header("Allow", "OPTIONS, PROPFIND, REPORT, GET, HEAD, PUT, DELETE")
+	c.Status(http.StatusOK)
+}
+
+// Propfind lists the properties of a resource and, with Depth: 1, of its
+// members
+func (h *CalDAVHandler) Propfind(c *gin.Context) {
+	userID, resource, ok := h.resource(c)
+	if !ok {
+		return
+	}
+	propfind, err := webdav.ParsePropfind(http.MaxBytesReader(c.Writer, c.Request.Body, maxCalDAVBody))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid PROPFIND", err.Error())
+		return
+	}
+	depth := c.GetHeader("Depth")
+	members := depth == "1" || strings.EqualFold(depth, "infinity")
+	withData := hasProp(propfind.Props, propCalendarData)
+
+	var responses []webdav.Response
+	add := func(href string, props []webdav.Prop) {
+		responses = append(responses, selectProps(href, props, propfind.AllProp, propfind.PropName, propfind.Props))
+	}
+
+	switch resource.kind {
+	case davRoot:
+		add(CalDAVPrefix+"/", principalLinks(userID, collectionType("")))
+	case davPrincipal:
+		user, err := h.caldavService.Principal(userID)
+		if err != nil {
+			sendCalDAVError(c, err, "Failed to get principal")
+			return
+		}
+		props := principalLinks(userID, collectionType(webdav.Element(davName("principal"), "")))
+		props = append(props,
+			webdav.Prop{Name: propDisplayName, Value: webdav.Escape(user.Name)},
+			webdav.Prop{Name: propCalendarUser, Value: hrefElement("mailto:" + user.Email)},
+		)
+		add(principalHref(userID), props)
+	case davHome:
+		add(homeHref(userID), principalLinks(userID, collectionType("")))
+		if members {
+			calendars, err := h.caldavService.Calendars(userID)
+			if err != nil {
+				sendCalDAVError(c, err, "Failed to get calendars")
+				return
+			}
+			for i := range calendars {
+				add(calendarHref(userID, calendars[i].ID), calendarProps(userID, &calendars[i]))
+			}
+		}
+	case davCalendar:
+		calendar, err := h.caldavService.Calendar(userID, resource.calendarID)
+		if err != nil {
+			sendCalDAVError(c, err, "Failed to get calendar")
+			return
+		}
+		add(calendarHref(userID, calendar.ID), calendarProps(userID, calendar))
+		if members {
+			objects, err := h.caldavService.Objects(userID, calendar.ID)
+			if err != nil {
+				sendCalDAVError(c, err, "Failed to get calendar objects")
+				return
+			}
+			for i := range objects {
+				add(objectHref(userID, calendar.ID, objects[i].Name), objectProps(&objects[i], withData))
+			}
+		}
+	case davObject:
+		object, err := h.caldavService.Object(userID, resource.calendarID, resource.name)
+		if err != nil {
+			sendCalDAVError(c, err, "Failed to get calendar object")
+			return
+		}
+		add(objectHref(userID, resource.calendarID, object.Name), objectProps(object, withData))
+	}
+
+	writeMultistatus(c, responses)
+}
+
+// Report answers calendar-query and calendar-multiget reports on a calendar.
+// Queries return every todo of the calendar: time ranges are not applied.
+func (h *CalDAVHandler) Report(c *gin.Context) {
+	userID, resource, ok := h.resource(c)
+	if !ok {
+		return
+	}
+	if resource.kind != davCalendar {
+		utils.SendErrorResponse(c, http.StatusForbidden, "Unsupported report", "reports are only supported on calendars")
+		return
+	}
+	report, err := webdav.ParseReport(http.MaxBytesReader(c.Writer, c.Request.Body, maxCalDAVBody))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid REPORT", err.Error())
+		return
+	}
+
+	names := report.Props
+	if report.AllProp || len(names) == 0 {
+		names = []xml.Name{propETag, propCalendarData}
+	}
+	withData := hasProp(names, propCalendarData)
+
+	var responses []webdav.Response
+	switch report.Kind {
+	case webdav.ReportCalendarQuery:
+		objects, err := h.caldavService.Objects(userID, resource.calendarID)
+		if err != nil {
+			sendCalDAVError(c, err, "Failed to query calendar")
+			return
+		}
+		if !queriesTodos(report.Components) {
+			objects = nil
+		}
+		for i := range objects {
+			href := objectHref(userID, resource.calendarID, objects[i].Name)
+			responses = append(responses, selectProps(href, objectProps(&objects[i], withData), false, false, names))
+		}
+	case webdav.ReportCalendarMultiget:
+		for _, href := range report.Hrefs {
+			target, ok := parseDAVHref(href, userID)
+			if !ok || target.kind != davObject || target.calendarID != resource.calendarID {
+				responses = append(responses, webdav.Response{Href: href, Status: http.StatusNotFound})
+				continue
+			}
+			object, err := h.caldavService.Object(userID, target.calendarID, target.name)
+			if err != nil {
+				if !isCalDAVNotFound(err) {
+					sendCalDAVError(c, err, "Failed to get calendar object")
+					return
+				}
+				responses = append(responses, webdav.Response{Href: href, Status: http.StatusNotFound})
+				continue
+			}
+			responses = append(responses, selectProps(href, objectProps(object, withData), false, false, names))
+		}
+	default:
+		utils.SendErrorResponse(c, http.StatusForbidden, "Unsupported report", "unsupported report: "+report.Kind)
+		return
+	}
+
+	writeMultistatus(c, responses)
+}
+
+// Get returns a todo as an iCalendar object; HEAD is served by the same
+// handler
+func (h *CalDAVHandler) Get(c *gin.Context) {
+	userID, resource, ok := h.resource(c)
+	if !ok {
+		return
+	}
+	if resource.kind != davObject {
+		utils.SendErrorResponse(c, http.StatusMethodNotAllowed, "Method not allowed", "only calendar objects can be downloaded")
+		return
+	}
+
+	object, err := h.caldavService.Object(userID, resource.calendarID, resource.name)
+	if err != nil {
+		sendCalDAVError(c, err, "Failed to get calendar object")
+		return
+	}
+
+	c.Header("ETag", object.ETag)
+	if etagMatches(c.GetHeader("If-None-Match"), object.ETag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, objectContentType, []byte(object.Data))
+}
+
+// Put creates or replaces a todo from an iCalendar object holding a VTODO.
+// The stored todo is not byte-for-byte what was sent, so no ETag is returned
+// and clients fetch the todo again (RFC 4791, section 5.3.4).
+func (h *CalDAVHandler) Put(c *gin.Context) {
+	userID, resource, ok := h.resource(c)
+	if !ok {
+		return
+	}
+	if resource.kind != davObject {
+		utils.SendErrorResponse(c, http.StatusMethodNotAllowed, "Method not allowed", "only calendar objects can be written")
+		return
+	}
+
+	conditions := models.CalDAVConditions{
+		IfMatch:     c.GetHeader("If-Match"),
+		IfNoneMatch: c.GetHeader("If-None-Match"),
+	}
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxCalDAVBody)
+	_, created, err := h.caldavService.Put(userID, resource.calendarID, resource.name, body, conditions)
+	if err != nil {
+		sendCalDAVError(c, err, "Failed to save calendar object")
+		return
+	}
+
+	if created {
+		c.Status(http.StatusCreated)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Delete moves a todo to the trash
+func (h *CalDAVHandler) Delete(c *gin.Context) {
+	userID, resource, ok := h.resource(c)
+	if !ok {
+		return
+	}
+	if resource.kind != davObject {
+		utils.SendErrorResponse(c, http.StatusForbidden, "Forbidden", "only calendar objects can be deleted")
+		return
+	}
+
+	conditions := models.CalDAVConditions{IfMatch: c.GetHeader("If-Match")}
+	if err := h.caldavService.Delete(userID, resource.calendarID, resource.name, conditions); err != nil {
+		sendCalDAVError(c, err, "Failed to delete calendar object")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// resource returns the authenticated user and the resource addressed by the
+// request, answering 404 for paths that don't exist or aren't theirs
+func (h *CalDAVHandler) resource(c *gin.Context) (uuid.UUID, *davResource, bool) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return uuid.Nil, nil, false
+	}
+	resource, ok := parseDAVPath(c.Param("path"), userID)
+	if !ok {
+		utils.SendErrorResponse(c, http.StatusNotFound, "Not found", "resource not found")
+		return uuid.Nil, nil, false
+	}
+	return userID, resource, true
+}
+
+// parseDAVPath parses a path below CalDAVPrefix
+func parseDAVPath(path string, userID uuid.UUID) (*davResource, bool) {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return &davResource{kind: davRoot}, true
+	}
+
+	segments := strings.Split(path, "/")
+	if len(segments) < 2 || segments[1] != userID.String() {
+		return nil, false
+	}
+	switch {
+	case segments[0] == "principals" && len(segments) == 2:
+		return &davResource{kind: davPrincipal}, true
+	case segments[0] != "calendars" || len(segments) > 4:
+		return nil, false
+	case len(segments) == 2:
+		return &davResource{kind: davHome}, true
+	case len(segments) == 3:
+		return &davResource{kind: davCalendar, calendarID: segments[2]}, true
+	}
+	if segments[3] == "" {
+		return nil, false
+	}
+	return &davResource{kind: davObject, calendarID: segments[2], name: segments[3]}, true
+}
+
+// parseDAVHref parses an href of a multiget, a path or an absolute URL
+func parseDAVHref(href string, userID uuid.UUID) (*davResource, bool) {
+	u, err := url.Parse(href)
+	if err != nil {
+		return nil, false
+	}
+	path, ok := strings.CutPrefix(u.Path, CalDAVPrefix+"/")
+	if !ok {
+		return nil, false
+	}
+	return parseDAVPath(path, userID)
+}
+
+func principalHref(userID uuid.UUID) string {
+	return CalDAVPrefix + "/principals/" + userID.String() + "/"
+}
+
+func homeHref(userID uuid.UUID) string {
+	return CalDAVPrefix + "/calendars/" + userID.String() + "/"
+}
+
+func calendarHref(userID uuid.UUID, calendarID string) string {
+	return homeHref(userID) + url.PathEscape(calendarID) + "/"
+}
+
+func objectHref(userID uuid.UUID, calendarID, name string) string {
+	return calendarHref(userID, calendarID) + url.PathEscape(name)
+}
+
+func hrefElement(href string) string {
+	return webdav.Element(davName("href"), webdav.Escape(href))
+}
+
+// collectionType is the resourcetype of a collection, with extra types
+func collectionType(extra string) webdav.Prop {
+	return webdav.Prop{Name: propResourceType, Value: webdav.Element(davName("collection"), "") + extra}
+}
+
+// principalLinks are the properties clients use to discover the principal
+// and its calendars from any collection
+func principalLinks(userID uuid.UUID, resourceType webdav.Prop) []webdav.Prop {
+	return []webdav.Prop{
+		resourceType,
+		{Name: propCurrentUser, Value: hrefElement(principalHref(userID))},
+		{Name: propPrincipalURL, Value: hrefElement(principalHref(userID))},
+		{Name: propCalendarHome, Value: hrefElement(homeHref(userID))},
+		{Name: propPrivileges, Value: privileges},
+	}
+}
+
+func calendarProps(userID uuid.UUID, calendar *models.CalDAVCalendar) []webdav.Prop {
+	reports := ""
+	for _, report := range []string{webdav.ReportCalendarQuery, webdav.ReportCalendarMultiget} {
+		reports += "<supported-report><report>" + webdav.Element(caldavName(report), "") + "</report></supported-report>"
+	}
+	return []webdav.Prop{
+		collectionType(webdav.Element(caldavName("calendar"), "")),
+		{Name: propDisplayName, Value: webdav.Escape(calendar.Name)},
+		{Name: propCurrentUser, Value: hrefElement(principalHref(userID))},
+		{Name: propPrivileges, Value: privileges},
+		{Name: propSupportedComponent, Value: `<comp name="VTODO"/>`},
+		{Name: propSupportedReports, Value: reports},
+		{Name: propCTag, Value: webdav.Escape(calendar.CTag)},
+	}
+}
+
+// objectProps are the properties of a todo; its data is only included when
+// asked for by name
+func objectProps(object *models.CalDAVObject, withData bool) []webdav.Prop {
+	props := []webdav.Prop{
+		{Name: propResourceType},
+		{Name: propETag, Value: webdav.Escape(object.ETag)},
+		{Name: propContentType, Value: objectContentType},
+		{Name: propPrivileges, Value: privileges},
+	}
+	if withData {
+		props = append(props, webdav.Prop{Name: propCalendarData, Value: webdav.Escape(object.Data)})
+	}
+	return props
+}
+
+// selectProps picks the properties a request asked for: all of them, their
+// names only, or the named ones, listing the unknown ones as not found
+func selectProps(href string, props []webdav.Prop, all, namesOnly bool, names []xml.Name) webdav.Response {
+	response := webdav.Response{Href: href}
+	switch {
+	case all:
+		response.Found = props
+	case namesOnly:
+		for _, p := range props {
+			response.Found = append(response.Found, webdav.Prop{Name: p.Name})
+		}
+	default:
+		for _, name := range names {
+			found := false
+			for _, p := range props {
+				if p.Name == name {
+					response.Found = append(response.Found, p)
+					found = true
+					break
+				}
+			}
+			if !found {
+				response.NotFound = append(response.NotFound, name)
+			}
+		}
+	}
+	return response
+}
+
+func hasProp(names []xml.Name, name xml.Name) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// queriesTodos reports whether a calendar-query's component filter can match
+// VTODOs; queries without one match everything
+func queriesTodos(components []string) bool {
+	return len(components) < 2 || components[1] == "VTODO"
+}
+
+// etagMatches reports whether an If-None-Match header lists etag
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+func writeMultistatus(c *gin.Context, responses []webdav.Response) {
+	var body bytes.Buffer
+	if err := webdav.WriteMultistatus(&body, responses); err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, "Failed to write response", err.Error())
+		return
+	}
+	c.Data(http.StatusMultiStatus, "application/xml; charset=utf-8", body.Bytes())
+}
+
+func isCalDAVNotFound(err error) bool {
+	msg := err.Error()
+	return msg == "calendar not found" || msg == "calendar object not found"
+}
+
+func sendCalDAVError(c *gin.Context, err error, message string) {
+	msg := err.Error()
+	switch {
+	case isCalDAVNotFound(err), msg == "user not found":
+		utils.SendErrorResponse(c, http.StatusNotFound, "Not found", msg)
+	case msg == "precondition failed":
+		utils.SendErrorResponse(c, http.StatusPreconditionFailed, "Precondition failed", msg)
+	case msg == "calendar object UID conflict", isWorkflowViolation(err):
+		utils.SendErrorResponse(c, http.StatusConflict, "Conflict", msg)
+	case strings.HasPrefix(msg, "unsupported calendar component"):
+		utils.SendErrorResponse(c, http.StatusForbidden, "Unsupported calendar component", msg)
+	case strings.Contains(msg, "request body too large"):
+		utils.SendErrorResponse(c, http.StatusRequestEntityTooLarge, "Calendar object too large", msg)
+	case strings.HasPrefix(msg, "invalid calendar object"), isInvalidTodoInput(err):
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid calendar object", msg)
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, message, msg)
+	}
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"todo-backend/internal/models"
+	"todo-backend/internal/service"
+	"todo-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CalendarHandler struct {
+	calendarService service.CalendarService
+}
+
+func NewCalendarHandler(calendarService service.CalendarService) *CalendarHandler {
+	return &CalendarHandler{
+		calendarService: calendarService,
+	}
+}
+
+// GetCalendarFeed godoc
+// @Summary Get the calendar feed URLs
+// @Description Get the secret iCalendar URLs of the user's todos with due dates, for all todos and for each list, turning the feed on if it's off. Anyone with a URL can read the feed.
+// @Tags calendar
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=models.CalendarFeedResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/calendar/feed [get]
+func (h *CalendarHandler) GetCalendarFeed(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	feed, err := h.calendarService.GetFeed(userID)
+	if err != nil {
+		sendCalendarError(c, err, "Failed to get calendar feed")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Calendar feed retrieved successfully", feed)
+}
+
+// RegenerateCalendarFeed godoc
+// @Summary Regenerate the calendar feed URLs
+// @Description Replace the secret of the calendar feed; the previous URLs stop working
+// @Tags calendar
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=models.CalendarFeedResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/calendar/feed/regenerate [post]
+func (h *CalendarHandler) RegenerateCalendarFeed(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	feed, err := h.calendarService.RegenerateFeed(userID)
+	if err != nil {
+		sendCalendarError(c, err, "Failed to regenerate calendar feed")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Calendar feed regenerated successfully", feed)
+}
+
+// DisableCalendarFeed godoc
+// @Summary Turn the calendar feed off
+// @Description Invalidate the calendar feed URLs until the feed is requested again
+// @Tags calendar
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/calendar/feed [delete]
+func (h *CalendarHandler) DisableCalendarFeed(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	if err := h.calendarService.DisableFeed(userID); err != nil {
+		sendCalendarError(c, err, "Failed to disable calendar feed")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Calendar feed disabled successfully", nil)
+}
+
+// GetCalendar godoc
+// @Summary Get the iCalendar feed
+// @Description Serve the todos with due dates of the user owning the token as an RFC 5545 calendar: a VEVENT at each due date, repeating for recurring todos, and a VTODO per todo. The secret token in the path authorizes the request.
+// @Tags calendar
+// @Produce text/calendar
+// @Param token path string true "Feed token followed by .ics"
+// @Param list_id query string false "Only todos in this list"
+// @Param component query string false "vevent or vtodo; both when omitted"
+// @Param completed query bool false "Include completed todos" default(true)
+// @Success 200 {string} string "iCalendar data"
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /calendar/{token}.ics [get]
+func (h *CalendarHandler) GetCalendar(c *gin.Context) {
+	token, ok := strings.CutSuffix(c.Param("file"), ".ics")
+	if !ok || token == "" {
+		utils.SendErrorResponse(c, http.StatusNotFound, "Calendar not found", "calendar not found")
+		return
+	}
+
+	query := models.CalendarFeedQuery{IncludeCompleted: true}
+	if listIDStr := c.Query("list_id"); listIDStr != "" {
+		listID, err := uuid.Parse(listIDStr)
+		if err != nil {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid list ID", "list_id must be a valid UUID")
+			return
+		}
+		query.ListID = &listID
+	}
+	query.Component = strings.ToLower(c.Query("component"))
+	if query.Component != "" && query.Component != models.CalendarComponentEvent && query.Component != models.CalendarComponentTodo {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid component", "component must be vevent or vtodo")
+		return
+	}
+	if completed := c.Query("completed"); completed != "" {
+		include, err := strconv.ParseBool(completed)
+		if err != nil {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid completed", "completed must be true or false")
+			return
+		}
+		query.IncludeCompleted = include
+	}
+
+	cal, err := h.calendarService.Calendar(token, &query)
+	if err != nil {
+		sendCalendarError(c, err, "Failed to build calendar")
+		return
+	}
+
+	c.Header("Content-Type", "text/calendar; charset=utf-8")
+	c.Header("Content-Disposition", `inline; filename="todos.ics"`)
+	c.Header("Cache-Control", "private, max-age=300")
+	c.Status(http.StatusOK)
+	if err := cal.Encode(c.Writer); err != nil {
+		_ = c.Error(err)
+	}
+}
+
+func sendCalendarError(c *gin.Context, err error, message string) {
+	msg := err.Error()
+	switch msg {
+	case "calendar not found":
+		utils.SendErrorResponse(c, http.StatusNotFound, "Calendar not found", msg)
+	case "list not found":
+		utils.SendErrorResponse(c, http.StatusNotFound, "List not found", msg)
+	case "user not found":
+		utils.SendErrorResponse(c, http.StatusNotFound, "User not found", msg)
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, message, msg)
+	}
+}
@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"todo-backend/internal/models"
+	"todo-backend/internal/service"
+	"todo-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CommentHandler struct {
+	commentService service.CommentService
+}
+
+func NewCommentHandler(commentService service.CommentService) *CommentHandler {
+	return &CommentHandler{
+		commentService: commentService,
+	}
+}
+
+// CreateComment godoc
+// @Summary Comment on a todo
+// @Description Add a comment to a todo's thread. Users mentioned as @email are recorded.
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Todo ID"
+// @Param comment body models.CommentCreateRequest true "Comment data"
+// @Success 201 {object} utils.Response{data=models.CommentResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 422 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/todos/{id}/comments [post]
+func (h *CommentHandler) CreateComment(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	todoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid todo ID", err.Error())
+		return
+	}
+
+	var req models.CommentCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	comment, err := h.commentService.Create(todoID, userID, &req)
+	if err != nil {
+		sendCommentError(c, err, "Failed to create comment")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Comment created successfully", comment.ToResponse())
+}
+
+// GetComments godoc
+// @Summary List the comments of a todo
+// @Description Get a todo's comment thread, oldest first, using cursor pagination
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Todo ID"
+// @Param cursor query string false "Cursor returned by the previous page"
+// @Param limit query int false "Number of comments per page" default(20)
+// @Success 200 {object} utils.CursorPaginatedResponse{data=[]models.CommentResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/todos/{id}/comments [get]
+func (h *CommentHandler) GetComments(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	todoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid todo ID", err.Error())
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	comments, nextCursor, err := h.commentService.List(todoID, userID, c.Query("cursor"), limit)
+	if err != nil {
+		sendCommentError(c, err, "Failed to get comments")
+		return
+	}
+
+	responses := make([]models.CommentResponse, 0, len(comments))
+	for _, comment := range comments {
+		responses = append(responses, comment.ToResponse())
+	}
+
+	pagination := utils.CursorPagination{
+		Limit:      limit,
+		NextCursor: nextCursor,
+		HasMore:    nextCursor != "",
+	}
+	utils.CursorPaginatedSuccessResponse(c, http.StatusOK, "Comments retrieved successfully", responses, pagination)
+}
+
+// UpdateComment godoc
+// @Summary Edit a comment
+// @Description Edit the body of a comment. Only its author may do this.
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Todo ID"
+// @Param comment_id path string true "Comment ID"
+// @Param comment body models.CommentUpdateRequest true "Comment data"
+// @Success 200 {object} utils.Response{data=models.CommentResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 422 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/todos/{id}/comments/{comment_id} [put]
+func (h *CommentHandler) UpdateComment(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	todoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid todo ID", err.Error())
+		return
+	}
+
+	commentID, err := uuid.Parse(c.Param("comment_id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid comment ID", err.Error())
+		return
+	}
+
+	var req models.CommentUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	comment, err := h.commentService.Update(commentID, todoID, userID, &req)
+	if err != nil {
+		sendCommentError(c, err, "Failed to update comment")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Comment updated successfully", comment.ToResponse())
+}
+
+// DeleteComment godoc
+// @Summary Delete a comment
+// @Description Soft-delete a comment. Only its author may do this.
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Todo ID"
+// @Param comment_id path string true "Comment ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/todos/{id}/comments/{comment_id} [delete]
+func (h *CommentHandler) DeleteComment(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	todoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid todo ID", err.Error())
+		return
+	}
+
+	commentID, err := uuid.Parse(c.Param("comment_id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid comment ID", err.Error())
+		return
+	}
+
+	if err := h.commentService.Delete(commentID, todoID, userID); err != nil {
+		sendCommentError(c, err, "Failed to delete comment")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Comment deleted successfully", nil)
+}
+
+func sendCommentError(c *gin.Context, err error, message string) {
+	switch err.Error() {
+	case "todo not found":
+		utils.SendErrorResponse(c, http.StatusNotFound, "Todo not found", err.Error())
+	case "comment not found":
+		utils.SendErrorResponse(c, http.StatusNotFound, "Comment not found", err.Error())
+	case "unauthorized to access this todo",
+		"only the author can edit this comment",
+		"only the author can delete this comment":
+		utils.SendErrorResponse(c, http.StatusForbidden, "Forbidden", err.Error())
+	case "invalid cursor":
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid cursor", err.Error())
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, message, err.Error())
+	}
+}
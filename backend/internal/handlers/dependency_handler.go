@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"net/http"
+	"todo-backend/internal/models"
+	"todo-backend/internal/service"
+	"todo-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type DependencyHandler struct {
+	dependencyService service.DependencyService
+}
+
+func NewDependencyHandler(dependencyService service.DependencyService) *DependencyHandler {
+	return &DependencyHandler{
+		dependencyService: dependencyService,
+	}
+}
+
+// GetDependencies godoc
+// @Summary List the dependencies of a todo
+// @Description Get the todos that must be done before this one can start
+// @Tags dependencies
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Todo ID"
+// @Success 200 {object} utils.Response{data=[]models.TodoResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/todos/{id}/dependencies [get]
+func (h *DependencyHandler) GetDependencies(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	todoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid todo ID", err.Error())
+		return
+	}
+
+	todos, err := h.dependencyService.List(todoID, userID)
+	if err != nil {
+		sendDependencyError(c, err, "Failed to get dependencies")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Dependencies retrieved successfully", toTodoResponses(todos))
+}
+
+// AddDependency godoc
+// @Summary Add a dependency to a todo
+// @Description Mark a todo as blocked by another todo. Edges that would create a cycle are rejected.
+// @Tags dependencies
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Todo ID"
+// @Param dependency body models.DependencyCreateRequest true "Dependency data"
+// @Success 201 {object} utils.Response{data=[]models.TodoResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 422 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/todos/{id}/dependencies [post]
+func (h *DependencyHandler) AddDependency(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	todoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid todo ID", err.Error())
+		return
+	}
+
+	var req models.DependencyCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	todos, err := h.dependencyService.Add(todoID, userID, req.DependsOnID)
+	if err != nil {
+		sendDependencyError(c, err, "Failed to add dependency")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Dependency added successfully", toTodoResponses(todos))
+}
+
+// RemoveDependency godoc
+// @Summary Remove a dependency from a todo
+// @Description Remove the edge between a todo and one of its dependencies
+// @Tags dependencies
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Todo ID"
+// @Param depends_on_id path string true "ID of the todo depended on"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/todos/{id}/dependencies/{depends_on_id} [delete]
+func (h *DependencyHandler) RemoveDependency(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	todoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid todo ID", err.Error())
+		return
+	}
+
+	dependsOnID, err := uuid.Parse(c.Param("depends_on_id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid dependency ID", err.Error())
+		return
+	}
+
+	if err := h.dependencyService.Remove(todoID, userID, dependsOnID); err != nil {
+		sendDependencyError(c, err, "Failed to remove dependency")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Dependency removed successfully", nil)
+}
+
+func sendDependencyError(c *gin.Context, err error, message string) {
+	switch err.Error() {
+	case "todo not found", "dependency todo not found", "dependency not found":
+		utils.SendErrorResponse(c, http.StatusNotFound, "Not found", err.Error())
+	case "unauthorized to access this todo", "dependencies must belong to the same user":
+		utils.SendErrorResponse(c, http.StatusForbidden, "Forbidden", err.Error())
+	case "todo cannot depend on itself":
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid dependency", err.Error())
+	case "dependency already exists", "dependency would create a cycle":
+		utils.SendErrorResponse(c, http.StatusConflict, "Invalid dependency", err.Error())
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, message, err.Error())
+	}
+}
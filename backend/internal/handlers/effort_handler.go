@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"todo-backend/internal/service"
+	"todo-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type EffortHandler struct {
+	effortService service.EffortService
+}
+
+func NewEffortHandler(effortService service.EffortService) *EffortHandler {
+	return &EffortHandler{
+		effortService: effortService,
+	}
+}
+
+// GetTodoEffort godoc
+// @Summary Get the effort of a subtask tree
+// @Description Sum the remaining and completed estimates of a todo and all of its subtasks, one row per estimate unit
+// @Tags effort
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Todo ID"
+// @Success 200 {object} utils.Response{data=[]models.EffortRollup}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/todos/{id}/effort [get]
+func (h *EffortHandler) GetTodoEffort(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	todoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid todo ID", err.Error())
+		return
+	}
+
+	rows, err := h.effortService.Subtree(todoID, userID)
+	if err != nil {
+		sendEffortError(c, err, "Failed to get effort")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Effort retrieved successfully", rows)
+}
+
+// GetEffortReport godoc
+// @Summary Get an effort rollup
+// @Description Sum the remaining and completed estimates of the user's todos by list or tag, split by estimate unit. A todo counts for each of its tags.
+// @Tags effort
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param group_by query string false "list or tag" default(list)
+// @Success 200 {object} utils.Response{data=models.EffortReport}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/reports/effort [get]
+func (h *EffortHandler) GetEffortReport(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	report, err := h.effortService.Report(userID, c.Query("group_by"))
+	if err != nil {
+		sendEffortError(c, err, "Failed to get effort report")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Effort report retrieved successfully", report)
+}
+
+// GetBurndown godoc
+// @Summary Get a burndown series
+// @Description Remaining and completed effort at the end of each day between from and to (inclusive dates in the user's timezone, default the last 7 days), rebuilt from the todos' history. Only todos estimated in one unit are counted: the list's unit when list_id is given, otherwise unit.
+// @Tags effort
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param from query string false "First day (YYYY-MM-DD)"
+// @Param to query string false "Last day (YYYY-MM-DD)"
+// @Param list_id query string false "Only todos in this list"
+// @Param tag query string false "Only todos with this tag"
+// @Param unit query string false "minutes or points" default(minutes)
+// @Success 200 {object} utils.Response{data=models.Burndown}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/reports/burndown [get]
+func (h *EffortHandler) GetBurndown(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	query := &service.BurndownQuery{
+		From: c.Query("from"),
+		To:   c.Query("to"),
+		Tag:  c.Query("tag"),
+		Unit: c.Query("unit"),
+	}
+	if raw := c.Query("list_id"); raw != "" {
+		listID, err := uuid.Parse(raw)
+		if err != nil {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid list ID", err.Error())
+			return
+		}
+		query.ListID = &listID
+	}
+
+	burndown, err := h.effortService.Burndown(userID, query)
+	if err != nil {
+		sendEffortError(c, err, "Failed to get burndown")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Burndown retrieved successfully", burndown)
+}
+
+func sendEffortError(c *gin.Context, err error, message string) {
+	msg := err.Error()
+	switch {
+	case msg == "todo not found":
+		utils.SendErrorResponse(c, http.StatusNotFound, "Todo not found", msg)
+	case msg == "list not found":
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid list ID", msg)
+	case msg == "unauthorized to access this todo":
+		utils.SendErrorResponse(c, http.StatusForbidden, "Forbidden", msg)
+	case strings.HasPrefix(msg, "invalid "):
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request", msg)
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, message, msg)
+	}
+}
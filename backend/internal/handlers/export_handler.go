@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"todo-backend/internal/models"
+	"todo-backend/internal/service"
+	"todo-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// exportPagingParams are listing parameters an export does not take; it
+// always streams every matching todo in creation order
+var exportPagingParams = []string{"page", "limit", "cursor", "include_total", "sort"}
+
+type ExportHandler struct {
+	exportService service.ExportService
+}
+
+func NewExportHandler(exportService service.ExportService) *ExportHandler {
+	return &ExportHandler{
+		exportService: exportService,
+	}
+}
+
+// Export godoc
+// @Summary Export todos
+// @Description Download the user's todos as JSON, CSV, Markdown or todo.txt, optionally only those of a list, tag or filter (the filter parameters of GET /todos, including q). The file is streamed as it is read. The JSON format is a versioned schema that POST /import reads back without loss: lists and their workflows, subtasks, tags, states, manual order, estimates and recurrence included. Exports are held to the limits of imports: more than 10000 todos are refused, and the download is cut off past 10MB.
+// @Tags export
+// @Produce json,text/csv,text/markdown,plain
+// @Security BearerAuth
+// @Param format query string false "Export format (default json)" Enums(json, csv, markdown, todotxt)
+// @Param list_id query []string false "Only todos of these lists" collectionFormat(multi)
+// @Param tag query []string false "Only todos with any of these tags" collectionFormat(multi)
+// @Param q query string false "Query language filter, e.g. \"priority>=3 due<7d\""
+// @Success 200 {file} file
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/export [get]
+func (h *ExportHandler) Export(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	values := c.Request.URL.Query()
+	format := strings.ToLower(values.Get("format"))
+	if format == "" {
+		format = models.ExportFormatJSON
+	}
+	values.Del("format")
+	file, ok := models.ExportFiles[format]
+	if !ok {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid export format", "format must be json, csv, markdown or todotxt")
+		return
+	}
+	for _, key := range exportPagingParams {
+		if values.Has(key) {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid query", "unknown query parameter: "+key)
+			return
+		}
+	}
+
+	filter, err := models.ParseTodoFilter(values)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid query", err.Error())
+		return
+	}
+
+	c.Header("Content-Type", file.ContentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="todos-%s.%s"`, time.Now().UTC().Format("2006-01-02"), file.Extension))
+	c.Status(http.StatusOK)
+
+	err = h.exportService.Export(userID, &models.ExportRequest{Format: format, Filter: filter}, c.Writer)
+	if err == nil {
+		return
+	}
+	if c.Writer.Written() {
+		// The status is sent; all that is left is to cut the download short
+		log.Error().Err(err).Str("user_id", userID.String()).Msg("Export failed while streaming")
+		c.Abort()
+		return
+	}
+	c.Writer.Header().Del("Content-Disposition")
+	sendExportError(c, err, "Failed to export todos")
+}
+
+func sendExportError(c *gin.Context, err error, message string) {
+	msg := err.Error()
+	switch {
+	case msg == "user not found":
+		utils.SendErrorResponse(c, http.StatusNotFound, "User not found", msg)
+	case strings.HasPrefix(msg, "invalid "):
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid export", msg)
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, message, msg)
+	}
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"todo-backend/internal/models"
+	"todo-backend/internal/service"
+	"todo-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ImportHandler struct {
+	importService service.ImportService
+}
+
+func NewImportHandler(importService service.ImportService) *ImportHandler {
+	return &ImportHandler{
+		importService: importService,
+	}
+}
+
+// Import godoc
+// @Summary Import todos from another app
+// @Description Import a todo.txt file, a Todoist CSV export, a Taskwarrior JSON export or a JSON export of this app, uploaded as the multipart field file or as the raw request body. The format is detected unless given. Projects become lists, which are matched to the user's lists by name or created; contexts, labels and tags become tags. With dry_run nothing is created and the response previews the first 100 todos. Imports of up to 200 todos complete before the response (201); larger ones run in the background (202) and are polled through their job. The report lists the fields the import leaves out.
+// @Tags import
+// @Accept multipart/form-data,plain,json
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file false "Export to import"
+// @Param format query string false "Format of the export" Enums(todotxt, todoist, taskwarrior, json)
+// @Param dry_run query bool false "Only preview the import"
+// @Success 200 {object} utils.Response{data=models.ImportPreview}
+// @Success 201 {object} utils.Response{data=models.ImportJob}
+// @Success 202 {object} utils.Response{data=models.ImportJob}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 413 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Failure 503 {object} utils.ErrorResponse
+// @Router /api/v1/import [post]
+func (h *ImportHandler) Import(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	req, err := readImportRequest(c)
+	if err != nil {
+		if strings.Contains(err.Error(), "request body too large") {
+			utils.SendErrorResponse(c, http.StatusRequestEntityTooLarge, "File too large", fmt.Sprintf("imports are at most %d bytes", models.ImportMaxBytes))
+			return
+		}
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid upload", err.Error())
+		return
+	}
+
+	if req.DryRun {
+		preview, err := h.importService.Preview(userID, req)
+		if err != nil {
+			sendImportError(c, err, "Failed to preview import")
+			return
+		}
+		utils.SuccessResponse(c, http.StatusOK, "Import previewed successfully", preview)
+		return
+	}
+
+	job, err := h.importService.Import(userID, req)
+	if err != nil {
+		sendImportError(c, err, "Failed to import")
+		return
+	}
+
+	if job.Status == models.ImportJobCompleted {
+		utils.SuccessResponse(c, http.StatusCreated, "Imported successfully", job)
+		return
+	}
+	c.Header("Location", "/api/v1/import/"+job.ID.String())
+	utils.SuccessResponse(c, http.StatusAccepted, "Import started", job)
+}
+
+// GetImportJob godoc
+// @Summary Get an import job
+// @Description Poll the progress of an import: processed and created count the todos handled so far out of total, and the report lists what the import left out
+// @Tags import
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Import job ID"
+// @Success 200 {object} utils.Response{data=models.ImportJob}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/import/{id} [get]
+func (h *ImportHandler) GetImportJob(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid import job ID", err.Error())
+		return
+	}
+
+	job, err := h.importService.GetJob(id, userID)
+	if err != nil {
+		sendImportError(c, err, "Failed to get import job")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Import job retrieved successfully", job)
+}
+
+// readImportRequest reads an export from the multipart field file or, for
+// any other content type, from the request body
+func readImportRequest(c *gin.Context) (*models.ImportRequest, error) {
+	req := &models.ImportRequest{Format: c.Query("format")}
+	req.DryRun, _ = strconv.ParseBool(c.Query("dry_run"))
+
+	var body io.Reader
+	if strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, models.ImportMaxBytes+multipartOverhead)
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			return nil, err
+		}
+		if fileHeader.Size > models.ImportMaxBytes {
+			return nil, errors.New("http: request body too large")
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		req.FileName = fileHeader.Filename
+		body = file
+	} else {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, models.ImportMaxBytes)
+		body = c.Request.Body
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	req.Data = data
+	return req, nil
+}
+
+func sendImportError(c *gin.Context, err error, message string) {
+	msg := err.Error()
+	switch {
+	case msg == "import job not found":
+		utils.SendErrorResponse(c, http.StatusNotFound, "Import job not found", msg)
+	case strings.HasPrefix(msg, "unauthorized"):
+		utils.SendErrorResponse(c, http.StatusForbidden, "Forbidden", msg)
+	case strings.HasPrefix(msg, "invalid "):
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid import", msg)
+	case msg == "too many background jobs, try again later":
+		utils.SendErrorResponse(c, http.StatusServiceUnavailable, message, msg)
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, message, msg)
+	}
+}
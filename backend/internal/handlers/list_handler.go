@@ -0,0 +1,424 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"todo-backend/internal/models"
+	"todo-backend/internal/service"
+	"todo-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ListHandler struct {
+	listService service.ListService
+}
+
+func NewListHandler(listService service.ListService) *ListHandler {
+	return &ListHandler{
+		listService: listService,
+	}
+}
+
+// CreateList godoc
+// @Summary Create a list
+// @Description Create a list to group todos
+// @Tags lists
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param list body models.ListCreateRequest true "List data"
+// @Success 201 {object} utils.Response{data=models.ListResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 422 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/lists [post]
+func (h *ListHandler) CreateList(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	var req models.ListCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	list, err := h.listService.Create(userID, &req)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, "Failed to create list", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "List created successfully", list.ToResponse())
+}
+
+// GetLists godoc
+// @Summary Get lists for user
+// @Description Get the lists of the authenticated user ordered by name
+// @Tags lists
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=[]models.ListResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/lists [get]
+func (h *ListHandler) GetLists(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	lists, err := h.listService.GetByUserID(userID)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get lists", err.Error())
+		return
+	}
+
+	responses := make([]models.ListResponse, 0, len(lists))
+	for _, list := range lists {
+		responses = append(responses, list.ToResponse())
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Lists retrieved successfully", responses)
+}
+
+// UpdateList godoc
+// @Summary Update a list
+// @Description Rename a list
+// @Tags lists
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "List ID"
+// @Param list body models.ListUpdateRequest true "List data"
+// @Success 200 {object} utils.Response{data=models.ListResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 422 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/lists/{id} [put]
+func (h *ListHandler) UpdateList(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid list ID", err.Error())
+		return
+	}
+
+	var req models.ListUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	list, err := h.listService.Update(id, userID, &req)
+	if err != nil {
+		sendListError(c, err, "Failed to update list")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "List updated successfully", list.ToResponse())
+}
+
+// DeleteList godoc
+// @Summary Delete a list
+// @Description Delete a list. Its todos are kept and moved out of the list.
+// @Tags lists
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "List ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/lists/{id} [delete]
+func (h *ListHandler) DeleteList(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid list ID", err.Error())
+		return
+	}
+
+	if err := h.listService.Delete(id, userID); err != nil {
+		sendListError(c, err, "Failed to delete list")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "List deleted successfully", nil)
+}
+
+// GetTags godoc
+// @Summary Get tags for user
+// @Description Get the tags the authenticated user has put on todos
+// @Tags lists
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=[]string}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/tags [get]
+func (h *ListHandler) GetTags(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	tags, err := h.listService.GetTags(userID)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get tags", err.Error())
+		return
+	}
+
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Tags retrieved successfully", names)
+}
+
+// GetWorkflow godoc
+// @Summary Get a list's workflow
+// @Description Get the states of a list's board in order. Lists without states of their own use the default pending, in_progress and completed states.
+// @Tags lists
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "List ID"
+// @Success 200 {object} utils.Response{data=models.WorkflowResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/lists/{id}/workflow [get]
+func (h *ListHandler) GetWorkflow(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid list ID", err.Error())
+		return
+	}
+
+	states, custom, err := h.listService.GetWorkflow(id, userID)
+	if err != nil {
+		sendListError(c, err, "Failed to get workflow")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Workflow retrieved successfully", toWorkflowResponse(id, states, custom))
+}
+
+// SetWorkflow godoc
+// @Summary Set a list's workflow
+// @Description Replace the states of a list's board. Each state maps to a category (todo, doing or done) that sets the status of its todos, may limit the states it moves to with transitions and caps its todos with wip_limit (0 for none). Todos in removed states move to the first state of their category.
+// @Tags lists
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "List ID"
+// @Param workflow body models.WorkflowRequest true "Workflow states in board order"
+// @Success 200 {object} utils.Response{data=models.WorkflowResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 422 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/lists/{id}/workflow [put]
+func (h *ListHandler) SetWorkflow(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid list ID", err.Error())
+		return
+	}
+
+	var req models.WorkflowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	states, err := h.listService.SetWorkflow(id, userID, &req)
+	if err != nil {
+		sendListError(c, err, "Failed to set workflow")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Workflow updated successfully", toWorkflowResponse(id, states, true))
+}
+
+// ResetWorkflow godoc
+// @Summary Reset a list's workflow
+// @Description Put a list back on the default pending, in_progress and completed states. Todos keep their status.
+// @Tags lists
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "List ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/lists/{id}/workflow [delete]
+func (h *ListHandler) ResetWorkflow(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid list ID", err.Error())
+		return
+	}
+
+	if err := h.listService.ResetWorkflow(id, userID); err != nil {
+		sendListError(c, err, "Failed to reset workflow")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Workflow reset successfully", nil)
+}
+
+// GetBoard godoc
+// @Summary Get a list's board
+// @Description Get one column per workflow state with its number of todos, WIP limit and first cards in manual order. Move cards with POST /todos/{id}/move and a state.
+// @Tags lists
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "List ID"
+// @Param limit query int false "Cards per column (max 100)" default(50)
+// @Success 200 {object} utils.Response{data=models.BoardResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/lists/{id}/board [get]
+func (h *ListHandler) GetBoard(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid list ID", err.Error())
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if limit < 1 || limit > 100 {
+		limit = 50
+	}
+
+	board, err := h.listService.Board(id, userID, limit)
+	if err != nil {
+		sendListError(c, err, "Failed to get board")
+		return
+	}
+
+	resp := models.BoardResponse{
+		List:    board.List.ToResponse(),
+		Custom:  board.Custom,
+		Columns: make([]models.BoardColumn, 0, len(board.Workflow)),
+	}
+	for i := range board.Workflow {
+		state := &board.Workflow[i]
+		count := board.Counts[state.Key]
+		resp.Columns = append(resp.Columns, models.BoardColumn{
+			WorkflowStateResponse: state.ToResponse(),
+			Count:                 count,
+			OverLimit:             state.WIPLimit > 0 && count > int64(state.WIPLimit),
+			Cards:                 toTodoResponses(board.Cards[state.Key]),
+		})
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Board retrieved successfully", resp)
+}
+
+func toWorkflowResponse(listID uuid.UUID, states models.Workflow, custom bool) models.WorkflowResponse {
+	resp := models.WorkflowResponse{
+		ListID: listID,
+		Custom: custom,
+		States: make([]models.WorkflowStateResponse, 0, len(states)),
+	}
+	for i := range states {
+		resp.States = append(resp.States, states[i].ToResponse())
+	}
+	return resp
+}
+
+func sendListError(c *gin.Context, err error, message string) {
+	if strings.HasPrefix(err.Error(), "invalid workflow") {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid workflow", err.Error())
+		return
+	}
+
+	switch err.Error() {
+	case "list not found":
+		utils.SendErrorResponse(c, http.StatusNotFound, "List not found", err.Error())
+	case "unauthorized to access this list":
+		utils.SendErrorResponse(c, http.StatusForbidden, "Forbidden", err.Error())
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, message, err.Error())
+	}
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"todo-backend/internal/models"
+	"todo-backend/internal/service"
+	"todo-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type QuickAddHandler struct {
+	quickAddService service.QuickAddService
+	todoService     service.TodoService
+}
+
+func NewQuickAddHandler(quickAddService service.QuickAddService, todoService service.TodoService) *QuickAddHandler {
+	return &QuickAddHandler{
+		quickAddService: quickAddService,
+		todoService:     todoService,
+	}
+}
+
+// QuickAddTodo godoc
+// @Summary Quick-add a todo from free text
+// @Description Parse free text such as "Pay rent tomorrow 9am !3 #home @Personal" into a todo and create it. Dates and times are in the user's timezone, !0 to !5 set the priority, #words are tags and @names match the user's lists. The response lists the recognized tokens with their character offsets; with dry_run nothing is created.
+// @Tags todos
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param todo body models.QuickAddRequest true "Quick-add text"
+// @Param dry_run query bool false "Only parse the text"
+// @Success 200 {object} utils.Response{data=models.QuickAddResponse}
+// @Success 201 {object} utils.Response{data=models.QuickAddResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 422 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/todos/quick [post]
+func (h *QuickAddHandler) QuickAddTodo(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	var req models.QuickAddRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	result, err := h.quickAddService.Parse(userID, &req)
+	if err != nil {
+		sendQuickAddError(c, err, "Failed to parse todo")
+		return
+	}
+	if err := utils.ValidateStruct(&result.Request); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	if dryRun, _ := strconv.ParseBool(c.Query("dry_run")); dryRun {
+		utils.SuccessResponse(c, http.StatusOK, "Todo parsed successfully", result.ToResponse(nil))
+		return
+	}
+
+	todo, err := h.todoService.ForClient(clientIDFromContext(c)).Create(userID, &result.Request)
+	if err != nil {
+		sendQuickAddError(c, err, "Failed to create todo")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Todo created successfully", result.ToResponse(todo))
+}
+
+func sendQuickAddError(c *gin.Context, err error, message string) {
+	msg := err.Error()
+	switch {
+	case msg == "user not found":
+		utils.SendErrorResponse(c, http.StatusNotFound, "User not found", msg)
+	case isWorkflowViolation(err):
+		utils.SendErrorResponse(c, http.StatusConflict, "Workflow violation", msg)
+	case strings.HasPrefix(msg, "invalid quick add"), isInvalidTodoInput(err):
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid todo", msg)
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, message, msg)
+	}
+}
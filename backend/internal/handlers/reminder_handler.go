@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"net/http"
+	"todo-backend/internal/models"
+	"todo-backend/internal/service"
+	"todo-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ReminderHandler struct {
+	reminderService service.ReminderService
+}
+
+func NewReminderHandler(reminderService service.ReminderService) *ReminderHandler {
+	return &ReminderHandler{
+		reminderService: reminderService,
+	}
+}
+
+// CreateReminder godoc
+// @Summary Add a reminder to a todo
+// @Description Schedule a reminder at an absolute time or a number of minutes before the todo's due date
+// @Tags reminders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Todo ID"
+// @Param reminder body models.ReminderCreateRequest true "Reminder data"
+// @Success 201 {object} utils.Response{data=models.ReminderResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 422 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/todos/{id}/reminders [post]
+func (h *ReminderHandler) CreateReminder(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	todoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid todo ID", err.Error())
+		return
+	}
+
+	var req models.ReminderCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	reminder, err := h.reminderService.Create(todoID, userID, &req)
+	if err != nil {
+		switch err.Error() {
+		case "todo not found":
+			utils.SendErrorResponse(c, http.StatusNotFound, "Todo not found", err.Error())
+		case "unauthorized to access this todo":
+			utils.SendErrorResponse(c, http.StatusForbidden, "Forbidden", err.Error())
+		case "reminder requires exactly one of remind_at or offset_minutes",
+			"offset reminders require the todo to have a due date",
+			"reminder channel is not configured":
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid reminder", err.Error())
+		default:
+			utils.SendErrorResponse(c, http.StatusInternalServerError, "Failed to create reminder", err.Error())
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Reminder created successfully", reminder.ToResponse())
+}
+
+// GetReminders godoc
+// @Summary List reminders of a todo
+// @Description Get all reminders scheduled for a todo
+// @Tags reminders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Todo ID"
+// @Success 200 {object} utils.Response{data=[]models.ReminderResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/todos/{id}/reminders [get]
+func (h *ReminderHandler) GetReminders(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	todoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid todo ID", err.Error())
+		return
+	}
+
+	reminders, err := h.reminderService.GetByTodoID(todoID, userID)
+	if err != nil {
+		switch err.Error() {
+		case "todo not found":
+			utils.SendErrorResponse(c, http.StatusNotFound, "Todo not found", err.Error())
+		case "unauthorized to access this todo":
+			utils.SendErrorResponse(c, http.StatusForbidden, "Forbidden", err.Error())
+		default:
+			utils.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get reminders", err.Error())
+		}
+		return
+	}
+
+	responses := make([]models.ReminderResponse, 0, len(reminders))
+	for _, reminder := range reminders {
+		responses = append(responses, reminder.ToResponse())
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Reminders retrieved successfully", responses)
+}
+
+// DeleteReminder godoc
+// @Summary Delete a reminder
+// @Description Remove a reminder from a todo
+// @Tags reminders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Todo ID"
+// @Param reminder_id path string true "Reminder ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/todos/{id}/reminders/{reminder_id} [delete]
+func (h *ReminderHandler) DeleteReminder(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	todoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid todo ID", err.Error())
+		return
+	}
+
+	reminderID, err := uuid.Parse(c.Param("reminder_id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid reminder ID", err.Error())
+		return
+	}
+
+	if err := h.reminderService.Delete(reminderID, todoID, userID); err != nil {
+		switch err.Error() {
+		case "todo not found":
+			utils.SendErrorResponse(c, http.StatusNotFound, "Todo not found", err.Error())
+		case "reminder not found":
+			utils.SendErrorResponse(c, http.StatusNotFound, "Reminder not found", err.Error())
+		case "unauthorized to access this todo":
+			utils.SendErrorResponse(c, http.StatusForbidden, "Forbidden", err.Error())
+		default:
+			utils.SendErrorResponse(c, http.StatusInternalServerError, "Failed to delete reminder", err.Error())
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Reminder deleted successfully", nil)
+}
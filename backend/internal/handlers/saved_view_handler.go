@@ -0,0 +1,283 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"todo-backend/internal/models"
+	"todo-backend/internal/service"
+	"todo-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type SavedViewHandler struct {
+	viewService service.SavedViewService
+}
+
+func NewSavedViewHandler(viewService service.SavedViewService) *SavedViewHandler {
+	return &SavedViewHandler{
+		viewService: viewService,
+	}
+}
+
+// CreateView godoc
+// @Summary Save a view
+// @Description Save a named todo query (smart list), e.g. "status:pending priority>=3 due<7d tag:work -tag:someday"
+// @Tags views
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param view body models.SavedViewCreateRequest true "View data"
+// @Success 201 {object} utils.Response{data=models.SavedViewResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 422 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/views [post]
+func (h *SavedViewHandler) CreateView(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	var req models.SavedViewCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	view, err := h.viewService.Create(userID, &req)
+	if err != nil {
+		sendSavedViewError(c, err, "Failed to create view")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "View created successfully", view.ToResponse())
+}
+
+// GetViews godoc
+// @Summary Get saved views
+// @Description Get the saved views of the authenticated user ordered by name
+// @Tags views
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=[]models.SavedViewResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/views [get]
+func (h *SavedViewHandler) GetViews(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	views, err := h.viewService.GetByUserID(userID)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get views", err.Error())
+		return
+	}
+
+	responses := make([]models.SavedViewResponse, 0, len(views))
+	for _, view := range views {
+		responses = append(responses, view.ToResponse())
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Views retrieved successfully", responses)
+}
+
+// GetView godoc
+// @Summary Get a saved view
+// @Description Get a saved view by its ID
+// @Tags views
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "View ID"
+// @Success 200 {object} utils.Response{data=models.SavedViewResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/views/{id} [get]
+func (h *SavedViewHandler) GetView(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid view ID", err.Error())
+		return
+	}
+
+	view, err := h.viewService.GetByID(id, userID)
+	if err != nil {
+		sendSavedViewError(c, err, "Failed to get view")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "View retrieved successfully", view.ToResponse())
+}
+
+// UpdateView godoc
+// @Summary Update a saved view
+// @Description Rename a saved view or change its query or sort order
+// @Tags views
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "View ID"
+// @Param view body models.SavedViewUpdateRequest true "View data"
+// @Success 200 {object} utils.Response{data=models.SavedViewResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 422 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/views/{id} [put]
+func (h *SavedViewHandler) UpdateView(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid view ID", err.Error())
+		return
+	}
+
+	var req models.SavedViewUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	view, err := h.viewService.Update(id, userID, &req)
+	if err != nil {
+		sendSavedViewError(c, err, "Failed to update view")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "View updated successfully", view.ToResponse())
+}
+
+// DeleteView godoc
+// @Summary Delete a saved view
+// @Description Delete a saved view. The todos it matches are not affected.
+// @Tags views
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "View ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/views/{id} [delete]
+func (h *SavedViewHandler) DeleteView(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid view ID", err.Error())
+		return
+	}
+
+	if err := h.viewService.Delete(id, userID); err != nil {
+		sendSavedViewError(c, err, "Failed to delete view")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "View deleted successfully", nil)
+}
+
+// GetViewTodos godoc
+// @Summary Get the todos of a saved view
+// @Description Run a saved view's query and get a page of matching todos
+// @Tags views
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "View ID"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of items per page" default(10)
+// @Success 200 {object} utils.PaginatedResponse{data=[]models.TodoResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/views/{id}/todos [get]
+func (h *SavedViewHandler) GetViewTodos(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid view ID", err.Error())
+		return
+	}
+
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	todos, total, err := h.viewService.Results(id, userID, page, limit)
+	if err != nil {
+		sendSavedViewError(c, err, "Failed to get todos")
+		return
+	}
+
+	pagination := utils.CalculatePagination(page, limit, int(total))
+	utils.PaginatedSuccessResponse(c, http.StatusOK, "Todos retrieved successfully", toTodoResponses(todos), pagination)
+}
+
+func sendSavedViewError(c *gin.Context, err error, message string) {
+	msg := err.Error()
+	switch {
+	case msg == "view not found":
+		utils.SendErrorResponse(c, http.StatusNotFound, "View not found", msg)
+	case msg == "unauthorized to access this view":
+		utils.SendErrorResponse(c, http.StatusForbidden, "Forbidden", msg)
+	case strings.HasPrefix(msg, "invalid query"), strings.HasPrefix(msg, "invalid sort"):
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid view", msg)
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, message, msg)
+	}
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"todo-backend/internal/models"
+	"todo-backend/internal/service"
+	"todo-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SearchHandler struct {
+	searchService service.SearchService
+}
+
+func NewSearchHandler(searchService service.SearchService) *SearchHandler {
+	return &SearchHandler{
+		searchService: searchService,
+	}
+}
+
+// SearchTodos godoc
+// @Summary Search todos
+// @Description Full-text search over the titles and descriptions of the authenticated user's todos. Every word must match and may be a prefix ("inv" finds "invoice"). Results are ranked by relevance and carry a snippet with matches wrapped in <mark> tags.
+// @Tags todos
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param q query string true "Search text"
+// @Param limit query int false "Maximum number of results" default(20)
+// @Success 200 {object} utils.Response{data=[]models.TodoSearchResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/todos/search [get]
+func (h *SearchHandler) SearchTodos(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	results, err := h.searchService.Search(userID, c.Query("q"), limit)
+	if err != nil {
+		switch err.Error() {
+		case "search query is required", "search query is too long":
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid search query", err.Error())
+		default:
+			utils.SendErrorResponse(c, http.StatusInternalServerError, "Failed to search todos", err.Error())
+		}
+		return
+	}
+
+	responses := make([]models.TodoSearchResponse, 0, len(results))
+	for _, result := range results {
+		responses = append(responses, models.TodoSearchResponse{
+			TodoResponse: result.Todo.ToResponse(),
+			Rank:         result.Rank,
+			Snippet:      result.Snippet,
+		})
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Todos retrieved successfully", responses)
+}
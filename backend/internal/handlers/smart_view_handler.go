@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"todo-backend/internal/models"
+	"todo-backend/internal/service"
+	"todo-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type SmartViewHandler struct {
+	smartViewService service.SmartViewService
+}
+
+func NewSmartViewHandler(smartViewService service.SmartViewService) *SmartViewHandler {
+	return &SmartViewHandler{
+		smartViewService: smartViewService,
+	}
+}
+
+// GetToday godoc
+// @Summary Get today's todos
+// @Description Get the open todos due today in the user's timezone
+// @Tags views
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of items per page" default(10)
+// @Success 200 {object} utils.PaginatedResponse{data=[]models.TodoResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/todos/views/today [get]
+func (h *SmartViewHandler) GetToday(c *gin.Context) {
+	h.handleBucket(c, h.smartViewService.Today)
+}
+
+// GetOverdue godoc
+// @Summary Get overdue todos
+// @Description Get the open todos due before today in the user's timezone
+// @Tags views
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of items per page" default(10)
+// @Success 200 {object} utils.PaginatedResponse{data=[]models.TodoResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/todos/views/overdue [get]
+func (h *SmartViewHandler) GetOverdue(c *gin.Context) {
+	h.handleBucket(c, h.smartViewService.Overdue)
+}
+
+// GetSomeday godoc
+// @Summary Get someday todos
+// @Description Get the open todos without a due date, most important first
+// @Tags views
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of items per page" default(10)
+// @Success 200 {object} utils.PaginatedResponse{data=[]models.TodoResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/todos/views/someday [get]
+func (h *SmartViewHandler) GetSomeday(c *gin.Context) {
+	h.handleBucket(c, h.smartViewService.Someday)
+}
+
+// GetUpcoming godoc
+// @Summary Get upcoming todos
+// @Description Get the open todos due from tomorrow on, grouped by day in the user's timezone. By default the view runs to the end of next week, following the user's week start.
+// @Tags views
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param days query int false "Number of days to cover instead of the rest of this and next week (1-90)"
+// @Success 200 {object} utils.Response{data=models.UpcomingResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/todos/views/upcoming [get]
+func (h *SmartViewHandler) GetUpcoming(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	days := 0
+	if value := c.Query("days"); value != "" {
+		days, err = strconv.Atoi(value)
+		if err != nil || days < 1 || days > 90 {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid days", "days must be an integer between 1 and 90")
+			return
+		}
+	}
+
+	groups, truncated, err := h.smartViewService.Upcoming(userID, days)
+	if err != nil {
+		sendSmartViewError(c, err)
+		return
+	}
+
+	response := models.UpcomingResponse{
+		Days:      make([]models.TodoDayResponse, 0, len(groups)),
+		Truncated: truncated,
+	}
+	for _, group := range groups {
+		response.Days = append(response.Days, models.TodoDayResponse{
+			Date:    group.Date.Format("2006-01-02"),
+			Weekday: group.Date.Weekday().String(),
+			Todos:   toTodoResponses(group.Todos),
+		})
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Todos retrieved successfully", response)
+}
+
+func (h *SmartViewHandler) handleBucket(c *gin.Context, bucket func(userID uuid.UUID, page, limit int) ([]models.Todo, int64, error)) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	todos, total, err := bucket(userID, page, limit)
+	if err != nil {
+		sendSmartViewError(c, err)
+		return
+	}
+
+	pagination := utils.CalculatePagination(page, limit, int(total))
+	utils.PaginatedSuccessResponse(c, http.StatusOK, "Todos retrieved successfully", toTodoResponses(todos), pagination)
+}
+
+func sendSmartViewError(c *gin.Context, err error) {
+	if err.Error() == "user not found" {
+		utils.SendErrorResponse(c, http.StatusNotFound, "User not found", err.Error())
+		return
+	}
+	utils.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get todos", err.Error())
+}
@@ -0,0 +1,367 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"todo-backend/internal/models"
+	"todo-backend/internal/service"
+	"todo-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type TemplateHandler struct {
+	templateService service.TemplateService
+}
+
+func NewTemplateHandler(templateService service.TemplateService) *TemplateHandler {
+	return &TemplateHandler{
+		templateService: templateService,
+	}
+}
+
+// CreateTemplate godoc
+// @Summary Create a template
+// @Description Save a reusable todo tree, e.g. a release checklist. Items take subtasks, tags and a due date relative to the anchor date given when instantiating.
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param template body models.TemplateCreateRequest true "Template data"
+// @Success 201 {object} utils.Response{data=models.TemplateResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 422 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/templates [post]
+func (h *TemplateHandler) CreateTemplate(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	var req models.TemplateCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	template, err := h.templateService.Create(userID, &req)
+	if err != nil {
+		sendTemplateError(c, err, "Failed to create template")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Template created successfully", template.ToResponse())
+}
+
+// GetTemplates godoc
+// @Summary Get templates
+// @Description Get the templates of the authenticated user ordered by name
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=[]models.TemplateResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/templates [get]
+func (h *TemplateHandler) GetTemplates(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	templates, err := h.templateService.GetByUserID(userID)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get templates", err.Error())
+		return
+	}
+
+	responses := make([]models.TemplateResponse, 0, len(templates))
+	for _, template := range templates {
+		responses = append(responses, template.ToResponse())
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Templates retrieved successfully", responses)
+}
+
+// GetTemplate godoc
+// @Summary Get a template
+// @Description Get a template by its ID
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Template ID"
+// @Success 200 {object} utils.Response{data=models.TemplateResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/templates/{id} [get]
+func (h *TemplateHandler) GetTemplate(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid template ID", err.Error())
+		return
+	}
+
+	template, err := h.templateService.GetByID(id, userID)
+	if err != nil {
+		sendTemplateError(c, err, "Failed to get template")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Template retrieved successfully", template.ToResponse())
+}
+
+// UpdateTemplate godoc
+// @Summary Update a template
+// @Description Rename a template, change its description or replace its items
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Template ID"
+// @Param template body models.TemplateUpdateRequest true "Template data"
+// @Success 200 {object} utils.Response{data=models.TemplateResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 422 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/templates/{id} [put]
+func (h *TemplateHandler) UpdateTemplate(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid template ID", err.Error())
+		return
+	}
+
+	var req models.TemplateUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	template, err := h.templateService.Update(id, userID, &req)
+	if err != nil {
+		sendTemplateError(c, err, "Failed to update template")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Template updated successfully", template.ToResponse())
+}
+
+// DeleteTemplate godoc
+// @Summary Delete a template
+// @Description Delete a template. Todos created from it are not affected.
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Template ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/templates/{id} [delete]
+func (h *TemplateHandler) DeleteTemplate(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid template ID", err.Error())
+		return
+	}
+
+	if err := h.templateService.Delete(id, userID); err != nil {
+		sendTemplateError(c, err, "Failed to delete template")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Template deleted successfully", nil)
+}
+
+// InstantiateTemplate godoc
+// @Summary Instantiate a template
+// @Description Create the template's todos and subtasks in a list (the inbox without list_id), with due dates counted from anchor_date (default today). The todos are created as one operation that a single undo takes back.
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Template ID"
+// @Param instantiate body models.TemplateInstantiateRequest false "Target list and anchor date"
+// @Success 201 {object} utils.Response{data=[]models.TodoResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 422 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/templates/{id}/instantiate [post]
+func (h *TemplateHandler) InstantiateTemplate(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid template ID", err.Error())
+		return
+	}
+
+	// The body is optional
+	var req models.TemplateInstantiateRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	todos, err := h.templateService.Instantiate(id, userID, &req)
+	if err != nil {
+		sendTemplateError(c, err, "Failed to instantiate template")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Template instantiated successfully", toTodoResponses(todos))
+}
+
+// SaveTodoAsTemplate godoc
+// @Summary Save a todo as a template
+// @Description Save a todo and its subtasks as a template. Due dates become offsets from the earliest due day.
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Todo ID"
+// @Param template body models.TemplateSaveRequest false "Template name and description"
+// @Success 201 {object} utils.Response{data=models.TemplateResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 422 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/todos/{id}/template [post]
+func (h *TemplateHandler) SaveTodoAsTemplate(c *gin.Context) {
+	h.saveAsTemplate(c, "Invalid todo ID", h.templateService.FromTodo)
+}
+
+// SaveListAsTemplate godoc
+// @Summary Save a list as a template
+// @Description Save the todos of a list, with their subtasks, as a template. Due dates become offsets from the earliest due day.
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "List ID"
+// @Param template body models.TemplateSaveRequest false "Template name and description"
+// @Success 201 {object} utils.Response{data=models.TemplateResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 422 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/lists/{id}/template [post]
+func (h *TemplateHandler) SaveListAsTemplate(c *gin.Context) {
+	h.saveAsTemplate(c, "Invalid list ID", h.templateService.FromList)
+}
+
+func (h *TemplateHandler) saveAsTemplate(c *gin.Context, invalidID string, save func(id uuid.UUID, userID uuid.UUID, req *models.TemplateSaveRequest) (*models.Template, error)) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, invalidID, err.Error())
+		return
+	}
+
+	// The body is optional
+	var req models.TemplateSaveRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	template, err := save(id, userID, &req)
+	if err != nil {
+		sendTemplateError(c, err, "Failed to save template")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Template created successfully", template.ToResponse())
+}
+
+func sendTemplateError(c *gin.Context, err error, message string) {
+	msg := err.Error()
+	switch {
+	case msg == "template not found":
+		utils.SendErrorResponse(c, http.StatusNotFound, "Template not found", msg)
+	case msg == "todo not found":
+		utils.SendErrorResponse(c, http.StatusNotFound, "Todo not found", msg)
+	case msg == "list not found":
+		utils.SendErrorResponse(c, http.StatusNotFound, "List not found", msg)
+	case strings.HasPrefix(msg, "unauthorized"):
+		utils.SendErrorResponse(c, http.StatusForbidden, "Forbidden", msg)
+	case isWorkflowViolation(err):
+		utils.SendErrorResponse(c, http.StatusConflict, "Workflow violation", msg)
+	case strings.HasPrefix(msg, "invalid "):
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid template", msg)
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, message, msg)
+	}
+}
@@ -0,0 +1,423 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"todo-backend/internal/models"
+	"todo-backend/internal/service"
+	"todo-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type TimeEntryHandler struct {
+	timeEntryService service.TimeEntryService
+}
+
+func NewTimeEntryHandler(timeEntryService service.TimeEntryService) *TimeEntryHandler {
+	return &TimeEntryHandler{
+		timeEntryService: timeEntryService,
+	}
+}
+
+// StartTimer godoc
+// @Summary Start a timer on a todo
+// @Description Start tracking time on a todo. Each user has one running timer: a timer running on another todo is stopped and returned as stopped.
+// @Tags time
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Todo ID"
+// @Param timer body models.TimerStartRequest false "Note for the time entry"
+// @Success 201 {object} utils.Response{data=models.TimerResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 422 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/todos/{id}/timer/start [post]
+func (h *TimeEntryHandler) StartTimer(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	todoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid todo ID", err.Error())
+		return
+	}
+
+	// The body is optional
+	var req models.TimerStartRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	entry, stopped, err := h.timeEntryService.StartTimer(todoID, userID, &req)
+	if err != nil {
+		sendTimeEntryError(c, err, "Failed to start timer")
+		return
+	}
+
+	resp := models.TimerResponse{Entry: entry.ToResponse()}
+	if stopped != nil {
+		stoppedResp := stopped.ToResponse()
+		resp.Stopped = &stoppedResp
+	}
+	utils.SuccessResponse(c, http.StatusCreated, "Timer started successfully", resp)
+}
+
+// StopTimer godoc
+// @Summary Stop the timer on a todo
+// @Description Stop the timer running on a todo and log its duration
+// @Tags time
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Todo ID"
+// @Success 200 {object} utils.Response{data=models.TimeEntryResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/todos/{id}/timer/stop [post]
+func (h *TimeEntryHandler) StopTimer(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	todoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid todo ID", err.Error())
+		return
+	}
+
+	entry, err := h.timeEntryService.StopTimer(todoID, userID)
+	if err != nil {
+		sendTimeEntryError(c, err, "Failed to stop timer")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Timer stopped successfully", entry.ToResponse())
+}
+
+// GetRunningTimer godoc
+// @Summary Get the running timer
+// @Description Get the authenticated user's running timer; data is null when none is running
+// @Tags time
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=models.TimeEntryResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/timer [get]
+func (h *TimeEntryHandler) GetRunningTimer(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	entry, err := h.timeEntryService.RunningTimer(userID)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get timer", err.Error())
+		return
+	}
+
+	if entry == nil {
+		utils.SuccessResponse(c, http.StatusOK, "No timer is running", nil)
+		return
+	}
+	utils.SuccessResponse(c, http.StatusOK, "Timer retrieved successfully", entry.ToResponse())
+}
+
+// CreateTimeEntry godoc
+// @Summary Log time on a todo
+// @Description Add a finished time entry to a todo after the fact
+// @Tags time
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Todo ID"
+// @Param entry body models.TimeEntryCreateRequest true "Time entry"
+// @Success 201 {object} utils.Response{data=models.TimeEntryResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 422 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/todos/{id}/time-entries [post]
+func (h *TimeEntryHandler) CreateTimeEntry(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	todoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid todo ID", err.Error())
+		return
+	}
+
+	var req models.TimeEntryCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	entry, err := h.timeEntryService.Create(todoID, userID, &req)
+	if err != nil {
+		sendTimeEntryError(c, err, "Failed to create time entry")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Time entry created successfully", entry.ToResponse())
+}
+
+// GetTimeEntries godoc
+// @Summary Get a todo's time entries
+// @Description Get the time logged on a todo, latest first
+// @Tags time
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Todo ID"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of items per page" default(10)
+// @Success 200 {object} utils.PaginatedResponse{data=[]models.TimeEntryResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/todos/{id}/time-entries [get]
+func (h *TimeEntryHandler) GetTimeEntries(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	todoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid todo ID", err.Error())
+		return
+	}
+
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	entries, total, err := h.timeEntryService.List(todoID, userID, page, limit)
+	if err != nil {
+		sendTimeEntryError(c, err, "Failed to get time entries")
+		return
+	}
+
+	responses := make([]models.TimeEntryResponse, 0, len(entries))
+	for _, entry := range entries {
+		responses = append(responses, entry.ToResponse())
+	}
+
+	pagination := utils.CalculatePagination(page, limit, int(total))
+	utils.PaginatedSuccessResponse(c, http.StatusOK, "Time entries retrieved successfully", responses, pagination)
+}
+
+// UpdateTimeEntry godoc
+// @Summary Edit a time entry
+// @Description Correct the start, end or note of a time entry. Setting ended_at on a running timer stops it.
+// @Tags time
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Todo ID"
+// @Param entry_id path string true "Time entry ID"
+// @Param entry body models.TimeEntryUpdateRequest true "Fields to change"
+// @Success 200 {object} utils.Response{data=models.TimeEntryResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 422 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/todos/{id}/time-entries/{entry_id} [put]
+func (h *TimeEntryHandler) UpdateTimeEntry(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	todoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid todo ID", err.Error())
+		return
+	}
+
+	entryID, err := uuid.Parse(c.Param("entry_id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid time entry ID", err.Error())
+		return
+	}
+
+	var req models.TimeEntryUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	entry, err := h.timeEntryService.Update(entryID, todoID, userID, &req)
+	if err != nil {
+		sendTimeEntryError(c, err, "Failed to update time entry")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Time entry updated successfully", entry.ToResponse())
+}
+
+// DeleteTimeEntry godoc
+// @Summary Delete a time entry
+// @Description Delete a time entry; deleting a running timer discards it
+// @Tags time
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Todo ID"
+// @Param entry_id path string true "Time entry ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/todos/{id}/time-entries/{entry_id} [delete]
+func (h *TimeEntryHandler) DeleteTimeEntry(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	todoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid todo ID", err.Error())
+		return
+	}
+
+	entryID, err := uuid.Parse(c.Param("entry_id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid time entry ID", err.Error())
+		return
+	}
+
+	if err := h.timeEntryService.Delete(entryID, todoID, userID); err != nil {
+		sendTimeEntryError(c, err, "Failed to delete time entry")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Time entry deleted successfully", nil)
+}
+
+// GetTimeReport godoc
+// @Summary Get a time report
+// @Description Sum the finished time entries started between from and to (inclusive dates in the user's timezone, default the last 7 days) by day, list or tag. An entry counts for each tag of its todo. format=csv returns the rows as a CSV file.
+// @Tags time
+// @Accept json
+// @Produce json,text/csv
+// @Security BearerAuth
+// @Param from query string false "First day (YYYY-MM-DD)"
+// @Param to query string false "Last day (YYYY-MM-DD)"
+// @Param group_by query string false "day, list or tag" default(day)
+// @Param format query string false "json or csv" default(json)
+// @Success 200 {object} utils.Response{data=models.TimeReport}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/reports/time [get]
+func (h *TimeEntryHandler) GetTimeReport(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid format", "format must be json or csv")
+		return
+	}
+
+	report, err := h.timeEntryService.Report(userID, c.Query("from"), c.Query("to"), c.Query("group_by"))
+	if err != nil {
+		sendTimeEntryError(c, err, "Failed to build time report")
+		return
+	}
+
+	if format == "csv" {
+		filename := fmt.Sprintf("time-by-%s-%s-%s.csv", report.GroupBy, report.From, report.To)
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+		c.Status(http.StatusOK)
+		if err := report.WriteCSV(c.Writer); err != nil {
+			_ = c.Error(err)
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Time report retrieved successfully", report)
+}
+
+func sendTimeEntryError(c *gin.Context, err error, message string) {
+	msg := err.Error()
+	switch {
+	case msg == "todo not found":
+		utils.SendErrorResponse(c, http.StatusNotFound, "Todo not found", msg)
+	case msg == "time entry not found", msg == "no timer is running for this todo":
+		utils.SendErrorResponse(c, http.StatusNotFound, "Time entry not found", msg)
+	case msg == "unauthorized to access this todo":
+		utils.SendErrorResponse(c, http.StatusForbidden, "Forbidden", msg)
+	case msg == "timer is already running for this todo":
+		utils.SendErrorResponse(c, http.StatusConflict, "Timer already running", msg)
+	case strings.HasPrefix(msg, "invalid "):
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request", msg)
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, message, msg)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 	"todo-backend/internal/models"
 	"todo-backend/internal/service"
 	"todo-backend/pkg/utils"
@@ -12,6 +13,9 @@ import (
 	"github.com/google/uuid"
 )
 
+// maxClientIDLength is how much of the X-Client-ID header is kept in todo history
+const maxClientIDLength = 100
+
 type TodoHandler struct {
 	todoService service.TodoService
 }
@@ -29,10 +33,12 @@ func NewTodoHandler(todoService service.TodoService) *TodoHandler {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Param X-Client-ID header string false "App or device ID recorded in the todo's history"
 // @Param todo body models.TodoCreateRequest true "Todo data"
 // @Success 201 {object} utils.Response{data=models.TodoResponse}
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
 // @Failure 422 {object} utils.ErrorResponse
 // @Failure 500 {object} utils.ErrorResponse
 // @Router /api/v1/todos [post]
@@ -54,8 +60,16 @@ func (h *TodoHandler) CreateTodo(c *gin.Context) {
 		return
 	}
 
-	todo, err := h.todoService.Create(userID, &req)
+	todo, err := h.forClient(c).Create(userID, &req)
 	if err != nil {
+		if isInvalidTodoInput(err) {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid todo", err.Error())
+			return
+		}
+		if isWorkflowViolation(err) {
+			utils.SendErrorResponse(c, http.StatusConflict, "Workflow violation", err.Error())
+			return
+		}
 		utils.SendErrorResponse(c, http.StatusInternalServerError, "Failed to create todo", err.Error())
 		return
 	}
@@ -65,15 +79,32 @@ func (h *TodoHandler) CreateTodo(c *gin.Context) {
 
 // GetTodos godoc
 // @Summary Get todos for user
-// @Description Get paginated list of todos for the authenticated user
+// @Description Get a filtered, sorted and paginated list of todos for the authenticated user. All filters combine with AND; multi-value filters accept repeated or comma-separated values. Unknown parameters are rejected. Passing cursor switches to keyset pagination and returns a utils.CursorPaginatedResponse.
 // @Tags todos
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Number of items per page" default(10)
-// @Param status query string false "Filter by status" Enums(pending,in_progress,completed)
+// @Param status query string false "Filter by one or more statuses, e.g. pending,in_progress"
+// @Param priority_min query int false "Minimum priority (0-5)"
+// @Param priority_max query int false "Maximum priority (0-5)"
+// @Param due_before query string false "Due strictly before (RFC 3339 or YYYY-MM-DD)"
+// @Param due_after query string false "Due at or after (RFC 3339 or YYYY-MM-DD)"
+// @Param overdue query bool false "Only todos past their due date and not completed (or none of them)"
+// @Param has_due_date query bool false "Only todos with (or without) a due date"
+// @Param created_before query string false "Created strictly before"
+// @Param created_after query string false "Created at or after"
+// @Param updated_before query string false "Updated strictly before"
+// @Param updated_after query string false "Updated at or after"
+// @Param list_id query string false "Filter by one or more list IDs"
+// @Param tag query string false "Filter by one or more tags (any of)"
+// @Param q query string false "Query language expression, e.g. status:pending priority>=3 due<7d tag:work -tag:someday"
+// @Param sort query string false "Comma-separated sort keys, '-' for descending, e.g. -priority,due_date" default(-created_at)
+// @Param cursor query string false "Switch to keyset pagination; empty for the first page, then next_cursor or prev_cursor"
+// @Param include_total query bool false "Include the total number of matches in cursor pagination"
 // @Success 200 {object} utils.PaginatedResponse{data=[]models.TodoResponse}
+// @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
 // @Failure 500 {object} utils.ErrorResponse
 // @Router /api/v1/todos [get]
@@ -84,42 +115,76 @@ func (h *TodoHandler) GetTodos(c *gin.Context) {
 		return
 	}
 
+	filter, err := models.ParseTodoFilter(c.Request.URL.Query())
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid query", err.Error())
+		return
+	}
+
 	// Parse pagination parameters
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
-	status := c.Query("status")
-
-	if status != "" {
-		// Filter by status
-		todos, err := h.todoService.GetByStatus(userID, models.TodoStatus(status))
-		if err != nil {
-			utils.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get todos", err.Error())
-			return
-		}
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
 
-		var responses []models.TodoResponse
-		for _, todo := range todos {
-			responses = append(responses, todo.ToResponse())
-		}
+	// The presence of a cursor parameter selects keyset pagination
+	if cursor, ok := c.GetQuery("cursor"); ok {
+		h.getTodosPage(c, userID, filter, cursor, limit)
+		return
+	}
 
-		utils.SuccessResponse(c, http.StatusOK, "Todos retrieved successfully", responses)
+	todos, total, err := h.todoService.List(userID, filter, page, limit)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get todos", err.Error())
 		return
 	}
 
-	// Get paginated todos
-	todos, total, err := h.todoService.GetByUserID(userID, page, limit)
+	pagination := utils.CalculatePagination(page, limit, int(total))
+	utils.PaginatedSuccessResponse(c, http.StatusOK, "Todos retrieved successfully", toTodoResponses(todos), pagination)
+}
+
+// getTodosPage serves a keyset page of a todo listing
+func (h *TodoHandler) getTodosPage(c *gin.Context, userID uuid.UUID, filter *models.TodoFilter, cursor string, limit int) {
+	includeTotal, _ := strconv.ParseBool(c.Query("include_total"))
+
+	page, err := h.todoService.ListPage(userID, filter, cursor, limit, includeTotal)
 	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid cursor") {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid cursor", err.Error())
+			return
+		}
 		utils.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get todos", err.Error())
 		return
 	}
 
-	var responses []models.TodoResponse
-	for _, todo := range todos {
-		responses = append(responses, todo.ToResponse())
+	pagination := utils.CursorPagination{
+		Limit:      limit,
+		NextCursor: page.NextCursor,
+		PrevCursor: page.PrevCursor,
+		HasMore:    page.NextCursor != "",
+		Total:      page.Total,
+	}
+	if page.NextCursor != "" {
+		pagination.Next = cursorLink(c, page.NextCursor)
+	}
+	if page.PrevCursor != "" {
+		pagination.Prev = cursorLink(c, page.PrevCursor)
 	}
 
-	pagination := utils.CalculatePagination(page, limit, int(total))
-	utils.PaginatedSuccessResponse(c, http.StatusOK, "Todos retrieved successfully", responses, pagination)
+	utils.CursorPaginatedSuccessResponse(c, http.StatusOK, "Todos retrieved successfully", toTodoResponses(page.Todos), pagination)
+}
+
+// cursorLink returns the current request URL with its cursor replaced
+func cursorLink(c *gin.Context, cursor string) string {
+	link := *c.Request.URL
+	query := link.Query()
+	query.Set("cursor", cursor)
+	link.RawQuery = query.Encode()
+	return link.RequestURI()
 }
 
 // GetTodo godoc
@@ -164,12 +229,14 @@ func (h *TodoHandler) GetTodo(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Param X-Client-ID header string false "App or device ID recorded in the todo's history"
 // @Param id path string true "Todo ID"
 // @Param todo body models.TodoUpdateRequest true "Todo data"
 // @Success 200 {object} utils.Response{data=models.TodoResponse}
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
 // @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
 // @Failure 422 {object} utils.ErrorResponse
 // @Failure 500 {object} utils.ErrorResponse
 // @Router /api/v1/todos/{id} [put]
@@ -198,7 +265,7 @@ func (h *TodoHandler) UpdateTodo(c *gin.Context) {
 		return
 	}
 
-	todo, err := h.todoService.Update(id, userID, &req)
+	todo, err := h.forClient(c).Update(id, userID, &req)
 	if err != nil {
 		if err.Error() == "todo not found" {
 			utils.SendErrorResponse(c, http.StatusNotFound, "Todo not found", err.Error())
@@ -208,6 +275,18 @@ func (h *TodoHandler) UpdateTodo(c *gin.Context) {
 			utils.SendErrorResponse(c, http.StatusForbidden, "Forbidden", err.Error())
 			return
 		}
+		if err.Error() == "todo is blocked by unfinished dependencies" {
+			utils.SendErrorResponse(c, http.StatusConflict, "Todo is blocked", err.Error())
+			return
+		}
+		if isWorkflowViolation(err) {
+			utils.SendErrorResponse(c, http.StatusConflict, "Workflow violation", err.Error())
+			return
+		}
+		if isInvalidTodoInput(err) {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid todo", err.Error())
+			return
+		}
 		utils.SendErrorResponse(c, http.StatusInternalServerError, "Failed to update todo", err.Error())
 		return
 	}
@@ -215,6 +294,52 @@ func (h *TodoHandler) UpdateTodo(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, "Todo updated successfully", todo.ToResponse())
 }
 
+// BulkTodos godoc
+// @Summary Apply an action to many todos
+// @Description Complete, set status or priority, move, tag, untag or delete the todos listed in ids or matched by filter (query language, e.g. "status:completed updated<-30d") in one transaction. The response reports the outcome per todo; with atomic set, any failure rolls every change back.
+// @Tags todos
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param X-Client-ID header string false "App or device ID recorded in the todo's history"
+// @Param request body models.TodoBulkRequest true "Bulk action"
+// @Success 200 {object} utils.Response{data=models.TodoBulkResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 422 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/todos/bulk [post]
+func (h *TodoHandler) BulkTodos(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	var req models.TodoBulkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	result, err := h.forClient(c).Bulk(userID, &req)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid bulk request") || strings.HasPrefix(err.Error(), "invalid filter") {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid bulk request", err.Error())
+			return
+		}
+		utils.SendErrorResponse(c, http.StatusInternalServerError, "Failed to apply bulk action", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Bulk action applied", result)
+}
+
 // DeleteTodo godoc
 // @Summary Delete a todo
 // @Description Delete a todo by its ID
@@ -222,6 +347,7 @@ func (h *TodoHandler) UpdateTodo(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Param X-Client-ID header string false "App or device ID recorded in the todo's history"
 // @Param id path string true "Todo ID"
 // @Success 200 {object} utils.Response
 // @Failure 400 {object} utils.ErrorResponse
@@ -243,7 +369,7 @@ func (h *TodoHandler) DeleteTodo(c *gin.Context) {
 		return
 	}
 
-	err = h.todoService.Delete(id, userID)
+	err = h.forClient(c).Delete(id, userID)
 	if err != nil {
 		if err.Error() == "todo not found" {
 			utils.SendErrorResponse(c, http.StatusNotFound, "Todo not found", err.Error())
@@ -260,6 +386,375 @@ func (h *TodoHandler) DeleteTodo(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, "Todo deleted successfully", nil)
 }
 
+// GetTodoHistory godoc
+// @Summary Get the history of a todo
+// @Description Get the changes made to a todo, newest first: who made them, when, from which client, and each field's old and new value
+// @Tags todos
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Todo ID"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of items per page" default(10)
+// @Success 200 {object} utils.PaginatedResponse{data=[]models.TodoChangeResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/todos/{id}/history [get]
+func (h *TodoHandler) GetTodoHistory(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid todo ID", err.Error())
+		return
+	}
+
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	changes, total, err := h.todoService.History(id, userID, page, limit)
+	if err != nil {
+		switch err.Error() {
+		case "todo not found":
+			utils.SendErrorResponse(c, http.StatusNotFound, "Todo not found", err.Error())
+		case "unauthorized to access this todo":
+			utils.SendErrorResponse(c, http.StatusForbidden, "Forbidden", err.Error())
+		default:
+			utils.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get todo history", err.Error())
+		}
+		return
+	}
+
+	responses := make([]models.TodoChangeResponse, 0, len(changes))
+	for _, change := range changes {
+		responses = append(responses, change.ToResponse())
+	}
+
+	pagination := utils.CalculatePagination(page, limit, int(total))
+	utils.PaginatedSuccessResponse(c, http.StatusOK, "History retrieved successfully", responses, pagination)
+}
+
+// GetTrash godoc
+// @Summary Get deleted todos
+// @Description Get the todos in the trash, most recently deleted first. Trashed todos are purged for good after the retention period.
+// @Tags todos
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of items per page" default(10)
+// @Success 200 {object} utils.PaginatedResponse{data=[]models.TrashedTodoResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/todos/trash [get]
+func (h *TodoHandler) GetTrash(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	todos, total, err := h.todoService.GetTrash(userID, page, limit)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get trash", err.Error())
+		return
+	}
+
+	responses := make([]models.TrashedTodoResponse, 0, len(todos))
+	for _, todo := range todos {
+		responses = append(responses, todo.ToTrashedResponse())
+	}
+
+	pagination := utils.CalculatePagination(page, limit, int(total))
+	utils.PaginatedSuccessResponse(c, http.StatusOK, "Trash retrieved successfully", responses, pagination)
+}
+
+// RestoreTodo godoc
+// @Summary Restore a deleted todo
+// @Description Take a todo out of the trash together with the reminders, comments and attachments deleted with it
+// @Tags todos
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param X-Client-ID header string false "App or device ID recorded in the todo's history"
+// @Param id path string true "Todo ID"
+// @Success 200 {object} utils.Response{data=models.TodoResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/todos/{id}/restore [post]
+func (h *TodoHandler) RestoreTodo(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid todo ID", err.Error())
+		return
+	}
+
+	todo, err := h.forClient(c).Restore(id, userID)
+	if err != nil {
+		sendTrashError(c, err, "Failed to restore todo")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Todo restored successfully", todo.ToResponse())
+}
+
+// PurgeTodo godoc
+// @Summary Permanently delete a todo
+// @Description Delete a todo in the trash for good, including its reminders, comments and attachment files
+// @Tags todos
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Todo ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/todos/{id}/purge [delete]
+func (h *TodoHandler) PurgeTodo(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid todo ID", err.Error())
+		return
+	}
+
+	if err := h.todoService.Purge(id, userID); err != nil {
+		sendTrashError(c, err, "Failed to purge todo")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Todo permanently deleted", nil)
+}
+
+// SkipOccurrence godoc
+// @Summary Skip an occurrence of a recurring todo
+// @Description Move a recurring todo to its next occurrence without completing it
+// @Tags todos
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param X-Client-ID header string false "App or device ID recorded in the todo's history"
+// @Param id path string true "Todo ID"
+// @Success 200 {object} utils.Response{data=models.TodoResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/todos/{id}/skip [post]
+func (h *TodoHandler) SkipOccurrence(c *gin.Context) {
+	h.handleSeriesAction(c, h.forClient(c).SkipOccurrence, "Occurrence skipped successfully")
+}
+
+// EndSeries godoc
+// @Summary End a recurring series
+// @Description Stop a recurring todo from generating further occurrences
+// @Tags todos
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param X-Client-ID header string false "App or device ID recorded in the todo's history"
+// @Param id path string true "Todo ID"
+// @Success 200 {object} utils.Response{data=models.TodoResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/todos/{id}/end-series [post]
+func (h *TodoHandler) EndSeries(c *gin.Context) {
+	h.handleSeriesAction(c, h.forClient(c).EndSeries, "Series ended successfully")
+}
+
+func (h *TodoHandler) handleSeriesAction(c *gin.Context, action func(id, userID uuid.UUID) (*models.Todo, error), message string) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid todo ID", err.Error())
+		return
+	}
+
+	todo, err := action(id, userID)
+	if err != nil {
+		switch err.Error() {
+		case "todo not found":
+			utils.SendErrorResponse(c, http.StatusNotFound, "Todo not found", err.Error())
+		case "unauthorized to update this todo":
+			utils.SendErrorResponse(c, http.StatusForbidden, "Forbidden", err.Error())
+		case "todo is not recurring":
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Todo is not recurring", err.Error())
+		case "no more occurrences in this series":
+			utils.SendErrorResponse(c, http.StatusConflict, "Series has no more occurrences", err.Error())
+		default:
+			utils.SendErrorResponse(c, http.StatusInternalServerError, "Failed to update todo", err.Error())
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, message, todo.ToResponse())
+}
+
+func sendTrashError(c *gin.Context, err error, message string) {
+	switch err.Error() {
+	case "todo not found in trash":
+		utils.SendErrorResponse(c, http.StatusNotFound, "Todo not found in trash", err.Error())
+	case "unauthorized to restore this todo", "unauthorized to purge this todo":
+		utils.SendErrorResponse(c, http.StatusForbidden, "Forbidden", err.Error())
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, message, err.Error())
+	}
+}
+
+// MoveTodo godoc
+// @Summary Move a todo in the manual order or on its board
+// @Description Place a todo right after after_id and/or right before before_id, and/or move it to another state of its list's board. Only the moved todo is rewritten; list with sort=position to read the manual order. State moves must be allowed by the list's workflow and stay within the target state's WIP limit.
+// @Tags todos
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param X-Client-ID header string false "App or device ID recorded in the todo's history"
+// @Param id path string true "Todo ID"
+// @Param move body models.TodoMoveRequest true "Neighbours of the new position"
+// @Success 200 {object} utils.Response{data=models.TodoResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 422 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/todos/{id}/move [post]
+func (h *TodoHandler) MoveTodo(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid todo ID", err.Error())
+		return
+	}
+
+	var req models.TodoMoveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	todo, err := h.forClient(c).Move(id, userID, &req)
+	if err != nil {
+		switch {
+		case err.Error() == "todo not found":
+			utils.SendErrorResponse(c, http.StatusNotFound, "Todo not found", err.Error())
+		case err.Error() == "unauthorized to update this todo":
+			utils.SendErrorResponse(c, http.StatusForbidden, "Forbidden", err.Error())
+		case strings.HasPrefix(err.Error(), "invalid move"):
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid move", err.Error())
+		case err.Error() == "todo is blocked by unfinished dependencies":
+			utils.SendErrorResponse(c, http.StatusConflict, "Todo is blocked", err.Error())
+		case isWorkflowViolation(err):
+			utils.SendErrorResponse(c, http.StatusConflict, "Workflow violation", err.Error())
+		default:
+			utils.SendErrorResponse(c, http.StatusInternalServerError, "Failed to move todo", err.Error())
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Todo moved successfully", todo.ToResponse())
+}
+
+// isWorkflowViolation reports whether a change was refused by the workflow of
+// the todo's list
+func isWorkflowViolation(err error) bool {
+	msg := err.Error()
+	return strings.HasPrefix(msg, "transition not allowed") || strings.HasPrefix(msg, "wip limit reached")
+}
+
+// isInvalidTodoInput reports whether a service error was caused by client input
+func isInvalidTodoInput(err error) bool {
+	msg := err.Error()
+	return strings.HasPrefix(msg, "invalid recurrence rule") || strings.HasPrefix(msg, "invalid timezone") ||
+		strings.HasPrefix(msg, "invalid parent") || msg == "list not found" || msg == "parent todo not found"
+}
+
+func toTodoResponses(todos []models.Todo) []models.TodoResponse {
+	responses := make([]models.TodoResponse, 0, len(todos))
+	for _, todo := range todos {
+		responses = append(responses, todo.ToResponse())
+	}
+	return responses
+}
+
+// forClient returns the todo service recording the requesting client in the
+// history of the todos it changes
+func (h *TodoHandler) forClient(c *gin.Context) service.TodoService {
+	return h.todoService.ForClient(clientIDFromContext(c))
+}
+
+// clientIDFromContext returns the X-Client-ID header identifying the app or
+// device making a request
+func clientIDFromContext(c *gin.Context) string {
+	clientID := strings.TrimSpace(c.GetHeader("X-Client-ID"))
+	if len(clientID) > maxClientIDLength {
+		clientID = clientID[:maxClientIDLength]
+	}
+	return clientID
+}
+
 func getUserIDFromContext(c *gin.Context) (uuid.UUID, error) {
 	userIDInterface, exists := c.Get("user_id")
 	if !exists {
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"todo-backend/internal/models"
+	"todo-backend/internal/service"
+	"todo-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UndoHandler struct {
+	todoService service.TodoService
+}
+
+func NewUndoHandler(todoService service.TodoService) *UndoHandler {
+	return &UndoHandler{
+		todoService: todoService,
+	}
+}
+
+// GetOperations godoc
+// @Summary Get recent operations
+// @Description Get the latest entries of the user's operation log, newest first. Operations from the last hour can be undone.
+// @Tags undo
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Number of operations" default(20)
+// @Success 200 {object} utils.Response{data=[]models.OperationResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/operations [get]
+func (h *UndoHandler) GetOperations(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	ops, err := h.todoService.Operations(userID, limit)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get operations", err.Error())
+		return
+	}
+
+	responses := make([]models.OperationResponse, 0, len(ops))
+	for _, op := range ops {
+		responses = append(responses, op.ToResponse())
+	}
+	utils.SuccessResponse(c, http.StatusOK, "Operations retrieved successfully", responses)
+}
+
+// Undo godoc
+// @Summary Undo an operation
+// @Description Revert the most recent create, update, delete, restore or bulk action, or the operation given by op_id, from its recorded before-images. Fails with 409 if an affected todo was changed since.
+// @Tags undo
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param X-Client-ID header string false "App or device ID recorded in the todo's history"
+// @Param request body models.UndoRequest false "Operation to undo"
+// @Success 200 {object} utils.Response{data=models.UndoResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/undo [post]
+func (h *UndoHandler) Undo(c *gin.Context) {
+	h.handleRevert(c, models.OperationUndo)
+}
+
+// Redo godoc
+// @Summary Redo an undone operation
+// @Description Revert the most recent undo, or the undo given by op_id, as long as nothing else was changed after it
+// @Tags undo
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param X-Client-ID header string false "App or device ID recorded in the todo's history"
+// @Param request body models.UndoRequest false "Undo operation to redo"
+// @Success 200 {object} utils.Response{data=models.UndoResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/redo [post]
+func (h *UndoHandler) Redo(c *gin.Context) {
+	h.handleRevert(c, models.OperationRedo)
+}
+
+func (h *UndoHandler) handleRevert(c *gin.Context, kind models.OperationKind) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	// The body is optional
+	var req models.UndoRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	todoService := h.todoService.ForClient(clientIDFromContext(c))
+	revert := todoService.Undo
+	if kind == models.OperationRedo {
+		revert = todoService.Redo
+	}
+
+	result, err := revert(userID, req.OpID)
+	if err != nil {
+		sendUndoError(c, err)
+		return
+	}
+
+	response := models.UndoResponse{
+		Operation: result.Operation.ToResponse(),
+		Reverted:  result.Reverted.ToResponse(),
+	}
+	response.Operation.TodoIDs = result.TodoIDs
+	utils.SuccessResponse(c, http.StatusOK, "Operation reverted successfully", response)
+}
+
+func sendUndoError(c *gin.Context, err error) {
+	msg := err.Error()
+	switch {
+	case msg == "operation not found" || strings.HasPrefix(msg, "nothing to "):
+		utils.SendErrorResponse(c, http.StatusNotFound, "Nothing to revert", msg)
+	case strings.HasPrefix(msg, "cannot "):
+		utils.SendErrorResponse(c, http.StatusConflict, "Conflict", msg)
+	case msg == "operation was already reverted" || strings.HasPrefix(msg, "operation is ") || msg == "only undo operations can be redone":
+		utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid operation", msg)
+	default:
+		utils.SendErrorResponse(c, http.StatusInternalServerError, "Failed to revert operation", msg)
+	}
+}
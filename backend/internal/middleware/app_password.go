@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// AppPasswordAuthenticator returns the ID of the user owning an app password;
+// email is empty for bearer tokens
+type AppPasswordAuthenticator func(email, password string) (uuid.UUID, error)
+
+// AppPasswordMiddleware authenticates clients such as CalDAV apps with an app
+// password, sent with HTTP Basic authentication (email and password) or as a
+// bearer token. Failures ask for Basic credentials in realm.
+func AppPasswordMiddleware(realm string, authenticate AppPasswordAuthenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		email, password, ok := c.Request.BasicAuth()
+		if !ok {
+			if token, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); found {
+				password, ok = token, true
+			}
+		}
+		if !ok || password == "" {
+			unauthorized(c, realm, "Authorization header is required")
+			return
+		}
+
+		userID, err := authenticate(email, password)
+		if err != nil {
+			unauthorized(c, realm, "Invalid credentials")
+			return
+		}
+
+		c.Set("user_id", userID)
+		c.Next()
+	}
+}
+
+func unauthorized(c *gin.Context, realm, message string) {
+	c.Header("WWW-Authenticate", `Basic realm="`+realm+`", charset="UTF-8"`)
+	c.JSON(http.StatusUnauthorized, gin.H{"error": message})
+	c.Abort()
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AccountExportStatus string
+
+const (
+	AccountExportPending   AccountExportStatus = "pending"
+	AccountExportRunning   AccountExportStatus = "running"
+	AccountExportCompleted AccountExportStatus = "completed"
+	AccountExportFailed    AccountExportStatus = "failed"
+)
+
+// AccountExport is a ZIP archive of everything stored about a user. It is
+// built in the background and can be downloaded until it expires.
+type AccountExport struct {
+	ID          uuid.UUID           `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID      uuid.UUID           `json:"user_id" gorm:"type:uuid;not null;index"`
+	Status      AccountExportStatus `json:"status" gorm:"type:varchar(20);not null;default:'pending'"`
+	StorageKey  string              `json:"-" gorm:"type:varchar(512)"`
+	Size        int64               `json:"size" gorm:"not null;default:0"`
+	Error       string              `json:"error,omitempty" gorm:"type:text"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+	CompletedAt *time.Time          `json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time          `json:"expires_at,omitempty" gorm:"index"`
+
+	// Relationships
+	User User `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+// AccountExportResponse carries the download link of a completed export
+type AccountExportResponse struct {
+	ID          uuid.UUID           `json:"id"`
+	Status      AccountExportStatus `json:"status"`
+	Size        int64               `json:"size"`
+	Error       string              `json:"error,omitempty"`
+	CreatedAt   time.Time           `json:"created_at"`
+	CompletedAt *time.Time          `json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time          `json:"expires_at,omitempty"`
+	DownloadURL string              `json:"download_url,omitempty"`
+}
+
+func (e *AccountExport) ToResponse() AccountExportResponse {
+	return AccountExportResponse{
+		ID:          e.ID,
+		Status:      e.Status,
+		Size:        e.Size,
+		Error:       e.Error,
+		CreatedAt:   e.CreatedAt,
+		CompletedAt: e.CompletedAt,
+		ExpiresAt:   e.ExpiresAt,
+	}
+}
+
+// AccountDeleteRequest proves the user is still the one signed in: the
+// password of an email account or a fresh authorization code of an Apple one
+type AccountDeleteRequest struct {
+	Password  string `json:"password,omitempty"`
+	AppleCode string `json:"apple_code,omitempty"`
+}
+
+// AccountDeletionResponse says when a deleted account is purged; nil when it
+// already has been
+type AccountDeletionResponse struct {
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AppPassword is a personal access token for clients that can't sign in with
+// Apple or a JWT, such as CalDAV apps. It is sent as the password of HTTP
+// Basic authentication or as a bearer token; only its SHA-256 hash is kept.
+type AppPassword struct {
+	ID         uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID     uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	Name       string     `json:"name" gorm:"type:varchar(100);not null"`
+	TokenHash  string     `json:"-" gorm:"type:varchar(64);uniqueIndex;not null"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+
+	// Relationships
+	User User `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+type AppPasswordCreateRequest struct {
+	Name string `json:"name" validate:"required,min=1,max=100"`
+}
+
+type AppPasswordResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// AppPasswordCreatedResponse includes the password itself, which is only
+// shown once
+type AppPasswordCreatedResponse struct {
+	AppPasswordResponse
+	Password string `json:"password"`
+}
+
+func (p *AppPassword) ToResponse() AppPasswordResponse {
+	return AppPasswordResponse{
+		ID:         p.ID,
+		Name:       p.Name,
+		LastUsedAt: p.LastUsedAt,
+		CreatedAt:  p.CreatedAt,
+	}
+}
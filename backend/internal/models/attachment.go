@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Attachment struct {
+	ID          uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	TodoID      uuid.UUID      `json:"todo_id" gorm:"type:uuid;not null;index"`
+	UserID      uuid.UUID      `json:"user_id" gorm:"type:uuid;not null;index"`
+	FileName    string         `json:"file_name" gorm:"type:varchar(255);not null"`
+	ContentType string         `json:"content_type" gorm:"type:varchar(127);not null"`
+	Size        int64          `json:"size" gorm:"not null"`
+	Checksum    string         `json:"checksum" gorm:"type:varchar(64)"` // hex SHA-256 of the content
+	StorageKey  string         `json:"-" gorm:"type:varchar(512);not null;uniqueIndex"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// Relationships
+	Todo Todo `json:"-" gorm:"foreignKey:TodoID;constraint:OnDelete:CASCADE"`
+	User User `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+type AttachmentResponse struct {
+	ID          uuid.UUID `json:"id"`
+	TodoID      uuid.UUID `json:"todo_id"`
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	Checksum    string    `json:"checksum"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// AttachmentDownloadResponse carries a signed, expiring download link
+type AttachmentDownloadResponse struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (a *Attachment) ToResponse() AttachmentResponse {
+	return AttachmentResponse{
+		ID:          a.ID,
+		TodoID:      a.TodoID,
+		FileName:    a.FileName,
+		ContentType: a.ContentType,
+		Size:        a.Size,
+		Checksum:    a.Checksum,
+		CreatedAt:   a.CreatedAt,
+	}
+}
@@ -0,0 +1,31 @@
+package models
+
+import "github.com/google/uuid"
+
+// CalDAVInbox is the ID of the calendar holding the todos without a list;
+// the other calendars are identified by their list's ID
+const CalDAVInbox = "inbox"
+
+// CalDAVCalendar is a CalDAV calendar collection: a list, or the inbox.
+// CTag changes whenever one of its todos does.
+type CalDAVCalendar struct {
+	ID     string
+	ListID *uuid.UUID
+	Name   string
+	CTag   string
+}
+
+// CalDAVObject is a todo as a calendar object resource: a VCALENDAR holding
+// one VTODO, named "<name>.ics" within its calendar
+type CalDAVObject struct {
+	Name string
+	ETag string // quoted, from the todo's version
+	Data string
+	Todo *Todo
+}
+
+// CalDAVConditions are the If-Match and If-None-Match headers of a write
+type CalDAVConditions struct {
+	IfMatch     string
+	IfNoneMatch string
+}
@@ -0,0 +1,31 @@
+package models
+
+import "github.com/google/uuid"
+
+// Components of a calendar feed
+const (
+	CalendarComponentEvent = "vevent"
+	CalendarComponentTodo  = "vtodo"
+)
+
+// CalendarFeedQuery narrows a calendar feed. Component is vevent or vtodo,
+// both when empty.
+type CalendarFeedQuery struct {
+	ListID           *uuid.UUID
+	Component        string
+	IncludeCompleted bool
+}
+
+// CalendarFeedResponse has the secret URLs of a user's calendar feed, for
+// all todos and for each list
+type CalendarFeedResponse struct {
+	URL       string             `json:"url"`
+	WebcalURL string             `json:"webcal_url"`
+	Lists     []CalendarListFeed `json:"lists"`
+}
+
+type CalendarListFeed struct {
+	ListID uuid.UUID `json:"list_id"`
+	Name   string    `json:"name"`
+	URL    string    `json:"url"`
+}
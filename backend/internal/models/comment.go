@@ -0,0 +1,85 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Comment struct {
+	ID        uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	TodoID    uuid.UUID      `json:"todo_id" gorm:"type:uuid;not null;index:idx_comments_thread,priority:1"`
+	AuthorID  uuid.UUID      `json:"author_id" gorm:"type:uuid;not null;index"`
+	Body      string         `json:"body" gorm:"type:text;not null"`
+	EditedAt  *time.Time     `json:"edited_at,omitempty"`
+	CreatedAt time.Time      `json:"created_at" gorm:"index:idx_comments_thread,priority:2"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// Relationships
+	Todo     Todo             `json:"-" gorm:"foreignKey:TodoID;constraint:OnDelete:CASCADE"`
+	Author   User             `json:"author,omitempty" gorm:"foreignKey:AuthorID;constraint:OnDelete:CASCADE"`
+	Mentions []CommentMention `json:"mentions,omitempty" gorm:"foreignKey:CommentID;constraint:OnDelete:CASCADE"`
+}
+
+// DeletedUserHandle replaces mentions of users who deleted their account
+const DeletedUserHandle = "deleted-user"
+
+// CommentMention records a user referenced with @ in a comment body
+type CommentMention struct {
+	CommentID uuid.UUID `json:"comment_id" gorm:"type:uuid;primaryKey"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;primaryKey;index"`
+	Handle    string    `json:"handle" gorm:"type:varchar(255)"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Relationships
+	User User `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+type CommentCreateRequest struct {
+	Body string `json:"body" validate:"required,min=1,max=5000"`
+}
+
+type CommentUpdateRequest struct {
+	Body string `json:"body" validate:"required,min=1,max=5000"`
+}
+
+type CommentAuthorResponse struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+}
+
+type CommentMentionResponse struct {
+	UserID uuid.UUID `json:"user_id"`
+	Handle string    `json:"handle"`
+}
+
+type CommentResponse struct {
+	ID        uuid.UUID                `json:"id"`
+	TodoID    uuid.UUID                `json:"todo_id"`
+	Author    CommentAuthorResponse    `json:"author"`
+	Body      string                   `json:"body"`
+	Mentions  []CommentMentionResponse `json:"mentions"`
+	EditedAt  *time.Time               `json:"edited_at,omitempty"`
+	CreatedAt time.Time                `json:"created_at"`
+	UpdatedAt time.Time                `json:"updated_at"`
+}
+
+func (c *Comment) ToResponse() CommentResponse {
+	mentions := make([]CommentMentionResponse, 0, len(c.Mentions))
+	for _, m := range c.Mentions {
+		mentions = append(mentions, CommentMentionResponse{UserID: m.UserID, Handle: m.Handle})
+	}
+
+	return CommentResponse{
+		ID:        c.ID,
+		TodoID:    c.TodoID,
+		Author:    CommentAuthorResponse{ID: c.AuthorID, Name: c.Author.Name},
+		Body:      c.Body,
+		Mentions:  mentions,
+		EditedAt:  c.EditedAt,
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+	}
+}
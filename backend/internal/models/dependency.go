@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TodoDependency records that TodoID cannot start until DependsOnID is done
+type TodoDependency struct {
+	TodoID      uuid.UUID `json:"todo_id" gorm:"type:uuid;primaryKey"`
+	DependsOnID uuid.UUID `json:"depends_on_id" gorm:"type:uuid;primaryKey;index"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	// Relationships
+	Todo      Todo `json:"-" gorm:"foreignKey:TodoID;constraint:OnDelete:CASCADE"`
+	DependsOn Todo `json:"-" gorm:"foreignKey:DependsOnID;constraint:OnDelete:CASCADE"`
+}
+
+type DependencyCreateRequest struct {
+	DependsOnID uuid.UUID `json:"depends_on_id" validate:"required"`
+}
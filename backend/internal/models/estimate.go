@@ -0,0 +1,60 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+// EstimateUnit is the unit todo estimates are given in, set per list. Todos
+// outside a list are estimated in minutes.
+type EstimateUnit string
+
+const (
+	EstimateUnitMinutes EstimateUnit = "minutes"
+	EstimateUnitPoints  EstimateUnit = "points"
+)
+
+// Groupings of an effort rollup
+const (
+	EffortByList = "list"
+	EffortByTag  = "tag"
+)
+
+// EffortRollup is the estimated effort of a group of todos in one unit. Key
+// is the list ID, tag name or root todo ID, empty for todos without a list or
+// tag. Todos without an estimate count towards Todos but not Estimated.
+type EffortRollup struct {
+	Key       string       `json:"key"`
+	Label     string       `json:"label"`
+	Unit      EstimateUnit `json:"unit"`
+	Remaining float64      `json:"remaining"`
+	Completed float64      `json:"completed"`
+	Todos     int64        `json:"todos"`
+	Estimated int64        `json:"estimated"`
+}
+
+// EffortReport is the rollup of a user's todos by list or tag. A todo
+// counts for every one of its tags.
+type EffortReport struct {
+	GroupBy string         `json:"group_by"`
+	Rows    []EffortRollup `json:"rows"`
+}
+
+// BurndownPoint is the effort left and done at the end of one day, or now
+// for today
+type BurndownPoint struct {
+	Date      string  `json:"date"` // YYYY-MM-DD in the user's timezone
+	Remaining float64 `json:"remaining"`
+	Completed float64 `json:"completed"`
+}
+
+// Burndown is the daily remaining and completed effort of the todos in one
+// unit, optionally narrowed to a list or tag, rebuilt from their history
+type Burndown struct {
+	From     string          `json:"from"`
+	To       string          `json:"to"`
+	Timezone string          `json:"timezone"`
+	Unit     EstimateUnit    `json:"unit"`
+	ListID   *uuid.UUID      `json:"list_id,omitempty"`
+	Tag      string          `json:"tag,omitempty"`
+	Points   []BurndownPoint `json:"points"`
+}
@@ -0,0 +1,34 @@
+package models
+
+// Export formats
+const (
+	ExportFormatJSON     = "json"
+	ExportFormatCSV      = "csv"
+	ExportFormatMarkdown = "markdown"
+	ExportFormatTodoTxt  = "todotxt"
+)
+
+// ExportFile is the kind of file an export format produces
+type ExportFile struct {
+	ContentType string
+	Extension   string
+}
+
+// ExportFiles maps the export formats to their files
+var ExportFiles = map[string]ExportFile{
+	ExportFormatJSON:     {ContentType: "application/json; charset=utf-8", Extension: "json"},
+	ExportFormatCSV:      {ContentType: "text/csv; charset=utf-8", Extension: "csv"},
+	ExportFormatMarkdown: {ContentType: "text/markdown; charset=utf-8", Extension: "md"},
+	ExportFormatTodoTxt:  {ContentType: "text/plain; charset=utf-8", Extension: "txt"},
+}
+
+// ExportRequest exports the todos matching Filter, all of them when nil.
+// The filter's sort is ignored; todos are exported in the order they were
+// created.
+type ExportRequest struct {
+	Format string
+	Filter *TodoFilter
+	// Complete lifts the limits exports share with imports, for account
+	// archives, which hold everything whatever its size
+	Complete bool
+}
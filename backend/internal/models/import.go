@@ -0,0 +1,133 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+	"todo-backend/pkg/todoimport"
+
+	"github.com/google/uuid"
+)
+
+// Limits of an import
+const (
+	ImportMaxBytes = 10 << 20
+	ImportMaxItems = 10000
+	// ImportMaxSkipped is the number of skipped fields a report lists; all of
+	// them are counted in SkippedFields
+	ImportMaxSkipped = 200
+	// ImportPreviewTodos is the number of todos a dry run returns
+	ImportPreviewTodos = 100
+)
+
+type ImportJobStatus string
+
+const (
+	ImportJobPending   ImportJobStatus = "pending"
+	ImportJobRunning   ImportJobStatus = "running"
+	ImportJobCompleted ImportJobStatus = "completed"
+	ImportJobFailed    ImportJobStatus = "failed"
+)
+
+// ImportRequest is an uploaded export of another todo app. An empty Format is
+// detected from Data; FileName names the list of a Todoist export.
+type ImportRequest struct {
+	Format   string
+	FileName string
+	Data     []byte
+	DryRun   bool
+}
+
+// ImportJob is the progress and outcome of an import. Large imports run in
+// the background and are polled through their job.
+type ImportJob struct {
+	ID         uuid.UUID       `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID     uuid.UUID       `json:"user_id" gorm:"type:uuid;not null;index"`
+	Format     string          `json:"format" gorm:"type:varchar(20);not null"`
+	Status     ImportJobStatus `json:"status" gorm:"type:varchar(20);not null;default:'pending'"`
+	Total      int             `json:"total" gorm:"not null;default:0"`     // todos in the import
+	Processed  int             `json:"processed" gorm:"not null;default:0"` // todos handled so far
+	Created    int             `json:"created" gorm:"not null;default:0"`   // todos created so far
+	Report     ImportReport    `json:"report" gorm:"type:jsonb;not null"`
+	Error      string          `json:"error,omitempty" gorm:"type:text"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+	FinishedAt *time.Time      `json:"finished_at,omitempty"`
+
+	// Relationships
+	User User `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+// ImportReport lists the lists an import creates and what it leaves out
+type ImportReport struct {
+	Lists         []string             `json:"lists"`
+	SkippedFields map[string]int       `json:"skipped_fields"`
+	Skipped       []todoimport.Skipped `json:"skipped"`
+}
+
+// Skip records a skipped field, listing at most ImportMaxSkipped of them
+func (r *ImportReport) Skip(skipped todoimport.Skipped) {
+	if r.SkippedFields == nil {
+		r.SkippedFields = make(map[string]int)
+	}
+	r.SkippedFields[skipped.Field]++
+	if len(r.Skipped) < ImportMaxSkipped {
+		r.Skipped = append(r.Skipped, skipped)
+	}
+}
+
+func (r ImportReport) Value() (driver.Value, error) {
+	if r.Lists == nil {
+		r.Lists = []string{}
+	}
+	if r.SkippedFields == nil {
+		r.SkippedFields = map[string]int{}
+	}
+	if r.Skipped == nil {
+		r.Skipped = []todoimport.Skipped{}
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (r *ImportReport) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*r = ImportReport{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, r)
+	case string:
+		return json.Unmarshal([]byte(v), r)
+	}
+	return errors.New("unsupported type for import report")
+}
+
+// ImportPreviewTodo is a todo an import would create. ParentLine is the line
+// of its parent task.
+type ImportPreviewTodo struct {
+	Line           int        `json:"line"`
+	Title          string     `json:"title"`
+	Description    string     `json:"description,omitempty"`
+	Status         TodoStatus `json:"status"`
+	Priority       int        `json:"priority"`
+	DueDate        *time.Time `json:"due_date,omitempty"`
+	RecurrenceRule string     `json:"recurrence_rule,omitempty"`
+	Estimate       *float64   `json:"estimate,omitempty"`
+	List           string     `json:"list,omitempty"`
+	Tags           []string   `json:"tags"`
+	ParentLine     *int       `json:"parent_line,omitempty"`
+}
+
+// ImportPreview is the outcome of a dry run: the first ImportPreviewTodos
+// todos and the report of the whole import
+type ImportPreview struct {
+	Format string              `json:"format"`
+	Total  int                 `json:"total"`
+	Todos  []ImportPreviewTodo `json:"todos"`
+	Report ImportReport        `json:"report"`
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// List groups todos, e.g. "Work" or "Groceries"
+type List struct {
+	ID           uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID       uuid.UUID      `json:"user_id" gorm:"type:uuid;not null;index"`
+	Name         string         `json:"name" gorm:"type:varchar(100);not null" validate:"required,min=1,max=100"`
+	EstimateUnit EstimateUnit   `json:"estimate_unit" gorm:"type:varchar(10);not null;default:'minutes'"` // unit of its todos' estimates
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// Relationships
+	User User `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+type ListCreateRequest struct {
+	Name         string       `json:"name" validate:"required,min=1,max=100"`
+	EstimateUnit EstimateUnit `json:"estimate_unit" validate:"omitempty,oneof=minutes points"`
+}
+
+type ListUpdateRequest struct {
+	Name         string       `json:"name" validate:"omitempty,min=1,max=100"`
+	EstimateUnit EstimateUnit `json:"estimate_unit" validate:"omitempty,oneof=minutes points"`
+}
+
+type ListResponse struct {
+	ID           uuid.UUID    `json:"id"`
+	Name         string       `json:"name"`
+	EstimateUnit EstimateUnit `json:"estimate_unit"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+}
+
+func (l *List) ToResponse() ListResponse {
+	return ListResponse{
+		ID:           l.ID,
+		Name:         l.Name,
+		EstimateUnit: l.EstimateUnit,
+		CreatedAt:    l.CreatedAt,
+		UpdatedAt:    l.UpdatedAt,
+	}
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type OperationKind string
+
+const (
+	OperationCreate      OperationKind = "create"
+	OperationUpdate      OperationKind = "update"
+	OperationDelete      OperationKind = "delete"
+	OperationRestore     OperationKind = "restore"
+	OperationSkip        OperationKind = "skip"
+	OperationEndSeries   OperationKind = "end_series"
+	OperationBulk        OperationKind = "bulk"
+	OperationMove        OperationKind = "move"
+	OperationInstantiate OperationKind = "instantiate"
+	OperationUndo        OperationKind = "undo"
+	OperationRedo        OperationKind = "redo"
+)
+
+// Operation is one entry of a user's operation log: a single call that
+// changed one or more todos, whose history entries point back to it. Undo
+// reverts an operation using the before-images of those entries.
+type Operation struct {
+	ID        uuid.UUID     `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID    uuid.UUID     `json:"user_id" gorm:"type:uuid;not null;index:idx_operations_user_created,priority:1"`
+	Kind      OperationKind `json:"kind" gorm:"type:varchar(20);not null"`
+	ClientID  string        `json:"client_id,omitempty" gorm:"type:varchar(100)"`
+	RevertsID *uuid.UUID    `json:"reverts_id,omitempty" gorm:"type:uuid"` // operation undone (or redone) by this one
+	UndoneAt  *time.Time    `json:"undone_at,omitempty"`
+	CreatedAt time.Time     `json:"created_at" gorm:"index:idx_operations_user_created,priority:2"`
+
+	// Relationships
+	User User `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+type UndoRequest struct {
+	OpID *uuid.UUID `json:"op_id,omitempty"`
+}
+
+type OperationResponse struct {
+	ID        uuid.UUID     `json:"id"`
+	Kind      OperationKind `json:"kind"`
+	ClientID  string        `json:"client_id,omitempty"`
+	RevertsID *uuid.UUID    `json:"reverts_id,omitempty"`
+	Undone    bool          `json:"undone"`
+	UndoneAt  *time.Time    `json:"undone_at,omitempty"`
+	CreatedAt time.Time     `json:"created_at"`
+	TodoIDs   []uuid.UUID   `json:"todo_ids,omitempty"`
+}
+
+// UndoResponse describes an undo or redo and the operation it reverted
+type UndoResponse struct {
+	Operation OperationResponse `json:"operation"`
+	Reverted  OperationResponse `json:"reverted"`
+}
+
+func (o *Operation) ToResponse() OperationResponse {
+	return OperationResponse{
+		ID:        o.ID,
+		Kind:      o.Kind,
+		ClientID:  o.ClientID,
+		RevertsID: o.RevertsID,
+		Undone:    o.UndoneAt != nil,
+		UndoneAt:  o.UndoneAt,
+		CreatedAt: o.CreatedAt,
+	}
+}
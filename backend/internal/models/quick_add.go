@@ -0,0 +1,43 @@
+package models
+
+import (
+	"todo-backend/pkg/quickadd"
+
+	"github.com/google/uuid"
+)
+
+// QuickAddRequest creates a todo from free text such as
+// "Pay rent tomorrow 9am !3 #home @Personal"; ListID is the list when the
+// text names none
+type QuickAddRequest struct {
+	Text   string     `json:"text" validate:"required,min=1,max=500"`
+	ListID *uuid.UUID `json:"list_id,omitempty"`
+}
+
+// QuickAddResult is the todo parsed from a quick-add text and the tokens
+// that were recognized in it
+type QuickAddResult struct {
+	Request TodoCreateRequest
+	Tokens  []quickadd.Token
+}
+
+type QuickAddResponse struct {
+	Todo   *TodoResponse     `json:"todo,omitempty"`
+	Parsed TodoCreateRequest `json:"parsed"`
+	Tokens []quickadd.Token  `json:"tokens"`
+}
+
+func (r *QuickAddResult) ToResponse(todo *Todo) QuickAddResponse {
+	response := QuickAddResponse{
+		Parsed: r.Request,
+		Tokens: r.Tokens,
+	}
+	if response.Tokens == nil {
+		response.Tokens = []quickadd.Token{}
+	}
+	if todo != nil {
+		todoResponse := todo.ToResponse()
+		response.Todo = &todoResponse
+	}
+	return response
+}
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ReminderStatus string
+
+const (
+	ReminderStatusPending    ReminderStatus = "pending"
+	ReminderStatusProcessing ReminderStatus = "processing"
+	ReminderStatusSent       ReminderStatus = "sent"
+	ReminderStatusFailed     ReminderStatus = "failed"
+	ReminderStatusCancelled  ReminderStatus = "cancelled"
+)
+
+// Notification channels a reminder can be delivered through
+const (
+	ReminderChannelLog     = "log"
+	ReminderChannelWebhook = "webhook"
+	ReminderChannelEmail   = "email"
+)
+
+// Reminder fires either at an absolute RemindAt time or OffsetMinutes before
+// the todo's due date. FireAt is the resolved time the scheduler polls on.
+type Reminder struct {
+	ID            uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	TodoID        uuid.UUID      `json:"todo_id" gorm:"type:uuid;not null;index"`
+	UserID        uuid.UUID      `json:"user_id" gorm:"type:uuid;not null;index"`
+	RemindAt      *time.Time     `json:"remind_at,omitempty"`
+	OffsetMinutes *int           `json:"offset_minutes,omitempty"`
+	Channel       string         `json:"channel" gorm:"type:varchar(20);not null;default:'log'"`
+	FireAt        *time.Time     `json:"fire_at,omitempty" gorm:"index:idx_reminders_due,priority:2"`
+	Status        ReminderStatus `json:"status" gorm:"type:varchar(20);not null;default:'pending';index:idx_reminders_due,priority:1"`
+	Attempts      int            `json:"attempts" gorm:"default:0"`
+	LastError     string         `json:"last_error,omitempty" gorm:"type:text"`
+	ClaimedAt     *time.Time     `json:"-"`
+	SentAt        *time.Time     `json:"sent_at,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// Relationships
+	Todo Todo `json:"-" gorm:"foreignKey:TodoID;constraint:OnDelete:CASCADE"`
+	User User `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+// Resolve recomputes FireAt from the absolute time or the todo's due date.
+// Offset reminders on a todo without a due date have no fire time.
+func (r *Reminder) Resolve(dueDate *time.Time) {
+	switch {
+	case r.RemindAt != nil:
+		fireAt := *r.RemindAt
+		r.FireAt = &fireAt
+	case r.OffsetMinutes != nil && dueDate != nil:
+		fireAt := dueDate.Add(-time.Duration(*r.OffsetMinutes) * time.Minute)
+		r.FireAt = &fireAt
+	default:
+		r.FireAt = nil
+	}
+}
+
+type ReminderCreateRequest struct {
+	RemindAt      *time.Time `json:"remind_at,omitempty"`
+	OffsetMinutes *int       `json:"offset_minutes,omitempty" validate:"omitempty,min=0,max=525600"`
+	Channel       string     `json:"channel,omitempty" validate:"omitempty,oneof=log webhook email"`
+}
+
+type ReminderResponse struct {
+	ID            uuid.UUID      `json:"id"`
+	TodoID        uuid.UUID      `json:"todo_id"`
+	RemindAt      *time.Time     `json:"remind_at,omitempty"`
+	OffsetMinutes *int           `json:"offset_minutes,omitempty"`
+	Channel       string         `json:"channel"`
+	FireAt        *time.Time     `json:"fire_at,omitempty"`
+	Status        ReminderStatus `json:"status"`
+	SentAt        *time.Time     `json:"sent_at,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
+}
+
+func (r *Reminder) ToResponse() ReminderResponse {
+	return ReminderResponse{
+		ID:            r.ID,
+		TodoID:        r.TodoID,
+		RemindAt:      r.RemindAt,
+		OffsetMinutes: r.OffsetMinutes,
+		Channel:       r.Channel,
+		FireAt:        r.FireAt,
+		Status:        r.Status,
+		SentAt:        r.SentAt,
+		CreatedAt:     r.CreatedAt,
+	}
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tag is a per-user label attached to todos by name
+type Tag struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_tags_user_name"`
+	Name      string    `json:"name" gorm:"type:varchar(50);not null;uniqueIndex:idx_tags_user_name"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Relationships
+	User User `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+// NormalizeTagName trims and lowercases a tag so "Work" and " work" are the same tag
+func NormalizeTagName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
@@ -0,0 +1,303 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Limits of a template's todo tree
+const (
+	TemplateMaxItems = 200
+	TemplateMaxDepth = 5
+)
+
+// templateDueTime is the time of day of a due date without a due_time
+const templateDueTime = "09:00"
+
+// Template is a reusable todo tree, e.g. a release checklist, instantiated
+// with due dates relative to an anchor date
+type Template struct {
+	ID          uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID      uuid.UUID      `json:"user_id" gorm:"type:uuid;not null;index"`
+	Name        string         `json:"name" gorm:"type:varchar(100);not null"`
+	Description string         `json:"description" gorm:"type:text"`
+	Items       TemplateItems  `json:"items" gorm:"type:jsonb;not null"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// Relationships
+	User User `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+// TemplateItem is one todo of a template. Its due date is DueOffsetDays
+// after the anchor date (before it when negative) at DueTime (HH:MM, default
+// 09:00) in the user's timezone; without an offset the todo has no due date.
+type TemplateItem struct {
+	Title         string         `json:"title" validate:"required,min=1,max=255"`
+	Description   string         `json:"description,omitempty" validate:"max=1000"`
+	Priority      int            `json:"priority,omitempty" validate:"min=0,max=5"`
+	Estimate      *float64       `json:"estimate,omitempty" validate:"omitempty,min=0,max=100000"`
+	Tags          []string       `json:"tags,omitempty" validate:"max=20,dive,min=1,max=50"`
+	DueOffsetDays *int           `json:"due_offset_days,omitempty" validate:"omitempty,min=-3650,max=3650"`
+	DueTime       string         `json:"due_time,omitempty" validate:"max=5"`
+	Subtasks      []TemplateItem `json:"subtasks,omitempty" validate:"max=200,dive"`
+}
+
+// TemplateItems is stored as a JSON array
+type TemplateItems []TemplateItem
+
+func (items TemplateItems) Value() (driver.Value, error) {
+	if items == nil {
+		items = TemplateItems{}
+	}
+	data, err := json.Marshal(items)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (items *TemplateItems) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*items = TemplateItems{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, items)
+	case string:
+		return json.Unmarshal([]byte(v), items)
+	}
+	return errors.New("unsupported type for template items")
+}
+
+// Count returns the number of todos in the tree
+func (items TemplateItems) Count() int {
+	count := 0
+	for _, item := range items {
+		count += 1 + TemplateItems(item.Subtasks).Count()
+	}
+	return count
+}
+
+// Validate checks what the struct tags can't: the size and depth of the tree
+// and the due times
+func (items TemplateItems) Validate() error {
+	if len(items) == 0 {
+		return errors.New("invalid template: at least one item is required")
+	}
+	if count := items.Count(); count > TemplateMaxItems {
+		return fmt.Errorf("invalid template: at most %d items, got %d", TemplateMaxItems, count)
+	}
+	return items.validate(1)
+}
+
+func (items TemplateItems) validate(depth int) error {
+	if depth > TemplateMaxDepth {
+		return fmt.Errorf("invalid template: subtasks nest at most %d levels", TemplateMaxDepth)
+	}
+	for _, item := range items {
+		if item.DueTime != "" {
+			if item.DueOffsetDays == nil {
+				return fmt.Errorf("invalid template: item %q has a due_time without a due_offset_days", item.Title)
+			}
+			if _, err := time.Parse("15:04", item.DueTime); err != nil {
+				return fmt.Errorf("invalid template: item %q has due_time %q, expected HH:MM", item.Title, item.DueTime)
+			}
+		}
+		if err := TemplateItems(item.Subtasks).validate(depth + 1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DueDate resolves the item's due date from the start of the anchor day,
+// keeping the wall-clock time across DST changes
+func (item *TemplateItem) DueDate(anchor time.Time) *time.Time {
+	if item.DueOffsetDays == nil {
+		return nil
+	}
+	dueTime := item.DueTime
+	if dueTime == "" {
+		dueTime = templateDueTime
+	}
+	clock, err := time.Parse("15:04", dueTime)
+	if err != nil {
+		return nil
+	}
+	day := anchor.AddDate(0, 0, *item.DueOffsetDays)
+	due := time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(), 0, 0, anchor.Location())
+	return &due
+}
+
+// TodoTree turns the items into todo create requests filed into listID, with
+// due dates resolved from anchor
+func (items TemplateItems) TodoTree(anchor time.Time, listID *uuid.UUID) []TodoTreeRequest {
+	tree := make([]TodoTreeRequest, 0, len(items))
+	for i := range items {
+		item := &items[i]
+		tree = append(tree, TodoTreeRequest{
+			TodoCreateRequest: TodoCreateRequest{
+				Title:       item.Title,
+				Description: item.Description,
+				Priority:    item.Priority,
+				Estimate:    item.Estimate,
+				DueDate:     item.DueDate(anchor),
+				ListID:      listID,
+				Tags:        item.Tags,
+			},
+			Subtasks: TemplateItems(item.Subtasks).TodoTree(anchor, listID),
+		})
+	}
+	return tree
+}
+
+// NewTemplateItems captures todos as template items. Todos whose parent is
+// not among them become top-level items and siblings keep their manual
+// order. Due dates become offsets from the earliest due day, in loc.
+func NewTemplateItems(todos []Todo, loc *time.Location) TemplateItems {
+	byID := make(map[uuid.UUID]bool, len(todos))
+	for i := range todos {
+		byID[todos[i].ID] = true
+	}
+
+	var anchor *time.Time
+	children := make(map[uuid.UUID][]*Todo)
+	var roots []*Todo
+	for i := range todos {
+		todo := &todos[i]
+		if todo.DueDate != nil {
+			day := startOfDayIn(*todo.DueDate, loc)
+			if anchor == nil || day.Before(*anchor) {
+				anchor = &day
+			}
+		}
+		if todo.ParentID != nil && byID[*todo.ParentID] && *todo.ParentID != todo.ID {
+			children[*todo.ParentID] = append(children[*todo.ParentID], todo)
+		} else {
+			roots = append(roots, todo)
+		}
+	}
+
+	seen := make(map[uuid.UUID]bool, len(todos))
+	var build func(todos []*Todo) []TemplateItem
+	build = func(todos []*Todo) []TemplateItem {
+		sortTodosByPosition(todos)
+		var items []TemplateItem
+		for _, todo := range todos {
+			if seen[todo.ID] {
+				continue
+			}
+			seen[todo.ID] = true
+
+			item := TemplateItem{
+				Title:       todo.Title,
+				Description: todo.Description,
+				Priority:    todo.Priority,
+				Estimate:    todo.Estimate,
+			}
+			if len(todo.Tags) > 0 {
+				item.Tags = todo.TagNames()
+			}
+			if todo.DueDate != nil {
+				due := todo.DueDate.In(loc)
+				offset := daysBetween(*anchor, startOfDayIn(due, loc))
+				item.DueOffsetDays = &offset
+				item.DueTime = due.Format("15:04")
+			}
+			item.Subtasks = build(children[todo.ID])
+			items = append(items, item)
+		}
+		return items
+	}
+	return build(roots)
+}
+
+// sortTodosByPosition sorts todos in manual order, unpositioned ones last and
+// oldest first
+func sortTodosByPosition(todos []*Todo) {
+	sort.SliceStable(todos, func(i, j int) bool {
+		a, b := todos[i], todos[j]
+		switch {
+		case a.Position != nil && b.Position != nil && *a.Position != *b.Position:
+			return *a.Position < *b.Position
+		case (a.Position == nil) != (b.Position == nil):
+			return a.Position != nil
+		}
+		return a.CreatedAt.Before(b.CreatedAt)
+	})
+}
+
+func startOfDayIn(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// daysBetween counts calendar days from one day start to another
+func daysBetween(from, to time.Time) int {
+	a := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	b := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(b.Sub(a).Hours() / 24)
+}
+
+type TemplateCreateRequest struct {
+	Name        string         `json:"name" validate:"required,min=1,max=100"`
+	Description string         `json:"description" validate:"max=1000"`
+	Items       []TemplateItem `json:"items" validate:"required,min=1,max=200,dive"`
+}
+
+// TemplateUpdateRequest edits a template; Items replaces the tree when present
+type TemplateUpdateRequest struct {
+	Name        string         `json:"name" validate:"omitempty,min=1,max=100"`
+	Description string         `json:"description" validate:"max=1000"`
+	Items       []TemplateItem `json:"items" validate:"omitempty,max=200,dive"`
+}
+
+// TemplateSaveRequest names a template saved from a todo or list; the name
+// defaults to the todo's title or list's name
+type TemplateSaveRequest struct {
+	Name        string `json:"name" validate:"omitempty,min=1,max=100"`
+	Description string `json:"description" validate:"max=1000"`
+}
+
+// TemplateInstantiateRequest creates a template's todos in ListID (none for
+// the inbox) with due dates counted from AnchorDate (YYYY-MM-DD in the user's
+// timezone, default today)
+type TemplateInstantiateRequest struct {
+	ListID     *uuid.UUID `json:"list_id,omitempty"`
+	AnchorDate string     `json:"anchor_date,omitempty" validate:"max=10"`
+}
+
+type TemplateResponse struct {
+	ID          uuid.UUID      `json:"id"`
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Items       []TemplateItem `json:"items"`
+	ItemCount   int            `json:"item_count"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+func (t *Template) ToResponse() TemplateResponse {
+	items := t.Items
+	if items == nil {
+		items = TemplateItems{}
+	}
+	return TemplateResponse{
+		ID:          t.ID,
+		Name:        t.Name,
+		Description: t.Description,
+		Items:       items,
+		ItemCount:   items.Count(),
+		CreatedAt:   t.CreatedAt,
+		UpdatedAt:   t.UpdatedAt,
+	}
+}
@@ -0,0 +1,135 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func days(n int) *int { return &n }
+
+func TestTemplateItemsValidate(t *testing.T) {
+	deep := TemplateItem{Title: "level 6"}
+	for i := 5; i >= 1; i-- {
+		deep = TemplateItem{Title: "level", Subtasks: []TemplateItem{deep}}
+	}
+	many := make(TemplateItems, TemplateMaxItems+1)
+	for i := range many {
+		many[i] = TemplateItem{Title: "item"}
+	}
+
+	tests := []struct {
+		name    string
+		items   TemplateItems
+		wantErr string
+	}{
+		{name: "valid", items: TemplateItems{{Title: "Tag release", DueOffsetDays: days(0), DueTime: "17:30"}}},
+		{name: "empty", items: TemplateItems{}, wantErr: "invalid template: at least one item is required"},
+		{name: "too many", items: many, wantErr: "invalid template: at most 200 items, got 201"},
+		{name: "too deep", items: TemplateItems{deep}, wantErr: "invalid template: subtasks nest at most 5 levels"},
+		{
+			name:    "time without offset",
+			items:   TemplateItems{{Title: "Ship", DueTime: "09:00"}},
+			wantErr: `invalid template: item "Ship" has a due_time without a due_offset_days`,
+		},
+		{
+			name:    "bad time",
+			items:   TemplateItems{{Title: "Ship", DueOffsetDays: days(1), DueTime: "9am"}},
+			wantErr: `invalid template: item "Ship" has due_time "9am", expected HH:MM`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.items.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTemplateItemDueDate(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("timezone data not available")
+	}
+	// Clocks go forward on 2024-03-31
+	anchor := time.Date(2024, 3, 29, 0, 0, 0, 0, loc)
+
+	tests := []struct {
+		name string
+		item TemplateItem
+		want *time.Time
+	}{
+		{name: "no offset", item: TemplateItem{}, want: nil},
+		{name: "default time", item: TemplateItem{DueOffsetDays: days(0)}, want: timePtr(time.Date(2024, 3, 29, 9, 0, 0, 0, loc))},
+		{name: "before the anchor", item: TemplateItem{DueOffsetDays: days(-3), DueTime: "17:30"}, want: timePtr(time.Date(2024, 3, 26, 17, 30, 0, 0, loc))},
+		{name: "across DST", item: TemplateItem{DueOffsetDays: days(3), DueTime: "08:00"}, want: timePtr(time.Date(2024, 4, 1, 8, 0, 0, 0, loc))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.item.DueDate(anchor)
+			if tt.want == nil {
+				assert.Nil(t, got)
+				return
+			}
+			require.NotNil(t, got)
+			assert.True(t, tt.want.Equal(*got), "got %s, want %s", got, tt.want)
+		})
+	}
+}
+
+func TestTemplateTodoTree(t *testing.T) {
+	listID := uuid.New()
+	anchor := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	items := TemplateItems{{
+		Title: "Release",
+		Tags:  []string{"release"},
+		Subtasks: []TemplateItem{
+			{Title: "Freeze", DueOffsetDays: days(-2)},
+			{Title: "Tag", DueOffsetDays: days(0), DueTime: "16:00"},
+		},
+	}}
+
+	tree := items.TodoTree(anchor, &listID)
+	require.Len(t, tree, 1)
+	assert.Equal(t, "Release", tree[0].Title)
+	assert.Nil(t, tree[0].DueDate)
+	assert.Equal(t, &listID, tree[0].ListID)
+	require.Len(t, tree[0].Subtasks, 2)
+	assert.Equal(t, time.Date(2024, 2, 28, 9, 0, 0, 0, time.UTC), *tree[0].Subtasks[0].DueDate)
+	assert.Equal(t, time.Date(2024, 3, 1, 16, 0, 0, 0, time.UTC), *tree[0].Subtasks[1].DueDate)
+	assert.Equal(t, &listID, tree[0].Subtasks[1].ListID)
+}
+
+func TestNewTemplateItems(t *testing.T) {
+	first, second := "a", "b"
+	root, freeze, tag, other := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	outside := uuid.New()
+	todos := []Todo{
+		{ID: tag, Title: "Tag", ParentID: &root, Position: &second, DueDate: timePtr(time.Date(2024, 3, 5, 16, 0, 0, 0, time.UTC))},
+		{ID: root, Title: "Release", Tags: []Tag{{Name: "release"}}},
+		{ID: freeze, Title: "Freeze", ParentID: &root, Position: &first, DueDate: timePtr(time.Date(2024, 3, 3, 9, 30, 0, 0, time.UTC))},
+		{ID: other, Title: "Announce", ParentID: &outside},
+	}
+
+	items := NewTemplateItems(todos, time.UTC)
+
+	require.Len(t, items, 2)
+	assert.Equal(t, "Release", items[0].Title)
+	assert.Equal(t, []string{"release"}, items[0].Tags)
+	assert.Nil(t, items[0].DueOffsetDays)
+	require.Len(t, items[0].Subtasks, 2)
+	assert.Equal(t, TemplateItem{Title: "Freeze", DueOffsetDays: days(0), DueTime: "09:30"}, items[0].Subtasks[0])
+	assert.Equal(t, TemplateItem{Title: "Tag", DueOffsetDays: days(2), DueTime: "16:00"}, items[0].Subtasks[1])
+	assert.Equal(t, "Announce", items[1].Title, "a todo whose parent is not saved becomes top-level")
+}
+
+func timePtr(t time.Time) *time.Time { return &t }
@@ -0,0 +1,171 @@
+package models
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TimeEntry is time spent on a todo. A running timer is an entry without an
+// end; each user has at most one.
+type TimeEntry struct {
+	ID        uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	TodoID    uuid.UUID      `json:"todo_id" gorm:"type:uuid;not null;index"`
+	UserID    uuid.UUID      `json:"user_id" gorm:"type:uuid;not null;index:idx_time_entries_user_started,priority:1;uniqueIndex:idx_time_entries_running,where:ended_at IS NULL AND deleted_at IS NULL"`
+	StartedAt time.Time      `json:"started_at" gorm:"not null;index:idx_time_entries_user_started,priority:2"`
+	EndedAt   *time.Time     `json:"ended_at,omitempty"`
+	Duration  int64          `json:"duration_seconds" gorm:"column:duration_seconds;not null;default:0"` // set when the entry ends
+	Note      string         `json:"note" gorm:"type:text"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// Relationships
+	Todo Todo `json:"-" gorm:"foreignKey:TodoID;constraint:OnDelete:CASCADE"`
+	User User `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+// IsRunning reports whether the entry is a timer that has not been stopped
+func (e *TimeEntry) IsRunning() bool {
+	return e.EndedAt == nil
+}
+
+// SetTimes sets the start and end of the entry and its duration; a nil end
+// leaves the timer running. Entries can't start in the future or end before
+// they start.
+func (e *TimeEntry) SetTimes(start time.Time, end *time.Time, now time.Time) error {
+	if start.After(now) {
+		return errors.New("invalid time entry: started_at is in the future")
+	}
+	e.StartedAt = start
+	e.EndedAt = nil
+	e.Duration = 0
+	if end == nil {
+		return nil
+	}
+	if !end.After(start) {
+		return errors.New("invalid time entry: ended_at must be after started_at")
+	}
+	e.EndedAt = end
+	e.Duration = int64(end.Sub(start) / time.Second)
+	return nil
+}
+
+type TimerStartRequest struct {
+	Note string `json:"note" validate:"max=1000"`
+}
+
+type TimeEntryCreateRequest struct {
+	StartedAt time.Time `json:"started_at" validate:"required"`
+	EndedAt   time.Time `json:"ended_at" validate:"required"`
+	Note      string    `json:"note" validate:"max=1000"`
+}
+
+// TimeEntryUpdateRequest edits an entry; omitted fields are kept
+type TimeEntryUpdateRequest struct {
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"`
+	Note      *string    `json:"note,omitempty" validate:"omitempty,max=1000"`
+}
+
+type TimeEntryResponse struct {
+	ID        uuid.UUID  `json:"id"`
+	TodoID    uuid.UUID  `json:"todo_id"`
+	StartedAt time.Time  `json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"`
+	Running   bool       `json:"running"`
+	Duration  int64      `json:"duration_seconds"` // elapsed so far while running
+	Note      string     `json:"note"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+func (e *TimeEntry) ToResponse() TimeEntryResponse {
+	duration := e.Duration
+	if e.IsRunning() {
+		duration = int64(time.Since(e.StartedAt) / time.Second)
+	}
+	return TimeEntryResponse{
+		ID:        e.ID,
+		TodoID:    e.TodoID,
+		StartedAt: e.StartedAt,
+		EndedAt:   e.EndedAt,
+		Running:   e.IsRunning(),
+		Duration:  duration,
+		Note:      e.Note,
+		CreatedAt: e.CreatedAt,
+		UpdatedAt: e.UpdatedAt,
+	}
+}
+
+// TimerResponse is the result of starting a timer; Stopped is the timer that
+// was running before, if any
+type TimerResponse struct {
+	Entry   TimeEntryResponse  `json:"entry"`
+	Stopped *TimeEntryResponse `json:"stopped,omitempty"`
+}
+
+// Groupings of a time report
+const (
+	TimeReportByDay  = "day"
+	TimeReportByList = "list"
+	TimeReportByTag  = "tag"
+)
+
+// TimeReportRow is the time logged for one day, list or tag. Key is the date
+// (YYYY-MM-DD), list ID or tag name, empty for todos without a list or tag.
+type TimeReportRow struct {
+	Key     string `json:"key"`
+	Label   string `json:"label"`
+	Seconds int64  `json:"seconds"`
+	Entries int64  `json:"entries"`
+}
+
+// TimeReport aggregates the finished time entries started in [From, To], both
+// dates in Timezone. An entry counts for every tag of its todo, so tag rows
+// can add up to more than TotalSeconds.
+type TimeReport struct {
+	From         string          `json:"from"`
+	To           string          `json:"to"`
+	Timezone     string          `json:"timezone"`
+	GroupBy      string          `json:"group_by"`
+	TotalSeconds int64           `json:"total_seconds"`
+	Rows         []TimeReportRow `json:"rows"`
+}
+
+// WriteCSV writes the report rows as CSV with a header line
+func (r *TimeReport) WriteCSV(w io.Writer) error {
+	out := csv.NewWriter(w)
+	if err := out.Write([]string{r.GroupBy, "label", "seconds", "hours", "entries"}); err != nil {
+		return err
+	}
+	for _, row := range r.Rows {
+		record := []string{
+			csvCell(row.Key),
+			csvCell(row.Label),
+			strconv.FormatInt(row.Seconds, 10),
+			fmt.Sprintf("%.2f", float64(row.Seconds)/3600),
+			strconv.FormatInt(row.Entries, 10),
+		}
+		if err := out.Write(record); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// csvCell keeps user-entered text from being read as a formula by spreadsheets
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
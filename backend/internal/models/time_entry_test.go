@@ -0,0 +1,64 @@
+package models
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeEntrySetTimes(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	hourAgo := now.Add(-time.Hour)
+
+	tests := []struct {
+		name         string
+		start        time.Time
+		end          *time.Time
+		wantRunning  bool
+		wantDuration int64
+		wantErr      string
+	}{
+		{name: "finished", start: hourAgo, end: &now, wantDuration: 3600},
+		{name: "running", start: hourAgo, wantRunning: true},
+		{name: "starts in the future", start: now.Add(time.Minute), wantErr: "invalid time entry: started_at is in the future"},
+		{name: "ends before it starts", start: now, end: &hourAgo, wantErr: "invalid time entry: ended_at must be after started_at"},
+		{name: "ends when it starts", start: now, end: &now, wantErr: "invalid time entry: ended_at must be after started_at"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := TimeEntry{Duration: 99}
+			err := entry.SetTimes(tt.start, tt.end, now)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantRunning, entry.IsRunning())
+			assert.Equal(t, tt.wantDuration, entry.Duration)
+		})
+	}
+}
+
+func TestTimeReportWriteCSV(t *testing.T) {
+	report := TimeReport{
+		GroupBy: TimeReportByTag,
+		Rows: []TimeReportRow{
+			{Key: "work", Label: "work", Seconds: 5400, Entries: 3},
+			{Key: "=cmd()", Label: "=cmd()", Seconds: 60, Entries: 1},
+			{Seconds: 1800, Entries: 1},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteCSV(&buf))
+
+	want := "tag,label,seconds,hours,entries\n" +
+		"work,work,5400,1.50,3\n" +
+		"'=cmd(),'=cmd(),60,0.02,1\n" +
+		",,1800,0.50,1\n"
+	assert.Equal(t, want, buf.String())
+}
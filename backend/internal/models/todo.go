@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
@@ -22,29 +23,164 @@ type Todo struct {
 	Status      TodoStatus     `json:"status" gorm:"type:varchar(20);default:'pending'" validate:"required,oneof=pending in_progress completed"`
 	Priority    int            `json:"priority" gorm:"default:0" validate:"min=0,max=5"`
 	DueDate     *time.Time     `json:"due_date,omitempty"`
-	UserID      uuid.UUID      `json:"user_id" gorm:"type:uuid;not null;index"`
+	UserID      uuid.UUID      `json:"user_id" gorm:"type:uuid;not null;index;uniqueIndex:idx_todos_user_ical_uid,priority:1,where:ical_uid IS NOT NULL AND deleted_at IS NULL;uniqueIndex:idx_todos_user_caldav_name,priority:1,where:caldav_name IS NOT NULL AND deleted_at IS NULL"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
 
+	// Recurrence (RFC 5545 RRULE evaluated in Timezone)
+	RecurrenceRule  string     `json:"recurrence_rule,omitempty" gorm:"type:text"`
+	Timezone        string     `json:"timezone,omitempty" gorm:"type:varchar(64)"`
+	SeriesID        *uuid.UUID `json:"series_id,omitempty" gorm:"type:uuid;index"`
+	RecurrenceStart *time.Time `json:"-"` // DTSTART of the series, used for COUNT/INTERVAL alignment
+
+	// Key of the todo's state on a list with a custom workflow, kept in step
+	// with Status; nil on lists using the default workflow
+	State *string `json:"state,omitempty" gorm:"type:varchar(50)"`
+
+	// Manual order: a rank key (see pkg/rank) compared byte-wise; todos
+	// without one sort after those with one
+	Position *string `json:"position,omitempty" gorm:"type:varchar(64);index"`
+
+	// Effort estimate in the unit of the todo's list (minutes or points)
+	Estimate *float64 `json:"estimate,omitempty" gorm:"type:numeric(10,2)"`
+
+	// Subtasks point at the todo they belong to
+	ParentID *uuid.UUID `json:"parent_id,omitempty" gorm:"type:uuid;index"`
+
+	// CalDAV identity of todos created by a CalDAV client: the client's UID
+	// and resource name, each unique among the user's todos outside the
+	// trash. Other todos use their ID for both.
+	ICalUID    *string `json:"-" gorm:"column:ical_uid;type:varchar(255);uniqueIndex:idx_todos_user_ical_uid,priority:2"`
+	CalDAVName *string `json:"-" gorm:"column:caldav_name;type:varchar(255);uniqueIndex:idx_todos_user_caldav_name,priority:2"`
+
+	// IDs of unfinished todos this one depends on, loaded by the repository
+	BlockingIDs []uuid.UUID `json:"-" gorm:"-"`
+
+	// Organisation
+	ListID *uuid.UUID `json:"list_id,omitempty" gorm:"type:uuid;index"`
+	Tags   []Tag      `json:"tags,omitempty" gorm:"many2many:todo_tags;constraint:OnDelete:CASCADE"`
+
 	// Relationships
-	User User `json:"user,omitempty" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	User   User  `json:"user,omitempty" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	List   *List `json:"-" gorm:"foreignKey:ListID;constraint:OnDelete:SET NULL"`
+	Parent *Todo `json:"-" gorm:"foreignKey:ParentID;constraint:OnDelete:SET NULL"`
+}
+
+// TagNames returns the names of the todo's tags
+func (t *Todo) TagNames() []string {
+	names := make([]string, 0, len(t.Tags))
+	for _, tag := range t.Tags {
+		names = append(names, tag.Name)
+	}
+	return names
+}
+
+// IsBlocked reports whether any dependency of the todo is still unfinished
+func (t *Todo) IsBlocked() bool {
+	return len(t.BlockingIDs) > 0
+}
+
+// IsRecurring reports whether completing the todo generates a next occurrence
+func (t *Todo) IsRecurring() bool {
+	return t.RecurrenceRule != ""
 }
 
+// Version identifies the todo's current state; it changes with every update.
+// Timestamps are compared at the database's microsecond precision.
+func (t *Todo) Version() string {
+	return strconv.FormatInt(t.UpdatedAt.UnixMicro(), 36)
+}
+
+// UID returns the todo's iCalendar UID
+func (t *Todo) UID() string {
+	if t.ICalUID != nil {
+		return *t.ICalUID
+	}
+	return t.ID.String()
+}
+
+// TodoMoveRequest places a todo between two others in the manual order. One
+// neighbour is enough: the todo then goes right after AfterID or right
+// before BeforeID. State moves the todo to another column of its list's
+// board, subject to the workflow's transitions and WIP limits.
+type TodoMoveRequest struct {
+	AfterID  *uuid.UUID `json:"after_id,omitempty"`
+	BeforeID *uuid.UUID `json:"before_id,omitempty"`
+	State    string     `json:"state,omitempty" validate:"max=50"`
+}
+
+// Edit scopes for recurring todos
+const (
+	TodoScopeInstance = "instance"
+	TodoScopeSeries   = "series"
+)
+
 type TodoCreateRequest struct {
-	Title       string     `json:"title" validate:"required,min=1,max=255"`
-	Description string     `json:"description" validate:"max=1000"`
-	Status      TodoStatus `json:"status" validate:"omitempty,oneof=pending in_progress completed"`
-	Priority    int        `json:"priority" validate:"min=0,max=5"`
-	DueDate     *time.Time `json:"due_date,omitempty"`
+	Title          string     `json:"title" validate:"required,min=1,max=255"`
+	Description    string     `json:"description" validate:"max=1000"`
+	Status         TodoStatus `json:"status" validate:"omitempty,oneof=pending in_progress completed"`
+	Priority       int        `json:"priority" validate:"min=0,max=5"`
+	DueDate        *time.Time `json:"due_date,omitempty"`
+	RecurrenceRule string     `json:"recurrence_rule,omitempty" validate:"max=500"`
+	Timezone       string     `json:"timezone,omitempty" validate:"max=64"`
+	ListID         *uuid.UUID `json:"list_id,omitempty"`
+	Tags           []string   `json:"tags,omitempty" validate:"max=20,dive,min=1,max=50"`
+	Estimate       *float64   `json:"estimate,omitempty" validate:"omitempty,min=0,max=100000"`
+	ParentID       *uuid.UUID `json:"parent_id,omitempty"`
+
+	// CalDAV identity, set for todos created by a CalDAV client
+	ICalUID    *string `json:"-"`
+	CalDAVName *string `json:"-"`
+	// State on the list's board, set by imports of this app's exports
+	State string `json:"-"`
+}
+
+// TodoTreeRequest creates a todo together with its subtasks
+type TodoTreeRequest struct {
+	TodoCreateRequest
+	Subtasks []TodoTreeRequest
 }
 
 type TodoUpdateRequest struct {
-	Title       string     `json:"title" validate:"omitempty,min=1,max=255"`
-	Description string     `json:"description" validate:"max=1000"`
-	Status      TodoStatus `json:"status" validate:"omitempty,oneof=pending in_progress completed"`
-	Priority    int        `json:"priority" validate:"min=0,max=5"`
-	DueDate     *time.Time `json:"due_date,omitempty"`
+	Title          string     `json:"title" validate:"omitempty,min=1,max=255"`
+	Description    string     `json:"description" validate:"max=1000"`
+	Status         TodoStatus `json:"status" validate:"omitempty,oneof=pending in_progress completed"`
+	Priority       int        `json:"priority" validate:"min=0,max=5"`
+	DueDate        *time.Time `json:"due_date,omitempty"`
+	RecurrenceRule string     `json:"recurrence_rule,omitempty" validate:"max=500"`
+	Timezone       string     `json:"timezone,omitempty" validate:"max=64"`
+	// ListID moves the todo to a list; the nil UUID removes it from its list
+	ListID *uuid.UUID `json:"list_id,omitempty"`
+	// Tags replaces the todo's tags when present; an empty array clears them
+	Tags []string `json:"tags" validate:"omitempty,max=20,dive,min=1,max=50"`
+	// Estimate sets the effort estimate; a negative value clears it
+	Estimate *float64 `json:"estimate,omitempty" validate:"omitempty,max=100000"`
+	// ParentID makes the todo a subtask; the nil UUID makes it top-level again
+	ParentID *uuid.UUID `json:"parent_id,omitempty"`
+	// Scope selects whether a recurring todo is edited on its own ("instance")
+	// or together with the other open occurrences of its series ("series")
+	Scope string `json:"scope,omitempty" validate:"omitempty,oneof=instance series"`
+	// Clear resets fields that empty values leave unchanged: "description",
+	// "priority" and "due_date". Recurring todos need their due date.
+	Clear []string `json:"clear,omitempty" validate:"omitempty,max=3,dive,oneof=description priority due_date"`
+}
+
+// Fields a todo update can clear
+const (
+	TodoFieldDescription = "description"
+	TodoFieldPriority    = "priority"
+	TodoFieldDueDate     = "due_date"
+)
+
+// Clears reports whether the update clears field
+func (r *TodoUpdateRequest) Clears(field string) bool {
+	for _, f := range r.Clear {
+		if f == field {
+			return true
+		}
+	}
+	return false
 }
 
 type TodoResponse struct {
@@ -57,6 +193,55 @@ type TodoResponse struct {
 	UserID      uuid.UUID  `json:"user_id"`
 	CreatedAt   time.Time  `json:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at"`
+
+	RecurrenceRule string     `json:"recurrence_rule,omitempty"`
+	Timezone       string     `json:"timezone,omitempty"`
+	SeriesID       *uuid.UUID `json:"series_id,omitempty"`
+
+	Blocked     bool        `json:"blocked"`
+	BlockingIDs []uuid.UUID `json:"blocking_ids"`
+
+	ListID   *uuid.UUID `json:"list_id,omitempty"`
+	Tags     []string   `json:"tags"`
+	Position *string    `json:"position,omitempty"`
+	State    *string    `json:"state,omitempty"`
+	Estimate *float64   `json:"estimate,omitempty"`
+	ParentID *uuid.UUID `json:"parent_id,omitempty"`
+}
+
+// TodoSearchResponse is a todo matching a search with its relevance and a
+// highlighted excerpt of the matching text
+type TodoSearchResponse struct {
+	TodoResponse
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
+
+// TodoDayResponse groups the todos due on one calendar day
+type TodoDayResponse struct {
+	Date    string         `json:"date"` // YYYY-MM-DD in the user's timezone
+	Weekday string         `json:"weekday"`
+	Todos   []TodoResponse `json:"todos"`
+}
+
+// UpcomingResponse is the upcoming view. Truncated is set when more todos are
+// due in the range than the view returns; the last days are then incomplete.
+type UpcomingResponse struct {
+	Days      []TodoDayResponse `json:"days"`
+	Truncated bool              `json:"truncated"`
+}
+
+// TrashedTodoResponse is a deleted todo that can still be restored
+type TrashedTodoResponse struct {
+	TodoResponse
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+func (t *Todo) ToTrashedResponse() TrashedTodoResponse {
+	return TrashedTodoResponse{
+		TodoResponse: t.ToResponse(),
+		DeletedAt:    t.DeletedAt.Time,
+	}
 }
 
 type TodoWithUserResponse struct {
@@ -65,6 +250,11 @@ type TodoWithUserResponse struct {
 }
 
 func (t *Todo) ToResponse() TodoResponse {
+	blockingIDs := t.BlockingIDs
+	if blockingIDs == nil {
+		blockingIDs = []uuid.UUID{}
+	}
+
 	return TodoResponse{
 		ID:          t.ID,
 		Title:       t.Title,
@@ -75,6 +265,20 @@ func (t *Todo) ToResponse() TodoResponse {
 		UserID:      t.UserID,
 		CreatedAt:   t.CreatedAt,
 		UpdatedAt:   t.UpdatedAt,
+
+		RecurrenceRule: t.RecurrenceRule,
+		Timezone:       t.Timezone,
+		SeriesID:       t.SeriesID,
+
+		Blocked:     t.IsBlocked(),
+		BlockingIDs: blockingIDs,
+
+		ListID:   t.ListID,
+		Tags:     t.TagNames(),
+		Position: t.Position,
+		State:    t.State,
+		Estimate: t.Estimate,
+		ParentID: t.ParentID,
 	}
 }
 
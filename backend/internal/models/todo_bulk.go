@@ -0,0 +1,53 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+// TodoBulkAction is an operation applied to every todo of a bulk request
+type TodoBulkAction string
+
+const (
+	TodoBulkComplete    TodoBulkAction = "complete"
+	TodoBulkSetStatus   TodoBulkAction = "set_status"
+	TodoBulkSetPriority TodoBulkAction = "set_priority"
+	TodoBulkMove        TodoBulkAction = "move"
+	TodoBulkAddTag      TodoBulkAction = "add_tag"
+	TodoBulkRemoveTag   TodoBulkAction = "remove_tag"
+	TodoBulkDelete      TodoBulkAction = "delete"
+)
+
+// TodoBulkMaxItems caps how many todos one bulk request may touch
+const TodoBulkMaxItems = 500
+
+// TodoBulkRequest applies one action to the todos listed in IDs or matched by
+// Filter, a query in the language of pkg/tql. Exactly one of them is set.
+// Status, Priority, ListID and Tag are the action's argument; a nil ListID
+// (or the nil UUID) moves the todos out of their list.
+type TodoBulkRequest struct {
+	Action   TodoBulkAction `json:"action" validate:"required,oneof=complete set_status set_priority move add_tag remove_tag delete"`
+	IDs      []uuid.UUID    `json:"ids,omitempty" validate:"max=500"`
+	Filter   string         `json:"filter,omitempty" validate:"max=1000"`
+	Status   TodoStatus     `json:"status,omitempty" validate:"omitempty,oneof=pending in_progress completed"`
+	Priority *int           `json:"priority,omitempty" validate:"omitempty,min=0,max=5"`
+	ListID   *uuid.UUID     `json:"list_id,omitempty"`
+	Tag      string         `json:"tag,omitempty" validate:"max=50"`
+
+	// Atomic rolls every change back when any todo fails
+	Atomic bool `json:"atomic,omitempty"`
+}
+
+// TodoBulkItemResult is the outcome of a bulk action for one todo
+type TodoBulkItemResult struct {
+	ID    uuid.UUID `json:"id"`
+	OK    bool      `json:"ok"`
+	Error string    `json:"error,omitempty"`
+}
+
+type TodoBulkResponse struct {
+	Action     TodoBulkAction       `json:"action"`
+	Succeeded  int                  `json:"succeeded"`
+	Failed     int                  `json:"failed"`
+	RolledBack bool                 `json:"rolled_back"`
+	Results    []TodoBulkItemResult `json:"results"`
+}
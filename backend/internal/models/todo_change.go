@@ -0,0 +1,281 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TodoChangeAction string
+
+const (
+	TodoChangeCreated  TodoChangeAction = "created"
+	TodoChangeUpdated  TodoChangeAction = "updated"
+	TodoChangeDeleted  TodoChangeAction = "deleted"
+	TodoChangeRestored TodoChangeAction = "restored"
+)
+
+// TodoChange is one entry of a todo's history: who changed which fields, when
+// and from which client
+type TodoChange struct {
+	ID        uuid.UUID        `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	TodoID    uuid.UUID        `json:"todo_id" gorm:"type:uuid;not null;index:idx_todo_changes_todo_created,priority:1"`
+	UserID    uuid.UUID        `json:"user_id" gorm:"type:uuid;not null;index"` // owner of the todo
+	ActorID   uuid.UUID        `json:"actor_id" gorm:"type:uuid;not null"`
+	Action    TodoChangeAction `json:"action" gorm:"type:varchar(20);not null"`
+	Changes   FieldChanges     `json:"changes" gorm:"type:jsonb;not null"`
+	ClientID  string           `json:"client_id,omitempty" gorm:"type:varchar(100)"`
+	CreatedAt time.Time        `json:"created_at" gorm:"index:idx_todo_changes_todo_created,priority:2"`
+
+	// Undo support: the operation the change belongs to and the todo as it
+	// was before (unset for creations)
+	OperationID *uuid.UUID `json:"operation_id,omitempty" gorm:"type:uuid;index"`
+	Before      *TodoImage `json:"-" gorm:"type:jsonb"`
+
+	// Seq orders changes by when they were written; changes made in one
+	// transaction share created_at, so undo replays them by seq
+	Seq int64 `json:"-" gorm:"autoIncrement;not null"`
+
+	// Relationships
+	Todo Todo `json:"-" gorm:"foreignKey:TodoID;constraint:OnDelete:CASCADE"`
+}
+
+// FieldChange is the old and new value of one field. Dates are RFC 3339
+// strings and tags a sorted list of names; nil means unset.
+type FieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// FieldChanges is stored as a JSON array
+type FieldChanges []FieldChange
+
+func (c FieldChanges) Value() (driver.Value, error) {
+	if c == nil {
+		c = FieldChanges{}
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (c *FieldChanges) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*c = FieldChanges{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, c)
+	case string:
+		return json.Unmarshal([]byte(v), c)
+	}
+	return errors.New("unsupported type for field changes")
+}
+
+// TodoImage is the part of a todo that undo puts back
+type TodoImage struct {
+	Title           string     `json:"title"`
+	Description     string     `json:"description"`
+	Status          TodoStatus `json:"status"`
+	Priority        int        `json:"priority"`
+	DueDate         *time.Time `json:"due_date"`
+	ListID          *uuid.UUID `json:"list_id"`
+	Tags            []string   `json:"tags"`
+	RecurrenceRule  string     `json:"recurrence_rule"`
+	Timezone        string     `json:"timezone"`
+	SeriesID        *uuid.UUID `json:"series_id"`
+	RecurrenceStart *time.Time `json:"recurrence_start"`
+	Position        *string    `json:"position"`
+	State           *string    `json:"state"`
+	Estimate        *float64   `json:"estimate"`
+	ParentID        *uuid.UUID `json:"parent_id"`
+}
+
+// NewTodoImage captures the current state of a todo; nil gives nil
+func NewTodoImage(t *Todo) *TodoImage {
+	if t == nil {
+		return nil
+	}
+	return &TodoImage{
+		Title:           t.Title,
+		Description:     t.Description,
+		Status:          t.Status,
+		Priority:        t.Priority,
+		DueDate:         t.DueDate,
+		ListID:          t.ListID,
+		Tags:            t.TagNames(),
+		RecurrenceRule:  t.RecurrenceRule,
+		Timezone:        t.Timezone,
+		SeriesID:        t.SeriesID,
+		RecurrenceStart: t.RecurrenceStart,
+		Position:        t.Position,
+		State:           t.State,
+		Estimate:        t.Estimate,
+		ParentID:        t.ParentID,
+	}
+}
+
+// ApplyTo puts the captured fields back on a todo. Tags are left to the
+// caller since they are stored separately.
+func (i *TodoImage) ApplyTo(t *Todo) {
+	t.Title = i.Title
+	t.Description = i.Description
+	t.Status = i.Status
+	t.Priority = i.Priority
+	t.DueDate = i.DueDate
+	t.ListID = i.ListID
+	t.RecurrenceRule = i.RecurrenceRule
+	t.Timezone = i.Timezone
+	t.SeriesID = i.SeriesID
+	t.RecurrenceStart = i.RecurrenceStart
+	t.Position = i.Position
+	t.State = i.State
+	t.Estimate = i.Estimate
+	t.ParentID = i.ParentID
+}
+
+func (i TodoImage) Value() (driver.Value, error) {
+	data, err := json.Marshal(i)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (i *TodoImage) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, i)
+	case string:
+		return json.Unmarshal([]byte(v), i)
+	}
+	return errors.New("unsupported type for todo image")
+}
+
+// Matches reports whether the recorded fields of the todo still hold the
+// values a change set them to, i.e. nothing changed them since
+func (c FieldChanges) Matches(t *Todo) bool {
+	current := make(map[string]interface{})
+	for _, value := range t.auditValues() {
+		current[value.Field] = value.New
+	}
+	for _, change := range c {
+		if !reflect.DeepEqual(normalizeJSON(current[change.Field]), normalizeJSON(change.New)) {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeJSON maps a value to its JSON-decoded form, so values read back
+// from the database compare equal to freshly computed ones
+func normalizeJSON(value interface{}) interface{} {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return value
+	}
+	return normalized
+}
+
+// DiffTodo lists the fields that differ between two versions of a todo. A nil
+// before describes a newly created todo, whose old values are all unset.
+func DiffTodo(before, after *Todo) FieldChanges {
+	created := before == nil
+	if created {
+		before = &Todo{}
+	}
+
+	prev, next := before.auditValues(), after.auditValues()
+	changes := FieldChanges{}
+	for i := range next {
+		if reflect.DeepEqual(prev[i].New, next[i].New) {
+			continue
+		}
+		change := FieldChange{Field: next[i].Field, Old: prev[i].New, New: next[i].New}
+		if created {
+			change.Old = nil
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// auditValues returns the recorded fields of the todo in a fixed order, with
+// each value in New
+func (t *Todo) auditValues() []FieldChange {
+	var dueDate, listID, position, state, estimate, parentID interface{}
+	if t.DueDate != nil {
+		dueDate = t.DueDate.UTC().Format(time.RFC3339)
+	}
+	if t.ListID != nil {
+		listID = t.ListID.String()
+	}
+	if t.Position != nil {
+		position = *t.Position
+	}
+	if t.State != nil {
+		state = *t.State
+	}
+	if t.Estimate != nil {
+		estimate = *t.Estimate
+	}
+	if t.ParentID != nil {
+		parentID = t.ParentID.String()
+	}
+	tags := t.TagNames()
+	sort.Strings(tags)
+
+	return []FieldChange{
+		{Field: "title", New: t.Title},
+		{Field: "description", New: t.Description},
+		{Field: "status", New: string(t.Status)},
+		{Field: "priority", New: t.Priority},
+		{Field: "due_date", New: dueDate},
+		{Field: "list_id", New: listID},
+		{Field: "tags", New: tags},
+		{Field: "recurrence_rule", New: t.RecurrenceRule},
+		{Field: "timezone", New: t.Timezone},
+		{Field: "position", New: position},
+		{Field: "state", New: state},
+		{Field: "estimate", New: estimate},
+		{Field: "parent_id", New: parentID},
+	}
+}
+
+type TodoChangeResponse struct {
+	ID        uuid.UUID        `json:"id"`
+	TodoID    uuid.UUID        `json:"todo_id"`
+	ActorID   uuid.UUID        `json:"actor_id"`
+	Action    TodoChangeAction `json:"action"`
+	Changes   FieldChanges     `json:"changes"`
+	ClientID  string           `json:"client_id,omitempty"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+func (c *TodoChange) ToResponse() TodoChangeResponse {
+	changes := c.Changes
+	if changes == nil {
+		changes = FieldChanges{}
+	}
+	return TodoChangeResponse{
+		ID:        c.ID,
+		TodoID:    c.TodoID,
+		ActorID:   c.ActorID,
+		Action:    c.Action,
+		Changes:   changes,
+		ClientID:  c.ClientID,
+		CreatedAt: c.CreatedAt,
+	}
+}
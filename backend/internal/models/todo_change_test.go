@@ -0,0 +1,117 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffTodo(t *testing.T) {
+	due := time.Date(2024, 3, 1, 9, 0, 0, 0, time.FixedZone("CET", 3600))
+	listID := uuid.New()
+	before := &Todo{
+		Title:    "Pay rent",
+		Status:   TodoStatusPending,
+		Priority: 2,
+		Tags:     []Tag{{Name: "home"}, {Name: "bills"}},
+	}
+
+	t.Run("changed fields only", func(t *testing.T) {
+		after := *before
+		after.Priority = 4
+		after.DueDate = &due
+		after.ListID = &listID
+		after.Tags = []Tag{{Name: "bills"}, {Name: "home"}}
+
+		assert.Equal(t, FieldChanges{
+			{Field: "priority", Old: 2, New: 4},
+			{Field: "due_date", Old: nil, New: "2024-03-01T08:00:00Z"},
+			{Field: "list_id", Old: nil, New: listID.String()},
+		}, DiffTodo(before, &after))
+	})
+
+	t.Run("estimate and parent", func(t *testing.T) {
+		estimate := 2.5
+		parentID := uuid.New()
+		after := *before
+		after.Estimate = &estimate
+		after.ParentID = &parentID
+
+		assert.Equal(t, FieldChanges{
+			{Field: "estimate", Old: nil, New: 2.5},
+			{Field: "parent_id", Old: nil, New: parentID.String()},
+		}, DiffTodo(before, &after))
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		assert.Empty(t, DiffTodo(before, before))
+	})
+
+	t.Run("created", func(t *testing.T) {
+		assert.Equal(t, FieldChanges{
+			{Field: "title", Old: nil, New: "Pay rent"},
+			{Field: "status", Old: nil, New: "pending"},
+			{Field: "priority", Old: nil, New: 2},
+			{Field: "tags", Old: nil, New: []string{"bills", "home"}},
+		}, DiffTodo(nil, before))
+	})
+}
+
+func TestFieldChangesRoundTrip(t *testing.T) {
+	changes := FieldChanges{{Field: "title", Old: "a", New: "b"}, {Field: "due_date", Old: nil, New: "2024-03-01T08:00:00Z"}}
+
+	value, err := changes.Value()
+	require.NoError(t, err)
+	assert.Equal(t, `[{"field":"title","old":"a","new":"b"},{"field":"due_date","old":null,"new":"2024-03-01T08:00:00Z"}]`, value)
+
+	var scanned FieldChanges
+	require.NoError(t, scanned.Scan([]byte(value.(string))))
+	assert.Equal(t, changes, scanned)
+
+	empty, err := FieldChanges(nil).Value()
+	require.NoError(t, err)
+	assert.Equal(t, "[]", empty)
+}
+
+func TestFieldChangesMatches(t *testing.T) {
+	due := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	before := &Todo{Title: "Pay rent", Priority: 2, Tags: []Tag{{Name: "home"}}}
+	after := &Todo{Title: "Pay rent", Priority: 4, DueDate: &due, Tags: []Tag{{Name: "home"}, {Name: "bills"}}}
+
+	// Changes are matched after a trip through the database
+	value, err := DiffTodo(before, after).Value()
+	require.NoError(t, err)
+	var stored FieldChanges
+	require.NoError(t, stored.Scan(value))
+
+	assert.True(t, stored.Matches(after))
+
+	edited := *after
+	edited.Priority = 5
+	assert.False(t, stored.Matches(&edited))
+}
+
+func TestTodoImageApplyTo(t *testing.T) {
+	due := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	listID := uuid.New()
+	original := &Todo{Title: "Pay rent", Status: TodoStatusPending, Priority: 2, DueDate: &due, ListID: &listID}
+
+	value, err := NewTodoImage(original).Value()
+	require.NoError(t, err)
+	var image TodoImage
+	require.NoError(t, image.Scan(value))
+
+	todo := &Todo{Title: "Paid", Status: TodoStatusCompleted, Priority: 5}
+	image.ApplyTo(todo)
+
+	assert.Equal(t, "Pay rent", todo.Title)
+	assert.Equal(t, TodoStatusPending, todo.Status)
+	assert.Equal(t, 2, todo.Priority)
+	assert.True(t, due.Equal(*todo.DueDate))
+	assert.Equal(t, listID, *todo.ListID)
+	assert.Empty(t, image.Tags)
+	assert.Nil(t, NewTodoImage(nil))
+}
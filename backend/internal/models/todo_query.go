@@ -0,0 +1,324 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"todo-backend/pkg/tql"
+
+	"github.com/google/uuid"
+)
+
+// TodoSort is one key of a multi-key sort, e.g. "-priority"
+type TodoSort struct {
+	Field string
+	Desc  bool
+}
+
+// TodoSortFields maps the sort keys accepted by the API to columns
+var TodoSortFields = map[string]string{
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+	"due_date":   "due_date",
+	"priority":   "priority",
+	"title":      "title",
+	"status":     "status",
+	"position":   "position",
+}
+
+// TodoSortNullable lists the sort keys whose column may be NULL; such todos
+// sort last
+var TodoSortNullable = map[string]bool{
+	"due_date": true,
+	"position": true,
+}
+
+// DefaultTodoSort is applied when a listing does not ask for an order
+var DefaultTodoSort = []TodoSort{{Field: "created_at", Desc: true}}
+
+// TodoFilter combines the conditions of a todo listing. Zero values mean the
+// condition is not applied.
+type TodoFilter struct {
+	Statuses      []TodoStatus
+	PriorityMin   *int
+	PriorityMax   *int
+	DueBefore     *time.Time
+	DueAfter      *time.Time
+	Overdue       *bool
+	HasDueDate    *bool
+	CreatedBefore *time.Time
+	CreatedAfter  *time.Time
+	UpdatedBefore *time.Time
+	UpdatedAfter  *time.Time
+	ListIDs       []uuid.UUID
+	WithoutList   bool // todos outside any list; not a query parameter
+	Tags          []string
+	Query         tql.Expr // parsed query language expression, see pkg/tql
+	Sort          []TodoSort
+
+	// Location sets the day boundaries of relative dates in Query (default UTC)
+	Location *time.Location
+}
+
+// todoQueryParams lists every query parameter the todo listing understands
+var todoQueryParams = map[string]bool{
+	"page": true, "limit": true, "sort": true, "cursor": true, "include_total": true,
+	"status": true, "priority_min": true, "priority_max": true,
+	"due_before": true, "due_after": true, "overdue": true, "has_due_date": true,
+	"created_before": true, "created_after": true,
+	"updated_before": true, "updated_after": true,
+	"list_id": true, "tag": true, "q": true,
+}
+
+// ParseTodoFilter builds a TodoFilter from listing query parameters. Multi-value
+// parameters accept both repetition and comma-separated values. Unknown
+// parameters and malformed values are rejected.
+func ParseTodoFilter(values url.Values) (*TodoFilter, error) {
+	for key := range values {
+		if !todoQueryParams[key] {
+			return nil, fmt.Errorf("unknown query parameter: %s", key)
+		}
+	}
+
+	filter := &TodoFilter{}
+	var err error
+
+	for _, s := range splitValues(values["status"]) {
+		status := TodoStatus(s)
+		if status != TodoStatusPending && status != TodoStatusInProgress && status != TodoStatusCompleted {
+			return nil, fmt.Errorf("invalid status: %s", s)
+		}
+		filter.Statuses = append(filter.Statuses, status)
+	}
+
+	if filter.PriorityMin, err = parsePriority(values, "priority_min"); err != nil {
+		return nil, err
+	}
+	if filter.PriorityMax, err = parsePriority(values, "priority_max"); err != nil {
+		return nil, err
+	}
+	if filter.PriorityMin != nil && filter.PriorityMax != nil && *filter.PriorityMin > *filter.PriorityMax {
+		return nil, fmt.Errorf("invalid priority range: priority_min is greater than priority_max")
+	}
+
+	times := map[string]**time.Time{
+		"due_before":     &filter.DueBefore,
+		"due_after":      &filter.DueAfter,
+		"created_before": &filter.CreatedBefore,
+		"created_after":  &filter.CreatedAfter,
+		"updated_before": &filter.UpdatedBefore,
+		"updated_after":  &filter.UpdatedAfter,
+	}
+	for key, dst := range times {
+		if *dst, err = parseTimeParam(values, key); err != nil {
+			return nil, err
+		}
+	}
+
+	if filter.Overdue, err = parseBoolParam(values, "overdue"); err != nil {
+		return nil, err
+	}
+	if filter.HasDueDate, err = parseBoolParam(values, "has_due_date"); err != nil {
+		return nil, err
+	}
+
+	for _, s := range splitValues(values["list_id"]) {
+		id, err := uuid.Parse(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid list_id: %s", s)
+		}
+		filter.ListIDs = append(filter.ListIDs, id)
+	}
+
+	for _, s := range splitValues(values["tag"]) {
+		filter.Tags = append(filter.Tags, NormalizeTagName(s))
+	}
+
+	if filter.Query, err = tql.Parse(values.Get("q")); err != nil {
+		return nil, fmt.Errorf("invalid q: %w", err)
+	}
+
+	if filter.Sort, err = ParseTodoSort(values.Get("sort")); err != nil {
+		return nil, err
+	}
+
+	return filter, nil
+}
+
+// ParseTodoSort parses a comma-separated sort expression such as
+// "-priority,due_date", where a leading "-" sorts descending
+func ParseTodoSort(s string) ([]TodoSort, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	var sorts []TodoSort
+	seen := make(map[string]bool)
+	for _, key := range strings.Split(s, ",") {
+		key = strings.TrimSpace(key)
+		sort := TodoSort{Field: strings.TrimPrefix(key, "-"), Desc: strings.HasPrefix(key, "-")}
+		if _, ok := TodoSortFields[sort.Field]; !ok {
+			return nil, fmt.Errorf("invalid sort field: %s", sort.Field)
+		}
+		if seen[sort.Field] {
+			return nil, fmt.Errorf("duplicate sort field: %s", sort.Field)
+		}
+		seen[sort.Field] = true
+		sorts = append(sorts, sort)
+	}
+	return sorts, nil
+}
+
+// FormatTodoSort renders sort keys back to their query form, e.g. "-priority,due_date"
+func FormatTodoSort(sorts []TodoSort) string {
+	if len(sorts) == 0 {
+		sorts = DefaultTodoSort
+	}
+	keys := make([]string, len(sorts))
+	for i, s := range sorts {
+		keys[i] = s.Field
+		if s.Desc {
+			keys[i] = "-" + s.Field
+		}
+	}
+	return strings.Join(keys, ",")
+}
+
+// TodoCursor is the decoded position of a keyset page boundary. Keys holds the
+// boundary row's sort key values (nil for NULL) in the order of Sort.
+type TodoCursor struct {
+	Backward bool      `json:"b,omitempty"`
+	Sort     string    `json:"s"`
+	Keys     []*string `json:"k"`
+	ID       uuid.UUID `json:"i"`
+}
+
+// NewTodoCursor captures the position of todo under the given sort order
+func NewTodoCursor(todo *Todo, sorts []TodoSort, backward bool) *TodoCursor {
+	if len(sorts) == 0 {
+		sorts = DefaultTodoSort
+	}
+
+	keys := make([]*string, len(sorts))
+	for i, s := range sorts {
+		var value string
+		switch s.Field {
+		case "created_at":
+			value = todo.CreatedAt.UTC().Format(time.RFC3339Nano)
+		case "updated_at":
+			value = todo.UpdatedAt.UTC().Format(time.RFC3339Nano)
+		case "due_date":
+			if todo.DueDate == nil {
+				continue
+			}
+			value = todo.DueDate.UTC().Format(time.RFC3339Nano)
+		case "priority":
+			value = strconv.Itoa(todo.Priority)
+		case "title":
+			value = todo.Title
+		case "status":
+			value = string(todo.Status)
+		case "position":
+			if todo.Position == nil {
+				continue
+			}
+			value = *todo.Position
+		}
+		keys[i] = &value
+	}
+
+	return &TodoCursor{Backward: backward, Sort: FormatTodoSort(sorts), Keys: keys, ID: todo.ID}
+}
+
+// KeyValues converts the cursor keys back to typed values for comparison in
+// SQL, validating them against the sort order they were captured under
+func (c *TodoCursor) KeyValues(sorts []TodoSort) ([]interface{}, error) {
+	if len(sorts) == 0 {
+		sorts = DefaultTodoSort
+	}
+	if c.Sort != FormatTodoSort(sorts) || len(c.Keys) != len(sorts) {
+		return nil, errors.New("invalid cursor: it was issued for a different sort order")
+	}
+
+	values := make([]interface{}, len(sorts))
+	for i, s := range sorts {
+		key := c.Keys[i]
+		if key == nil {
+			if !TodoSortNullable[s.Field] {
+				return nil, errors.New("invalid cursor")
+			}
+			continue
+		}
+		switch s.Field {
+		case "created_at", "updated_at", "due_date":
+			t, err := time.Parse(time.RFC3339Nano, *key)
+			if err != nil {
+				return nil, errors.New("invalid cursor")
+			}
+			values[i] = t
+		case "priority":
+			n, err := strconv.Atoi(*key)
+			if err != nil {
+				return nil, errors.New("invalid cursor")
+			}
+			values[i] = n
+		default:
+			values[i] = *key
+		}
+	}
+	return values, nil
+}
+
+func splitValues(values []string) []string {
+	var out []string
+	for _, v := range values {
+		for _, part := range strings.Split(v, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				out = append(out, part)
+			}
+		}
+	}
+	return out
+}
+
+func parsePriority(values url.Values, key string) (*int, error) {
+	s := values.Get(key)
+	if s == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 || n > 5 {
+		return nil, fmt.Errorf("invalid %s: must be an integer between 0 and 5", key)
+	}
+	return &n, nil
+}
+
+// parseTimeParam accepts RFC 3339 timestamps or plain dates (midnight UTC)
+func parseTimeParam(values url.Values, key string) (*time.Time, error) {
+	s := values.Get(key)
+	if s == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return &t, nil
+	}
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return &t, nil
+	}
+	return nil, fmt.Errorf("invalid %s: expected RFC 3339 timestamp or YYYY-MM-DD date", key)
+}
+
+func parseBoolParam(values url.Values, key string) (*bool, error) {
+	s := values.Get(key)
+	if s == "" {
+		return nil, nil
+	}
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: expected true or false", key)
+	}
+	return &b, nil
+}
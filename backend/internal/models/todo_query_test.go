@@ -0,0 +1,127 @@
+package models
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTodoFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		wantErr string
+		check   func(t *testing.T, f *TodoFilter)
+	}{
+		{
+			name:  "empty",
+			query: "",
+			check: func(t *testing.T, f *TodoFilter) {
+				assert.Empty(t, f.Statuses)
+				assert.Nil(t, f.Sort)
+			},
+		},
+		{
+			name:  "status repeated and comma separated",
+			query: "status=pending,in_progress&status=completed",
+			check: func(t *testing.T, f *TodoFilter) {
+				assert.Equal(t, []TodoStatus{TodoStatusPending, TodoStatusInProgress, TodoStatusCompleted}, f.Statuses)
+			},
+		},
+		{
+			name:  "priority range and booleans",
+			query: "priority_min=2&priority_max=4&overdue=true&has_due_date=false",
+			check: func(t *testing.T, f *TodoFilter) {
+				assert.Equal(t, 2, *f.PriorityMin)
+				assert.Equal(t, 4, *f.PriorityMax)
+				assert.True(t, *f.Overdue)
+				assert.False(t, *f.HasDueDate)
+			},
+		},
+		{
+			name:  "dates and timestamps",
+			query: "due_before=2024-02-01&created_after=2024-01-01T08:00:00Z",
+			check: func(t *testing.T, f *TodoFilter) {
+				assert.True(t, f.DueBefore.Equal(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)))
+				assert.True(t, f.CreatedAfter.Equal(time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)))
+			},
+		},
+		{
+			name:  "lists, tags and sort",
+			query: "list_id=7d444840-9dc0-11d1-b245-5ffdce74fad2&tag=Work,home&sort=-priority,due_date",
+			check: func(t *testing.T, f *TodoFilter) {
+				require.Len(t, f.ListIDs, 1)
+				assert.Equal(t, []string{"work", "home"}, f.Tags)
+				assert.Equal(t, []TodoSort{{Field: "priority", Desc: true}, {Field: "due_date"}}, f.Sort)
+			},
+		},
+		{name: "unknown parameter", query: "colour=red", wantErr: "unknown query parameter: colour"},
+		{name: "bad status", query: "status=done", wantErr: "invalid status: done"},
+		{name: "priority out of range", query: "priority_min=9", wantErr: "invalid priority_min"},
+		{name: "inverted priority range", query: "priority_min=4&priority_max=1", wantErr: "invalid priority range"},
+		{name: "bad date", query: "due_after=tomorrow", wantErr: "invalid due_after"},
+		{name: "bad bool", query: "overdue=maybe", wantErr: "invalid overdue"},
+		{name: "bad list id", query: "list_id=inbox", wantErr: "invalid list_id"},
+		{name: "bad sort field", query: "sort=-owner", wantErr: "invalid sort field: owner"},
+		{name: "duplicate sort field", query: "sort=priority,-priority", wantErr: "duplicate sort field: priority"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			require.NoError(t, err)
+
+			filter, err := ParseTodoFilter(values)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			tt.check(t, filter)
+		})
+	}
+}
+
+func TestTodoCursorRoundTrip(t *testing.T) {
+	due := time.Date(2024, 3, 1, 9, 30, 0, 123000, time.UTC)
+	todo := &Todo{
+		ID:        uuid.New(),
+		Priority:  3,
+		DueDate:   &due,
+		CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	sorts := []TodoSort{{Field: "priority", Desc: true}, {Field: "due_date"}}
+
+	cursor := NewTodoCursor(todo, sorts, false)
+	values, err := cursor.KeyValues(sorts)
+	require.NoError(t, err)
+	assert.Equal(t, 3, values[0])
+	assert.True(t, due.Equal(values[1].(time.Time)))
+
+	// NULL due dates survive the round trip
+	todo.DueDate = nil
+	values, err = NewTodoCursor(todo, sorts, false).KeyValues(sorts)
+	require.NoError(t, err)
+	assert.Nil(t, values[1])
+
+	// So do todos without a manual position
+	byPosition := []TodoSort{{Field: "position"}}
+	values, err = NewTodoCursor(todo, byPosition, false).KeyValues(byPosition)
+	require.NoError(t, err)
+	assert.Nil(t, values[0])
+
+	position := "V1"
+	todo.Position = &position
+	values, err = NewTodoCursor(todo, byPosition, false).KeyValues(byPosition)
+	require.NoError(t, err)
+	assert.Equal(t, "V1", values[0])
+
+	// A cursor can't be replayed under another sort order
+	_, err = cursor.KeyValues([]TodoSort{{Field: "title"}})
+	assert.Error(t, err)
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -22,6 +23,16 @@ type User struct {
 	IsPrivateEmail bool   `json:"is_private_email" gorm:"default:false"`
 	AuthProvider   string `json:"auth_provider" gorm:"default:'email'"` // 'email', 'apple'
 
+	// Preferences
+	Timezone  string `json:"timezone" gorm:"type:varchar(64);default:'UTC'"`      // IANA name used for day boundaries
+	WeekStart string `json:"week_start" gorm:"type:varchar(10);default:'monday'"` // lower-case weekday name
+
+	// Secret of the user's calendar feed URL; nil while the feed is off
+	CalendarToken *string `json:"-" gorm:"type:varchar(64);uniqueIndex"`
+
+	// Set while the account waits out its deletion grace period
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty" gorm:"index"`
+
 	// Relationships
 	Todos []Todo `json:"todos,omitempty" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
 }
@@ -37,6 +48,11 @@ type UserUpdateRequest struct {
 	Name  string `json:"name" validate:"omitempty"`
 }
 
+type UserPreferencesRequest struct {
+	Timezone  string `json:"timezone" validate:"omitempty,max=64"`
+	WeekStart string `json:"week_start" validate:"omitempty,oneof=sunday monday tuesday wednesday thursday friday saturday"`
+}
+
 type UserResponse struct {
 	ID             uuid.UUID `json:"id"`
 	Email          string    `json:"email"`
@@ -44,8 +60,12 @@ type UserResponse struct {
 	IsActive       bool      `json:"is_active"`
 	IsPrivateEmail bool      `json:"is_private_email"`
 	AuthProvider   string    `json:"auth_provider"`
+	Timezone       string    `json:"timezone"`
+	WeekStart      string    `json:"week_start"`
 	CreatedAt      time.Time `json:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at"`
+
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
 }
 
 func (u *User) ToResponse() UserResponse {
@@ -56,7 +76,39 @@ func (u *User) ToResponse() UserResponse {
 		IsActive:       u.IsActive,
 		IsPrivateEmail: u.IsPrivateEmail,
 		AuthProvider:   u.AuthProvider,
+		Timezone:       u.Timezone,
+		WeekStart:      u.WeekStart,
 		CreatedAt:      u.CreatedAt,
 		UpdatedAt:      u.UpdatedAt,
+
+		DeletionScheduledAt: u.DeletionScheduledAt,
 	}
-} 
\ No newline at end of file
+}
+
+// Location returns the user's timezone, falling back to UTC
+func (u *User) Location() *time.Location {
+	if u.Timezone != "" {
+		if loc, err := time.LoadLocation(u.Timezone); err == nil {
+			return loc
+		}
+	}
+	return time.UTC
+}
+
+// FirstDayOfWeek returns the day the user's weeks start on, Monday by default
+func (u *User) FirstDayOfWeek() time.Weekday {
+	if day, ok := ParseWeekday(u.WeekStart); ok {
+		return day
+	}
+	return time.Monday
+}
+
+// ParseWeekday parses a lower-case English weekday name
+func ParseWeekday(name string) (time.Weekday, bool) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.ToLower(day.String()) == name {
+			return day, true
+		}
+	}
+	return time.Sunday, false
+}
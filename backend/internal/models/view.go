@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SavedView is a named todo query ("smart list") written in the query
+// language of pkg/tql, e.g. "status:pending tag:work due<7d"
+type SavedView struct {
+	ID        uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID    uuid.UUID      `json:"user_id" gorm:"type:uuid;not null;index"`
+	Name      string         `json:"name" gorm:"type:varchar(100);not null"`
+	Query     string         `json:"query" gorm:"type:text;not null"`
+	Sort      string         `json:"sort" gorm:"type:varchar(200)"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// Relationships
+	User User `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+type SavedViewCreateRequest struct {
+	Name  string `json:"name" validate:"required,min=1,max=100"`
+	Query string `json:"query" validate:"required,max=1000"`
+	Sort  string `json:"sort,omitempty" validate:"max=200"`
+}
+
+type SavedViewUpdateRequest struct {
+	Name  string `json:"name" validate:"omitempty,min=1,max=100"`
+	Query string `json:"query" validate:"max=1000"`
+	Sort  string `json:"sort" validate:"max=200"`
+}
+
+type SavedViewResponse struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Query     string    `json:"query"`
+	Sort      string    `json:"sort,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (v *SavedView) ToResponse() SavedViewResponse {
+	return SavedViewResponse{
+		ID:        v.ID,
+		Name:      v.Name,
+		Query:     v.Query,
+		Sort:      v.Sort,
+		CreatedAt: v.CreatedAt,
+		UpdatedAt: v.UpdatedAt,
+	}
+}
@@ -0,0 +1,267 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StateCategory is the base meaning of a workflow state. It decides the
+// todo's status, so completion, recurrence and dependencies keep working on
+// lists with custom states.
+type StateCategory string
+
+const (
+	StateCategoryTodo  StateCategory = "todo"
+	StateCategoryDoing StateCategory = "doing"
+	StateCategoryDone  StateCategory = "done"
+)
+
+// Status returns the todo status a category maps to
+func (c StateCategory) Status() TodoStatus {
+	switch c {
+	case StateCategoryDoing:
+		return TodoStatusInProgress
+	case StateCategoryDone:
+		return TodoStatusCompleted
+	}
+	return TodoStatusPending
+}
+
+// CategoryOf returns the category of a todo status
+func CategoryOf(status TodoStatus) StateCategory {
+	switch status {
+	case TodoStatusInProgress:
+		return StateCategoryDoing
+	case TodoStatusCompleted:
+		return StateCategoryDone
+	}
+	return StateCategoryTodo
+}
+
+// WorkflowStateMax caps how many states a list's workflow may have
+const WorkflowStateMax = 20
+
+// WorkflowState is one column of a list's board, e.g. "review" or "blocked".
+// Lists without states of their own use DefaultWorkflow.
+type WorkflowState struct {
+	ID          uuid.UUID     `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	ListID      uuid.UUID     `json:"list_id" gorm:"type:uuid;not null;uniqueIndex:idx_workflow_states_list_key,priority:1"`
+	Key         string        `json:"key" gorm:"type:varchar(50);not null;uniqueIndex:idx_workflow_states_list_key,priority:2"`
+	Name        string        `json:"name" gorm:"type:varchar(100);not null"`
+	Category    StateCategory `json:"category" gorm:"type:varchar(10);not null"`
+	Position    int           `json:"position" gorm:"not null"`
+	WIPLimit    int           `json:"wip_limit" gorm:"not null;default:0"` // 0 means no limit
+	Transitions StateKeys     `json:"transitions" gorm:"type:jsonb;not null"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+
+	// Relationships
+	List List `json:"-" gorm:"foreignKey:ListID;constraint:OnDelete:CASCADE"`
+}
+
+// StateKeys are the states a todo may move to from a state; empty allows
+// every state. Stored as a JSON array.
+type StateKeys []string
+
+func (k StateKeys) Value() (driver.Value, error) {
+	if k == nil {
+		k = StateKeys{}
+	}
+	data, err := json.Marshal(k)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (k *StateKeys) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*k = StateKeys{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, k)
+	case string:
+		return json.Unmarshal([]byte(v), k)
+	}
+	return errors.New("unsupported type for state keys")
+}
+
+// Workflow is a list's states in board order
+type Workflow []WorkflowState
+
+// DefaultWorkflow is the board of a list without states of its own: one state
+// per todo status, keyed by the status, with every move allowed
+func DefaultWorkflow() Workflow {
+	return Workflow{
+		{Key: string(TodoStatusPending), Name: "To do", Category: StateCategoryTodo, Position: 0, Transitions: StateKeys{}},
+		{Key: string(TodoStatusInProgress), Name: "In progress", Category: StateCategoryDoing, Position: 1, Transitions: StateKeys{}},
+		{Key: string(TodoStatusCompleted), Name: "Done", Category: StateCategoryDone, Position: 2, Transitions: StateKeys{}},
+	}
+}
+
+// Find returns the state with the given key, or nil
+func (w Workflow) Find(key string) *WorkflowState {
+	for i := range w {
+		if w[i].Key == key {
+			return &w[i]
+		}
+	}
+	return nil
+}
+
+// First returns the first state of a category, or nil
+func (w Workflow) First(category StateCategory) *WorkflowState {
+	for i := range w {
+		if w[i].Category == category {
+			return &w[i]
+		}
+	}
+	return nil
+}
+
+// StateOf returns the state a todo is in: its own state key on lists with a
+// custom workflow, the state of its status otherwise or when the key is no
+// longer part of the workflow
+func (w Workflow) StateOf(todo *Todo) *WorkflowState {
+	if todo.State != nil {
+		if state := w.Find(*todo.State); state != nil {
+			return state
+		}
+	}
+	if state := w.Find(string(todo.Status)); state != nil {
+		return state
+	}
+	return w.First(CategoryOf(todo.Status))
+}
+
+// CanMove reports whether a todo in the state may move to the state keyed to
+func (s *WorkflowState) CanMove(to string) bool {
+	if s.Key == to || len(s.Transitions) == 0 {
+		return true
+	}
+	for _, key := range s.Transitions {
+		if key == to {
+			return true
+		}
+	}
+	return false
+}
+
+// stateKeyPattern restricts state keys to lowercase identifiers
+var stateKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+type WorkflowStateRequest struct {
+	Key         string        `json:"key" validate:"required,max=50"`
+	Name        string        `json:"name" validate:"required,min=1,max=100"`
+	Category    StateCategory `json:"category" validate:"required,oneof=todo doing done"`
+	WIPLimit    int           `json:"wip_limit" validate:"min=0,max=1000"`
+	Transitions []string      `json:"transitions,omitempty" validate:"max=20"`
+}
+
+// WorkflowRequest replaces the states of a list's workflow, in board order
+type WorkflowRequest struct {
+	States []WorkflowStateRequest `json:"states" validate:"required,min=1,max=20,dive"`
+}
+
+// Validate checks that state keys are well-formed and unique, that
+// transitions name states of the workflow and that new and finished todos
+// have a state to go to
+func (r *WorkflowRequest) Validate() error {
+	if len(r.States) == 0 || len(r.States) > WorkflowStateMax {
+		return fmt.Errorf("invalid workflow: between 1 and %d states are required", WorkflowStateMax)
+	}
+
+	keys := make(map[string]bool, len(r.States))
+	categories := make(map[StateCategory]bool)
+	for _, state := range r.States {
+		if !stateKeyPattern.MatchString(state.Key) {
+			return fmt.Errorf("invalid workflow: state key %q must be lowercase letters, digits and underscores", state.Key)
+		}
+		if keys[state.Key] {
+			return fmt.Errorf("invalid workflow: duplicate state key %q", state.Key)
+		}
+		keys[state.Key] = true
+		categories[state.Category] = true
+	}
+	if !categories[StateCategoryTodo] || !categories[StateCategoryDone] {
+		return errors.New("invalid workflow: at least one todo and one done state are required")
+	}
+
+	for _, state := range r.States {
+		for _, to := range state.Transitions {
+			if !keys[to] {
+				return fmt.Errorf("invalid workflow: state %q has a transition to unknown state %q", state.Key, to)
+			}
+		}
+	}
+	return nil
+}
+
+// Workflow builds the states of a list's workflow from the request
+func (r *WorkflowRequest) Workflow(listID uuid.UUID) Workflow {
+	states := make(Workflow, len(r.States))
+	for i, state := range r.States {
+		states[i] = WorkflowState{
+			ListID:      listID,
+			Key:         state.Key,
+			Name:        state.Name,
+			Category:    state.Category,
+			Position:    i,
+			WIPLimit:    state.WIPLimit,
+			Transitions: append(StateKeys{}, state.Transitions...),
+		}
+	}
+	return states
+}
+
+type WorkflowStateResponse struct {
+	Key         string        `json:"key"`
+	Name        string        `json:"name"`
+	Category    StateCategory `json:"category"`
+	WIPLimit    int           `json:"wip_limit"`
+	Transitions []string      `json:"transitions"`
+}
+
+func (s *WorkflowState) ToResponse() WorkflowStateResponse {
+	transitions := []string(s.Transitions)
+	if transitions == nil {
+		transitions = []string{}
+	}
+	return WorkflowStateResponse{
+		Key:         s.Key,
+		Name:        s.Name,
+		Category:    s.Category,
+		WIPLimit:    s.WIPLimit,
+		Transitions: transitions,
+	}
+}
+
+// WorkflowResponse is a list's workflow; Custom is false for DefaultWorkflow
+type WorkflowResponse struct {
+	ListID uuid.UUID               `json:"list_id"`
+	Custom bool                    `json:"custom"`
+	States []WorkflowStateResponse `json:"states"`
+}
+
+// BoardColumn is one state of a list's board with its first cards in manual
+// order. Count is the number of todos in the state, which may exceed the
+// number of cards returned.
+type BoardColumn struct {
+	WorkflowStateResponse
+	Count     int64          `json:"count"`
+	OverLimit bool           `json:"over_limit"`
+	Cards     []TodoResponse `json:"cards"`
+}
+
+type BoardResponse struct {
+	List    ListResponse  `json:"list"`
+	Custom  bool          `json:"custom"`
+	Columns []BoardColumn `json:"columns"`
+}
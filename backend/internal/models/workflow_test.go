@@ -0,0 +1,104 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func teamWorkflow() *WorkflowRequest {
+	return &WorkflowRequest{States: []WorkflowStateRequest{
+		{Key: "backlog", Name: "Backlog", Category: StateCategoryTodo, Transitions: []string{"doing", "blocked"}},
+		{Key: "doing", Name: "Doing", Category: StateCategoryDoing, WIPLimit: 3},
+		{Key: "blocked", Name: "Blocked", Category: StateCategoryDoing, Transitions: []string{"doing"}},
+		{Key: "review", Name: "Review", Category: StateCategoryDoing, Transitions: []string{"doing", "done"}},
+		{Key: "done", Name: "Done", Category: StateCategoryDone},
+	}}
+}
+
+func TestWorkflowRequestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(r *WorkflowRequest)
+		wantErr string
+	}{
+		{name: "valid", change: func(r *WorkflowRequest) {}},
+		{
+			name:    "bad key",
+			change:  func(r *WorkflowRequest) { r.States[0].Key = "In Review" },
+			wantErr: `invalid workflow: state key "In Review" must be lowercase letters, digits and underscores`,
+		},
+		{
+			name:    "duplicate key",
+			change:  func(r *WorkflowRequest) { r.States[1].Key = "backlog" },
+			wantErr: `invalid workflow: duplicate state key "backlog"`,
+		},
+		{
+			name:    "no done state",
+			change:  func(r *WorkflowRequest) { r.States = r.States[:4] },
+			wantErr: "invalid workflow: at least one todo and one done state are required",
+		},
+		{
+			name:    "unknown transition",
+			change:  func(r *WorkflowRequest) { r.States[0].Transitions = []string{"qa"} },
+			wantErr: `invalid workflow: state "backlog" has a transition to unknown state "qa"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := teamWorkflow()
+			tt.change(req)
+			err := req.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWorkflowStateOf(t *testing.T) {
+	workflow := teamWorkflow().Workflow(uuid.New())
+	require.Len(t, workflow, 5)
+	assert.Equal(t, 3, workflow[3].Position)
+
+	review := "review"
+	removed := "qa"
+	tests := []struct {
+		name string
+		todo Todo
+		want string
+	}{
+		{name: "own state", todo: Todo{State: &review, Status: TodoStatusInProgress}, want: "review"},
+		{name: "removed state falls back to category", todo: Todo{State: &removed, Status: TodoStatusInProgress}, want: "doing"},
+		{name: "no state", todo: Todo{Status: TodoStatusCompleted}, want: "done"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, workflow.StateOf(&tt.todo).Key)
+		})
+	}
+
+	// The default workflow is keyed by status
+	assert.Equal(t, "in_progress", DefaultWorkflow().StateOf(&Todo{Status: TodoStatusInProgress}).Key)
+}
+
+func TestWorkflowStateCanMove(t *testing.T) {
+	workflow := teamWorkflow().Workflow(uuid.New())
+
+	assert.True(t, workflow.Find("backlog").CanMove("blocked"))
+	assert.False(t, workflow.Find("backlog").CanMove("done"))
+	assert.True(t, workflow.Find("blocked").CanMove("blocked"))
+	assert.True(t, workflow.Find("doing").CanMove("done"), "no transitions allow every state")
+}
+
+func TestStateCategoryStatus(t *testing.T) {
+	for _, status := range []TodoStatus{TodoStatusPending, TodoStatusInProgress, TodoStatusCompleted} {
+		assert.Equal(t, status, CategoryOf(status).Status())
+	}
+}
@@ -0,0 +1,142 @@
+package notifier
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// emailTimeout bounds a whole SMTP exchange, from dial to QUIT
+const emailTimeout = 30 * time.Second
+
+// EmailNotifier sends notifications as plain-text email over SMTP
+type EmailNotifier struct {
+	host     string
+	port     string
+	username string
+	password string
+	from     string
+}
+
+func NewEmailNotifier(host, port, username, password, from string) *EmailNotifier {
+	return &EmailNotifier{
+		host:     host,
+		port:     port,
+		username: username,
+		password: password,
+		from:     from,
+	}
+}
+
+func (n *EmailNotifier) Notify(ctx context.Context, notification Notification) error {
+	if notification.Email == "" {
+		return errors.New("user has no email address")
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	subject, body := emailContent(notification)
+
+	msg := strings.Join([]string{
+		"From: " + n.from,
+		"To: " + notification.Email,
+		"Subject: " + subject,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		body,
+	}, "\r\n")
+
+	return n.send(ctx, notification.Email, []byte(msg))
+}
+
+// emailContent returns the subject and plain-text body of a notification
+func emailContent(notification Notification) (string, string) {
+	if notification.EventName() == EventAccountExportReady {
+		body := "The export of your account data is ready. Download it here:\r\n\r\n" + notification.URL + "\r\n"
+		if notification.ExpiresAt != nil {
+			body += fmt.Sprintf("\r\nThe link expires %s.\r\n", notification.ExpiresAt.UTC().Format(time.RFC1123))
+		}
+		return "Your data export is ready", body
+	}
+
+	subject := "Reminder: " + sanitizeHeader(notification.Title)
+	body := fmt.Sprintf("This is a reminder for your todo %q.\r\n", notification.Title)
+	if notification.DueDate != nil {
+		body += fmt.Sprintf("It is due %s.\r\n", notification.DueDate.UTC().Format(time.RFC1123))
+	}
+	return subject, body
+}
+
+// send delivers msg the way smtp.SendMail does, but over a connection that
+// is bounded by emailTimeout and torn down when ctx is cancelled, so a stalled
+// SMTP server can't hold up the dispatcher.
+func (n *EmailNotifier) send(ctx context.Context, to string, msg []byte) error {
+	dialer := net.Dialer{Timeout: emailTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(n.host, n.port))
+	if err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	deadline := time.Now().Add(emailTimeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	conn.SetDeadline(deadline)
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	if err := n.deliver(conn, to, msg); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+func (n *EmailNotifier) deliver(conn net.Conn, to string, msg []byte) error {
+	client, err := smtp.NewClient(conn, n.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: n.host}); err != nil {
+			return err
+		}
+	}
+	if n.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", n.username, n.password, n.host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(n.from); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+func sanitizeHeader(value string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(value)
+}
@@ -0,0 +1,40 @@
+package notifier
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+)
+
+// LogNotifier writes notifications to the application log
+type LogNotifier struct {
+	logger zerolog.Logger
+}
+
+func NewLogNotifier(logger zerolog.Logger) *LogNotifier {
+	return &LogNotifier{logger: logger}
+}
+
+func (n *LogNotifier) Notify(ctx context.Context, notification Notification) error {
+	if notification.EventName() == EventAccountExportReady {
+		event := n.logger.Info().
+			Str("user_id", notification.UserID.String()).
+			Str("url", notification.URL)
+		if notification.ExpiresAt != nil {
+			event = event.Time("expires_at", *notification.ExpiresAt)
+		}
+		event.Msg("Account export ready")
+		return nil
+	}
+
+	event := n.logger.Info().
+		Str("reminder_id", notification.ReminderID.String()).
+		Str("todo_id", notification.TodoID.String()).
+		Str("user_id", notification.UserID.String()).
+		Str("title", notification.Title)
+	if notification.DueDate != nil {
+		event = event.Time("due_date", *notification.DueDate)
+	}
+	event.Msg("Reminder")
+	return nil
+}
@@ -0,0 +1,57 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Events a notification can be about
+const (
+	EventReminderDue        = "reminder.due"
+	EventAccountExportReady = "account.export_ready"
+)
+
+// Notification is the payload delivered when a reminder fires or when
+// something else the user asked for is done, told apart by Event
+type Notification struct {
+	Event      string     `json:"event,omitempty"` // EventReminderDue when empty
+	ReminderID uuid.UUID  `json:"reminder_id"`
+	TodoID     uuid.UUID  `json:"todo_id"`
+	UserID     uuid.UUID  `json:"user_id"`
+	Email      string     `json:"email"`
+	Title      string     `json:"title"`
+	DueDate    *time.Time `json:"due_date,omitempty"`
+	FireAt     time.Time  `json:"fire_at"`
+
+	// Account notifications link to what is ready and say until when
+	URL       string     `json:"url,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// EventName returns the event of n, defaulting to EventReminderDue
+func (n Notification) EventName() string {
+	if n.Event == "" {
+		return EventReminderDue
+	}
+	return n.Event
+}
+
+// Notifier delivers notifications through one channel
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// Registry routes notifications to the notifier registered for a channel
+type Registry map[string]Notifier
+
+// Notify delivers n through the notifier registered for channel
+func (r Registry) Notify(ctx context.Context, channel string, n Notification) error {
+	notifier, ok := r[channel]
+	if !ok {
+		return fmt.Errorf("no notifier configured for channel %q", channel)
+	}
+	return notifier.Notify(ctx, n)
+}
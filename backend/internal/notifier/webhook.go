@@ -0,0 +1,63 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// WebhookNotifier POSTs notifications as JSON to a fixed URL. When a secret is
+// set the body is signed with HMAC-SHA256 in the X-Todo-Signature header.
+type WebhookNotifier struct {
+	url        string
+	secret     string
+	httpClient *http.Client
+}
+
+func NewWebhookNotifier(url, secret string) *WebhookNotifier {
+	return &WebhookNotifier{
+		url:        url,
+		secret:     secret,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, notification Notification) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"event":        notification.EventName(),
+		"notification": notification,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if n.secret != "" {
+		mac := hmac.New(sha256.New, []byte(n.secret))
+		mac.Write(body)
+		req.Header.Set("X-Todo-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"time"
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AccountExportRepository interface {
+	Create(export *models.AccountExport) error
+	GetByID(id uuid.UUID) (*models.AccountExport, error)
+	GetByUserID(userID uuid.UUID) ([]models.AccountExport, error)
+	Update(export *models.AccountExport) error
+	Delete(id uuid.UUID) error
+	FindExpired(expiredBefore time.Time, limit int) ([]models.AccountExport, error)
+	FailStale(updatedBefore time.Time, reason string) (int64, error)
+}
+
+type accountExportRepository struct {
+	db *gorm.DB
+}
+
+func NewAccountExportRepository(db *gorm.DB) AccountExportRepository {
+	return &accountExportRepository{db: db}
+}
+
+func (r *accountExportRepository) Create(export *models.AccountExport) error {
+	return r.db.Omit(clause.Associations).Create(export).Error
+}
+
+func (r *accountExportRepository) GetByID(id uuid.UUID) (*models.AccountExport, error) {
+	var export models.AccountExport
+	err := r.db.First(&export, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+// GetByUserID returns a user's exports, newest first
+func (r *accountExportRepository) GetByUserID(userID uuid.UUID) ([]models.AccountExport, error) {
+	var exports []models.AccountExport
+	err := r.db.Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&exports).Error
+	return exports, err
+}
+
+func (r *accountExportRepository) Update(export *models.AccountExport) error {
+	return r.db.Omit(clause.Associations).Save(export).Error
+}
+
+func (r *accountExportRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&models.AccountExport{}, "id = ?", id).Error
+}
+
+// FindExpired returns up to limit exports that expired before expiredBefore
+func (r *accountExportRepository) FindExpired(expiredBefore time.Time, limit int) ([]models.AccountExport, error) {
+	var exports []models.AccountExport
+	err := r.db.Where("expires_at IS NOT NULL AND expires_at < ?", expiredBefore).
+		Order("expires_at ASC").
+		Limit(limit).
+		Find(&exports).Error
+	return exports, err
+}
+
+// FailStale marks pending and running exports last updated before
+// updatedBefore as failed and returns how many it marked
+func (r *accountExportRepository) FailStale(updatedBefore time.Time, reason string) (int64, error) {
+	result := r.db.Model(&models.AccountExport{}).
+		Where("status IN ? AND updated_at < ?", []models.AccountExportStatus{models.AccountExportPending, models.AccountExportRunning}, updatedBefore).
+		Updates(map[string]interface{}{"status": models.AccountExportFailed, "error": reason})
+	return result.RowsAffected, result.Error
+}
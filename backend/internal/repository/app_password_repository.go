@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"time"
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AppPasswordRepository interface {
+	Create(password *models.AppPassword) error
+	GetByID(id uuid.UUID) (*models.AppPassword, error)
+	GetByTokenHash(hash string) (*models.AppPassword, error)
+	GetByUserID(userID uuid.UUID) ([]models.AppPassword, error)
+	Touch(id uuid.UUID, usedAt time.Time) error
+	Delete(id uuid.UUID) error
+}
+
+type appPasswordRepository struct {
+	db *gorm.DB
+}
+
+func NewAppPasswordRepository(db *gorm.DB) AppPasswordRepository {
+	return &appPasswordRepository{db: db}
+}
+
+func (r *appPasswordRepository) Create(password *models.AppPassword) error {
+	return r.db.Create(password).Error
+}
+
+func (r *appPasswordRepository) GetByID(id uuid.UUID) (*models.AppPassword, error) {
+	var password models.AppPassword
+	err := r.db.First(&password, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &password, nil
+}
+
+func (r *appPasswordRepository) GetByTokenHash(hash string) (*models.AppPassword, error) {
+	var password models.AppPassword
+	err := r.db.Preload("User").Where("token_hash = ?", hash).First(&password).Error
+	if err != nil {
+		return nil, err
+	}
+	return &password, nil
+}
+
+func (r *appPasswordRepository) GetByUserID(userID uuid.UUID) ([]models.AppPassword, error) {
+	var passwords []models.AppPassword
+	err := r.db.Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&passwords).Error
+	return passwords, err
+}
+
+// Touch records when a password was last used
+func (r *appPasswordRepository) Touch(id uuid.UUID, usedAt time.Time) error {
+	return r.db.Model(&models.AppPassword{}).
+		Where("id = ?", id).
+		Update("last_used_at", usedAt).Error
+}
+
+func (r *appPasswordRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&models.AppPassword{}, "id = ?", id).Error
+}
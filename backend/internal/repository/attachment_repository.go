@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AttachmentRepository interface {
+	Create(attachment *models.Attachment) error
+	GetByID(id uuid.UUID) (*models.Attachment, error)
+	GetByTodoID(todoID uuid.UUID) ([]models.Attachment, error)
+	GetAllByTodoID(todoID uuid.UUID) ([]models.Attachment, error)
+	GetAllByUserID(userID uuid.UUID) ([]models.Attachment, error)
+	Delete(id uuid.UUID) error
+}
+
+type attachmentRepository struct {
+	db *gorm.DB
+}
+
+func NewAttachmentRepository(db *gorm.DB) AttachmentRepository {
+	return &attachmentRepository{db: db}
+}
+
+func (r *attachmentRepository) Create(attachment *models.Attachment) error {
+	return r.db.Omit("Todo", "User").Create(attachment).Error
+}
+
+func (r *attachmentRepository) GetByID(id uuid.UUID) (*models.Attachment, error) {
+	var attachment models.Attachment
+	err := r.db.First(&attachment, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+func (r *attachmentRepository) GetByTodoID(todoID uuid.UUID) ([]models.Attachment, error) {
+	var attachments []models.Attachment
+	err := r.db.Where("todo_id = ?", todoID).
+		Order("created_at ASC").
+		Find(&attachments).Error
+	return attachments, err
+}
+
+// GetAllByTodoID returns the attachments of a todo including deleted ones
+func (r *attachmentRepository) GetAllByTodoID(todoID uuid.UUID) ([]models.Attachment, error) {
+	var attachments []models.Attachment
+	err := r.db.Unscoped().Where("todo_id = ?", todoID).Find(&attachments).Error
+	return attachments, err
+}
+
+// GetAllByUserID returns every attachment of a user including deleted ones
+func (r *attachmentRepository) GetAllByUserID(userID uuid.UUID) ([]models.Attachment, error) {
+	var attachments []models.Attachment
+	err := r.db.Unscoped().Where("user_id = ?", userID).
+		Order("created_at ASC").
+		Find(&attachments).Error
+	return attachments, err
+}
+
+func (r *attachmentRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&models.Attachment{}, "id = ?", id).Error
+}
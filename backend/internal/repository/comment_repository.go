@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"time"
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CommentRepository interface {
+	Create(comment *models.Comment) error
+	GetByID(id uuid.UUID) (*models.Comment, error)
+	ListByTodoID(todoID uuid.UUID, afterCreatedAt *time.Time, afterID uuid.UUID, limit int) ([]models.Comment, error)
+	FindByAuthorID(authorID uuid.UUID) ([]models.Comment, error)
+	Update(comment *models.Comment) error
+	Delete(id uuid.UUID) error
+}
+
+type commentRepository struct {
+	db *gorm.DB
+}
+
+func NewCommentRepository(db *gorm.DB) CommentRepository {
+	return &commentRepository{db: db}
+}
+
+func (r *commentRepository) Create(comment *models.Comment) error {
+	return r.db.Omit("Author", "Todo", "Mentions.User").Create(comment).Error
+}
+
+func (r *commentRepository) GetByID(id uuid.UUID) (*models.Comment, error) {
+	var comment models.Comment
+	err := r.db.Preload("Author").Preload("Mentions").First(&comment, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// ListByTodoID returns a todo's comments oldest first, starting after the
+// (created_at, id) position of the previous page when one is given
+func (r *commentRepository) ListByTodoID(todoID uuid.UUID, afterCreatedAt *time.Time, afterID uuid.UUID, limit int) ([]models.Comment, error) {
+	var comments []models.Comment
+
+	query := r.db.Preload("Author").Preload("Mentions").Where("todo_id = ?", todoID)
+	if afterCreatedAt != nil {
+		query = query.Where("(created_at, id) > (?, ?)", *afterCreatedAt, afterID)
+	}
+
+	err := query.Order("created_at ASC, id ASC").
+		Limit(limit).
+		Find(&comments).Error
+	return comments, err
+}
+
+// FindByAuthorID returns the comments a user wrote, oldest first
+func (r *commentRepository) FindByAuthorID(authorID uuid.UUID) ([]models.Comment, error) {
+	var comments []models.Comment
+	err := r.db.Preload("Author").Preload("Mentions").
+		Where("author_id = ?", authorID).
+		Order("created_at ASC, id ASC").
+		Find(&comments).Error
+	return comments, err
+}
+
+// Update saves the comment body and replaces its mentions
+func (r *commentRepository) Update(comment *models.Comment) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(comment).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("comment_id = ?", comment.ID).Delete(&models.CommentMention{}).Error; err != nil {
+			return err
+		}
+		if len(comment.Mentions) == 0 {
+			return nil
+		}
+		for i := range comment.Mentions {
+			comment.Mentions[i].CommentID = comment.ID
+		}
+		return tx.Omit("User").Create(&comment.Mentions).Error
+	})
+}
+
+func (r *commentRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&models.Comment{}, "id = ?", id).Error
+}
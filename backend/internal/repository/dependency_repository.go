@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type DependencyRepository interface {
+	Create(dependency *models.TodoDependency) error
+	Exists(todoID, dependsOnID uuid.UUID) (bool, error)
+	Delete(todoID, dependsOnID uuid.UUID) (bool, error)
+	GetDependsOn(todoID uuid.UUID) ([]models.Todo, error)
+	GetDependsOnIDs(todoIDs []uuid.UUID) ([]uuid.UUID, error)
+	WithGraphLock(userID uuid.UUID, fn func(repo DependencyRepository) error) error
+}
+
+type dependencyRepository struct {
+	db *gorm.DB
+}
+
+func NewDependencyRepository(db *gorm.DB) DependencyRepository {
+	return &dependencyRepository{db: db}
+}
+
+func (r *dependencyRepository) Create(dependency *models.TodoDependency) error {
+	return r.db.Omit("Todo", "DependsOn").Create(dependency).Error
+}
+
+func (r *dependencyRepository) Exists(todoID, dependsOnID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.TodoDependency{}).
+		Where("todo_id = ? AND depends_on_id = ?", todoID, dependsOnID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// WithGraphLock runs fn in a transaction holding an advisory lock on the
+// user's dependency graph, so concurrent additions can't each pass the cycle
+// check and then close a cycle together
+func (r *dependencyRepository) WithGraphLock(userID uuid.UUID, fn func(repo DependencyRepository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "todo_dependencies:"+userID.String()).Error; err != nil {
+			return err
+		}
+		return fn(&dependencyRepository{db: tx})
+	})
+}
+
+// Delete removes an edge and reports whether it existed
+func (r *dependencyRepository) Delete(todoID, dependsOnID uuid.UUID) (bool, error) {
+	result := r.db.Where("todo_id = ? AND depends_on_id = ?", todoID, dependsOnID).
+		Delete(&models.TodoDependency{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// GetDependsOn returns the todos that todoID depends on, finished or not
+func (r *dependencyRepository) GetDependsOn(todoID uuid.UUID) ([]models.Todo, error) {
+	var todos []models.Todo
+	err := r.db.Joins("JOIN todo_dependencies ON todo_dependencies.depends_on_id = todos.id").
+		Where("todo_dependencies.todo_id = ?", todoID).
+		Order("todos.created_at ASC").
+		Find(&todos).Error
+	if err != nil {
+		return nil, err
+	}
+	return todos, loadBlockers(r.db, todos)
+}
+
+// GetDependsOnIDs returns the direct dependencies of any of todoIDs
+func (r *dependencyRepository) GetDependsOnIDs(todoIDs []uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Model(&models.TodoDependency{}).
+		Where("todo_id IN ?", todoIDs).
+		Distinct().
+		Pluck("depends_on_id", &ids).Error
+	return ids, err
+}
+
+// loadBlockers fills BlockingIDs with the unfinished dependencies of each todo
+func loadBlockers(db *gorm.DB, todos []models.Todo) error {
+	if len(todos) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, len(todos))
+	index := make(map[uuid.UUID]int, len(todos))
+	for i := range todos {
+		ids[i] = todos[i].ID
+		index[todos[i].ID] = i
+	}
+
+	var edges []models.TodoDependency
+	err := db.Model(&models.TodoDependency{}).
+		Select("todo_dependencies.todo_id, todo_dependencies.depends_on_id").
+		Joins("JOIN todos ON todos.id = todo_dependencies.depends_on_id AND todos.deleted_at IS NULL").
+		Where("todo_dependencies.todo_id IN ? AND todos.status <> ?", ids, models.TodoStatusCompleted).
+		Find(&edges).Error
+	if err != nil {
+		return err
+	}
+
+	for _, edge := range edges {
+		i := index[edge.TodoID]
+		todos[i].BlockingIDs = append(todos[i].BlockingIDs, edge.DependsOnID)
+	}
+	return nil
+}
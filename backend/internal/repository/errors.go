@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// uniqueViolation is the SQLSTATE Postgres reports for a duplicate key
+const uniqueViolation = "23505"
+
+// IsUniqueViolation reports whether err was caused by a duplicate key in the
+// unique index named index
+func IsUniqueViolation(err error, index string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation && pgErr.ConstraintName == index
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ImportJobRepository interface {
+	Create(job *models.ImportJob) error
+	GetByID(id uuid.UUID) (*models.ImportJob, error)
+	UpdateIfStatus(job *models.ImportJob, statuses ...models.ImportJobStatus) (bool, error)
+}
+
+type importJobRepository struct {
+	db *gorm.DB
+}
+
+func NewImportJobRepository(db *gorm.DB) ImportJobRepository {
+	return &importJobRepository{db: db}
+}
+
+func (r *importJobRepository) Create(job *models.ImportJob) error {
+	return r.db.Create(job).Error
+}
+
+func (r *importJobRepository) GetByID(id uuid.UUID) (*models.ImportJob, error) {
+	var job models.ImportJob
+	err := r.db.First(&job, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// UpdateIfStatus saves a job only while its stored status is one of statuses,
+// reporting whether it did
+func (r *importJobRepository) UpdateIfStatus(job *models.ImportJob, statuses ...models.ImportJobStatus) (bool, error) {
+	result := r.db.Model(job).Omit(clause.Associations).
+		Where("status IN ?", statuses).
+		Select("*").
+		Updates(job)
+	return result.RowsAffected > 0, result.Error
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ListRepository interface {
+	Create(list *models.List) error
+	GetByID(id uuid.UUID) (*models.List, error)
+	GetByUserID(userID uuid.UUID) ([]models.List, error)
+	Update(list *models.List) error
+	Delete(id uuid.UUID) error
+	Lock(id uuid.UUID) error
+}
+
+type listRepository struct {
+	db *gorm.DB
+}
+
+func NewListRepository(db *gorm.DB) ListRepository {
+	return &listRepository{db: db}
+}
+
+func (r *listRepository) Create(list *models.List) error {
+	return r.db.Create(list).Error
+}
+
+func (r *listRepository) GetByID(id uuid.UUID) (*models.List, error) {
+	var list models.List
+	err := r.db.First(&list, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+func (r *listRepository) GetByUserID(userID uuid.UUID) ([]models.List, error) {
+	var lists []models.List
+	err := r.db.Where("user_id = ?", userID).
+		Order("name ASC").
+		Find(&lists).Error
+	return lists, err
+}
+
+func (r *listRepository) Update(list *models.List) error {
+	return r.db.Omit(clause.Associations).Save(list).Error
+}
+
+// Lock holds a row lock on a list until the end of the transaction, so
+// changes to its board are made one at a time
+func (r *listRepository) Lock(id uuid.UUID) error {
+	return r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		First(&models.List{}, "id = ?", id).Error
+}
+
+// Delete removes a list and its workflow and detaches its todos, which stay
+// in the inbox
+func (r *listRepository) Delete(id uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.Todo{}).Where("list_id = ?", id).
+			Updates(map[string]interface{}{"list_id": nil, "state": nil}).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("list_id = ?", id).Delete(&models.WorkflowState{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.List{}, "id = ?", id).Error
+	})
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"time"
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type OperationRepository interface {
+	Create(op *models.Operation) error
+	GetByID(id uuid.UUID) (*models.Operation, error)
+	Update(op *models.Operation) error
+	DeleteIfEmpty(id uuid.UUID) error
+	GetRecent(userID uuid.UUID, limit int) ([]models.Operation, error)
+	LatestUndoable(userID uuid.UUID, since time.Time) (*models.Operation, error)
+	LatestRedoable(userID uuid.UUID, since time.Time) (*models.Operation, error)
+}
+
+type operationRepository struct {
+	db *gorm.DB
+}
+
+func NewOperationRepository(db *gorm.DB) OperationRepository {
+	return &operationRepository{db: db}
+}
+
+// hasChanges limits operations to those that changed at least one todo
+const hasChanges = "EXISTS (SELECT 1 FROM todo_changes WHERE todo_changes.operation_id = operations.id)"
+
+func (r *operationRepository) Create(op *models.Operation) error {
+	return r.db.Omit("User").Create(op).Error
+}
+
+func (r *operationRepository) GetByID(id uuid.UUID) (*models.Operation, error) {
+	var op models.Operation
+	err := r.db.First(&op, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &op, nil
+}
+
+func (r *operationRepository) Update(op *models.Operation) error {
+	return r.db.Omit("User").Save(op).Error
+}
+
+// DeleteIfEmpty drops an operation that ended up changing nothing
+func (r *operationRepository) DeleteIfEmpty(id uuid.UUID) error {
+	return r.db.Where("id = ? AND NOT "+hasChanges, id).Delete(&models.Operation{}).Error
+}
+
+// GetRecent returns the user's latest operations, newest first
+func (r *operationRepository) GetRecent(userID uuid.UUID, limit int) ([]models.Operation, error) {
+	var ops []models.Operation
+	err := r.db.Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&ops).Error
+	return ops, err
+}
+
+// LatestUndoable returns the user's most recent operation since the given
+// time that is not an undo and has not been undone
+func (r *operationRepository) LatestUndoable(userID uuid.UUID, since time.Time) (*models.Operation, error) {
+	var op models.Operation
+	err := r.db.Where("user_id = ? AND kind <> ? AND undone_at IS NULL AND created_at >= ? AND "+hasChanges,
+		userID, models.OperationUndo, since).
+		Order("created_at DESC").
+		First(&op).Error
+	if err != nil {
+		return nil, err
+	}
+	return &op, nil
+}
+
+// LatestRedoable returns the user's most recent undo since the given time that
+// has not been redone, as long as no new change was made after it
+func (r *operationRepository) LatestRedoable(userID uuid.UUID, since time.Time) (*models.Operation, error) {
+	var op models.Operation
+	err := r.db.Where("user_id = ? AND kind = ? AND undone_at IS NULL AND created_at >= ? AND "+hasChanges,
+		userID, models.OperationUndo, since).
+		Where(`NOT EXISTS (SELECT 1 FROM operations AS later
+			WHERE later.user_id = operations.user_id AND later.created_at > operations.created_at
+			AND later.kind NOT IN ? AND `+"EXISTS (SELECT 1 FROM todo_changes WHERE todo_changes.operation_id = later.id))",
+			[]models.OperationKind{models.OperationUndo, models.OperationRedo}).
+		Order("created_at DESC").
+		First(&op).Error
+	if err != nil {
+		return nil, err
+	}
+	return &op, nil
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"errors"
+	"time"
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReminderRepository interface {
+	Create(reminder *models.Reminder) error
+	GetByID(id uuid.UUID) (*models.Reminder, error)
+	GetByTodoID(todoID uuid.UUID) ([]models.Reminder, error)
+	Update(reminder *models.Reminder) error
+	Delete(id uuid.UUID) error
+	RescheduleForTodo(todoID uuid.UUID, dueDate *time.Time) error
+	CancelForTodo(todoID uuid.UUID) error
+	ClaimDue(now time.Time, lease time.Duration, limit int) ([]models.Reminder, error)
+}
+
+type reminderRepository struct {
+	db *gorm.DB
+}
+
+func NewReminderRepository(db *gorm.DB) ReminderRepository {
+	return &reminderRepository{db: db}
+}
+
+func (r *reminderRepository) Create(reminder *models.Reminder) error {
+	return r.db.Create(reminder).Error
+}
+
+func (r *reminderRepository) GetByID(id uuid.UUID) (*models.Reminder, error) {
+	var reminder models.Reminder
+	err := r.db.First(&reminder, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &reminder, nil
+}
+
+func (r *reminderRepository) GetByTodoID(todoID uuid.UUID) ([]models.Reminder, error) {
+	var reminders []models.Reminder
+	err := r.db.Where("todo_id = ?", todoID).
+		Order("fire_at ASC NULLS LAST").
+		Find(&reminders).Error
+	return reminders, err
+}
+
+func (r *reminderRepository) Update(reminder *models.Reminder) error {
+	return r.db.Omit(clause.Associations).Save(reminder).Error
+}
+
+func (r *reminderRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&models.Reminder{}, "id = ?", id).Error
+}
+
+// RescheduleForTodo recomputes the fire time of a todo's pending offset
+// reminders after its due date changed
+func (r *reminderRepository) RescheduleForTodo(todoID uuid.UUID, dueDate *time.Time) error {
+	var reminders []models.Reminder
+	err := r.db.Where("todo_id = ? AND status = ? AND offset_minutes IS NOT NULL", todoID, models.ReminderStatusPending).
+		Find(&reminders).Error
+	if err != nil {
+		return err
+	}
+
+	for i := range reminders {
+		reminders[i].Resolve(dueDate)
+		if err := r.db.Model(&reminders[i]).Update("fire_at", reminders[i].FireAt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CancelForTodo cancels the reminders of a todo that have not fired yet
+func (r *reminderRepository) CancelForTodo(todoID uuid.UUID) error {
+	return r.db.Model(&models.Reminder{}).
+		Where("todo_id = ? AND status = ?", todoID, models.ReminderStatusPending).
+		Update("status", models.ReminderStatusCancelled).Error
+}
+
+// ClaimDue marks up to limit due reminders as processing and returns them with
+// their todo and user loaded. Rows are selected with FOR UPDATE SKIP LOCKED so
+// concurrent replicas never claim the same reminder; claims older than lease
+// are considered abandoned and can be taken over.
+func (r *reminderRepository) ClaimDue(now time.Time, lease time.Duration, limit int) ([]models.Reminder, error) {
+	var reminders []models.Reminder
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("(status = ? AND fire_at <= ?) OR (status = ? AND claimed_at < ?)",
+				models.ReminderStatusPending, now,
+				models.ReminderStatusProcessing, now.Add(-lease)).
+			Order("fire_at ASC").
+			Limit(limit).
+			Find(&reminders).Error
+		if err != nil || len(reminders) == 0 {
+			return err
+		}
+
+		ids := make([]uuid.UUID, len(reminders))
+		for i := range reminders {
+			ids[i] = reminders[i].ID
+			reminders[i].Status = models.ReminderStatusProcessing
+			reminders[i].ClaimedAt = &now
+		}
+		return tx.Model(&models.Reminder{}).
+			Where("id IN ?", ids).
+			Updates(map[string]interface{}{"status": models.ReminderStatusProcessing, "claimed_at": now}).Error
+	})
+	if err != nil || len(reminders) == 0 {
+		return nil, err
+	}
+
+	// Load relations outside the locking transaction
+	for i := range reminders {
+		if err := r.db.Preload("User").First(&reminders[i].Todo, "id = ?", reminders[i].TodoID).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		reminders[i].User = reminders[i].Todo.User
+	}
+	return reminders, nil
+}
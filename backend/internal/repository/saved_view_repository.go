@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SavedViewRepository interface {
+	Create(view *models.SavedView) error
+	GetByID(id uuid.UUID) (*models.SavedView, error)
+	GetByUserID(userID uuid.UUID) ([]models.SavedView, error)
+	Update(view *models.SavedView) error
+	Delete(id uuid.UUID) error
+}
+
+type savedViewRepository struct {
+	db *gorm.DB
+}
+
+func NewSavedViewRepository(db *gorm.DB) SavedViewRepository {
+	return &savedViewRepository{db: db}
+}
+
+func (r *savedViewRepository) Create(view *models.SavedView) error {
+	return r.db.Create(view).Error
+}
+
+func (r *savedViewRepository) GetByID(id uuid.UUID) (*models.SavedView, error) {
+	var view models.SavedView
+	err := r.db.First(&view, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &view, nil
+}
+
+func (r *savedViewRepository) GetByUserID(userID uuid.UUID) ([]models.SavedView, error) {
+	var views []models.SavedView
+	err := r.db.Where("user_id = ?", userID).
+		Order("name ASC").
+		Find(&views).Error
+	return views, err
+}
+
+func (r *savedViewRepository) Update(view *models.SavedView) error {
+	return r.db.Omit(clause.Associations).Save(view).Error
+}
+
+func (r *savedViewRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&models.SavedView{}, "id = ?", id).Error
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TagRepository interface {
+	GetByUserID(userID uuid.UUID) ([]models.Tag, error)
+	FindOrCreate(userID uuid.UUID, names []string) ([]models.Tag, error)
+}
+
+type tagRepository struct {
+	db *gorm.DB
+}
+
+func NewTagRepository(db *gorm.DB) TagRepository {
+	return &tagRepository{db: db}
+}
+
+func (r *tagRepository) GetByUserID(userID uuid.UUID) ([]models.Tag, error) {
+	var tags []models.Tag
+	err := r.db.Where("user_id = ?", userID).
+		Order("name ASC").
+		Find(&tags).Error
+	return tags, err
+}
+
+// FindOrCreate returns the user's tags with the given names, creating the
+// ones that do not exist yet
+func (r *tagRepository) FindOrCreate(userID uuid.UUID, names []string) ([]models.Tag, error) {
+	if len(names) == 0 {
+		return []models.Tag{}, nil
+	}
+
+	tags := make([]models.Tag, len(names))
+	for i, name := range names {
+		tags[i] = models.Tag{UserID: userID, Name: name}
+	}
+	err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&tags).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var found []models.Tag
+	err = r.db.Where("user_id = ? AND name IN ?", userID, names).
+		Order("name ASC").
+		Find(&found).Error
+	return found, err
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TemplateRepository interface {
+	Create(template *models.Template) error
+	GetByID(id uuid.UUID) (*models.Template, error)
+	GetByUserID(userID uuid.UUID) ([]models.Template, error)
+	Update(template *models.Template) error
+	Delete(id uuid.UUID) error
+}
+
+type templateRepository struct {
+	db *gorm.DB
+}
+
+func NewTemplateRepository(db *gorm.DB) TemplateRepository {
+	return &templateRepository{db: db}
+}
+
+func (r *templateRepository) Create(template *models.Template) error {
+	return r.db.Create(template).Error
+}
+
+func (r *templateRepository) GetByID(id uuid.UUID) (*models.Template, error) {
+	var template models.Template
+	err := r.db.First(&template, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+func (r *templateRepository) GetByUserID(userID uuid.UUID) ([]models.Template, error) {
+	var templates []models.Template
+	err := r.db.Where("user_id = ?", userID).
+		Order("name ASC").
+		Find(&templates).Error
+	return templates, err
+}
+
+func (r *templateRepository) Update(template *models.Template) error {
+	return r.db.Omit(clause.Associations).Save(template).Error
+}
+
+func (r *templateRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&models.Template{}, "id = ?", id).Error
+}
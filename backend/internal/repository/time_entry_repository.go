@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type TimeEntryRepository interface {
+	Create(entry *models.TimeEntry) error
+	GetByID(id uuid.UUID) (*models.TimeEntry, error)
+	Update(entry *models.TimeEntry) error
+	Delete(id uuid.UUID) error
+	ListByTodoID(todoID uuid.UUID, offset, limit int) ([]models.TimeEntry, int64, error)
+	GetRunning(userID uuid.UUID) (*models.TimeEntry, error)
+	Start(entry *models.TimeEntry) (*models.TimeEntry, error)
+	Report(userID uuid.UUID, from, to time.Time, groupBy string, timezone string) ([]models.TimeReportRow, int64, error)
+}
+
+type timeEntryRepository struct {
+	db *gorm.DB
+}
+
+func NewTimeEntryRepository(db *gorm.DB) TimeEntryRepository {
+	return &timeEntryRepository{db: db}
+}
+
+func (r *timeEntryRepository) Create(entry *models.TimeEntry) error {
+	return r.db.Create(entry).Error
+}
+
+func (r *timeEntryRepository) GetByID(id uuid.UUID) (*models.TimeEntry, error) {
+	var entry models.TimeEntry
+	err := r.db.First(&entry, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *timeEntryRepository) Update(entry *models.TimeEntry) error {
+	return r.db.Omit("Todo", "User").Save(entry).Error
+}
+
+func (r *timeEntryRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&models.TimeEntry{}, "id = ?", id).Error
+}
+
+// ListByTodoID returns one page of a todo's time entries, latest first
+func (r *timeEntryRepository) ListByTodoID(todoID uuid.UUID, offset, limit int) ([]models.TimeEntry, int64, error) {
+	query := r.db.Model(&models.TimeEntry{}).Where("todo_id = ?", todoID).Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var entries []models.TimeEntry
+	err := query.Order("started_at DESC, id").
+		Offset(offset).
+		Limit(limit).
+		Find(&entries).Error
+	return entries, total, err
+}
+
+// GetRunning returns the user's running timer
+func (r *timeEntryRepository) GetRunning(userID uuid.UUID) (*models.TimeEntry, error) {
+	var entry models.TimeEntry
+	err := r.db.Where("user_id = ? AND ended_at IS NULL", userID).First(&entry).Error
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// Start stops the user's running timer, if any, and starts entry in its
+// place. It returns the stopped timer.
+func (r *timeEntryRepository) Start(entry *models.TimeEntry) (*models.TimeEntry, error) {
+	var stopped *models.TimeEntry
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var running models.TimeEntry
+		err := tx.Where("user_id = ? AND ended_at IS NULL", entry.UserID).First(&running).Error
+		switch {
+		case err == nil:
+			end := entry.StartedAt
+			if err := running.SetTimes(running.StartedAt, &end, end); err != nil {
+				return err
+			}
+			if err := tx.Omit("Todo", "User").Save(&running).Error; err != nil {
+				return err
+			}
+			stopped = &running
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return err
+		}
+		return tx.Create(entry).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stopped, nil
+}
+
+// Report sums the user's finished time entries started in [from, to) by day
+// in timezone, by list or by tag
+func (r *timeEntryRepository) Report(userID uuid.UUID, from, to time.Time, groupBy string, timezone string) ([]models.TimeReportRow, int64, error) {
+	base := func() *gorm.DB {
+		return r.db.Model(&models.TimeEntry{}).
+			Where("time_entries.user_id = ? AND time_entries.ended_at IS NOT NULL", userID).
+			Where("time_entries.started_at >= ? AND time_entries.started_at < ?", from, to)
+	}
+
+	var total int64
+	err := base().Select("COALESCE(SUM(time_entries.duration_seconds), 0)").Scan(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	const sums = "SUM(time_entries.duration_seconds) AS seconds, COUNT(*) AS entries"
+	query := base()
+	switch groupBy {
+	case models.TimeReportByDay:
+		day := "to_char(time_entries.started_at AT TIME ZONE ?, 'YYYY-MM-DD')"
+		query = query.Select(day+" AS key, "+day+" AS label, "+sums, timezone, timezone).
+			Group("1, 2").
+			Order("1")
+	case models.TimeReportByList:
+		query = query.
+			Joins("JOIN todos ON todos.id = time_entries.todo_id").
+			Joins("LEFT JOIN lists ON lists.id = todos.list_id AND lists.deleted_at IS NULL").
+			Select("COALESCE(lists.id::text, '') AS key, COALESCE(lists.name, '') AS label, " + sums).
+			Group("1, 2").
+			Order("seconds DESC, 2")
+	case models.TimeReportByTag:
+		query = query.
+			Joins("LEFT JOIN todo_tags ON todo_tags.todo_id = time_entries.todo_id").
+			Joins("LEFT JOIN tags ON tags.id = todo_tags.tag_id").
+			Select("COALESCE(tags.name, '') AS key, COALESCE(tags.name, '') AS label, " + sums).
+			Group("1, 2").
+			Order("seconds DESC, 1")
+	default:
+		return nil, 0, fmt.Errorf("unknown time report grouping %q", groupBy)
+	}
+
+	var rows []models.TimeReportRow
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	return rows, total, nil
+}
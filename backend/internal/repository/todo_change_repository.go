@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"time"
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type TodoChangeRepository interface {
+	Create(change *models.TodoChange) error
+	GetByTodoID(todoID uuid.UUID, offset, limit int) ([]models.TodoChange, int64, error)
+	GetByOperationID(operationID uuid.UUID) ([]models.TodoChange, error)
+	GetByUserSince(userID uuid.UUID, since time.Time) ([]models.TodoChange, error)
+}
+
+type todoChangeRepository struct {
+	db *gorm.DB
+}
+
+func NewTodoChangeRepository(db *gorm.DB) TodoChangeRepository {
+	return &todoChangeRepository{db: db}
+}
+
+func (r *todoChangeRepository) Create(change *models.TodoChange) error {
+	return r.db.Omit("Todo").Create(change).Error
+}
+
+// GetByTodoID returns one page of a todo's history, newest first
+func (r *todoChangeRepository) GetByTodoID(todoID uuid.UUID, offset, limit int) ([]models.TodoChange, int64, error) {
+	query := r.db.Model(&models.TodoChange{}).Where("todo_id = ?", todoID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var changes []models.TodoChange
+	err := query.Order("created_at DESC, seq DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&changes).Error
+	return changes, total, err
+}
+
+// GetByOperationID returns the changes an operation made, latest first
+func (r *todoChangeRepository) GetByOperationID(operationID uuid.UUID) ([]models.TodoChange, error) {
+	var changes []models.TodoChange
+	err := r.db.Where("operation_id = ?", operationID).
+		Order("seq DESC").
+		Find(&changes).Error
+	return changes, err
+}
+
+// GetByUserSince returns the changes to a user's todos made at or after since,
+// latest first
+func (r *todoChangeRepository) GetByUserSince(userID uuid.UUID, since time.Time) ([]models.TodoChange, error) {
+	var changes []models.TodoChange
+	err := r.db.Select("id", "todo_id", "action", "changes", "created_at").
+		Where("user_id = ? AND created_at >= ?", userID, since).
+		Order("created_at DESC, id").
+		Find(&changes).Error
+	return changes, err
+}
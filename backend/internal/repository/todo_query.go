@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"todo-backend/internal/models"
+	"todo-backend/pkg/tql"
+)
+
+// compileTodoQuery translates a parsed query into a SQL condition on todos.
+// Day boundaries of date terms are taken in now's location.
+func compileTodoQuery(expr tql.Expr, now time.Time) (string, []interface{}) {
+	switch e := expr.(type) {
+	case *tql.And:
+		return compileTodoQueryList(e.Exprs, " AND ", now)
+	case *tql.Or:
+		return compileTodoQueryList(e.Exprs, " OR ", now)
+	case *tql.Not:
+		condition, args := compileTodoQuery(e.Expr, now)
+		return "NOT " + condition, args
+	case *tql.Text:
+		pattern := "%" + escapeLike(e.Text) + "%"
+		return "(todos.title ILIKE ? OR COALESCE(todos.description, '') ILIKE ?)", []interface{}{pattern, pattern}
+	case *tql.Condition:
+		condition, args := compileTodoCondition(e, now)
+		if e.Op == "!=" {
+			condition = "NOT " + condition
+		}
+		// NULL columns never match a term, so negating it must still match them
+		return "COALESCE(" + condition + ", FALSE)", args
+	}
+	return "TRUE", nil
+}
+
+func compileTodoQueryList(exprs []tql.Expr, separator string, now time.Time) (string, []interface{}) {
+	parts := make([]string, len(exprs))
+	var args []interface{}
+	for i, expr := range exprs {
+		condition, exprArgs := compileTodoQuery(expr, now)
+		parts[i] = condition
+		args = append(args, exprArgs...)
+	}
+	return "(" + strings.Join(parts, separator) + ")", args
+}
+
+// compileTodoCondition renders a field term; "!=" is rendered like "=" and
+// negated by the caller
+func compileTodoCondition(c *tql.Condition, now time.Time) (string, []interface{}) {
+	switch c.Field {
+	case tql.FieldStatus:
+		return "(todos.status IN ?)", []interface{}{c.Values}
+	case tql.FieldPriority:
+		op := c.Op
+		if op == "!=" {
+			op = "="
+		}
+		return fmt.Sprintf("(todos.priority %s ?)", op), []interface{}{c.Ints[0]}
+	case tql.FieldDue:
+		return compileDayCondition("todos.due_date", c.Op, c.Dates[0].Day(now))
+	case tql.FieldCreated:
+		return compileDayCondition("todos.created_at", c.Op, c.Dates[0].Day(now))
+	case tql.FieldUpdated:
+		return compileDayCondition("todos.updated_at", c.Op, c.Dates[0].Day(now))
+	case tql.FieldTag:
+		return `EXISTS (SELECT 1 FROM todo_tags JOIN tags ON tags.id = todo_tags.tag_id
+			WHERE todo_tags.todo_id = todos.id AND tags.name IN ?)`, []interface{}{c.Values}
+	case tql.FieldList:
+		return `(todos.list_id IN (SELECT lists.id FROM lists
+			WHERE lists.user_id = todos.user_id AND lists.deleted_at IS NULL AND LOWER(lists.name) IN ?))`, []interface{}{c.Values}
+	case tql.FieldIs:
+		return compileAnyOf(c.Values, func(value string) (string, []interface{}) {
+			switch value {
+			case "overdue":
+				return "(todos.due_date < ? AND todos.status <> ?)", []interface{}{now, models.TodoStatusCompleted}
+			case "recurring":
+				return "(todos.recurrence_rule <> '')", nil
+			default: // blocked
+				return `EXISTS (SELECT 1 FROM todo_dependencies
+					JOIN todos AS blockers ON blockers.id = todo_dependencies.depends_on_id AND blockers.deleted_at IS NULL
+					WHERE todo_dependencies.todo_id = todos.id AND blockers.status <> ?)`, []interface{}{models.TodoStatusCompleted}
+			}
+		})
+	case tql.FieldHas:
+		return compileAnyOf(c.Values, func(value string) (string, []interface{}) {
+			switch value {
+			case "due":
+				return "(todos.due_date IS NOT NULL)", nil
+			case "list":
+				return "(todos.list_id IS NOT NULL)", nil
+			default: // tags
+				return "EXISTS (SELECT 1 FROM todo_tags WHERE todo_tags.todo_id = todos.id)", nil
+			}
+		})
+	}
+	return "TRUE", nil
+}
+
+// compileDayCondition compares a timestamp column with a calendar day:
+// "=" matches the whole day and "<=" / ">" include / exclude it
+func compileDayCondition(column, op string, day time.Time) (string, []interface{}) {
+	next := day.AddDate(0, 0, 1)
+	switch op {
+	case "<":
+		return fmt.Sprintf("(%s < ?)", column), []interface{}{day}
+	case "<=":
+		return fmt.Sprintf("(%s < ?)", column), []interface{}{next}
+	case ">":
+		return fmt.Sprintf("(%s >= ?)", column), []interface{}{next}
+	case ">=":
+		return fmt.Sprintf("(%s >= ?)", column), []interface{}{day}
+	default: // "=" and "!="
+		return fmt.Sprintf("(%s >= ? AND %s < ?)", column, column), []interface{}{day, next}
+	}
+}
+
+func compileAnyOf(values []string, compile func(string) (string, []interface{})) (string, []interface{}) {
+	if len(values) == 1 {
+		return compile(values[0])
+	}
+	parts := make([]string, len(values))
+	var args []interface{}
+	for i, value := range values {
+		condition, valueArgs := compile(value)
+		parts[i] = condition
+		args = append(args, valueArgs...)
+	}
+	return "(" + strings.Join(parts, " OR ") + ")", args
+}
+
+// escapeLike escapes the LIKE wildcards in s
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"testing"
+	"time"
+	"todo-backend/pkg/tql"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompileTodoQuery(t *testing.T) {
+	now := time.Date(2024, 1, 10, 15, 0, 0, 0, time.UTC)
+	today := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		query string
+		want  string
+		args  []interface{}
+	}{
+		{
+			name:  "status alternatives",
+			query: "status:pending,in_progress",
+			want:  "COALESCE((todos.status IN ?), FALSE)",
+			args:  []interface{}{[]string{"pending", "in_progress"}},
+		},
+		{
+			name:  "relative due date",
+			query: "due<7d",
+			want:  "COALESCE((todos.due_date < ?), FALSE)",
+			args:  []interface{}{today.AddDate(0, 0, 7)},
+		},
+		{
+			name:  "day equality",
+			query: "due:today",
+			want:  "COALESCE((todos.due_date >= ? AND todos.due_date < ?), FALSE)",
+			args:  []interface{}{today, today.AddDate(0, 0, 1)},
+		},
+		{
+			name:  "inclusive upper bound",
+			query: "created<=yesterday",
+			want:  "COALESCE((todos.created_at < ?), FALSE)",
+			args:  []interface{}{today},
+		},
+		{
+			name:  "negated priority and text",
+			query: "-priority:0 OR 50%",
+			want:  "(NOT COALESCE((todos.priority = ?), FALSE) OR (todos.title ILIKE ? OR COALESCE(todos.description, '') ILIKE ?))",
+			args:  []interface{}{0, `%50\%%`, `%50\%%`},
+		},
+		{
+			name:  "not equal",
+			query: "status!=completed has:due",
+			want:  "(COALESCE(NOT (todos.status IN ?), FALSE) AND COALESCE((todos.due_date IS NOT NULL), FALSE))",
+			args:  []interface{}{[]string{"completed"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := tql.Parse(tt.query)
+			require.NoError(t, err)
+
+			condition, args := compileTodoQuery(expr, now)
+			assert.Equal(t, tt.want, condition)
+			assert.Equal(t, tt.args, args)
+		})
+	}
+}
@@ -1,21 +1,82 @@
 package repository
 
 import (
+	"fmt"
+	"strings"
+	"time"
 	"todo-backend/internal/models"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TodoRepository interface {
 	Create(todo *models.Todo) error
 	GetByID(id uuid.UUID) (*models.Todo, error)
-	GetByUserID(userID uuid.UUID, offset, limit int) ([]models.Todo, int64, error)
+	Find(userID uuid.UUID, filter *models.TodoFilter, offset, limit int) ([]models.Todo, int64, error)
+	FindAfter(userID uuid.UUID, filter *models.TodoFilter, cursor *models.TodoCursor, limit int) ([]models.Todo, error)
+	Count(userID uuid.UUID, filter *models.TodoFilter) (int64, error)
 	Update(todo *models.Todo) error
+	SetTags(todo *models.Todo, tags []models.Tag) error
 	Delete(id uuid.UUID) error
-	GetByStatus(userID uuid.UUID, status models.TodoStatus) ([]models.Todo, error)
+	GetOpenBySeriesID(seriesID uuid.UUID) ([]models.Todo, error)
+
+	// Trash
+	FindDeleted(userID uuid.UUID, offset, limit int) ([]models.Todo, int64, error)
+	GetDeletedByID(id uuid.UUID) (*models.Todo, error)
+	Restore(todo *models.Todo) error
+	Purge(id uuid.UUID) error
+	FindExpired(deletedBefore time.Time, limit int) ([]models.Todo, error)
+
+	// Manual order
+	LastPosition(userID uuid.UUID) (string, error)
+	AdjacentPosition(userID uuid.UUID, position string, after bool, excludeID uuid.UUID) (string, error)
+	FindInManualOrder(userID uuid.UUID) ([]models.Todo, error)
+	SetPositions(positions map[uuid.UUID]string, updatedAt time.Time) error
+
+	// Boards
+	CountByState(listID uuid.UUID) (map[string]int64, error)
+	FindByState(listID uuid.UUID, state string, limit int) ([]models.Todo, error)
+
+	// Estimates
+	Effort(userID uuid.UUID, groupBy string) ([]models.EffortRollup, error)
+	SubtreeEffort(rootID uuid.UUID) ([]models.EffortRollup, error)
+	FindAllWithDeleted(userID uuid.UUID) ([]models.Todo, error)
+
+	// Templates
+	FindSubtree(rootID uuid.UUID) ([]models.Todo, error)
+	FindByListID(listID uuid.UUID) ([]models.Todo, error)
+
+	// Calendar feeds
+	FindDue(userID uuid.UUID, listID *uuid.UUID, includeCompleted bool) ([]models.Todo, error)
+
+	// CalDAV
+	FindInCollection(userID uuid.UUID, listID *uuid.UUID) ([]models.Todo, error)
+	GetByCalDAVName(userID uuid.UUID, name string) (*models.Todo, error)
+	LockByCalDAVName(userID uuid.UUID, name string) (*models.Todo, error)
+	GetByICalUID(userID uuid.UUID, uid string) (*models.Todo, error)
+	CollectionTag(userID uuid.UUID, listID *uuid.UUID) (string, error)
 }
 
+// stateColumn is a todo's board state: its own state key on lists with a
+// custom workflow, its status otherwise
+const stateColumn = "COALESCE(todos.state, todos.status)"
+
+// Unique indexes on the CalDAV identity of todos, see models.Todo
+const (
+	TodoICalUIDIndex    = "idx_todos_user_ical_uid"
+	TodoCalDAVNameIndex = "idx_todos_user_caldav_name"
+)
+
+// positionColumn compares rank keys byte-wise whatever the database collation
+const positionColumn = `todos.position COLLATE "C"`
+
+// trashedWithTodo are the subresources moved to the trash together with their
+// todo. They share the todo's deletion time, which tells them apart from ones
+// deleted on their own earlier.
+var trashedWithTodo = []interface{}{&models.Reminder{}, &models.Comment{}, &models.Attachment{}, &models.TimeEntry{}}
+
 type todoRepository struct {
 	db *gorm.DB
 }
@@ -30,44 +91,665 @@ func (r *todoRepository) Create(todo *models.Todo) error {
 
 func (r *todoRepository) GetByID(id uuid.UUID) (*models.Todo, error) {
 	var todo models.Todo
-	err := r.db.Preload("User").First(&todo, "id = ?", id).Error
+	err := r.db.Preload("User").Preload("Tags").First(&todo, "id = ?", id).Error
 	if err != nil {
 		return nil, err
 	}
-	return &todo, nil
+	todos := []models.Todo{todo}
+	if err := loadBlockers(r.db, todos); err != nil {
+		return nil, err
+	}
+	return &todos[0], nil
 }
 
-func (r *todoRepository) GetByUserID(userID uuid.UUID, offset, limit int) ([]models.Todo, int64, error) {
-	var todos []models.Todo
-	var total int64
+// Find returns one page of a user's todos matching every condition of filter,
+// ordered by the filter's sort keys, together with the total number of matches
+func (r *todoRepository) Find(userID uuid.UUID, filter *models.TodoFilter, offset, limit int) ([]models.Todo, int64, error) {
+	if filter == nil {
+		filter = &models.TodoFilter{}
+	}
+
+	query := applyTodoFilter(r.db.Model(&models.Todo{}).Where("todos.user_id = ?", userID), filter, time.Now()).
+		Session(&gorm.Session{})
 
 	// Count total records
-	if err := r.db.Model(&models.Todo{}).Where("user_id = ?", userID).Count(&total).Error; err != nil {
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	// Get paginated records
-	err := r.db.Where("user_id = ?", userID).
-		Order("created_at DESC").
+	var todos []models.Todo
+	err := query.Preload("Tags").
+		Order(todoOrderClause(todoOrderKeys(filter.Sort, false))).
 		Offset(offset).
 		Limit(limit).
 		Find(&todos).Error
+	if err != nil {
+		return nil, 0, err
+	}
 
-	return todos, total, err
+	return todos, total, loadBlockers(r.db, todos)
+}
+
+// FindAfter returns up to limit todos matching filter that follow cursor in
+// the filter's sort order, or precede it for a backward cursor. A nil cursor
+// starts from the beginning. Rows come back in walk order, so backward pages
+// are reversed relative to the sort order.
+func (r *todoRepository) FindAfter(userID uuid.UUID, filter *models.TodoFilter, cursor *models.TodoCursor, limit int) ([]models.Todo, error) {
+	if filter == nil {
+		filter = &models.TodoFilter{}
+	}
+
+	backward := cursor != nil && cursor.Backward
+	keys := todoOrderKeys(filter.Sort, backward)
+	query := applyTodoFilter(r.db.Where("todos.user_id = ?", userID), filter, time.Now())
+
+	if cursor != nil {
+		values, err := cursor.KeyValues(filter.Sort)
+		if err != nil {
+			return nil, err
+		}
+		condition, args := keysetCondition(keys, append(values, cursor.ID))
+		query = query.Where(condition, args...)
+	}
+
+	var todos []models.Todo
+	err := query.Preload("Tags").
+		Order(todoOrderClause(keys)).
+		Limit(limit).
+		Find(&todos).Error
+	if err != nil {
+		return nil, err
+	}
+	return todos, loadBlockers(r.db, todos)
+}
+
+// Count returns the number of the user's todos matching filter
+func (r *todoRepository) Count(userID uuid.UUID, filter *models.TodoFilter) (int64, error) {
+	if filter == nil {
+		filter = &models.TodoFilter{}
+	}
+
+	var total int64
+	err := applyTodoFilter(r.db.Model(&models.Todo{}).Where("todos.user_id = ?", userID), filter, time.Now()).
+		Count(&total).Error
+	return total, err
 }
 
 func (r *todoRepository) Update(todo *models.Todo) error {
-	return r.db.Save(todo).Error
+	return r.db.Omit(clause.Associations).Save(todo).Error
+}
+
+// SetTags replaces the tags of a todo
+func (r *todoRepository) SetTags(todo *models.Todo, tags []models.Tag) error {
+	return r.db.Model(todo).Association("Tags").Replace(tags)
 }
 
+// Delete moves a todo and its subresources to the trash
 func (r *todoRepository) Delete(id uuid.UUID) error {
-	return r.db.Delete(&models.Todo{}, "id = ?", id).Error
+	// Postgres keeps microseconds; truncating lets Restore match the stored value
+	now := time.Now().Truncate(time.Microsecond)
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// A timer running on the todo stops, so restoring it can't leave the
+		// user with two running timers
+		err := tx.Model(&models.TimeEntry{}).Where("todo_id = ? AND ended_at IS NULL", id).
+			Updates(map[string]interface{}{
+				"ended_at":         now,
+				"duration_seconds": gorm.Expr("FLOOR(EXTRACT(EPOCH FROM (? - started_at)))", now),
+			}).Error
+		if err != nil {
+			return err
+		}
+
+		for _, model := range trashedWithTodo {
+			err := tx.Model(model).Where("todo_id = ?", id).Update("deleted_at", now).Error
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Model(&models.Todo{}).Where("id = ?", id).Update("deleted_at", now).Error
+	})
 }
 
-func (r *todoRepository) GetByStatus(userID uuid.UUID, status models.TodoStatus) ([]models.Todo, error) {
+// GetOpenBySeriesID returns the occurrences of a recurring series that are not completed yet
+func (r *todoRepository) GetOpenBySeriesID(seriesID uuid.UUID) ([]models.Todo, error) {
 	var todos []models.Todo
-	err := r.db.Where("user_id = ? AND status = ?", userID, status).
-		Order("created_at DESC").
+	err := r.db.Where("series_id = ? AND status <> ?", seriesID, models.TodoStatusCompleted).
+		Order("due_date ASC").
 		Find(&todos).Error
 	return todos, err
-} 
\ No newline at end of file
+}
+
+// FindDeleted returns one page of a user's trashed todos, most recently deleted first
+func (r *todoRepository) FindDeleted(userID uuid.UUID, offset, limit int) ([]models.Todo, int64, error) {
+	query := r.db.Unscoped().Model(&models.Todo{}).
+		Where("user_id = ? AND deleted_at IS NOT NULL", userID).
+		Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var todos []models.Todo
+	err := query.Preload("Tags").
+		Order("deleted_at DESC, id").
+		Offset(offset).
+		Limit(limit).
+		Find(&todos).Error
+	return todos, total, err
+}
+
+// GetDeletedByID returns a todo that is in the trash
+func (r *todoRepository) GetDeletedByID(id uuid.UUID) (*models.Todo, error) {
+	var todo models.Todo
+	err := r.db.Unscoped().Preload("Tags").
+		Where("deleted_at IS NOT NULL").
+		First(&todo, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &todo, nil
+}
+
+// Restore takes a trashed todo out of the trash together with the
+// subresources deleted along with it, and saves its list and board state
+func (r *todoRepository) Restore(todo *models.Todo) error {
+	deletedAt := todo.DeletedAt.Time
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, model := range trashedWithTodo {
+			err := tx.Unscoped().Model(model).
+				Where("todo_id = ? AND deleted_at = ?", todo.ID, deletedAt).
+				Update("deleted_at", nil).Error
+			if err != nil {
+				return err
+			}
+		}
+		err := tx.Unscoped().Model(&models.Todo{}).Where("id = ?", todo.ID).
+			Updates(map[string]interface{}{
+				"deleted_at":  nil,
+				"list_id":     todo.ListID,
+				"state":       todo.State,
+				"ical_uid":    todo.ICalUID,
+				"caldav_name": todo.CalDAVName,
+			}).Error
+		if err != nil {
+			return err
+		}
+		todo.DeletedAt = gorm.DeletedAt{}
+		return nil
+	})
+}
+
+// Purge deletes a todo for good; the database cascades the delete to its
+// reminders, comments, attachments, dependencies and tag links
+func (r *todoRepository) Purge(id uuid.UUID) error {
+	return r.db.Unscoped().Delete(&models.Todo{}, "id = ?", id).Error
+}
+
+// FindExpired returns up to limit todos that were moved to the trash before deletedBefore
+func (r *todoRepository) FindExpired(deletedBefore time.Time, limit int) ([]models.Todo, error) {
+	var todos []models.Todo
+	err := r.db.Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", deletedBefore).
+		Order("deleted_at ASC").
+		Limit(limit).
+		Find(&todos).Error
+	return todos, err
+}
+
+// LastPosition returns the user's highest position key, or "" when no todo
+// has one. Trashed todos count so a restored todo keeps a distinct key, at
+// least until the next rebalance.
+func (r *todoRepository) LastPosition(userID uuid.UUID) (string, error) {
+	var keys []string
+	err := r.db.Unscoped().Model(&models.Todo{}).
+		Where("todos.user_id = ? AND todos.position IS NOT NULL", userID).
+		Order(positionColumn+" DESC").
+		Limit(1).
+		Pluck("todos.position", &keys).Error
+	if err != nil || len(keys) == 0 {
+		return "", err
+	}
+	return keys[0], nil
+}
+
+// AdjacentPosition returns the position key closest to position on the given
+// side, ignoring the todo excludeID, or "" when there is none
+func (r *todoRepository) AdjacentPosition(userID uuid.UUID, position string, after bool, excludeID uuid.UUID) (string, error) {
+	op, order := "<", " DESC"
+	if after {
+		op, order = ">", " ASC"
+	}
+
+	var keys []string
+	err := r.db.Unscoped().Model(&models.Todo{}).
+		Where("todos.user_id = ? AND todos.id <> ?", userID, excludeID).
+		Where(positionColumn+" "+op+" ?", position).
+		Order(positionColumn+order).
+		Limit(1).
+		Pluck("todos.position", &keys).Error
+	if err != nil || len(keys) == 0 {
+		return "", err
+	}
+	return keys[0], nil
+}
+
+// FindInManualOrder returns the user's live todos in their manual order.
+// Todos without a position come last, newest first.
+func (r *todoRepository) FindInManualOrder(userID uuid.UUID) ([]models.Todo, error) {
+	var todos []models.Todo
+	err := r.db.Preload("Tags").
+		Where("todos.user_id = ?", userID).
+		Order(positionColumn + " ASC NULLS LAST, todos.created_at DESC, todos.id").
+		Find(&todos).Error
+	return todos, err
+}
+
+// SetPositions writes the given position keys in a single statement.
+// Trashed todos are left alone.
+func (r *todoRepository) SetPositions(positions map[uuid.UUID]string, updatedAt time.Time) error {
+	if len(positions) == 0 {
+		return nil
+	}
+
+	rows := make([]string, 0, len(positions))
+	args := make([]interface{}, 0, 2*len(positions)+1)
+	args = append(args, updatedAt)
+	for id, position := range positions {
+		rows = append(rows, "(?::uuid, ?)")
+		args = append(args, id, position)
+	}
+	return r.db.Exec(`UPDATE todos SET position = v.position, updated_at = ?
+		FROM (VALUES `+strings.Join(rows, ", ")+`) AS v(id, position)
+		WHERE todos.id = v.id AND todos.deleted_at IS NULL`, args...).Error
+}
+
+// CountByState returns the number of todos of a list in each board state
+func (r *todoRepository) CountByState(listID uuid.UUID) (map[string]int64, error) {
+	var rows []struct {
+		State string
+		Count int64
+	}
+	err := r.db.Model(&models.Todo{}).
+		Select(stateColumn+" AS state, COUNT(*) AS count").
+		Where("todos.list_id = ?", listID).
+		Group(stateColumn).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.State] = row.Count
+	}
+	return counts, nil
+}
+
+// FindByState returns up to limit todos of a list in a board state, in manual
+// order with unpositioned todos last, newest first
+func (r *todoRepository) FindByState(listID uuid.UUID, state string, limit int) ([]models.Todo, error) {
+	var todos []models.Todo
+	err := r.db.Preload("Tags").
+		Where("todos.list_id = ? AND "+stateColumn+" = ?", listID, state).
+		Order(positionColumn + " ASC NULLS LAST, todos.created_at DESC, todos.id").
+		Limit(limit).
+		Find(&todos).Error
+	if err != nil {
+		return nil, err
+	}
+	return todos, loadBlockers(r.db, todos)
+}
+
+// subtreeIDs is a CTE naming tree the IDs of a todo and all of its subtasks
+// outside the trash
+const subtreeIDs = `WITH RECURSIVE tree AS (
+		SELECT id FROM todos WHERE id = ? AND deleted_at IS NULL
+		UNION
+		SELECT todos.id FROM todos JOIN tree ON todos.parent_id = tree.id WHERE todos.deleted_at IS NULL
+	)`
+
+// effortColumns sum the estimates of the selected todos in the unit of their
+// list, or minutes outside a list
+const effortColumns = "COALESCE(lists.estimate_unit, 'minutes') AS unit, " +
+	"COALESCE(SUM(CASE WHEN todos.status <> 'completed' THEN todos.estimate END), 0) AS remaining, " +
+	"COALESCE(SUM(CASE WHEN todos.status = 'completed' THEN todos.estimate END), 0) AS completed, " +
+	"COUNT(*) AS todos, COUNT(todos.estimate) AS estimated"
+
+// Effort sums the estimates of the user's todos by list or by tag, split by
+// unit
+func (r *todoRepository) Effort(userID uuid.UUID, groupBy string) ([]models.EffortRollup, error) {
+	query := r.db.Model(&models.Todo{}).
+		Joins("LEFT JOIN lists ON lists.id = todos.list_id AND lists.deleted_at IS NULL").
+		Where("todos.user_id = ?", userID)
+
+	switch groupBy {
+	case models.EffortByList:
+		query = query.Select("COALESCE(lists.id::text, '') AS key, COALESCE(lists.name, '') AS label, " + effortColumns)
+	case models.EffortByTag:
+		query = query.
+			Joins("LEFT JOIN todo_tags ON todo_tags.todo_id = todos.id").
+			Joins("LEFT JOIN tags ON tags.id = todo_tags.tag_id").
+			Select("COALESCE(tags.name, '') AS key, COALESCE(tags.name, '') AS label, " + effortColumns)
+	default:
+		return nil, fmt.Errorf("unknown effort grouping %q", groupBy)
+	}
+
+	var rows []models.EffortRollup
+	err := query.Group("1, 2, 3").Order("2, 3").Scan(&rows).Error
+	return rows, err
+}
+
+// SubtreeEffort sums the estimates of a todo and all of its subtasks, split by
+// unit. The rows are keyed by the todo.
+func (r *todoRepository) SubtreeEffort(rootID uuid.UUID) ([]models.EffortRollup, error) {
+	var rows []models.EffortRollup
+	err := r.db.Raw(subtreeIDs+`
+		SELECT ?::text AS key, '' AS label, `+effortColumns+`
+		FROM todos
+		JOIN tree ON tree.id = todos.id
+		LEFT JOIN lists ON lists.id = todos.list_id AND lists.deleted_at IS NULL
+		GROUP BY 3
+		ORDER BY 3`, rootID, rootID.String()).
+		Scan(&rows).Error
+	return rows, err
+}
+
+// FindAllWithDeleted returns all of a user's todos including the trashed ones
+func (r *todoRepository) FindAllWithDeleted(userID uuid.UUID) ([]models.Todo, error) {
+	var todos []models.Todo
+	err := r.db.Unscoped().Preload("Tags").
+		Where("user_id = ?", userID).
+		Find(&todos).Error
+	return todos, err
+}
+
+// FindSubtree returns a todo and all of its subtasks
+func (r *todoRepository) FindSubtree(rootID uuid.UUID) ([]models.Todo, error) {
+	var todos []models.Todo
+	err := r.db.Preload("Tags").
+		Where("todos.id IN ("+subtreeIDs+" SELECT id FROM tree)", rootID).
+		Find(&todos).Error
+	return todos, err
+}
+
+// FindByListID returns all todos of a list
+func (r *todoRepository) FindByListID(listID uuid.UUID) ([]models.Todo, error) {
+	var todos []models.Todo
+	err := r.db.Preload("Tags").
+		Where("todos.list_id = ?", listID).
+		Find(&todos).Error
+	return todos, err
+}
+
+// FindDue returns the user's todos with a due date, soonest first, optionally
+// only those in listID
+func (r *todoRepository) FindDue(userID uuid.UUID, listID *uuid.UUID, includeCompleted bool) ([]models.Todo, error) {
+	query := r.db.Preload("Tags").
+		Where("todos.user_id = ? AND todos.due_date IS NOT NULL", userID)
+	if listID != nil {
+		query = query.Where("todos.list_id = ?", *listID)
+	}
+	if !includeCompleted {
+		query = query.Where("todos.status <> ?", models.TodoStatusCompleted)
+	}
+
+	var todos []models.Todo
+	err := query.Order("todos.due_date ASC, todos.id ASC").Find(&todos).Error
+	return todos, err
+}
+
+// FindInCollection returns the todos of a list, or the user's todos without a
+// list when listID is nil
+func (r *todoRepository) FindInCollection(userID uuid.UUID, listID *uuid.UUID) ([]models.Todo, error) {
+	var todos []models.Todo
+	err := inCollection(r.db.Preload("Tags").Preload("Parent"), userID, listID).
+		Order("todos.created_at ASC, todos.id ASC").
+		Find(&todos).Error
+	return todos, err
+}
+
+// GetByCalDAVName returns a todo by its CalDAV resource name, which is
+// "<id>.ics" for todos not created over CalDAV
+func (r *todoRepository) GetByCalDAVName(userID uuid.UUID, name string) (*models.Todo, error) {
+	return getByCalDAVName(r.db, userID, name)
+}
+
+// LockByCalDAVName is GetByCalDAVName holding a row lock on the todo until
+// the end of the transaction
+func (r *todoRepository) LockByCalDAVName(userID uuid.UUID, name string) (*models.Todo, error) {
+	return getByCalDAVName(r.db.Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "todos"}}), userID, name)
+}
+
+func getByCalDAVName(db *gorm.DB, userID uuid.UUID, name string) (*models.Todo, error) {
+	var todo models.Todo
+	err := db.Preload("Tags").Preload("Parent").
+		Where("todos.user_id = ?", userID).
+		Where("todos.caldav_name = ? OR (todos.caldav_name IS NULL AND todos.id::text || '.ics' = ?)", name, name).
+		First(&todo).Error
+	if err != nil {
+		return nil, err
+	}
+	return &todo, nil
+}
+
+// GetByICalUID returns a todo by its iCalendar UID, which is its ID for todos
+// not created over CalDAV
+func (r *todoRepository) GetByICalUID(userID uuid.UUID, uid string) (*models.Todo, error) {
+	var todo models.Todo
+	err := r.db.
+		Where("todos.user_id = ?", userID).
+		Where("todos.ical_uid = ? OR (todos.ical_uid IS NULL AND todos.id::text = ?)", uid, uid).
+		First(&todo).Error
+	if err != nil {
+		return nil, err
+	}
+	return &todo, nil
+}
+
+// CollectionTag returns a value that changes whenever a todo in the
+// collection is created, changed, moved away or deleted
+func (r *todoRepository) CollectionTag(userID uuid.UUID, listID *uuid.UUID) (string, error) {
+	var row struct {
+		Live    int64
+		Changed *time.Time
+	}
+	err := inCollection(r.db.Unscoped().Model(&models.Todo{}), userID, listID).
+		Select("COUNT(*) FILTER (WHERE todos.deleted_at IS NULL) AS live, " +
+			"MAX(GREATEST(todos.updated_at, COALESCE(todos.deleted_at, todos.updated_at))) AS changed").
+		Scan(&row).Error
+	if err != nil {
+		return "", err
+	}
+	changed := int64(0)
+	if row.Changed != nil {
+		changed = row.Changed.UnixMicro()
+	}
+	return fmt.Sprintf("%d-%d", changed, row.Live), nil
+}
+
+func inCollection(query *gorm.DB, userID uuid.UUID, listID *uuid.UUID) *gorm.DB {
+	query = query.Where("todos.user_id = ?", userID)
+	if listID == nil {
+		return query.Where("todos.list_id IS NULL")
+	}
+	return query.Where("todos.list_id = ?", *listID)
+}
+
+// applyTodoFilter adds the conditions of filter to query; now anchors "overdue"
+func applyTodoFilter(query *gorm.DB, filter *models.TodoFilter, now time.Time) *gorm.DB {
+	if len(filter.Statuses) > 0 {
+		query = query.Where("todos.status IN ?", filter.Statuses)
+	}
+	if filter.PriorityMin != nil {
+		query = query.Where("todos.priority >= ?", *filter.PriorityMin)
+	}
+	if filter.PriorityMax != nil {
+		query = query.Where("todos.priority <= ?", *filter.PriorityMax)
+	}
+	if filter.DueBefore != nil {
+		query = query.Where("todos.due_date < ?", *filter.DueBefore)
+	}
+	if filter.DueAfter != nil {
+		query = query.Where("todos.due_date >= ?", *filter.DueAfter)
+	}
+	if filter.HasDueDate != nil {
+		if *filter.HasDueDate {
+			query = query.Where("todos.due_date IS NOT NULL")
+		} else {
+			query = query.Where("todos.due_date IS NULL")
+		}
+	}
+	if filter.Overdue != nil {
+		overdue := "todos.due_date < ? AND todos.status <> ?"
+		if *filter.Overdue {
+			query = query.Where(overdue, now, models.TodoStatusCompleted)
+		} else {
+			query = query.Where("NOT (COALESCE("+overdue+", FALSE))", now, models.TodoStatusCompleted)
+		}
+	}
+	if filter.CreatedBefore != nil {
+		query = query.Where("todos.created_at < ?", *filter.CreatedBefore)
+	}
+	if filter.CreatedAfter != nil {
+		query = query.Where("todos.created_at >= ?", *filter.CreatedAfter)
+	}
+	if filter.UpdatedBefore != nil {
+		query = query.Where("todos.updated_at < ?", *filter.UpdatedBefore)
+	}
+	if filter.UpdatedAfter != nil {
+		query = query.Where("todos.updated_at >= ?", *filter.UpdatedAfter)
+	}
+	if len(filter.ListIDs) > 0 {
+		query = query.Where("todos.list_id IN ?", filter.ListIDs)
+	}
+	if filter.WithoutList {
+		query = query.Where("todos.list_id IS NULL")
+	}
+	if len(filter.Tags) > 0 {
+		query = query.Where(`EXISTS (SELECT 1 FROM todo_tags JOIN tags ON tags.id = todo_tags.tag_id
+			WHERE todo_tags.todo_id = todos.id AND tags.name IN ?)`, filter.Tags)
+	}
+	if filter.Query != nil {
+		loc := filter.Location
+		if loc == nil {
+			loc = time.UTC
+		}
+		condition, args := compileTodoQuery(filter.Query, now.In(loc))
+		query = query.Where(condition, args...)
+	}
+	return query
+}
+
+// orderKey is one column of the walk order of a listing
+type orderKey struct {
+	column     string
+	desc       bool
+	nullable   bool
+	nullsFirst bool
+}
+
+// todoOrderKeys expands sort keys into the walk order, with the id as the
+// final tie-breaker so the order is total. Todos without a due date or position
+// sort last; walking backward reverses every key, including where NULLs go.
+func todoOrderKeys(sorts []models.TodoSort, backward bool) []orderKey {
+	if len(sorts) == 0 {
+		sorts = models.DefaultTodoSort
+	}
+
+	keys := make([]orderKey, 0, len(sorts)+1)
+	for _, s := range sorts {
+		column := "todos." + models.TodoSortFields[s.Field]
+		if s.Field == "position" {
+			column = positionColumn
+		}
+		keys = append(keys, orderKey{
+			column:   column,
+			desc:     s.Desc,
+			nullable: models.TodoSortNullable[s.Field],
+		})
+	}
+	keys = append(keys, orderKey{column: "todos.id"})
+
+	if backward {
+		for i := range keys {
+			keys[i].desc = !keys[i].desc
+			keys[i].nullsFirst = true
+		}
+	}
+	return keys
+}
+
+// todoOrderClause renders order keys as an ORDER BY list
+func todoOrderClause(keys []orderKey) string {
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k.column + " ASC"
+		if k.desc {
+			parts[i] = k.column + " DESC"
+		}
+		if k.nullable {
+			if k.nullsFirst {
+				parts[i] += " NULLS FIRST"
+			} else {
+				parts[i] += " NULLS LAST"
+			}
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// keysetCondition builds the condition selecting rows strictly after the
+// position given by values (one per key, nil for NULL) in the walk order:
+// (k1 after v1) OR (k1 = v1 AND k2 after v2) OR ...
+func keysetCondition(keys []orderKey, values []interface{}) (string, []interface{}) {
+	var terms []string
+	var args []interface{}
+
+	var equal []string
+	var equalArgs []interface{}
+	for i, k := range keys {
+		after, afterArgs := keyAfter(k, values[i])
+		if after != "" {
+			term := append(append([]string{}, equal...), after)
+			terms = append(terms, "("+strings.Join(term, " AND ")+")")
+			args = append(append(args, equalArgs...), afterArgs...)
+		}
+
+		if values[i] == nil {
+			equal = append(equal, k.column+" IS NULL")
+		} else {
+			equal = append(equal, k.column+" = ?")
+			equalArgs = append(equalArgs, values[i])
+		}
+	}
+
+	if len(terms) == 0 {
+		return "FALSE", nil
+	}
+	return "(" + strings.Join(terms, " OR ") + ")", args
+}
+
+// keyAfter returns the condition for a column value coming strictly after
+// value in the walk order, or "" when nothing can
+func keyAfter(k orderKey, value interface{}) (string, []interface{}) {
+	if value == nil {
+		if k.nullsFirst {
+			return k.column + " IS NOT NULL", nil
+		}
+		return "", nil
+	}
+
+	op := ">"
+	if k.desc {
+		op = "<"
+	}
+	if k.nullable && !k.nullsFirst {
+		return fmt.Sprintf("(%s %s ? OR %s IS NULL)", k.column, op, k.column), []interface{}{value}
+	}
+	return fmt.Sprintf("%s %s ?", k.column, op), []interface{}{value}
+}
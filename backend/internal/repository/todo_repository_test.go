@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"testing"
+	"todo-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTodoOrderClause(t *testing.T) {
+	sorts := []models.TodoSort{{Field: "priority", Desc: true}, {Field: "due_date"}}
+
+	assert.Equal(t, "todos.priority DESC, todos.due_date ASC NULLS LAST, todos.id ASC",
+		todoOrderClause(todoOrderKeys(sorts, false)))
+	assert.Equal(t, "todos.priority ASC, todos.due_date DESC NULLS FIRST, todos.id DESC",
+		todoOrderClause(todoOrderKeys(sorts, true)))
+	assert.Equal(t, "todos.created_at DESC, todos.id ASC",
+		todoOrderClause(todoOrderKeys(nil, false)))
+	assert.Equal(t, `todos.position COLLATE "C" ASC NULLS LAST, todos.id ASC`,
+		todoOrderClause(todoOrderKeys([]models.TodoSort{{Field: "position"}}, false)))
+}
+
+func TestKeysetCondition(t *testing.T) {
+	sorts := []models.TodoSort{{Field: "priority", Desc: true}, {Field: "due_date"}}
+
+	tests := []struct {
+		name     string
+		backward bool
+		values   []interface{}
+		want     string
+		args     int
+	}{
+		{
+			name:   "forward from a dated row",
+			values: []interface{}{3, "2024-01-01", "id"},
+			want: "((todos.priority < ?) OR (todos.priority = ? AND (todos.due_date > ? OR todos.due_date IS NULL)) OR " +
+				"(todos.priority = ? AND todos.due_date = ? AND todos.id > ?))",
+			args: 6,
+		},
+		{
+			name:   "forward from an undated row",
+			values: []interface{}{3, nil, "id"},
+			want:   "((todos.priority < ?) OR (todos.priority = ? AND todos.due_date IS NULL AND todos.id > ?))",
+			args:   3,
+		},
+		{
+			name:     "backward from an undated row",
+			backward: true,
+			values:   []interface{}{3, nil, "id"},
+			want: "((todos.priority > ?) OR (todos.priority = ? AND todos.due_date IS NOT NULL) OR " +
+				"(todos.priority = ? AND todos.due_date IS NULL AND todos.id < ?))",
+			args: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			condition, args := keysetCondition(todoOrderKeys(sorts, tt.backward), tt.values)
+			assert.Equal(t, tt.want, condition)
+			assert.Len(t, args, tt.args)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package repository
+
+import "gorm.io/gorm"
+
+// Repositories are repositories sharing one database transaction
+type Repositories struct {
+	Todos      TodoRepository
+	Tags       TagRepository
+	Lists      ListRepository
+	Reminders  ReminderRepository
+	Changes    TodoChangeRepository
+	Operations OperationRepository
+	Workflows  WorkflowRepository
+
+	// Tx runs nested work in a savepoint of the transaction
+	Tx UnitOfWork
+}
+
+// UnitOfWork runs a function with repositories bound to a transaction. The
+// transaction commits when fn returns nil and rolls back otherwise; calling
+// Do on Repositories.Tx nests a savepoint.
+type UnitOfWork interface {
+	Do(fn func(repos Repositories) error) error
+}
+
+type unitOfWork struct {
+	db *gorm.DB
+}
+
+func NewUnitOfWork(db *gorm.DB) UnitOfWork {
+	return &unitOfWork{db: db}
+}
+
+func (u *unitOfWork) Do(fn func(repos Repositories) error) error {
+	return u.db.Transaction(func(tx *gorm.DB) error {
+		return fn(Repositories{
+			Todos:      NewTodoRepository(tx),
+			Tags:       NewTagRepository(tx),
+			Lists:      NewListRepository(tx),
+			Reminders:  NewReminderRepository(tx),
+			Changes:    NewTodoChangeRepository(tx),
+			Operations: NewOperationRepository(tx),
+			Workflows:  NewWorkflowRepository(tx),
+			Tx:         &unitOfWork{db: tx},
+		})
+	})
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"strings"
+	"time"
 	"todo-backend/internal/models"
 
 	"github.com/google/uuid"
@@ -12,9 +14,14 @@ type UserRepository interface {
 	GetByID(id uuid.UUID) (*models.User, error)
 	GetByEmail(email string) (*models.User, error)
 	GetByAppleID(appleID string) (*models.User, error)
+	GetByCalendarToken(token string) (*models.User, error)
 	Update(user *models.User) error
 	Delete(id uuid.UUID) error
 	List(offset, limit int) ([]models.User, int64, error)
+
+	// Account deletion
+	FindDueForDeletion(scheduledBefore time.Time, limit int) ([]models.User, error)
+	Purge(id uuid.UUID) error
 }
 
 type userRepository struct {
@@ -56,6 +63,15 @@ func (r *userRepository) GetByAppleID(appleID string) (*models.User, error) {
 	return &user, nil
 }
 
+func (r *userRepository) GetByCalendarToken(token string) (*models.User, error) {
+	var user models.User
+	err := r.db.Where("calendar_token = ?", token).First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
 func (r *userRepository) Update(user *models.User) error {
 	return r.db.Save(user).Error
 }
@@ -76,4 +92,43 @@ func (r *userRepository) List(offset, limit int) ([]models.User, int64, error) {
 	// Get paginated records
 	err := r.db.Offset(offset).Limit(limit).Find(&users).Error
 	return users, total, err
-} 
\ No newline at end of file
+}
+
+// FindDueForDeletion returns up to limit users whose deletion was scheduled
+// before scheduledBefore
+func (r *userRepository) FindDueForDeletion(scheduledBefore time.Time, limit int) ([]models.User, error) {
+	var users []models.User
+	err := r.db.Where("deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at < ?", scheduledBefore).
+		Order("deletion_scheduled_at ASC").
+		Limit(limit).
+		Find(&users).Error
+	return users, err
+}
+
+// Purge deletes a user for good in one transaction. The database cascades the
+// delete to everything the user owns; comments of other users that mention
+// the user are kept with the mention replaced by DeletedUserHandle.
+func (r *userRepository) Purge(id uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var mentions []models.CommentMention
+		if err := tx.Where("user_id = ?", id).Find(&mentions).Error; err != nil {
+			return err
+		}
+		for _, mention := range mentions {
+			var comment models.Comment
+			err := tx.Unscoped().Where("id = ? AND author_id <> ?", mention.CommentID, id).Limit(1).Find(&comment).Error
+			if err != nil {
+				return err
+			}
+			if comment.ID == uuid.Nil || mention.Handle == "" {
+				continue
+			}
+			body := strings.ReplaceAll(comment.Body, "@"+mention.Handle, "@"+models.DeletedUserHandle)
+			if err := tx.Unscoped().Model(&comment).UpdateColumn("body", body).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Unscoped().Delete(&models.User{}, "id = ?", id).Error
+	})
+}
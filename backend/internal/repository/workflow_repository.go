@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type WorkflowRepository interface {
+	GetByListID(listID uuid.UUID) (models.Workflow, error)
+	Replace(listID uuid.UUID, states models.Workflow) error
+}
+
+type workflowRepository struct {
+	db *gorm.DB
+}
+
+func NewWorkflowRepository(db *gorm.DB) WorkflowRepository {
+	return &workflowRepository{db: db}
+}
+
+// GetByListID returns a list's own workflow states in board order; empty when
+// the list uses the default workflow
+func (r *workflowRepository) GetByListID(listID uuid.UUID) (models.Workflow, error) {
+	var states models.Workflow
+	err := r.db.Where("list_id = ?", listID).
+		Order("position ASC").
+		Find(&states).Error
+	return states, err
+}
+
+// Replace swaps a list's workflow for states, or for the default workflow
+// when states is empty, and moves the list's todos along: todos keep their
+// state if it still exists, taking on its category's status, and otherwise go
+// to the first state of their status' category. Trashed todos are included so
+// they come back in a valid state.
+func (r *workflowRepository) Replace(listID uuid.UUID, states models.Workflow) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("list_id = ?", listID).Delete(&models.WorkflowState{}).Error; err != nil {
+			return err
+		}
+		todos := func() *gorm.DB {
+			return tx.Unscoped().Model(&models.Todo{}).Where("list_id = ?", listID)
+		}
+
+		if len(states) == 0 {
+			return todos().UpdateColumn("state", nil).Error
+		}
+		if err := tx.Create(&states).Error; err != nil {
+			return err
+		}
+
+		keys := make([]string, len(states))
+		for i, state := range states {
+			keys[i] = state.Key
+			err := todos().Where("state = ?", state.Key).
+				UpdateColumn("status", state.Category.Status()).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, category := range []models.StateCategory{models.StateCategoryTodo, models.StateCategoryDoing, models.StateCategoryDone} {
+			first := states.First(category)
+			if first == nil {
+				// No state for the category: its todos go to the first state
+				first = &states[0]
+			}
+			err := todos().
+				Where("(state IS NULL OR state NOT IN ?) AND status = ?", keys, category.Status()).
+				UpdateColumns(map[string]interface{}{"state": first.Key, "status": first.Category.Status()}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
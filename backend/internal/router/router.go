@@ -1,20 +1,28 @@
 package router
 
 import (
+	"fmt"
 	"todo-backend/internal/config"
 	"todo-backend/internal/handlers"
 	"todo-backend/internal/middleware"
+	"todo-backend/internal/notifier"
 	"todo-backend/internal/repository"
+	"todo-backend/internal/search"
 	"todo-backend/internal/service"
+	"todo-backend/internal/storage"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"gorm.io/gorm"
 )
 
-func SetupRouter(db *gorm.DB, cfg *config.Config) *gin.Engine {
+// SetupRouter wires the API's handlers. The blob store and the account
+// service are shared with the background purgers, so they are built by the
+// caller.
+func SetupRouter(db *gorm.DB, cfg *config.Config, blobStore storage.BlobStore, accountService service.AccountService, worker service.BackgroundQueue) (*gin.Engine, error) {
 	// Create Gin router
 	r := gin.Default()
 
@@ -41,17 +49,96 @@ func SetupRouter(db *gorm.DB, cfg *config.Config) *gin.Engine {
 	// Initialize repositories
 	todoRepo := repository.NewTodoRepository(db)
 	userRepo := repository.NewUserRepository(db)
+	reminderRepo := repository.NewReminderRepository(db)
+	commentRepo := repository.NewCommentRepository(db)
+	attachmentRepo := repository.NewAttachmentRepository(db)
+	dependencyRepo := repository.NewDependencyRepository(db)
+	listRepo := repository.NewListRepository(db)
+	tagRepo := repository.NewTagRepository(db)
+	savedViewRepo := repository.NewSavedViewRepository(db)
+	todoChangeRepo := repository.NewTodoChangeRepository(db)
+	operationRepo := repository.NewOperationRepository(db)
+	workflowRepo := repository.NewWorkflowRepository(db)
+	timeEntryRepo := repository.NewTimeEntryRepository(db)
+	templateRepo := repository.NewTemplateRepository(db)
+	appPasswordRepo := repository.NewAppPasswordRepository(db)
+	importJobRepo := repository.NewImportJobRepository(db)
+	unitOfWork := repository.NewUnitOfWork(db)
 
 	// Initialize services
-	todoService := service.NewTodoService(todoRepo)
+	attachmentService := service.NewAttachmentService(attachmentRepo, todoRepo, blobStore, cfg)
+	todoService := service.NewTodoService(todoRepo, reminderRepo, listRepo, tagRepo, userRepo, todoChangeRepo, operationRepo, workflowRepo, attachmentService, unitOfWork)
+	smartViewService := service.NewSmartViewService(todoRepo, userRepo)
+	listService := service.NewListService(listRepo, tagRepo, workflowRepo, todoRepo)
+	savedViewService := service.NewSavedViewService(savedViewRepo, todoService)
+	searchService := service.NewSearchService(newSearcher(db, cfg, todoRepo))
+	reminderService := service.NewReminderService(reminderRepo, todoRepo, cfg)
+	commentService := service.NewCommentService(commentRepo, todoRepo, userRepo)
+	dependencyService := service.NewDependencyService(dependencyRepo, todoRepo)
+	timeEntryService := service.NewTimeEntryService(timeEntryRepo, todoRepo, userRepo)
+	effortService := service.NewEffortService(todoRepo, listRepo, todoChangeRepo, userRepo)
+	templateService := service.NewTemplateService(templateRepo, todoRepo, listRepo, userRepo, todoService)
+	quickAddService := service.NewQuickAddService(listRepo, userRepo)
+	calendarService := service.NewCalendarService(userRepo, todoRepo, listRepo, cfg)
+	appPasswordService := service.NewAppPasswordService(appPasswordRepo)
+	caldavService := service.NewCalDAVService(todoRepo, listRepo, userRepo, todoService)
+	importService := service.NewImportService(importJobRepo, listService, userRepo, todoService, worker, cfg.BackgroundJobTimeout)
+	exportService := service.NewExportService(todoRepo, listRepo, workflowRepo, userRepo)
 	authService, err := service.NewAuthService(userRepo, cfg)
 	if err != nil {
-		panic("Failed to initialize auth service: " + err.Error())
+		return nil, fmt.Errorf("failed to initialize auth service: %w", err)
 	}
 
 	// Initialize handlers
 	todoHandler := handlers.NewTodoHandler(todoService)
 	authHandler := handlers.NewAuthHandler(authService)
+	reminderHandler := handlers.NewReminderHandler(reminderService)
+	commentHandler := handlers.NewCommentHandler(commentService)
+	attachmentHandler := handlers.NewAttachmentHandler(attachmentService, cfg.AttachmentMaxSize)
+	dependencyHandler := handlers.NewDependencyHandler(dependencyService)
+	listHandler := handlers.NewListHandler(listService)
+	searchHandler := handlers.NewSearchHandler(searchService)
+	savedViewHandler := handlers.NewSavedViewHandler(savedViewService)
+	smartViewHandler := handlers.NewSmartViewHandler(smartViewService)
+	undoHandler := handlers.NewUndoHandler(todoService)
+	timeEntryHandler := handlers.NewTimeEntryHandler(timeEntryService)
+	effortHandler := handlers.NewEffortHandler(effortService)
+	templateHandler := handlers.NewTemplateHandler(templateService)
+	quickAddHandler := handlers.NewQuickAddHandler(quickAddService, todoService)
+	calendarHandler := handlers.NewCalendarHandler(calendarService)
+	appPasswordHandler := handlers.NewAppPasswordHandler(appPasswordService)
+	caldavHandler := handlers.NewCalDAVHandler(caldavService)
+	importHandler := handlers.NewImportHandler(importService)
+	exportHandler := handlers.NewExportHandler(exportService)
+	accountHandler := handlers.NewAccountHandler(accountService)
+
+	// Calendar feeds are authorized by the secret token in their URL
+	r.GET("/calendar/:file", calendarHandler.GetCalendar)
+
+	// CalDAV server; clients sign in with an app password
+	r.GET("/.well-known/caldav", caldavHandler.WellKnown)
+	r.Handle("PROPFIND", "/.well-known/caldav", caldavHandler.WellKnown)
+	dav := r.Group(handlers.CalDAVPrefix)
+	{
+		dav.OPTIONS("/*path", caldavHandler.Options)
+
+		authenticated := dav.Group("")
+		authenticated.Use(middleware.AppPasswordMiddleware("Todos", func(email, password string) (uuid.UUID, error) {
+			user, err := appPasswordService.Authenticate(email, password)
+			if err != nil {
+				return uuid.Nil, err
+			}
+			return user.ID, nil
+		}))
+		{
+			authenticated.Handle("PROPFIND", "/*path", caldavHandler.Propfind)
+			authenticated.Handle("REPORT", "/*path", caldavHandler.Report)
+			authenticated.GET("/*path", caldavHandler.Get)
+			authenticated.HEAD("/*path", caldavHandler.Get)
+			authenticated.PUT("/*path", caldavHandler.Put)
+			authenticated.DELETE("/*path", caldavHandler.Delete)
+		}
+	}
 
 	// API v1 routes
 	v1 := r.Group("/api/v1")
@@ -70,7 +157,13 @@ func SetupRouter(db *gorm.DB, cfg *config.Config) *gin.Engine {
 			// Traditional auth routes (for future use)
 			auth.POST("/register", authHandler.RegisterUser)
 			auth.POST("/login", authHandler.LoginUser)
+
+			// Signed account export downloads carry their own authorization
+			auth.GET("/account/export/:id/download", accountHandler.DownloadExport)
 		}
+
+		// Signed attachment downloads carry their own authorization
+		v1.GET("/attachments/:attachment_id/download", attachmentHandler.DownloadAttachment)
 		
 		// Protected routes (authentication required)
 		protected := v1.Group("")
@@ -80,19 +173,193 @@ func SetupRouter(db *gorm.DB, cfg *config.Config) *gin.Engine {
 			auth := protected.Group("/auth")
 			{
 				auth.GET("/user/profile", authHandler.GetUserProfile)
+				auth.PUT("/user/preferences", authHandler.UpdatePreferences)
+
+				// App passwords for CalDAV and other clients
+				auth.GET("/app-passwords", appPasswordHandler.GetAppPasswords)
+				auth.POST("/app-passwords", appPasswordHandler.CreateAppPassword)
+				auth.DELETE("/app-passwords/:id", appPasswordHandler.DeleteAppPassword)
+
+				// Account data export and deletion
+				auth.POST("/account/export", accountHandler.RequestExport)
+				auth.GET("/account/export/:id", accountHandler.GetExport)
+				auth.DELETE("/account", accountHandler.DeleteAccount)
+				auth.POST("/account/restore", accountHandler.CancelDeletion)
 			}
 			
 			// Todo routes
 			todos := protected.Group("/todos")
 			{
 				todos.POST("", todoHandler.CreateTodo)
+				todos.POST("/quick", quickAddHandler.QuickAddTodo)
 				todos.GET("", todoHandler.GetTodos)
+				todos.GET("/search", searchHandler.SearchTodos)
+				todos.POST("/bulk", todoHandler.BulkTodos)
+				todos.GET("/trash", todoHandler.GetTrash)
+
+				// Built-in smart views
+				todos.GET("/views/today", smartViewHandler.GetToday)
+				todos.GET("/views/upcoming", smartViewHandler.GetUpcoming)
+				todos.GET("/views/overdue", smartViewHandler.GetOverdue)
+				todos.GET("/views/someday", smartViewHandler.GetSomeday)
+
 				todos.GET("/:id", todoHandler.GetTodo)
 				todos.PUT("/:id", todoHandler.UpdateTodo)
 				todos.DELETE("/:id", todoHandler.DeleteTodo)
+				todos.POST("/:id/skip", todoHandler.SkipOccurrence)
+				todos.POST("/:id/end-series", todoHandler.EndSeries)
+				todos.POST("/:id/move", todoHandler.MoveTodo)
+				todos.POST("/:id/restore", todoHandler.RestoreTodo)
+				todos.DELETE("/:id/purge", todoHandler.PurgeTodo)
+				todos.GET("/:id/history", todoHandler.GetTodoHistory)
+				todos.GET("/:id/effort", effortHandler.GetTodoEffort)
+				todos.POST("/:id/template", templateHandler.SaveTodoAsTemplate)
+
+				// Reminders
+				todos.POST("/:id/reminders", reminderHandler.CreateReminder)
+				todos.GET("/:id/reminders", reminderHandler.GetReminders)
+				todos.DELETE("/:id/reminders/:reminder_id", reminderHandler.DeleteReminder)
+
+				// Comments
+				todos.GET("/:id/comments", commentHandler.GetComments)
+				todos.POST("/:id/comments", commentHandler.CreateComment)
+				todos.PUT("/:id/comments/:comment_id", commentHandler.UpdateComment)
+				todos.DELETE("/:id/comments/:comment_id", commentHandler.DeleteComment)
+
+				// Attachments
+				todos.POST("/:id/attachments", attachmentHandler.UploadAttachment)
+				todos.GET("/:id/attachments", attachmentHandler.GetAttachments)
+				todos.GET("/:id/attachments/:attachment_id/url", attachmentHandler.GetAttachmentURL)
+				todos.DELETE("/:id/attachments/:attachment_id", attachmentHandler.DeleteAttachment)
+
+				// Dependencies
+				todos.GET("/:id/dependencies", dependencyHandler.GetDependencies)
+				todos.POST("/:id/dependencies", dependencyHandler.AddDependency)
+				todos.DELETE("/:id/dependencies/:depends_on_id", dependencyHandler.RemoveDependency)
+
+				// Time tracking
+				todos.POST("/:id/timer/start", timeEntryHandler.StartTimer)
+				todos.POST("/:id/timer/stop", timeEntryHandler.StopTimer)
+				todos.GET("/:id/time-entries", timeEntryHandler.GetTimeEntries)
+				todos.POST("/:id/time-entries", timeEntryHandler.CreateTimeEntry)
+				todos.PUT("/:id/time-entries/:entry_id", timeEntryHandler.UpdateTimeEntry)
+				todos.DELETE("/:id/time-entries/:entry_id", timeEntryHandler.DeleteTimeEntry)
 			}
+
+			// List routes
+			lists := protected.Group("/lists")
+			{
+				lists.POST("", listHandler.CreateList)
+				lists.GET("", listHandler.GetLists)
+				lists.PUT("/:id", listHandler.UpdateList)
+				lists.DELETE("/:id", listHandler.DeleteList)
+				lists.GET("/:id/workflow", listHandler.GetWorkflow)
+				lists.PUT("/:id/workflow", listHandler.SetWorkflow)
+				lists.DELETE("/:id/workflow", listHandler.ResetWorkflow)
+				lists.GET("/:id/board", listHandler.GetBoard)
+				lists.POST("/:id/template", templateHandler.SaveListAsTemplate)
+			}
+
+			protected.GET("/tags", listHandler.GetTags)
+
+			// Saved view (smart list) routes
+			views := protected.Group("/views")
+			{
+				views.POST("", savedViewHandler.CreateView)
+				views.GET("", savedViewHandler.GetViews)
+				views.GET("/:id", savedViewHandler.GetView)
+				views.PUT("/:id", savedViewHandler.UpdateView)
+				views.DELETE("/:id", savedViewHandler.DeleteView)
+				views.GET("/:id/todos", savedViewHandler.GetViewTodos)
+			}
+
+			// Template routes
+			templates := protected.Group("/templates")
+			{
+				templates.POST("", templateHandler.CreateTemplate)
+				templates.GET("", templateHandler.GetTemplates)
+				templates.GET("/:id", templateHandler.GetTemplate)
+				templates.PUT("/:id", templateHandler.UpdateTemplate)
+				templates.DELETE("/:id", templateHandler.DeleteTemplate)
+				templates.POST("/:id/instantiate", templateHandler.InstantiateTemplate)
+			}
+
+			// Operation log and undo
+			protected.GET("/operations", undoHandler.GetOperations)
+			protected.POST("/undo", undoHandler.Undo)
+			protected.POST("/redo", undoHandler.Redo)
+
+			// Time tracking
+			protected.GET("/timer", timeEntryHandler.GetRunningTimer)
+			protected.GET("/reports/time", timeEntryHandler.GetTimeReport)
+
+			// Estimates
+			protected.GET("/reports/effort", effortHandler.GetEffortReport)
+			protected.GET("/reports/burndown", effortHandler.GetBurndown)
+
+			// Calendar feed
+			calendar := protected.Group("/calendar")
+			{
+				calendar.GET("/feed", calendarHandler.GetCalendarFeed)
+				calendar.POST("/feed/regenerate", calendarHandler.RegenerateCalendarFeed)
+				calendar.DELETE("/feed", calendarHandler.DisableCalendarFeed)
+			}
+
+			// Import and export
+			imports := protected.Group("/import")
+			{
+				imports.POST("", importHandler.Import)
+				imports.GET("/:id", importHandler.GetImportJob)
+			}
+			protected.GET("/export", exportHandler.Export)
 		}
 	}
 
-	return r
-} 
\ No newline at end of file
+	return r, nil
+}
+
+// NewBlobStore creates the attachment blob store selected by BLOB_STORE
+func NewBlobStore(cfg *config.Config) (storage.BlobStore, error) {
+	if cfg.BlobStore == "s3" {
+		return storage.NewS3Store(storage.S3Config{
+			Endpoint:     cfg.S3Endpoint,
+			Region:       cfg.S3Region,
+			Bucket:       cfg.S3Bucket,
+			AccessKey:    cfg.S3AccessKey,
+			SecretKey:    cfg.S3SecretKey,
+			UsePathStyle: cfg.S3UsePathStyle,
+		})
+	}
+	return storage.NewLocalStore(cfg.BlobLocalDir)
+}
+
+// NewAccountService creates the service behind account export and deletion
+func NewAccountService(db *gorm.DB, cfg *config.Config, blobStore storage.BlobStore, notifiers notifier.Registry, worker service.BackgroundQueue) (service.AccountService, error) {
+	todoRepo := repository.NewTodoRepository(db)
+	userRepo := repository.NewUserRepository(db)
+	authService, err := service.NewAuthService(userRepo, cfg)
+	if err != nil {
+		return nil, err
+	}
+	exportService := service.NewExportService(todoRepo, repository.NewListRepository(db), repository.NewWorkflowRepository(db), userRepo)
+	return service.NewAccountService(
+		userRepo,
+		repository.NewAccountExportRepository(db),
+		repository.NewCommentRepository(db),
+		repository.NewAttachmentRepository(db),
+		repository.NewAppPasswordRepository(db),
+		exportService,
+		authService,
+		blobStore,
+		notifiers,
+		worker,
+		cfg,
+	), nil
+}
+
+func newSearcher(db *gorm.DB, cfg *config.Config, todoRepo repository.TodoRepository) search.Searcher {
+	if cfg.SearchBackend == "memory" {
+		return search.NewMemorySearcher(todoRepo)
+	}
+	return search.NewPostgresSearcher(db)
+}
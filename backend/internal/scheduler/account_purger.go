@@ -0,0 +1,159 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// AccountStore finds and purges accounts whose deletion grace period is over
+// and account exports that have expired, and gives up on exports that stopped
+// making progress
+type AccountStore interface {
+	FindDueForDeletion(scheduledBefore time.Time, limit int) ([]models.User, error)
+	PurgeAccount(ctx context.Context, userID uuid.UUID) error
+	FindExpiredExports(expiredBefore time.Time, limit int) ([]models.AccountExport, error)
+	PurgeExport(ctx context.Context, export *models.AccountExport) error
+	FailStaleExports() (int, error)
+}
+
+// AccountPurger periodically deletes accounts for good once the date their
+// deletion was scheduled for has passed, together with account exports that
+// can no longer be downloaded. It also fails exports whose worker went away,
+// so they don't block new ones. Purging is idempotent, so several replicas may
+// run it at the same time.
+type AccountPurger struct {
+	accounts  AccountStore
+	interval  time.Duration
+	batchSize int
+	logger    zerolog.Logger
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewAccountPurger(accounts AccountStore, interval time.Duration, logger zerolog.Logger) *AccountPurger {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	return &AccountPurger{
+		accounts:  accounts,
+		interval:  interval,
+		batchSize: 100,
+		logger:    logger,
+	}
+}
+
+// Start runs the purge loop in a background goroutine until Stop is called
+// or ctx is cancelled
+func (p *AccountPurger) Start(ctx context.Context) {
+	ctx, p.cancel = context.WithCancel(ctx)
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		p.logger.Info().Dur("interval", p.interval).Msg("Account purger started")
+		for {
+			p.RunOnce(ctx)
+
+			select {
+			case <-ctx.Done():
+				p.logger.Info().Msg("Account purger stopped")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop signals the purge loop to exit and waits for it to finish or ctx to expire
+func (p *AccountPurger) Stop(ctx context.Context) error {
+	if p.cancel != nil {
+		p.cancel()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RunOnce purges due accounts and expired exports batch by batch and returns
+// how many accounts and exports were purged
+func (p *AccountPurger) RunOnce(ctx context.Context) (int, int) {
+	now := time.Now()
+	accounts, exports := 0, 0
+
+	if stale, err := p.accounts.FailStaleExports(); err != nil {
+		p.logger.Error().Err(err).Msg("Failed to fail stale account exports")
+	} else if stale > 0 {
+		p.logger.Warn().Int("exports", stale).Msg("Failed account exports that stopped making progress")
+	}
+
+	for ctx.Err() == nil {
+		users, err := p.accounts.FindDueForDeletion(now, p.batchSize)
+		if err != nil {
+			p.logger.Error().Err(err).Msg("Failed to find accounts due for deletion")
+			break
+		}
+
+		failed := 0
+		for i := range users {
+			if err := p.accounts.PurgeAccount(ctx, users[i].ID); err != nil {
+				p.logger.Error().Err(err).Str("user_id", users[i].ID.String()).Msg("Failed to purge account")
+				failed++
+				continue
+			}
+			accounts++
+		}
+
+		// Stop on a short batch, or when nothing in it could be purged so the
+		// same rows aren't retried in a tight loop
+		if len(users) < p.batchSize || failed == len(users) {
+			break
+		}
+	}
+
+	for ctx.Err() == nil {
+		expired, err := p.accounts.FindExpiredExports(now, p.batchSize)
+		if err != nil {
+			p.logger.Error().Err(err).Msg("Failed to find expired account exports")
+			break
+		}
+
+		failed := 0
+		for i := range expired {
+			if err := p.accounts.PurgeExport(ctx, &expired[i]); err != nil {
+				p.logger.Error().Err(err).Str("export_id", expired[i].ID.String()).Msg("Failed to purge account export")
+				failed++
+				continue
+			}
+			exports++
+		}
+
+		if len(expired) < p.batchSize || failed == len(expired) {
+			break
+		}
+	}
+
+	if accounts > 0 || exports > 0 {
+		p.logger.Info().Int("accounts", accounts).Int("exports", exports).Msg("Purged deleted accounts and expired exports")
+	}
+	return accounts, exports
+}
@@ -0,0 +1,112 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeAccounts hands out due accounts and expired exports in batches and
+// records purges
+type fakeAccounts struct {
+	due     []models.User
+	expired []models.AccountExport
+	failFor map[uuid.UUID]bool
+	cutoffs []time.Time
+	stale   int
+
+	purgedAccounts []uuid.UUID
+	purgedExports  []uuid.UUID
+}
+
+func (f *fakeAccounts) FindDueForDeletion(scheduledBefore time.Time, limit int) ([]models.User, error) {
+	f.cutoffs = append(f.cutoffs, scheduledBefore)
+	var batch []models.User
+	for _, user := range f.due {
+		if !contains(f.purgedAccounts, user.ID) && len(batch) < limit {
+			batch = append(batch, user)
+		}
+	}
+	return batch, nil
+}
+
+func (f *fakeAccounts) PurgeAccount(ctx context.Context, userID uuid.UUID) error {
+	if f.failFor[userID] {
+		return errors.New("storage unavailable")
+	}
+	f.purgedAccounts = append(f.purgedAccounts, userID)
+	return nil
+}
+
+func (f *fakeAccounts) FindExpiredExports(expiredBefore time.Time, limit int) ([]models.AccountExport, error) {
+	var batch []models.AccountExport
+	for _, export := range f.expired {
+		if !contains(f.purgedExports, export.ID) && len(batch) < limit {
+			batch = append(batch, export)
+		}
+	}
+	return batch, nil
+}
+
+func (f *fakeAccounts) PurgeExport(ctx context.Context, export *models.AccountExport) error {
+	f.purgedExports = append(f.purgedExports, export.ID)
+	return nil
+}
+
+func (f *fakeAccounts) FailStaleExports() (int, error) {
+	stale := f.stale
+	f.stale = 0
+	return stale, nil
+}
+
+func contains(ids []uuid.UUID, id uuid.UUID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+func dueUsers(n int) []models.User {
+	users := make([]models.User, n)
+	for i := range users {
+		users[i].ID = uuid.New()
+	}
+	return users
+}
+
+func TestAccountPurger_PurgesAccountsAndExportsInBatches(t *testing.T) {
+	accounts := &fakeAccounts{
+		due:     dueUsers(3),
+		expired: []models.AccountExport{{ID: uuid.New()}, {ID: uuid.New()}},
+		stale:   1,
+	}
+	purger := NewAccountPurger(accounts, time.Hour, zerolog.Nop())
+	purger.batchSize = 2
+
+	before := time.Now()
+	purgedAccounts, purgedExports := purger.RunOnce(context.Background())
+	assert.Equal(t, 3, purgedAccounts)
+	assert.Equal(t, 2, purgedExports)
+
+	assert.Zero(t, accounts.stale)
+	assert.Len(t, accounts.cutoffs, 2)
+	assert.WithinDuration(t, before, accounts.cutoffs[0], time.Second)
+}
+
+func TestAccountPurger_KeepsAccountThatFailsToPurge(t *testing.T) {
+	users := dueUsers(2)
+	accounts := &fakeAccounts{due: users, failFor: map[uuid.UUID]bool{users[0].ID: true}}
+	purger := NewAccountPurger(accounts, time.Hour, zerolog.Nop())
+
+	purgedAccounts, _ := purger.RunOnce(context.Background())
+	assert.Equal(t, 1, purgedAccounts)
+	assert.Equal(t, []uuid.UUID{users[1].ID}, accounts.purgedAccounts)
+}
@@ -0,0 +1,174 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+	"todo-backend/internal/models"
+	"todo-backend/internal/notifier"
+	"todo-backend/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// maxReminderAttempts is how often delivery is retried before a reminder is marked failed
+const maxReminderAttempts = 5
+
+// ReminderScheduler polls for due reminders and delivers them. Several
+// replicas can run it against the same database; claiming uses row locks so
+// each reminder is delivered by exactly one of them.
+type ReminderScheduler struct {
+	reminderRepo repository.ReminderRepository
+	notifiers    notifier.Registry
+	interval     time.Duration
+	lease        time.Duration
+	batchSize    int
+	logger       zerolog.Logger
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewReminderScheduler(reminderRepo repository.ReminderRepository, notifiers notifier.Registry, interval time.Duration, batchSize int, logger zerolog.Logger) *ReminderScheduler {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	if batchSize <= 0 {
+		batchSize = 50
+	}
+	return &ReminderScheduler{
+		reminderRepo: reminderRepo,
+		notifiers:    notifiers,
+		interval:     interval,
+		lease:        5 * time.Minute,
+		batchSize:    batchSize,
+		logger:       logger,
+	}
+}
+
+// Start runs the polling loop in a background goroutine until Stop is called
+// or ctx is cancelled
+func (s *ReminderScheduler) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		s.logger.Info().Dur("interval", s.interval).Msg("Reminder scheduler started")
+		for {
+			s.RunOnce(ctx)
+
+			select {
+			case <-ctx.Done():
+				s.logger.Info().Msg("Reminder scheduler stopped")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop signals the polling loop to exit and waits for in-flight deliveries
+// to finish or ctx to expire
+func (s *ReminderScheduler) Stop(ctx context.Context) error {
+	if s.cancel != nil {
+		s.cancel()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RunOnce claims and delivers one batch of due reminders
+func (s *ReminderScheduler) RunOnce(ctx context.Context) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	reminders, err := s.reminderRepo.ClaimDue(time.Now(), s.lease, s.batchSize)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to claim due reminders")
+		return
+	}
+
+	// Deliveries already started are allowed to finish during shutdown; the
+	// rest of the batch is released so another replica can pick it up
+	deliveryCtx := context.WithoutCancel(ctx)
+	for i := range reminders {
+		if ctx.Err() != nil {
+			reminders[i].Status = models.ReminderStatusPending
+			reminders[i].ClaimedAt = nil
+			s.save(&reminders[i])
+			continue
+		}
+		s.deliver(deliveryCtx, &reminders[i])
+	}
+}
+
+func (s *ReminderScheduler) deliver(ctx context.Context, reminder *models.Reminder) {
+	now := time.Now()
+	todo := reminder.Todo
+
+	// The todo was deleted or finished after the reminder was scheduled
+	if todo.ID == uuid.Nil || todo.Status == models.TodoStatusCompleted {
+		reminder.Status = models.ReminderStatusCancelled
+		s.save(reminder)
+		return
+	}
+
+	fireAt := now
+	if reminder.FireAt != nil {
+		fireAt = *reminder.FireAt
+	}
+
+	err := s.notifiers.Notify(ctx, reminder.Channel, notifier.Notification{
+		ReminderID: reminder.ID,
+		TodoID:     todo.ID,
+		UserID:     reminder.UserID,
+		Email:      reminder.User.Email,
+		Title:      todo.Title,
+		DueDate:    todo.DueDate,
+		FireAt:     fireAt,
+	})
+
+	reminder.Attempts++
+	reminder.ClaimedAt = nil
+	if err == nil {
+		reminder.Status = models.ReminderStatusSent
+		reminder.SentAt = &now
+		reminder.LastError = ""
+	} else {
+		s.logger.Warn().Err(err).Str("reminder_id", reminder.ID.String()).Int("attempt", reminder.Attempts).Msg("Reminder delivery failed")
+		reminder.LastError = err.Error()
+		if reminder.Attempts >= maxReminderAttempts {
+			reminder.Status = models.ReminderStatusFailed
+		} else {
+			// Back off quadratically before the next attempt
+			retryAt := now.Add(time.Duration(reminder.Attempts*reminder.Attempts) * time.Minute)
+			reminder.Status = models.ReminderStatusPending
+			reminder.FireAt = &retryAt
+		}
+	}
+	s.save(reminder)
+}
+
+func (s *ReminderScheduler) save(reminder *models.Reminder) {
+	if err := s.reminderRepo.Update(reminder); err != nil {
+		s.logger.Error().Err(err).Str("reminder_id", reminder.ID.String()).Msg("Failed to update reminder")
+	}
+}
@@ -0,0 +1,142 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"todo-backend/internal/models"
+	"todo-backend/internal/notifier"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock repository for testing
+type MockReminderRepository struct {
+	mock.Mock
+}
+
+func (m *MockReminderRepository) Create(reminder *models.Reminder) error {
+	return m.Called(reminder).Error(0)
+}
+
+func (m *MockReminderRepository) GetByID(id uuid.UUID) (*models.Reminder, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Reminder), args.Error(1)
+}
+
+func (m *MockReminderRepository) GetByTodoID(todoID uuid.UUID) ([]models.Reminder, error) {
+	args := m.Called(todoID)
+	return args.Get(0).([]models.Reminder), args.Error(1)
+}
+
+func (m *MockReminderRepository) Update(reminder *models.Reminder) error {
+	return m.Called(reminder).Error(0)
+}
+
+func (m *MockReminderRepository) Delete(id uuid.UUID) error {
+	return m.Called(id).Error(0)
+}
+
+func (m *MockReminderRepository) RescheduleForTodo(todoID uuid.UUID, dueDate *time.Time) error {
+	return m.Called(todoID, dueDate).Error(0)
+}
+
+func (m *MockReminderRepository) CancelForTodo(todoID uuid.UUID) error {
+	return m.Called(todoID).Error(0)
+}
+
+func (m *MockReminderRepository) ClaimDue(now time.Time, lease time.Duration, limit int) ([]models.Reminder, error) {
+	args := m.Called(now, lease, limit)
+	return args.Get(0).([]models.Reminder), args.Error(1)
+}
+
+type fakeNotifier struct {
+	err  error
+	sent []notifier.Notification
+}
+
+func (f *fakeNotifier) Notify(ctx context.Context, n notifier.Notification) error {
+	f.sent = append(f.sent, n)
+	return f.err
+}
+
+func dueReminder() models.Reminder {
+	todoID := uuid.New()
+	return models.Reminder{
+		ID:      uuid.New(),
+		TodoID:  todoID,
+		UserID:  uuid.New(),
+		Channel: models.ReminderChannelLog,
+		Status:  models.ReminderStatusProcessing,
+		Todo:    models.Todo{ID: todoID, Title: "Pay rent", Status: models.TodoStatusPending},
+	}
+}
+
+func TestRunOnce_DeliversReminder(t *testing.T) {
+	repo := new(MockReminderRepository)
+	fake := &fakeNotifier{}
+	s := NewReminderScheduler(repo, notifier.Registry{models.ReminderChannelLog: fake}, time.Minute, 10, zerolog.Nop())
+
+	repo.On("ClaimDue", mock.Anything, mock.Anything, 10).Return([]models.Reminder{dueReminder()}, nil)
+	repo.On("Update", mock.MatchedBy(func(r *models.Reminder) bool {
+		return r.Status == models.ReminderStatusSent && r.SentAt != nil && r.Attempts == 1
+	})).Return(nil)
+
+	s.RunOnce(context.Background())
+
+	assert.Len(t, fake.sent, 1)
+	assert.Equal(t, "Pay rent", fake.sent[0].Title)
+	repo.AssertExpectations(t)
+}
+
+func TestRunOnce_RetriesFailedDelivery(t *testing.T) {
+	repo := new(MockReminderRepository)
+	fake := &fakeNotifier{err: errors.New("connection refused")}
+	s := NewReminderScheduler(repo, notifier.Registry{models.ReminderChannelLog: fake}, time.Minute, 10, zerolog.Nop())
+
+	repo.On("ClaimDue", mock.Anything, mock.Anything, 10).Return([]models.Reminder{dueReminder()}, nil)
+	repo.On("Update", mock.MatchedBy(func(r *models.Reminder) bool {
+		return r.Status == models.ReminderStatusPending && r.FireAt != nil && r.FireAt.After(time.Now()) && r.LastError == "connection refused"
+	})).Return(nil)
+
+	s.RunOnce(context.Background())
+
+	repo.AssertExpectations(t)
+}
+
+func TestRunOnce_CancelsReminderOfCompletedTodo(t *testing.T) {
+	repo := new(MockReminderRepository)
+	fake := &fakeNotifier{}
+	s := NewReminderScheduler(repo, notifier.Registry{models.ReminderChannelLog: fake}, time.Minute, 10, zerolog.Nop())
+
+	reminder := dueReminder()
+	reminder.Todo.Status = models.TodoStatusCompleted
+	repo.On("ClaimDue", mock.Anything, mock.Anything, 10).Return([]models.Reminder{reminder}, nil)
+	repo.On("Update", mock.MatchedBy(func(r *models.Reminder) bool {
+		return r.Status == models.ReminderStatusCancelled
+	})).Return(nil)
+
+	s.RunOnce(context.Background())
+
+	assert.Empty(t, fake.sent)
+	repo.AssertExpectations(t)
+}
+
+func TestStartStop(t *testing.T) {
+	repo := new(MockReminderRepository)
+	repo.On("ClaimDue", mock.Anything, mock.Anything, mock.Anything).Return([]models.Reminder{}, nil)
+	s := NewReminderScheduler(repo, notifier.Registry{}, time.Hour, 10, zerolog.Nop())
+
+	s.Start(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, s.Stop(ctx))
+}
@@ -0,0 +1,139 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// TrashStore finds and purges todos that have been in the trash too long
+type TrashStore interface {
+	FindExpired(deletedBefore time.Time, limit int) ([]models.Todo, error)
+	Purge(id uuid.UUID) error
+}
+
+// BlobCleaner removes the attachment files of a todo about to be purged
+type BlobCleaner interface {
+	DeleteBlobsForTodo(ctx context.Context, todoID uuid.UUID) error
+}
+
+// TrashPurger periodically deletes todos for good once they have been in the
+// trash for longer than the retention period. Purging is idempotent, so
+// several replicas may run it at the same time.
+type TrashPurger struct {
+	todos     TrashStore
+	blobs     BlobCleaner
+	retention time.Duration
+	interval  time.Duration
+	batchSize int
+	logger    zerolog.Logger
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewTrashPurger(todos TrashStore, blobs BlobCleaner, retention, interval time.Duration, logger zerolog.Logger) *TrashPurger {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	return &TrashPurger{
+		todos:     todos,
+		blobs:     blobs,
+		retention: retention,
+		interval:  interval,
+		batchSize: 100,
+		logger:    logger,
+	}
+}
+
+// Start runs the purge loop in a background goroutine until Stop is called
+// or ctx is cancelled
+func (p *TrashPurger) Start(ctx context.Context) {
+	ctx, p.cancel = context.WithCancel(ctx)
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		p.logger.Info().Dur("retention", p.retention).Dur("interval", p.interval).Msg("Trash purger started")
+		for {
+			p.RunOnce(ctx)
+
+			select {
+			case <-ctx.Done():
+				p.logger.Info().Msg("Trash purger stopped")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop signals the purge loop to exit and waits for it to finish or ctx to expire
+func (p *TrashPurger) Stop(ctx context.Context) error {
+	if p.cancel != nil {
+		p.cancel()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RunOnce purges expired todos batch by batch and returns how many were purged
+func (p *TrashPurger) RunOnce(ctx context.Context) int {
+	cutoff := time.Now().Add(-p.retention)
+	purged := 0
+
+	for ctx.Err() == nil {
+		todos, err := p.todos.FindExpired(cutoff, p.batchSize)
+		if err != nil {
+			p.logger.Error().Err(err).Msg("Failed to find expired trash")
+			break
+		}
+
+		failed := 0
+		for i := range todos {
+			if err := p.purge(ctx, todos[i].ID); err != nil {
+				p.logger.Error().Err(err).Str("todo_id", todos[i].ID.String()).Msg("Failed to purge todo")
+				failed++
+				continue
+			}
+			purged++
+		}
+
+		// Stop on a short batch, or when nothing in it could be purged so the
+		// same rows aren't retried in a tight loop
+		if len(todos) < p.batchSize || failed == len(todos) {
+			break
+		}
+	}
+
+	if purged > 0 {
+		p.logger.Info().Int("count", purged).Msg("Purged expired trash")
+	}
+	return purged
+}
+
+func (p *TrashPurger) purge(ctx context.Context, id uuid.UUID) error {
+	if err := p.blobs.DeleteBlobsForTodo(ctx, id); err != nil {
+		return err
+	}
+	return p.todos.Purge(id)
+}
@@ -0,0 +1,97 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeTrash hands out expired todos in batches and records purges
+type fakeTrash struct {
+	expired []models.Todo
+	cutoffs []time.Time
+	purged  []uuid.UUID
+}
+
+func (f *fakeTrash) FindExpired(deletedBefore time.Time, limit int) ([]models.Todo, error) {
+	f.cutoffs = append(f.cutoffs, deletedBefore)
+	var batch []models.Todo
+	for _, todo := range f.expired {
+		if !f.isPurged(todo.ID) && len(batch) < limit {
+			batch = append(batch, todo)
+		}
+	}
+	return batch, nil
+}
+
+func (f *fakeTrash) Purge(id uuid.UUID) error {
+	f.purged = append(f.purged, id)
+	return nil
+}
+
+func (f *fakeTrash) isPurged(id uuid.UUID) bool {
+	for _, purged := range f.purged {
+		if purged == id {
+			return true
+		}
+	}
+	return false
+}
+
+type fakeBlobCleaner struct {
+	failFor map[uuid.UUID]bool
+	cleaned []uuid.UUID
+}
+
+func (f *fakeBlobCleaner) DeleteBlobsForTodo(ctx context.Context, todoID uuid.UUID) error {
+	if f.failFor[todoID] {
+		return errors.New("storage unavailable")
+	}
+	f.cleaned = append(f.cleaned, todoID)
+	return nil
+}
+
+func expiredTodos(n int) []models.Todo {
+	todos := make([]models.Todo, n)
+	for i := range todos {
+		todos[i].ID = uuid.New()
+	}
+	return todos
+}
+
+func TestTrashPurger_PurgesInBatches(t *testing.T) {
+	trash := &fakeTrash{expired: expiredTodos(5)}
+	blobs := &fakeBlobCleaner{}
+	purger := NewTrashPurger(trash, blobs, 30*24*time.Hour, time.Hour, zerolog.Nop())
+	purger.batchSize = 2
+
+	before := time.Now()
+	assert.Equal(t, 5, purger.RunOnce(context.Background()))
+
+	assert.Len(t, trash.purged, 5)
+	assert.Equal(t, trash.purged, blobs.cleaned)
+	assert.Len(t, trash.cutoffs, 3)
+	assert.WithinDuration(t, before.Add(-30*24*time.Hour), trash.cutoffs[0], time.Second)
+}
+
+func TestTrashPurger_KeepsTodoWhenFilesCantBeDeleted(t *testing.T) {
+	todos := expiredTodos(2)
+	trash := &fakeTrash{expired: todos}
+	blobs := &fakeBlobCleaner{failFor: map[uuid.UUID]bool{todos[0].ID: true}}
+	purger := NewTrashPurger(trash, blobs, time.Hour, time.Hour, zerolog.Nop())
+	purger.batchSize = 1
+
+	// The failing todo fills the first batch on its own, so the run stops there
+	assert.Equal(t, 0, purger.RunOnce(context.Background()))
+	assert.Empty(t, trash.purged)
+
+	purger.batchSize = 10
+	assert.Equal(t, 1, purger.RunOnce(context.Background()))
+	assert.Equal(t, []uuid.UUID{todos[1].ID}, trash.purged)
+}
@@ -0,0 +1,108 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+// ErrWorkerBusy is returned by Enqueue when the queue is full
+var ErrWorkerBusy = errors.New("too many background jobs, try again later")
+
+// Worker runs background jobs such as large imports on a fixed number of
+// goroutines. Jobs get a context that is cancelled by Stop, which waits for
+// running jobs to return; jobs still queued at that point are dropped.
+type Worker struct {
+	workers   int
+	queueSize int
+	logger    zerolog.Logger
+
+	once   sync.Once
+	queue  chan func(ctx context.Context)
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewWorker(workers int, logger zerolog.Logger) *Worker {
+	if workers <= 0 {
+		workers = 1
+	}
+	return &Worker{
+		workers:   workers,
+		queueSize: 100,
+		logger:    logger,
+	}
+}
+
+func (w *Worker) init() {
+	w.once.Do(func() {
+		w.queue = make(chan func(ctx context.Context), w.queueSize)
+	})
+}
+
+// Start runs the worker goroutines until Stop is called or ctx is cancelled
+func (w *Worker) Start(ctx context.Context) {
+	w.init()
+	ctx, w.cancel = context.WithCancel(ctx)
+
+	for i := 0; i < w.workers; i++ {
+		w.wg.Add(1)
+		go func() {
+			defer w.wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case job := <-w.queue:
+					w.run(ctx, job)
+				}
+			}
+		}()
+	}
+	w.logger.Info().Int("workers", w.workers).Msg("Background worker started")
+}
+
+// Stop cancels running jobs and waits for them to return or ctx to expire
+func (w *Worker) Stop(ctx context.Context) error {
+	if w.cancel != nil {
+		w.cancel()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		w.logger.Info().Msg("Background worker stopped")
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Enqueue queues job to run on the worker, or returns ErrWorkerBusy
+func (w *Worker) Enqueue(job func(ctx context.Context)) error {
+	w.init()
+	select {
+	case w.queue <- job:
+		return nil
+	default:
+		return ErrWorkerBusy
+	}
+}
+
+// run runs one job, keeping a panic from taking the worker down with it
+func (w *Worker) run(ctx context.Context, job func(ctx context.Context)) {
+	defer func() {
+		if r := recover(); r != nil {
+			w.logger.Error().Err(fmt.Errorf("panic: %v", r)).Msg("Background job panicked")
+		}
+	}()
+	job(ctx)
+}
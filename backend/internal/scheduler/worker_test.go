@@ -0,0 +1,66 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorker_RunsJobsAndCancelsThemOnStop(t *testing.T) {
+	worker := NewWorker(2, zerolog.Nop())
+	worker.Start(context.Background())
+
+	done := make(chan struct{})
+	require.NoError(t, worker.Enqueue(func(ctx context.Context) { close(done) }))
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("job did not run")
+	}
+
+	started, cancelled := make(chan struct{}), make(chan struct{})
+	require.NoError(t, worker.Enqueue(func(ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+		close(cancelled)
+	}))
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, worker.Stop(ctx))
+
+	// Stop returns only once the running job has seen the cancellation
+	select {
+	case <-cancelled:
+	default:
+		t.Fatal("Stop returned before the job finished")
+	}
+}
+
+func TestWorker_RejectsJobsWhenTheQueueIsFull(t *testing.T) {
+	worker := NewWorker(1, zerolog.Nop())
+	worker.queueSize = 1
+
+	assert.NoError(t, worker.Enqueue(func(ctx context.Context) {}))
+	assert.ErrorIs(t, worker.Enqueue(func(ctx context.Context) {}), ErrWorkerBusy)
+}
+
+func TestWorker_SurvivesPanics(t *testing.T) {
+	worker := NewWorker(1, zerolog.Nop())
+	worker.Start(context.Background())
+	defer worker.Stop(context.Background())
+
+	done := make(chan struct{})
+	require.NoError(t, worker.Enqueue(func(ctx context.Context) { panic("boom") }))
+	require.NoError(t, worker.Enqueue(func(ctx context.Context) { close(done) }))
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("worker stopped after a panic")
+	}
+}
@@ -0,0 +1,151 @@
+package search
+
+import (
+	"html"
+	"math"
+	"sort"
+	"strings"
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// Weights of a term found in the title and in the description, mirroring the
+// A and B weights of the Postgres search vector
+const (
+	titleWeight       = 1.0
+	descriptionWeight = 0.4
+)
+
+// snippetWords is the number of words kept around the first match in a snippet
+const snippetWords = 20
+
+// TodoSource provides the todos an in-memory search scans
+type TodoSource interface {
+	Find(userID uuid.UUID, filter *models.TodoFilter, offset, limit int) ([]models.Todo, int64, error)
+}
+
+// MemorySearcher scans a user's todos on every query. It needs no database
+// support and suits tests and small installations. Terms match word prefixes
+// only, without the stemming Postgres applies, so "invoices" doesn't find
+// "invoice" here while it does with PostgresSearcher.
+type MemorySearcher struct {
+	source TodoSource
+}
+
+func NewMemorySearcher(source TodoSource) *MemorySearcher {
+	return &MemorySearcher{source: source}
+}
+
+func (s *MemorySearcher) Search(userID uuid.UUID, query string, limit int) ([]Result, error) {
+	terms := Terms(query)
+	if len(terms) == 0 {
+		return []Result{}, nil
+	}
+
+	todos, _, err := s.source.Find(userID, nil, 0, math.MaxInt32)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []Result{}
+	for _, todo := range todos {
+		if rank, ok := Rank(todo.Title, todo.Description, terms); ok {
+			text := strings.TrimSpace(todo.Title + " " + todo.Description)
+			results = append(results, Result{Todo: todo, Rank: rank, Snippet: Highlight(text, terms)})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Rank != results[j].Rank {
+			return results[i].Rank > results[j].Rank
+		}
+		return results[i].Todo.UpdatedAt.After(results[j].Todo.UpdatedAt)
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// Rank scores how well title and description match terms. It reports false
+// unless every term prefixes at least one word.
+func Rank(title, description string, terms []string) (float64, bool) {
+	titleWords := Terms(title)
+	descriptionWords := Terms(description)
+
+	rank := 0.0
+	for _, term := range terms {
+		hits := titleWeight*float64(countPrefixed(titleWords, term)) +
+			descriptionWeight*float64(countPrefixed(descriptionWords, term))
+		if hits == 0 {
+			return 0, false
+		}
+		rank += hits
+	}
+
+	// Dampen long documents like ts_rank's length normalization
+	return rank / math.Log(float64(len(titleWords)+len(descriptionWords))+math.E), true
+}
+
+// Highlight HTML-escapes text, wraps the words that match terms in highlight
+// markers and trims it to a window around the first match
+func Highlight(text string, terms []string) string {
+	words := strings.Fields(text)
+	first := -1
+	for i, word := range words {
+		if !matchesAny(word, terms) {
+			words[i] = html.EscapeString(word)
+			continue
+		}
+		if first < 0 {
+			first = i
+		}
+		words[i] = HighlightStart + html.EscapeString(word) + HighlightStop
+	}
+	if first < 0 {
+		first = 0
+	}
+
+	start := first - snippetWords/4
+	if start < 0 {
+		start = 0
+	}
+	end := start + snippetWords
+	if end > len(words) {
+		end = len(words)
+	}
+
+	snippet := strings.Join(words[start:end], " ")
+	if start > 0 {
+		snippet = "... " + snippet
+	}
+	if end < len(words) {
+		snippet += " ..."
+	}
+	return snippet
+}
+
+func countPrefixed(words []string, term string) int {
+	n := 0
+	for _, word := range words {
+		if strings.HasPrefix(word, term) {
+			n++
+		}
+	}
+	return n
+}
+
+// matchesAny reports whether a whitespace-separated word of text matches one
+// of terms. The word is split the way Terms splits queries, so "<b>invoice</b>"
+// matches "invoice" just as Rank counts it.
+func matchesAny(word string, terms []string) bool {
+	for _, part := range Terms(word) {
+		for _, term := range terms {
+			if strings.HasPrefix(part, term) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package search
+
+import (
+	"testing"
+	"time"
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSource struct {
+	todos []models.Todo
+}
+
+func (f *fakeSource) Find(userID uuid.UUID, filter *models.TodoFilter, offset, limit int) ([]models.Todo, int64, error) {
+	var todos []models.Todo
+	for _, todo := range f.todos {
+		if todo.UserID == userID {
+			todos = append(todos, todo)
+		}
+	}
+	return todos, int64(len(todos)), nil
+}
+
+func TestMemorySearcher(t *testing.T) {
+	userID := uuid.New()
+	now := time.Now()
+	source := &fakeSource{todos: []models.Todo{
+		{ID: uuid.New(), UserID: userID, Title: "Pay the invoice", Description: "Acme sent it last week", UpdatedAt: now},
+		{ID: uuid.New(), UserID: userID, Title: "Call Acme", Description: "Ask about the invoice total", UpdatedAt: now},
+		{ID: uuid.New(), UserID: userID, Title: "Buy milk", UpdatedAt: now},
+		{ID: uuid.New(), UserID: uuid.New(), Title: "Someone else's invoice", UpdatedAt: now},
+	}}
+	searcher := NewMemorySearcher(source)
+
+	tests := []struct {
+		name   string
+		query  string
+		titles []string
+	}{
+		{"title match ranks first", "invoice", []string{"Pay the invoice", "Call Acme"}},
+		{"prefix match", "inv", []string{"Pay the invoice", "Call Acme"}},
+		{"all terms required", "acme total", []string{"Call Acme"}},
+		{"case and punctuation ignored", "MILK!", []string{"Buy milk"}},
+		{"no match", "groceries", nil},
+		{"only punctuation", "--", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := searcher.Search(userID, tt.query, 10)
+			require.NoError(t, err)
+
+			var titles []string
+			for _, r := range results {
+				titles = append(titles, r.Todo.Title)
+			}
+			assert.Equal(t, tt.titles, titles)
+		})
+	}
+}
+
+func TestMemorySearcherLimit(t *testing.T) {
+	userID := uuid.New()
+	source := &fakeSource{}
+	for i := 0; i < 5; i++ {
+		source.todos = append(source.todos, models.Todo{ID: uuid.New(), UserID: userID, Title: "report"})
+	}
+
+	results, err := NewMemorySearcher(source).Search(userID, "report", 3)
+	require.NoError(t, err)
+	assert.Len(t, results, 3)
+}
+
+func TestHighlight(t *testing.T) {
+	assert.Equal(t, "Pay the <mark>invoice,</mark> then file it",
+		Highlight("Pay the invoice, then file it", []string{"inv"}))
+
+	long := "one two three four five six seven eight nine ten eleven twelve thirteen fourteen fifteen " +
+		"sixteen seventeen eighteen nineteen twenty twentyone twentytwo invoice end"
+	snippet := Highlight(long, []string{"invoice"})
+	assert.Contains(t, snippet, "<mark>invoice</mark>")
+	assert.True(t, len(snippet) < len(long))
+	assert.Equal(t, "... ", snippet[:4])
+}
+
+func TestMemorySearcher_EscapesMarkupInSnippets(t *testing.T) {
+	userID := uuid.New()
+	source := &fakeSource{todos: []models.Todo{
+		{ID: uuid.New(), UserID: userID, Title: `<img src=x onerror="alert(1)"> invoice & <b>receipt</b>`},
+	}}
+
+	results, err := NewMemorySearcher(source).Search(userID, "invoice receipt", 10)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, `&lt;img src=x onerror=&#34;alert(1)&#34;&gt; <mark>invoice</mark> &amp; <mark>&lt;b&gt;receipt&lt;/b&gt;</mark>`,
+		results[0].Snippet)
+}
+
+func TestMarkSnippet(t *testing.T) {
+	snippet := markSnippet("<script>x</script> pay the " + sentinelStart + "invoice" + sentinelStop + " & go")
+	assert.Equal(t, "&lt;script&gt;x&lt;/script&gt; pay the <mark>invoice</mark> &amp; go", snippet)
+}
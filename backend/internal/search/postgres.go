@@ -0,0 +1,129 @@
+package search
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PostgresSearcher queries the todos.search_vector column kept up to date by
+// the todos_search_vector_update trigger
+type PostgresSearcher struct {
+	db *gorm.DB
+}
+
+func NewPostgresSearcher(db *gorm.DB) *PostgresSearcher {
+	return &PostgresSearcher{db: db}
+}
+
+// ts_headline returns the todo text as is, so it marks matches with
+// private-use sentinels; the snippet is escaped before they become markers
+const (
+	sentinelStart = "\uE000"
+	sentinelStop  = "\uE001"
+)
+
+var sentinelMarkers = strings.NewReplacer(sentinelStart, HighlightStart, sentinelStop, HighlightStop)
+
+type postgresHit struct {
+	ID      uuid.UUID
+	Rank    float64
+	Snippet string
+}
+
+func (s *PostgresSearcher) Search(userID uuid.UUID, query string, limit int) ([]Result, error) {
+	terms := Terms(query)
+	if len(terms) == 0 {
+		return []Result{}, nil
+	}
+
+	// Each term becomes a prefix match; all of them must be present
+	for i, term := range terms {
+		terms[i] = term + ":*"
+	}
+	tsquery := strings.Join(terms, " & ")
+
+	var hits []postgresHit
+	err := s.db.Raw(`
+		SELECT todos.id,
+			ts_rank(todos.search_vector, q) AS rank,
+			ts_headline(?::regconfig, todos.title || ' ' || COALESCE(todos.description, ''), q, ?) AS snippet
+		FROM todos, to_tsquery(?::regconfig, ?) AS q
+		WHERE todos.user_id = ? AND todos.deleted_at IS NULL AND todos.search_vector @@ q
+		ORDER BY rank DESC, todos.updated_at DESC
+		LIMIT ?`,
+		TextSearchConfig, "StartSel="+sentinelStart+", StopSel="+sentinelStop+", MaxWords=20, MinWords=5, MaxFragments=2",
+		TextSearchConfig, tsquery, userID, limit).
+		Scan(&hits).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(hits) == 0 {
+		return []Result{}, nil
+	}
+
+	ids := make([]uuid.UUID, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.ID
+	}
+	var todos []models.Todo
+	if err := s.db.Preload("Tags").Where("id IN ?", ids).Find(&todos).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]models.Todo, len(todos))
+	for _, todo := range todos {
+		byID[todo.ID] = todo
+	}
+
+	results := make([]Result, 0, len(hits))
+	for _, hit := range hits {
+		if todo, ok := byID[hit.ID]; ok {
+			results = append(results, Result{Todo: todo, Rank: hit.Rank, Snippet: markSnippet(hit.Snippet)})
+		}
+	}
+	return results, nil
+}
+
+// markSnippet escapes a ts_headline snippet and turns its sentinels into
+// highlight markers
+func markSnippet(snippet string) string {
+	return sentinelMarkers.Replace(html.EscapeString(snippet))
+}
+
+// Migrate adds the search_vector column, its GIN index and the trigger that
+// maintains it, then backfills existing rows. It is safe to run repeatedly.
+func Migrate(db *gorm.DB) error {
+	statements := []string{
+		`ALTER TABLE todos ADD COLUMN IF NOT EXISTS search_vector tsvector`,
+		`CREATE INDEX IF NOT EXISTS idx_todos_search_vector ON todos USING GIN (search_vector)`,
+		`CREATE OR REPLACE FUNCTION todos_search_vector_update() RETURNS trigger AS $$
+		BEGIN
+			NEW.search_vector := ` + searchDocument("NEW") + `;
+			RETURN NEW;
+		END
+		$$ LANGUAGE plpgsql`,
+		`DROP TRIGGER IF EXISTS todos_search_vector_trigger ON todos`,
+		`CREATE TRIGGER todos_search_vector_trigger
+			BEFORE INSERT OR UPDATE OF title, description ON todos
+			FOR EACH ROW EXECUTE FUNCTION todos_search_vector_update()`,
+		`UPDATE todos SET search_vector = ` + searchDocument("todos") + ` WHERE search_vector IS NULL`,
+	}
+
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// searchDocument is the weighted tsvector expression of a todo row: title
+// words rank above description words
+func searchDocument(row string) string {
+	return fmt.Sprintf(`setweight(to_tsvector('%[1]s', COALESCE(%[2]s.title, '')), 'A') ||
+		setweight(to_tsvector('%[1]s', COALESCE(%[2]s.description, '')), 'B')`, TextSearchConfig, row)
+}
@@ -0,0 +1,41 @@
+package search
+
+import (
+	"strings"
+	"todo-backend/internal/models"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+// TextSearchConfig is the Postgres text search configuration used both to
+// build the todos.search_vector column and to parse queries
+const TextSearchConfig = "english"
+
+// Snippet highlight markers. Snippets are HTML: the todo text in them is
+// escaped, so the markers are the only markup.
+const (
+	HighlightStart = "<mark>"
+	HighlightStop  = "</mark>"
+)
+
+// Result is a todo matching a search, its relevance and a highlighted excerpt
+type Result struct {
+	Todo    models.Todo
+	Rank    float64
+	Snippet string
+}
+
+// Searcher finds a user's todos whose title or description match a query.
+// Every query term must match; the last characters of a term may be left out
+// ("inv" matches "invoice"). Results are ordered by decreasing rank.
+type Searcher interface {
+	Search(userID uuid.UUID, query string, limit int) ([]Result, error)
+}
+
+// Terms splits a query into lowercase words, dropping punctuation and operators
+func Terms(query string) []string {
+	return strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
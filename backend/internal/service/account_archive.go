@@ -0,0 +1,106 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"todo-backend/internal/models"
+	"todo-backend/internal/storage"
+)
+
+// accountArchive is everything an account export contains
+type accountArchive struct {
+	profile     models.UserResponse
+	todos       func(w io.Writer) error // writes the JSON export of the todos
+	comments    []models.CommentResponse
+	attachments []models.Attachment
+	sessions    []models.AppPasswordResponse
+}
+
+// accountArchiveAttachment describes an attachment and where its file is in
+// the archive; Path is empty when the file is missing from the store
+type accountArchiveAttachment struct {
+	models.AttachmentResponse
+	Deleted bool   `json:"deleted"`
+	Path    string `json:"path,omitempty"`
+}
+
+// write writes the archive as a ZIP file to w, copying the attachment files
+// from store
+func (a *accountArchive) write(ctx context.Context, w io.Writer, store storage.BlobStore) error {
+	zw := zip.NewWriter(w)
+
+	if err := writeArchiveJSON(zw, "profile.json", a.profile); err != nil {
+		return err
+	}
+
+	todos, err := zw.Create("todos.json")
+	if err != nil {
+		return err
+	}
+	if err := a.todos(todos); err != nil {
+		return err
+	}
+
+	if err := writeArchiveJSON(zw, "comments.json", a.comments); err != nil {
+		return err
+	}
+
+	attachments := make([]accountArchiveAttachment, len(a.attachments))
+	for i := range a.attachments {
+		attachment := &a.attachments[i]
+		attachments[i] = accountArchiveAttachment{AttachmentResponse: attachment.ToResponse(), Deleted: attachment.DeletedAt.Valid}
+
+		path := fmt.Sprintf("attachments/%s/%s-%s", attachment.TodoID, attachment.ID, attachment.FileName)
+		copied, err := copyArchiveBlob(ctx, zw, store, path, attachment.StorageKey)
+		if err != nil {
+			return err
+		}
+		if copied {
+			attachments[i].Path = path
+		}
+	}
+	if err := writeArchiveJSON(zw, "attachments.json", attachments); err != nil {
+		return err
+	}
+
+	if err := writeArchiveJSON(zw, "sessions.json", a.sessions); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func writeArchiveJSON(zw *zip.Writer, name string, v interface{}) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// copyArchiveBlob copies a blob into the archive, reporting false when the
+// store doesn't have it
+func copyArchiveBlob(ctx context.Context, zw *zip.Writer, store storage.BlobStore, path, key string) (bool, error) {
+	content, err := store.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer content.Close()
+
+	w, err := zw.Create(path)
+	if err != nil {
+		return false, err
+	}
+	if _, err := io.Copy(w, content); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,471 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"todo-backend/internal/config"
+	"todo-backend/internal/models"
+	"todo-backend/internal/notifier"
+	"todo-backend/internal/repository"
+	"todo-backend/internal/storage"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+// accountExportChannels are tried in order to tell a user their export is
+// ready; the log channel is used when none of them is configured
+var accountExportChannels = []string{models.ReminderChannelEmail, models.ReminderChannelWebhook}
+
+var (
+	errExportInterrupted = errors.New("account export interrupted by a server shutdown")
+	errExportStale       = errors.New("account export stopped making progress")
+)
+
+type AccountService interface {
+	// RequestExport starts building a ZIP archive of everything stored about
+	// the user in the background. The user is notified with a download link
+	// once it is ready.
+	RequestExport(userID uuid.UUID) (*models.AccountExport, error)
+	GetExport(id uuid.UUID, userID uuid.UUID) (*models.AccountExportResponse, error)
+	OpenSignedExport(ctx context.Context, id uuid.UUID, expires int64, signature string) (*models.AccountExport, io.ReadCloser, error)
+
+	// Delete reauthenticates the user and schedules their account to be
+	// purged after the grace period, or purges it right away when there is none
+	Delete(ctx context.Context, userID uuid.UUID, req *models.AccountDeleteRequest) (*models.AccountDeletionResponse, error)
+	CancelDeletion(userID uuid.UUID) (*models.User, error)
+
+	// Used by the account purger
+	FindDueForDeletion(scheduledBefore time.Time, limit int) ([]models.User, error)
+	PurgeAccount(ctx context.Context, userID uuid.UUID) error
+	FindExpiredExports(expiredBefore time.Time, limit int) ([]models.AccountExport, error)
+	PurgeExport(ctx context.Context, export *models.AccountExport) error
+	// FailStaleExports marks exports that stopped making progress as failed
+	// and returns how many there were
+	FailStaleExports() (int, error)
+}
+
+type accountService struct {
+	userRepo        repository.UserRepository
+	exportRepo      repository.AccountExportRepository
+	commentRepo     repository.CommentRepository
+	attachmentRepo  repository.AttachmentRepository
+	appPasswordRepo repository.AppPasswordRepository
+	exportService   ExportService
+	authService     AuthService
+	store           storage.BlobStore
+	notifiers       notifier.Registry
+	queue           BackgroundQueue
+	config          *config.Config
+	now             func() time.Time
+}
+
+func NewAccountService(userRepo repository.UserRepository, exportRepo repository.AccountExportRepository, commentRepo repository.CommentRepository, attachmentRepo repository.AttachmentRepository, appPasswordRepo repository.AppPasswordRepository, exportService ExportService, authService AuthService, store storage.BlobStore, notifiers notifier.Registry, queue BackgroundQueue, cfg *config.Config) AccountService {
+	return &accountService{
+		userRepo:        userRepo,
+		exportRepo:      exportRepo,
+		commentRepo:     commentRepo,
+		attachmentRepo:  attachmentRepo,
+		appPasswordRepo: appPasswordRepo,
+		exportService:   exportService,
+		authService:     authService,
+		store:           store,
+		notifiers:       notifiers,
+		queue:           queue,
+		config:          cfg,
+		now:             time.Now,
+	}
+}
+
+func (s *accountService) RequestExport(userID uuid.UUID) (*models.AccountExport, error) {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	exports, err := s.exportRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range exports {
+		if s.inProgress(&exports[i]) {
+			return nil, errors.New("an account export is already in progress")
+		}
+	}
+
+	export := &models.AccountExport{UserID: userID, Status: models.AccountExportPending}
+	if err := s.exportRepo.Create(export); err != nil {
+		return nil, err
+	}
+
+	background := *export
+	err = s.queue.Enqueue(func(ctx context.Context) {
+		defer func() {
+			if r := recover(); r != nil {
+				s.failExport(&background, fmt.Errorf("panic: %v", r))
+			}
+		}()
+		if err := s.runExport(ctx, user, &background); err != nil {
+			if ctx.Err() != nil {
+				err = errExportInterrupted
+			}
+			s.failExport(&background, err)
+		}
+	})
+	if err != nil {
+		s.failExport(export, err)
+		return nil, err
+	}
+	return export, nil
+}
+
+func (s *accountService) GetExport(id uuid.UUID, userID uuid.UUID) (*models.AccountExportResponse, error) {
+	export, err := s.exportRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("account export not found")
+		}
+		return nil, err
+	}
+	if export.UserID != userID {
+		return nil, errors.New("unauthorized to access this account export")
+	}
+	s.inProgress(export)
+
+	response := export.ToResponse()
+	if s.downloadable(export) {
+		if response.DownloadURL, err = s.downloadURL(export); err != nil {
+			return nil, err
+		}
+	}
+	return &response, nil
+}
+
+// OpenSignedExport verifies a link produced for a completed export and opens
+// its archive
+func (s *accountService) OpenSignedExport(ctx context.Context, id uuid.UUID, expires int64, signature string) (*models.AccountExport, io.ReadCloser, error) {
+	expected := s.sign(id, expires)
+	if !hmac.Equal([]byte(expected), []byte(signature)) || s.now().Unix() > expires {
+		return nil, nil, errors.New("invalid or expired download link")
+	}
+
+	export, err := s.exportRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, errors.New("account export not found")
+		}
+		return nil, nil, err
+	}
+	if !s.downloadable(export) {
+		return nil, nil, errors.New("account export not found")
+	}
+
+	content, err := s.store.Get(ctx, export.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil, errors.New("account export not found")
+		}
+		return nil, nil, err
+	}
+	return export, content, nil
+}
+
+func (s *accountService) Delete(ctx context.Context, userID uuid.UUID, req *models.AccountDeleteRequest) (*models.AccountDeletionResponse, error) {
+	if err := s.authService.Reauthenticate(userID, req); err != nil {
+		return nil, err
+	}
+	user, err := s.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.config.AccountDeletionGraceDays <= 0 {
+		if err := s.PurgeAccount(ctx, userID); err != nil {
+			return nil, err
+		}
+		return &models.AccountDeletionResponse{}, nil
+	}
+
+	// Asking again keeps the date of the first request
+	if user.DeletionScheduledAt == nil {
+		at := s.now().Add(time.Duration(s.config.AccountDeletionGraceDays) * 24 * time.Hour).Truncate(time.Second)
+		user.DeletionScheduledAt = &at
+		if err := s.userRepo.Update(user); err != nil {
+			return nil, err
+		}
+	}
+	return &models.AccountDeletionResponse{DeletionScheduledAt: user.DeletionScheduledAt}, nil
+}
+
+func (s *accountService) CancelDeletion(userID uuid.UUID) (*models.User, error) {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.DeletionScheduledAt == nil {
+		return nil, errors.New("account deletion is not scheduled")
+	}
+
+	user.DeletionScheduledAt = nil
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *accountService) FindDueForDeletion(scheduledBefore time.Time, limit int) ([]models.User, error) {
+	return s.userRepo.FindDueForDeletion(scheduledBefore, limit)
+}
+
+// PurgeAccount deletes a user and everything stored about them for good
+func (s *accountService) PurgeAccount(ctx context.Context, userID uuid.UUID) error {
+	// Files go first: if that fails the account stays to be retried
+	attachments, err := s.attachmentRepo.GetAllByUserID(userID)
+	if err != nil {
+		return err
+	}
+	for _, attachment := range attachments {
+		if err := s.store.Delete(ctx, attachment.StorageKey); err != nil {
+			return err
+		}
+	}
+	exports, err := s.exportRepo.GetByUserID(userID)
+	if err != nil {
+		return err
+	}
+	for _, export := range exports {
+		if export.StorageKey != "" {
+			if err := s.store.Delete(ctx, export.StorageKey); err != nil {
+				return err
+			}
+		}
+	}
+
+	return s.userRepo.Purge(userID)
+}
+
+func (s *accountService) FindExpiredExports(expiredBefore time.Time, limit int) ([]models.AccountExport, error) {
+	return s.exportRepo.FindExpired(expiredBefore, limit)
+}
+
+func (s *accountService) FailStaleExports() (int, error) {
+	if s.config.BackgroundJobTimeout <= 0 {
+		return 0, nil
+	}
+	count, err := s.exportRepo.FailStale(s.now().Add(-s.config.BackgroundJobTimeout), errExportStale.Error())
+	return int(count), err
+}
+
+// PurgeExport deletes an export and its archive
+func (s *accountService) PurgeExport(ctx context.Context, export *models.AccountExport) error {
+	if export.StorageKey != "" {
+		if err := s.store.Delete(ctx, export.StorageKey); err != nil {
+			return err
+		}
+	}
+	return s.exportRepo.Delete(export.ID)
+}
+
+// runExport builds the archive of an export in a temporary file, stores it
+// and notifies the user
+func (s *accountService) runExport(ctx context.Context, user *models.User, export *models.AccountExport) error {
+	// Skip exports that were given up on while they waited in the queue
+	current, err := s.exportRepo.GetByID(export.ID)
+	if err != nil {
+		return err
+	}
+	if current.Status != models.AccountExportPending {
+		return nil
+	}
+
+	export.Status = models.AccountExportRunning
+	if err := s.exportRepo.Update(export); err != nil {
+		return err
+	}
+
+	archive, err := s.archive(user)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp("", "account-export-*.zip")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	if err := archive.write(ctx, file, s.store); err != nil {
+		return err
+	}
+	size, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	key := fmt.Sprintf("accounts/%s/exports/%s.zip", user.ID, export.ID)
+	if err := s.store.Put(ctx, key, file, size, "application/zip"); err != nil {
+		return err
+	}
+
+	completed := s.now()
+	expires := completed.Add(s.config.AccountExportTTL).Truncate(time.Second)
+	export.Status = models.AccountExportCompleted
+	export.StorageKey = key
+	export.Size = size
+	export.CompletedAt = &completed
+	export.ExpiresAt = &expires
+	if err := s.exportRepo.Update(export); err != nil {
+		if delErr := s.store.Delete(ctx, key); delErr != nil {
+			log.Error().Err(delErr).Str("key", key).Msg("Failed to remove orphaned account export")
+		}
+		return err
+	}
+
+	s.notifyExportReady(ctx, user, export)
+	return nil
+}
+
+// archive gathers what goes into a user's export
+func (s *accountService) archive(user *models.User) (*accountArchive, error) {
+	comments, err := s.commentRepo.FindByAuthorID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	attachments, err := s.attachmentRepo.GetAllByUserID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	passwords, err := s.appPasswordRepo.GetByUserID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	archive := &accountArchive{
+		profile: user.ToResponse(),
+		todos: func(w io.Writer) error {
+			return s.exportService.Export(user.ID, &models.ExportRequest{Format: models.ExportFormatJSON, Complete: true}, w)
+		},
+		comments:    make([]models.CommentResponse, len(comments)),
+		attachments: attachments,
+		sessions:    make([]models.AppPasswordResponse, len(passwords)),
+	}
+	for i := range comments {
+		archive.comments[i] = comments[i].ToResponse()
+	}
+	for i := range passwords {
+		archive.sessions[i] = passwords[i].ToResponse()
+	}
+	return archive, nil
+}
+
+// inProgress reports whether an export is pending or running. An export
+// that stopped updating was lost, e.g. to a crash or a restart, and is marked
+// as failed instead.
+func (s *accountService) inProgress(export *models.AccountExport) bool {
+	if export.Status != models.AccountExportPending && export.Status != models.AccountExportRunning {
+		return false
+	}
+	if stale(export.UpdatedAt, s.config.BackgroundJobTimeout, s.now()) {
+		s.failExport(export, errExportStale)
+		return false
+	}
+	return true
+}
+
+func (s *accountService) failExport(export *models.AccountExport, err error) {
+	log.Error().Err(err).Str("export_id", export.ID.String()).Msg("Account export failed")
+
+	export.Status = models.AccountExportFailed
+	export.Error = err.Error()
+	if updateErr := s.exportRepo.Update(export); updateErr != nil {
+		log.Error().Err(updateErr).Str("export_id", export.ID.String()).Msg("Failed to record account export failure")
+	}
+}
+
+func (s *accountService) notifyExportReady(ctx context.Context, user *models.User, export *models.AccountExport) {
+	url, err := s.downloadURL(export)
+	if err != nil {
+		log.Error().Err(err).Str("export_id", export.ID.String()).Msg("Failed to sign account export link")
+		return
+	}
+
+	notification := notifier.Notification{
+		Event:     notifier.EventAccountExportReady,
+		UserID:    user.ID,
+		Email:     user.Email,
+		Title:     "Account export",
+		FireAt:    s.now(),
+		URL:       url,
+		ExpiresAt: export.ExpiresAt,
+	}
+
+	var channels []string
+	for _, channel := range accountExportChannels {
+		if _, ok := s.notifiers[channel]; ok {
+			channels = append(channels, channel)
+		}
+	}
+	if len(channels) == 0 {
+		channels = []string{models.ReminderChannelLog}
+	}
+	for _, channel := range channels {
+		if err := s.notifiers.Notify(ctx, channel, notification); err != nil {
+			log.Error().Err(err).Str("export_id", export.ID.String()).Str("channel", channel).Msg("Failed to notify about account export")
+		}
+	}
+}
+
+// downloadable reports whether an export has an archive that hasn't expired
+func (s *accountService) downloadable(export *models.AccountExport) bool {
+	return export.Status == models.AccountExportCompleted && export.StorageKey != "" &&
+		export.ExpiresAt != nil && s.now().Before(*export.ExpiresAt)
+}
+
+// downloadURL returns a link to the archive of an export that is valid until
+// the export expires. Stores that can presign URLs serve the download
+// directly; otherwise the link points at the API's signed download route.
+func (s *accountService) downloadURL(export *models.AccountExport) (string, error) {
+	if presigner, ok := s.store.(storage.Presigner); ok {
+		fileName := fmt.Sprintf("account-export-%s.zip", export.CompletedAt.UTC().Format("2006-01-02"))
+		return presigner.PresignGet(export.StorageKey, fileName, export.ExpiresAt.Sub(s.now()))
+	}
+
+	expires := export.ExpiresAt.Unix()
+	return fmt.Sprintf("%s/api/v1/auth/account/export/%s/download?expires=%d&signature=%s",
+		strings.TrimRight(s.config.PublicBaseURL, "/"), export.ID, expires, s.sign(export.ID, expires)), nil
+}
+
+func (s *accountService) sign(id uuid.UUID, expires int64) string {
+	key := s.config.AttachmentSigningKey
+	if key == "" {
+		key = s.config.JWTSecret
+	}
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte("account-export:" + id.String() + ":" + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *accountService) getUser(userID uuid.UUID) (*models.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+	return user, nil
+}
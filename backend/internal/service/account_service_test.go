@@ -0,0 +1,165 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+	"todo-backend/internal/config"
+	"todo-backend/internal/models"
+	"todo-backend/internal/repository"
+	"todo-backend/internal/storage"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountArchive(t *testing.T) {
+	store, err := storage.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	stored := models.Attachment{ID: uuid.New(), TodoID: uuid.New(), FileName: "notes.txt", StorageKey: "todos/a/b"}
+	missing := models.Attachment{ID: uuid.New(), TodoID: stored.TodoID, FileName: "gone.png", StorageKey: "todos/a/c"}
+	require.NoError(t, store.Put(context.Background(), stored.StorageKey, strings.NewReader("hello"), 5, "text/plain"))
+
+	archive := &accountArchive{
+		profile:     models.UserResponse{ID: uuid.New(), Email: "ana@example.com", Name: "Ana"},
+		todos:       func(w io.Writer) error { _, err := io.WriteString(w, `{"version":1}`); return err },
+		comments:    []models.CommentResponse{{ID: uuid.New(), Body: "Done?"}},
+		attachments: []models.Attachment{stored, missing},
+		sessions:    []models.AppPasswordResponse{{ID: uuid.New(), Name: "Phone"}},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, archive.write(context.Background(), &buf, store))
+
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	files := make(map[string]string)
+	for _, file := range reader.File {
+		r, err := file.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		files[file.Name] = string(data)
+	}
+
+	path := "attachments/" + stored.TodoID.String() + "/" + stored.ID.String() + "-notes.txt"
+	assert.Len(t, files, 6)
+	assert.Equal(t, `{"version":1}`, files["todos.json"])
+	assert.Equal(t, "hello", files[path])
+	assert.Contains(t, files["profile.json"], "ana@example.com")
+	assert.Contains(t, files["comments.json"], "Done?")
+	assert.Contains(t, files["sessions.json"], "Phone")
+
+	var attachments []accountArchiveAttachment
+	require.NoError(t, json.Unmarshal([]byte(files["attachments.json"]), &attachments))
+	require.Len(t, attachments, 2)
+	assert.Equal(t, path, attachments[0].Path)
+	assert.Empty(t, attachments[1].Path)
+}
+
+func TestAccountExportDownloadURL(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	s := &accountService{
+		config: &config.Config{JWTSecret: "secret", PublicBaseURL: "https://todo.example.com/"},
+		now:    func() time.Time { return now },
+	}
+
+	expires := now.Add(time.Hour)
+	export := &models.AccountExport{
+		ID: uuid.New(), Status: models.AccountExportCompleted, StorageKey: "accounts/a/exports/b.zip",
+		CompletedAt: &now, ExpiresAt: &expires,
+	}
+	assert.True(t, s.downloadable(export))
+
+	url, err := s.downloadURL(export)
+	require.NoError(t, err)
+	signature := s.sign(export.ID, expires.Unix())
+	assert.Equal(t, "https://todo.example.com/api/v1/auth/account/export/"+export.ID.String()+"/download?expires=1709298000&signature="+signature, url)
+
+	// The signature is bound to the export and to the expiry
+	assert.NotEqual(t, signature, s.sign(uuid.New(), expires.Unix()))
+	assert.NotEqual(t, signature, s.sign(export.ID, expires.Unix()+1))
+
+	// Links to attachments can't be passed off as links to exports
+	attachments := &attachmentService{config: s.config}
+	assert.NotEqual(t, signature, attachments.sign(export.ID, expires.Unix()))
+
+	later := expires.Add(time.Second)
+	s.now = func() time.Time { return later }
+	assert.False(t, s.downloadable(export))
+}
+
+// accountExports keeps account exports in memory
+type accountExports struct {
+	repository.AccountExportRepository
+	exports []models.AccountExport
+}
+
+func (r *accountExports) Create(export *models.AccountExport) error {
+	export.ID = uuid.New()
+	r.exports = append(r.exports, *export)
+	return nil
+}
+
+func (r *accountExports) GetByUserID(userID uuid.UUID) ([]models.AccountExport, error) {
+	return append([]models.AccountExport(nil), r.exports...), nil
+}
+
+func (r *accountExports) Update(export *models.AccountExport) error {
+	for i := range r.exports {
+		if r.exports[i].ID == export.ID {
+			r.exports[i] = *export
+		}
+	}
+	return nil
+}
+
+// queuedJobs records background jobs instead of running them
+type queuedJobs struct {
+	jobs []func(ctx context.Context)
+}
+
+func (q *queuedJobs) Enqueue(job func(ctx context.Context)) error {
+	q.jobs = append(q.jobs, job)
+	return nil
+}
+
+func TestRequestExportReplacesStaleExports(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	user := &models.User{ID: uuid.New()}
+	users := new(MockUserRepository)
+	users.On("GetByID", user.ID).Return(user, nil)
+
+	running := models.AccountExport{ID: uuid.New(), UserID: user.ID, Status: models.AccountExportRunning, UpdatedAt: now.Add(-time.Minute)}
+	exports := &accountExports{exports: []models.AccountExport{running}}
+	queue := &queuedJobs{}
+	s := &accountService{
+		userRepo:   users,
+		exportRepo: exports,
+		queue:      queue,
+		config:     &config.Config{BackgroundJobTimeout: 30 * time.Minute},
+		now:        func() time.Time { return now },
+	}
+
+	_, err := s.RequestExport(user.ID)
+	assert.EqualError(t, err, "an account export is already in progress")
+	assert.Empty(t, queue.jobs)
+
+	// Once the running export stops updating it no longer blocks new ones
+	now = now.Add(time.Hour)
+	export, err := s.RequestExport(user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.AccountExportPending, export.Status)
+	assert.Len(t, queue.jobs, 1)
+
+	require.Len(t, exports.exports, 2)
+	assert.Equal(t, models.AccountExportFailed, exports.exports[0].Status)
+	assert.Equal(t, errExportStale.Error(), exports.exports[0].Error)
+}
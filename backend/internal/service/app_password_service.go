@@ -0,0 +1,141 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+	"todo-backend/internal/models"
+	"todo-backend/internal/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// App passwords are appPasswordGroups groups of appPasswordGroupSize lower-case
+// letters, e.g. "abcd-efgh-ijkl-mnop": about 75 bits that are easy to type on
+// a phone
+const (
+	appPasswordGroups    = 4
+	appPasswordGroupSize = 4
+	appPasswordAlphabet  = "abcdefghijklmnopqrstuvwxyz"
+)
+
+// appPasswordTouchInterval limits how often last use is written
+const appPasswordTouchInterval = time.Hour
+
+type AppPasswordService interface {
+	// Create returns the new password together with its plain text
+	Create(userID uuid.UUID, req *models.AppPasswordCreateRequest) (*models.AppPassword, string, error)
+	GetByUserID(userID uuid.UUID) ([]models.AppPassword, error)
+	Delete(id uuid.UUID, userID uuid.UUID) error
+
+	// Authenticate returns the user owning password; email, when not empty,
+	// must be theirs
+	Authenticate(email, password string) (*models.User, error)
+}
+
+type appPasswordService struct {
+	passwordRepo repository.AppPasswordRepository
+	now          func() time.Time
+}
+
+func NewAppPasswordService(passwordRepo repository.AppPasswordRepository) AppPasswordService {
+	return &appPasswordService{
+		passwordRepo: passwordRepo,
+		now:          time.Now,
+	}
+}
+
+func (s *appPasswordService) Create(userID uuid.UUID, req *models.AppPasswordCreateRequest) (*models.AppPassword, string, error) {
+	plain, err := generateAppPassword()
+	if err != nil {
+		return nil, "", err
+	}
+
+	password := &models.AppPassword{
+		UserID:    userID,
+		Name:      req.Name,
+		TokenHash: hashAppPassword(plain),
+	}
+	if err := s.passwordRepo.Create(password); err != nil {
+		return nil, "", err
+	}
+	return password, plain, nil
+}
+
+func (s *appPasswordService) GetByUserID(userID uuid.UUID) ([]models.AppPassword, error) {
+	return s.passwordRepo.GetByUserID(userID)
+}
+
+func (s *appPasswordService) Delete(id uuid.UUID, userID uuid.UUID) error {
+	password, err := s.passwordRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("app password not found")
+		}
+		return err
+	}
+	if password.UserID != userID {
+		return errors.New("unauthorized to delete this app password")
+	}
+	return s.passwordRepo.Delete(id)
+}
+
+func (s *appPasswordService) Authenticate(email, plain string) (*models.User, error) {
+	password, err := s.passwordRepo.GetByTokenHash(hashAppPassword(plain))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("invalid app password")
+		}
+		return nil, err
+	}
+	user := &password.User
+	if email != "" && subtle.ConstantTimeCompare([]byte(strings.ToLower(email)), []byte(strings.ToLower(user.Email))) != 1 {
+		return nil, errors.New("invalid app password")
+	}
+	if !user.IsActive {
+		return nil, errors.New("user account is deactivated")
+	}
+
+	now := s.now()
+	if password.LastUsedAt == nil || now.Sub(*password.LastUsedAt) >= appPasswordTouchInterval {
+		if err := s.passwordRepo.Touch(password.ID, now); err != nil {
+			return nil, err
+		}
+	}
+	return user, nil
+}
+
+func generateAppPassword() (string, error) {
+	random := make([]byte, appPasswordGroups*appPasswordGroupSize)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for i, r := range random {
+		if i > 0 && i%appPasswordGroupSize == 0 {
+			b.WriteByte('-')
+		}
+		// 256 is not a multiple of 26; the bias is negligible here
+		b.WriteByte(appPasswordAlphabet[int(r)%len(appPasswordAlphabet)])
+	}
+	return b.String(), nil
+}
+
+// hashAppPassword hashes a password ignoring case, spaces and dashes, which
+// people add or drop when copying it by hand
+func hashAppPassword(plain string) string {
+	normalized := strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(plain))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateAppPassword(t *testing.T) {
+	format := regexp.MustCompile(`^[a-z]{4}-[a-z]{4}-[a-z]{4}-[a-z]{4}$`)
+	seen := make(map[string]bool)
+	for i := 0; i < 50; i++ {
+		password, err := generateAppPassword()
+		require.NoError(t, err)
+		assert.Regexp(t, format, password)
+		assert.False(t, seen[password], "duplicate password %s", password)
+		seen[password] = true
+	}
+}
+
+func TestHashAppPassword(t *testing.T) {
+	hash := hashAppPassword("abcd-efgh-ijkl-mnop")
+	assert.Len(t, hash, 64)
+
+	for _, typed := range []string{"ABCD-EFGH-IJKL-MNOP", "abcdefghijklmnop", "abcd efgh ijkl mnop"} {
+		assert.Equal(t, hash, hashAppPassword(typed), typed)
+	}
+	assert.NotEqual(t, hash, hashAppPassword("abcd-efgh-ijkl-mnoq"))
+}
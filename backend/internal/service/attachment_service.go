@@ -0,0 +1,270 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"todo-backend/internal/config"
+	"todo-backend/internal/models"
+	"todo-backend/internal/repository"
+	"todo-backend/internal/storage"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+type AttachmentService interface {
+	Upload(ctx context.Context, todoID uuid.UUID, userID uuid.UUID, fileName string, size int64, content io.Reader) (*models.Attachment, error)
+	GetByTodoID(todoID uuid.UUID, userID uuid.UUID) ([]models.Attachment, error)
+	GetDownloadURL(id uuid.UUID, todoID uuid.UUID, userID uuid.UUID) (*models.AttachmentDownloadResponse, error)
+	OpenSignedDownload(ctx context.Context, id uuid.UUID, expires int64, signature string) (*models.Attachment, io.ReadCloser, error)
+	Delete(ctx context.Context, id uuid.UUID, todoID uuid.UUID, userID uuid.UUID) error
+	DeleteBlobsForTodo(ctx context.Context, todoID uuid.UUID) error
+}
+
+type attachmentService struct {
+	attachmentRepo repository.AttachmentRepository
+	todoRepo       repository.TodoRepository
+	store          storage.BlobStore
+	config         *config.Config
+}
+
+func NewAttachmentService(attachmentRepo repository.AttachmentRepository, todoRepo repository.TodoRepository, store storage.BlobStore, cfg *config.Config) AttachmentService {
+	return &attachmentService{
+		attachmentRepo: attachmentRepo,
+		todoRepo:       todoRepo,
+		store:          store,
+		config:         cfg,
+	}
+}
+
+func (s *attachmentService) Upload(ctx context.Context, todoID uuid.UUID, userID uuid.UUID, fileName string, size int64, content io.Reader) (*models.Attachment, error) {
+	if _, err := s.getOwnedTodo(todoID, userID); err != nil {
+		return nil, err
+	}
+
+	if size > s.config.AttachmentMaxSize {
+		return nil, fmt.Errorf("file exceeds the maximum size of %d bytes", s.config.AttachmentMaxSize)
+	}
+
+	// Trust the content, not the client: sniff the type from the first bytes
+	buffered := bufio.NewReaderSize(content, 512)
+	head, _ := buffered.Peek(512)
+	contentType := detectContentType(head, fileName)
+	if !s.isAllowedType(contentType) {
+		return nil, fmt.Errorf("file type %s is not allowed", contentType)
+	}
+
+	attachment := &models.Attachment{
+		ID:          uuid.New(),
+		TodoID:      todoID,
+		UserID:      userID,
+		FileName:    sanitizeFileName(fileName),
+		ContentType: contentType,
+		Size:        size,
+	}
+	attachment.StorageKey = fmt.Sprintf("todos/%s/%s", todoID, attachment.ID)
+
+	hash := sha256.New()
+	reader := io.TeeReader(io.LimitReader(buffered, size), hash)
+	if err := s.store.Put(ctx, attachment.StorageKey, reader, size, contentType); err != nil {
+		return nil, err
+	}
+	attachment.Checksum = hex.EncodeToString(hash.Sum(nil))
+
+	if err := s.attachmentRepo.Create(attachment); err != nil {
+		// Don't leave an orphaned blob behind
+		if delErr := s.store.Delete(ctx, attachment.StorageKey); delErr != nil {
+			log.Error().Err(delErr).Str("key", attachment.StorageKey).Msg("Failed to remove orphaned blob")
+		}
+		return nil, err
+	}
+	return attachment, nil
+}
+
+func (s *attachmentService) GetByTodoID(todoID uuid.UUID, userID uuid.UUID) ([]models.Attachment, error) {
+	if _, err := s.getOwnedTodo(todoID, userID); err != nil {
+		return nil, err
+	}
+	return s.attachmentRepo.GetByTodoID(todoID)
+}
+
+// GetDownloadURL returns a time-limited link to the attachment's content.
+// Stores that can presign URLs serve the download directly; otherwise the
+// link points at the API's signed download route.
+func (s *attachmentService) GetDownloadURL(id uuid.UUID, todoID uuid.UUID, userID uuid.UUID) (*models.AttachmentDownloadResponse, error) {
+	if _, err := s.getOwnedTodo(todoID, userID); err != nil {
+		return nil, err
+	}
+	attachment, err := s.getAttachment(id, todoID)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := s.config.AttachmentURLTTL
+	expiresAt := time.Now().Add(ttl).Truncate(time.Second)
+
+	if presigner, ok := s.store.(storage.Presigner); ok {
+		url, err := presigner.PresignGet(attachment.StorageKey, attachment.FileName, ttl)
+		if err != nil {
+			return nil, err
+		}
+		return &models.AttachmentDownloadResponse{URL: url, ExpiresAt: expiresAt}, nil
+	}
+
+	expires := expiresAt.Unix()
+	url := fmt.Sprintf("%s/api/v1/attachments/%s/download?expires=%d&signature=%s",
+		strings.TrimRight(s.config.PublicBaseURL, "/"), attachment.ID, expires, s.sign(attachment.ID, expires))
+	return &models.AttachmentDownloadResponse{URL: url, ExpiresAt: expiresAt}, nil
+}
+
+// OpenSignedDownload verifies a link produced by GetDownloadURL and opens the blob
+func (s *attachmentService) OpenSignedDownload(ctx context.Context, id uuid.UUID, expires int64, signature string) (*models.Attachment, io.ReadCloser, error) {
+	expected := s.sign(id, expires)
+	if !hmac.Equal([]byte(expected), []byte(signature)) || time.Now().Unix() > expires {
+		return nil, nil, errors.New("invalid or expired download link")
+	}
+
+	attachment, err := s.attachmentRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, errors.New("attachment not found")
+		}
+		return nil, nil, err
+	}
+
+	content, err := s.store.Get(ctx, attachment.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil, errors.New("attachment not found")
+		}
+		return nil, nil, err
+	}
+	return attachment, content, nil
+}
+
+func (s *attachmentService) Delete(ctx context.Context, id uuid.UUID, todoID uuid.UUID, userID uuid.UUID) error {
+	if _, err := s.getOwnedTodo(todoID, userID); err != nil {
+		return err
+	}
+	attachment, err := s.getAttachment(id, todoID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.attachmentRepo.Delete(attachment.ID); err != nil {
+		return err
+	}
+	return s.store.Delete(ctx, attachment.StorageKey)
+}
+
+// DeleteBlobsForTodo removes the stored files of every attachment of a todo,
+// including deleted ones, before the todo is purged. The rows go with the todo.
+func (s *attachmentService) DeleteBlobsForTodo(ctx context.Context, todoID uuid.UUID) error {
+	attachments, err := s.attachmentRepo.GetAllByTodoID(todoID)
+	if err != nil {
+		return err
+	}
+
+	for _, attachment := range attachments {
+		if err := s.store.Delete(ctx, attachment.StorageKey); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *attachmentService) getAttachment(id uuid.UUID, todoID uuid.UUID) (*models.Attachment, error) {
+	attachment, err := s.attachmentRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("attachment not found")
+		}
+		return nil, err
+	}
+	if attachment.TodoID != todoID {
+		return nil, errors.New("attachment not found")
+	}
+	return attachment, nil
+}
+
+func (s *attachmentService) getOwnedTodo(todoID uuid.UUID, userID uuid.UUID) (*models.Todo, error) {
+	todo, err := s.todoRepo.GetByID(todoID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("todo not found")
+		}
+		return nil, err
+	}
+	if todo.UserID != userID {
+		return nil, errors.New("unauthorized to access this todo")
+	}
+	return todo, nil
+}
+
+func (s *attachmentService) isAllowedType(contentType string) bool {
+	for _, allowed := range s.config.AttachmentAllowedTypes {
+		allowed = strings.TrimSpace(allowed)
+		if allowed == contentType {
+			return true
+		}
+		// Support wildcards such as "image/*"
+		if strings.HasSuffix(allowed, "/*") && strings.HasPrefix(contentType, strings.TrimSuffix(allowed, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *attachmentService) sign(id uuid.UUID, expires int64) string {
+	key := s.config.AttachmentSigningKey
+	if key == "" {
+		key = s.config.JWTSecret
+	}
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(id.String() + ":" + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// detectContentType sniffs the media type of content. Formats the sniffer
+// can't tell apart from plain text fall back to the file extension.
+func detectContentType(head []byte, fileName string) string {
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	if contentType == "text/plain" || contentType == "application/octet-stream" {
+		if byExt := mime.TypeByExtension(strings.ToLower(filepath.Ext(fileName))); byExt != "" {
+			if parsed, _, err := mime.ParseMediaType(byExt); err == nil && strings.HasPrefix(parsed, "text/") {
+				return parsed
+			}
+		}
+	}
+	return contentType
+}
+
+func sanitizeFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+	if name == "" || name == "." || name == "/" {
+		return "attachment"
+	}
+	if len(name) > 255 {
+		name = name[:255]
+	}
+	return name
+}
@@ -36,6 +36,14 @@ type AuthService interface {
 	// Traditional auth methods (for future use)
 	RegisterUser(req *models.UserCreateRequest) (*models.User, error)
 	LoginUser(email, password string) (*models.LoginResponse, error)
+
+	// Profile and preferences
+	GetUser(userID uuid.UUID) (*models.User, error)
+	UpdatePreferences(userID uuid.UUID, req *models.UserPreferencesRequest) (*models.User, error)
+
+	// Reauthenticate checks the password of an email account or a fresh
+	// Apple authorization code before a sensitive change
+	Reauthenticate(userID uuid.UUID, req *models.AccountDeleteRequest) error
 }
 
 type authService struct {
@@ -396,6 +404,65 @@ func (s *authService) LoginUser(email, password string) (*models.LoginResponse,
 	return s.GenerateTokenPair(user.ID, user.Email, "")
 }
 
+// GetUser returns the profile of a user
+func (s *authService) GetUser(userID uuid.UUID) (*models.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	return user, nil
+}
+
+// UpdatePreferences changes the timezone and week start used for date buckets
+func (s *authService) UpdatePreferences(userID uuid.UUID, req *models.UserPreferencesRequest) (*models.User, error) {
+	user, err := s.GetUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Timezone != "" {
+		if _, err := time.LoadLocation(req.Timezone); err != nil {
+			return nil, fmt.Errorf("invalid timezone: %w", err)
+		}
+		user.Timezone = req.Timezone
+	}
+	if req.WeekStart != "" {
+		user.WeekStart = req.WeekStart
+	}
+
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// Reauthenticate verifies the credentials of the signed-in user again
+func (s *authService) Reauthenticate(userID uuid.UUID, req *models.AccountDeleteRequest) error {
+	user, err := s.GetUser(userID)
+	if err != nil {
+		return err
+	}
+
+	if user.AuthProvider == "apple" {
+		if req.AppleCode == "" {
+			return errors.New("reauthentication required: apple_code is missing")
+		}
+		appleUserInfo, err := s.ValidateAppleToken(req.AppleCode)
+		if err != nil || appleUserInfo.Sub != user.AppleID {
+			return errors.New("invalid credentials")
+		}
+		return nil
+	}
+
+	if req.Password == "" {
+		return errors.New("reauthentication required: password is missing")
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		return errors.New("invalid credentials")
+	}
+	return nil
+}
+
 // Helper method to generate JWT tokens
 func (s *authService) generateJWT(claims models.JWTClaims) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
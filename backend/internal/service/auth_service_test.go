@@ -10,6 +10,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
 )
 
 // Mock repository for testing
@@ -46,6 +47,14 @@ func (m *MockUserRepository) GetByAppleID(appleID string) (*models.User, error)
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
+func (m *MockUserRepository) GetByCalendarToken(token string) (*models.User, error) {
+	args := m.Called(token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
 func (m *MockUserRepository) Update(user *models.User) error {
 	args := m.Called(user)
 	return args.Error(0)
@@ -61,6 +70,16 @@ func (m *MockUserRepository) List(offset, limit int) ([]models.User, int64, erro
 	return args.Get(0).([]models.User), args.Get(1).(int64), args.Error(2)
 }
 
+func (m *MockUserRepository) FindDueForDeletion(scheduledBefore time.Time, limit int) ([]models.User, error) {
+	args := m.Called(scheduledBefore, limit)
+	return args.Get(0).([]models.User), args.Error(1)
+}
+
+func (m *MockUserRepository) Purge(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
 func setupAuthService() (*authService, *MockUserRepository) {
 	mockRepo := new(MockUserRepository)
 	cfg := &config.Config{
@@ -333,4 +352,38 @@ func TestRegisterUser_UserExists(t *testing.T) {
 	assert.Contains(t, err.Error(), "user already exists")
 
 	mockRepo.AssertExpectations(t)
-} 
\ No newline at end of file
+}
+
+func TestReauthenticate_Password(t *testing.T) {
+	service, mockRepo := setupAuthService()
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	assert.NoError(t, err)
+	user := &models.User{ID: uuid.New(), Email: "test@example.com", Password: string(hashed), AuthProvider: "email"}
+	mockRepo.On("GetByID", user.ID).Return(user, nil)
+
+	assert.NoError(t, service.Reauthenticate(user.ID, &models.AccountDeleteRequest{Password: "password123"}))
+
+	err = service.Reauthenticate(user.ID, &models.AccountDeleteRequest{Password: "wrong"})
+	assert.EqualError(t, err, "invalid credentials")
+
+	err = service.Reauthenticate(user.ID, &models.AccountDeleteRequest{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "reauthentication required")
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestReauthenticate_AppleNeedsCode(t *testing.T) {
+	service, mockRepo := setupAuthService()
+
+	user := &models.User{ID: uuid.New(), Email: "test@example.com", AppleID: "test-apple-id", AuthProvider: "apple"}
+	mockRepo.On("GetByID", user.ID).Return(user, nil)
+
+	// A password is no proof for an Apple account
+	err := service.Reauthenticate(user.ID, &models.AccountDeleteRequest{Password: "password123"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "apple_code is missing")
+
+	mockRepo.AssertExpectations(t)
+}
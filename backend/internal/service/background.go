@@ -0,0 +1,18 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+// BackgroundQueue runs jobs outside of the request that started them, with a
+// context that is cancelled when the server shuts down; see scheduler.Worker
+type BackgroundQueue interface {
+	Enqueue(job func(ctx context.Context)) error
+}
+
+// stale reports whether a background job last touched at updatedAt has
+// stopped making progress, e.g. because the server running it went away
+func stale(updatedAt time.Time, timeout time.Duration, now time.Time) bool {
+	return timeout > 0 && updatedAt.Before(now.Add(-timeout))
+}
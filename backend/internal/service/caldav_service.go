@@ -0,0 +1,515 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"todo-backend/internal/models"
+	"todo-backend/internal/repository"
+	"todo-backend/pkg/ical"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// caldavClientID is recorded in the history of todos changed over CalDAV
+const caldavClientID = "caldav"
+
+// caldavDefaultHour is the time of day given to due dates without one
+const caldavDefaultHour = 9
+
+// Limits of the todo fields a VTODO is mapped onto
+const (
+	caldavMaxTitle       = 255
+	caldavMaxDescription = 1000
+	caldavMaxTags        = 20
+	caldavMaxTag         = 50
+)
+
+// caldavPriorities maps iCalendar priorities 1 (high) to 9 (low) onto 5 to 1;
+// it inverts calendarPriority
+var caldavPriorities = [10]int{0, 5, 5, 4, 4, 3, 2, 2, 1, 1}
+
+type CalDAVService interface {
+	// Principal returns the user a principal resource describes
+	Principal(userID uuid.UUID) (*models.User, error)
+	Calendars(userID uuid.UUID) ([]models.CalDAVCalendar, error)
+	Calendar(userID uuid.UUID, calendarID string) (*models.CalDAVCalendar, error)
+	Objects(userID uuid.UUID, calendarID string) ([]models.CalDAVObject, error)
+	Object(userID uuid.UUID, calendarID, name string) (*models.CalDAVObject, error)
+
+	// Put creates or replaces the todo stored at name from an iCalendar
+	// object, reporting whether it was created
+	Put(userID uuid.UUID, calendarID, name string, body io.Reader, conditions models.CalDAVConditions) (*models.CalDAVObject, bool, error)
+	Delete(userID uuid.UUID, calendarID, name string, conditions models.CalDAVConditions) error
+}
+
+type caldavService struct {
+	todoRepo    repository.TodoRepository
+	listRepo    repository.ListRepository
+	userRepo    repository.UserRepository
+	todoService TodoService
+}
+
+// NewCalDAVService creates the CalDAV service; changes go through
+// todoService so that they follow the same rules as API requests
+func NewCalDAVService(todoRepo repository.TodoRepository, listRepo repository.ListRepository, userRepo repository.UserRepository, todoService TodoService) CalDAVService {
+	return &caldavService{
+		todoRepo:    todoRepo,
+		listRepo:    listRepo,
+		userRepo:    userRepo,
+		todoService: todoService.ForClient(caldavClientID),
+	}
+}
+
+func (s *caldavService) Principal(userID uuid.UUID) (*models.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *caldavService) Calendars(userID uuid.UUID) ([]models.CalDAVCalendar, error) {
+	lists, err := s.listRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	calendars := []models.CalDAVCalendar{{ID: models.CalDAVInbox, Name: "Inbox"}}
+	for _, list := range lists {
+		listID := list.ID
+		calendars = append(calendars, models.CalDAVCalendar{ID: list.ID.String(), ListID: &listID, Name: list.Name})
+	}
+	for i := range calendars {
+		ctag, err := s.todoRepo.CollectionTag(userID, calendars[i].ListID)
+		if err != nil {
+			return nil, err
+		}
+		calendars[i].CTag = ctag
+	}
+	return calendars, nil
+}
+
+func (s *caldavService) Calendar(userID uuid.UUID, calendarID string) (*models.CalDAVCalendar, error) {
+	calendar := &models.CalDAVCalendar{ID: models.CalDAVInbox, Name: "Inbox"}
+	if calendarID != models.CalDAVInbox {
+		listID, err := uuid.Parse(calendarID)
+		if err != nil {
+			return nil, errors.New("calendar not found")
+		}
+		list, err := s.listRepo.GetByID(listID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		if err != nil || list.UserID != userID {
+			return nil, errors.New("calendar not found")
+		}
+		calendar = &models.CalDAVCalendar{ID: list.ID.String(), ListID: &list.ID, Name: list.Name}
+	}
+
+	ctag, err := s.todoRepo.CollectionTag(userID, calendar.ListID)
+	if err != nil {
+		return nil, err
+	}
+	calendar.CTag = ctag
+	return calendar, nil
+}
+
+func (s *caldavService) Objects(userID uuid.UUID, calendarID string) ([]models.CalDAVObject, error) {
+	calendar, err := s.Calendar(userID, calendarID)
+	if err != nil {
+		return nil, err
+	}
+	todos, err := s.todoRepo.FindInCollection(userID, calendar.ListID)
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make([]models.CalDAVObject, 0, len(todos))
+	for i := range todos {
+		object, err := caldavObject(&todos[i])
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, *object)
+	}
+	return objects, nil
+}
+
+func (s *caldavService) Object(userID uuid.UUID, calendarID, name string) (*models.CalDAVObject, error) {
+	calendar, err := s.Calendar(userID, calendarID)
+	if err != nil {
+		return nil, err
+	}
+	todo, err := s.find(userID, calendar, name)
+	if err != nil {
+		return nil, err
+	}
+	if todo == nil {
+		return nil, errors.New("calendar object not found")
+	}
+	return caldavObject(todo)
+}
+
+// Put checks the request's conditions and writes the todo in one transaction
+// holding the todo's row lock, so a concurrent write can't slip in between
+func (s *caldavService) Put(userID uuid.UUID, calendarID, name string, body io.Reader, conditions models.CalDAVConditions) (*models.CalDAVObject, bool, error) {
+	calendar, err := s.Calendar(userID, calendarID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var created bool
+	err = s.todoService.Transaction(func(tx TodoService, todos repository.TodoRepository) error {
+		existing, err := findObject(todos.LockByCalDAVName, userID, calendar, name)
+		if err != nil {
+			return err
+		}
+		if err := checkConditions(existing, conditions); err != nil {
+			return err
+		}
+		if existing == nil {
+			// Names are unique among the user's todos, not just in one calendar
+			if _, err := todos.GetByCalDAVName(userID, name); !errors.Is(err, gorm.ErrRecordNotFound) {
+				if err != nil {
+					return err
+				}
+				return errors.New("calendar object UID conflict")
+			}
+		}
+
+		cal, err := ical.Decode(body)
+		if err != nil {
+			return fmt.Errorf("invalid calendar object: %w", err)
+		}
+		user, err := s.Principal(userID)
+		if err != nil {
+			return err
+		}
+		fields, err := parseVTodo(cal, user.Location())
+		if err != nil {
+			return err
+		}
+
+		// UIDs are unique among the user's todos and don't change
+		owner, err := todos.GetByICalUID(userID, fields.UID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if owner != nil && (existing == nil || owner.ID != existing.ID) || existing != nil && existing.UID() != fields.UID {
+			return errors.New("calendar object UID conflict")
+		}
+
+		var parentID *uuid.UUID
+		if fields.ParentUID != "" {
+			parent, err := todos.GetByICalUID(userID, fields.ParentUID)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			// Parents the server doesn't know yet are left out
+			if parent != nil {
+				parentID = &parent.ID
+			}
+		}
+
+		if existing == nil {
+			req := fields.createRequest(calendar.ListID)
+			req.ParentID = parentID
+			req.ICalUID = &fields.UID
+			req.CalDAVName = &name
+			created = true
+			_, err = tx.Create(userID, req)
+		} else {
+			_, err = tx.Update(existing.ID, userID, fields.updateRequest(existing, parentID))
+		}
+		return caldavConflict(err)
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	// Reload for the stored timestamp the ETag is derived from
+	todo, err := s.find(userID, calendar, name)
+	if err != nil {
+		return nil, false, err
+	}
+	if todo == nil {
+		return nil, false, errors.New("calendar object not found")
+	}
+	object, err := caldavObject(todo)
+	if err != nil {
+		return nil, false, err
+	}
+	return object, created, nil
+}
+
+func (s *caldavService) Delete(userID uuid.UUID, calendarID, name string, conditions models.CalDAVConditions) error {
+	calendar, err := s.Calendar(userID, calendarID)
+	if err != nil {
+		return err
+	}
+
+	return s.todoService.Transaction(func(tx TodoService, todos repository.TodoRepository) error {
+		todo, err := findObject(todos.LockByCalDAVName, userID, calendar, name)
+		if err != nil {
+			return err
+		}
+		if todo == nil {
+			return errors.New("calendar object not found")
+		}
+		if err := checkConditions(todo, conditions); err != nil {
+			return err
+		}
+		return tx.Delete(todo.ID, userID)
+	})
+}
+
+// find returns the todo stored at name in calendar, or nil
+func (s *caldavService) find(userID uuid.UUID, calendar *models.CalDAVCalendar, name string) (*models.Todo, error) {
+	return findObject(s.todoRepo.GetByCalDAVName, userID, calendar, name)
+}
+
+// findObject looks a todo up by name with get and returns it when it is
+// stored in calendar, nil otherwise
+func findObject(get func(uuid.UUID, string) (*models.Todo, error), userID uuid.UUID, calendar *models.CalDAVCalendar, name string) (*models.Todo, error) {
+	todo, err := get(userID, name)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !sameListID(todo.ListID, calendar.ListID) {
+		return nil, nil
+	}
+	return todo, nil
+}
+
+// caldavConflict maps a duplicate CalDAV name or UID, written by a concurrent
+// request after the checks above passed, onto the error those checks give
+func caldavConflict(err error) error {
+	switch {
+	case repository.IsUniqueViolation(err, repository.TodoCalDAVNameIndex):
+		return errors.New("precondition failed")
+	case repository.IsUniqueViolation(err, repository.TodoICalUIDIndex):
+		return errors.New("calendar object UID conflict")
+	}
+	return err
+}
+
+// checkConditions evaluates If-Match and If-None-Match against the todo
+// currently stored, nil when there is none
+func checkConditions(todo *models.Todo, conditions models.CalDAVConditions) error {
+	if match := conditions.IfMatch; match != "" {
+		if todo == nil || match != "*" && !etagListed(match, caldavETag(todo)) {
+			return errors.New("precondition failed")
+		}
+	}
+	if noneMatch := conditions.IfNoneMatch; noneMatch != "" && todo != nil {
+		if noneMatch == "*" || etagListed(noneMatch, caldavETag(todo)) {
+			return errors.New("precondition failed")
+		}
+	}
+	return nil
+}
+
+// etagListed reports whether a comma-separated If-Match list contains etag;
+// weak tags match too
+func etagListed(list, etag string) bool {
+	for _, candidate := range strings.Split(list, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+func caldavETag(todo *models.Todo) string {
+	return `"` + todo.Version() + `"`
+}
+
+// caldavObject encodes a todo as a calendar object resource
+func caldavObject(todo *models.Todo) (*models.CalDAVObject, error) {
+	cal := ical.NewComponent("VCALENDAR").
+		Set("VERSION", "2.0").
+		Set("PRODID", calendarProductID).
+		Add(todoVTodo(todo))
+
+	var data strings.Builder
+	if err := cal.Encode(&data); err != nil {
+		return nil, err
+	}
+
+	name := todo.ID.String() + ".ics"
+	if todo.CalDAVName != nil {
+		name = *todo.CalDAVName
+	}
+	return &models.CalDAVObject{
+		Name: name,
+		ETag: caldavETag(todo),
+		Data: data.String(),
+		Todo: todo,
+	}, nil
+}
+
+// vtodoFields are the todo fields a VTODO sets
+type vtodoFields struct {
+	UID         string
+	Title       string
+	Description string
+	Status      models.TodoStatus
+	Priority    int
+	Due         *time.Time
+	Tags        []string
+	ParentUID   string
+}
+
+// parseVTodo reads the VTODO of a calendar object. Due dates without a time
+// are due at caldavDefaultHour in loc. Recurrence rules are not read: series
+// are managed by the server.
+func parseVTodo(cal *ical.Component, loc *time.Location) (*vtodoFields, error) {
+	if cal.Name != "VCALENDAR" {
+		return nil, errors.New("invalid calendar object: expected a VCALENDAR")
+	}
+
+	// Overridden occurrences carry a RECURRENCE-ID; the master comes first
+	var vtodo *ical.Component
+	for _, child := range cal.Children("VTODO") {
+		if child.Get("RECURRENCE-ID") == nil {
+			vtodo = child
+			break
+		}
+	}
+	if vtodo == nil {
+		return nil, errors.New("unsupported calendar component: expected a VTODO")
+	}
+
+	fields := &vtodoFields{Status: models.TodoStatusPending, Tags: []string{}}
+	if uid := vtodo.Get("UID"); uid != nil {
+		fields.UID = strings.TrimSpace(uid.Value)
+	}
+	if fields.UID == "" || len(fields.UID) > 255 {
+		return nil, errors.New("invalid calendar object: a UID of up to 255 characters is required")
+	}
+
+	if summary := vtodo.Get("SUMMARY"); summary != nil {
+		fields.Title = truncateRunes(strings.TrimSpace(summary.Text()), caldavMaxTitle)
+	}
+	if fields.Title == "" {
+		fields.Title = "Untitled"
+	}
+	if description := vtodo.Get("DESCRIPTION"); description != nil {
+		fields.Description = truncateRunes(strings.TrimSpace(description.Text()), caldavMaxDescription)
+	}
+
+	if status := vtodo.Get("STATUS"); status != nil {
+		switch strings.ToUpper(status.Value) {
+		case "COMPLETED", "CANCELLED":
+			fields.Status = models.TodoStatusCompleted
+		case "IN-PROCESS":
+			fields.Status = models.TodoStatusInProgress
+		}
+	} else if vtodo.Get("COMPLETED") != nil {
+		fields.Status = models.TodoStatusCompleted
+	}
+
+	if priority := vtodo.Get("PRIORITY"); priority != nil {
+		var value int
+		if _, err := fmt.Sscan(priority.Value, &value); err == nil && value >= 0 && value < len(caldavPriorities) {
+			fields.Priority = caldavPriorities[value]
+		}
+	}
+
+	if due := vtodo.Get("DUE"); due != nil {
+		t, dateOnly, err := due.Time(loc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid calendar object: DUE: %w", err)
+		}
+		if dateOnly {
+			t = time.Date(t.Year(), t.Month(), t.Day(), caldavDefaultHour, 0, 0, 0, loc)
+		}
+		t = t.UTC()
+		fields.Due = &t
+	}
+
+	seen := make(map[string]bool)
+	for _, categories := range vtodo.GetAll("CATEGORIES") {
+		for _, tag := range categories.TextList() {
+			tag = truncateRunes(strings.TrimSpace(tag), caldavMaxTag)
+			key := strings.ToLower(tag)
+			if tag == "" || seen[key] || len(fields.Tags) == caldavMaxTags {
+				continue
+			}
+			seen[key] = true
+			fields.Tags = append(fields.Tags, tag)
+		}
+	}
+
+	for _, related := range vtodo.GetAll("RELATED-TO") {
+		if reltype := strings.ToUpper(related.Param("RELTYPE")); reltype == "" || reltype == "PARENT" {
+			fields.ParentUID = strings.TrimSpace(related.Value)
+			break
+		}
+	}
+	return fields, nil
+}
+
+func (f *vtodoFields) createRequest(listID *uuid.UUID) *models.TodoCreateRequest {
+	return &models.TodoCreateRequest{
+		Title:       f.Title,
+		Description: f.Description,
+		Status:      f.Status,
+		Priority:    f.Priority,
+		DueDate:     f.Due,
+		ListID:      listID,
+		Tags:        f.Tags,
+	}
+}
+
+// updateRequest replaces the fields of todo with the VTODO's, clearing the
+// ones the VTODO leaves out
+func (f *vtodoFields) updateRequest(todo *models.Todo, parentID *uuid.UUID) *models.TodoUpdateRequest {
+	req := &models.TodoUpdateRequest{
+		Title:       f.Title,
+		Description: f.Description,
+		Priority:    f.Priority,
+		DueDate:     f.Due,
+		Tags:        f.Tags,
+	}
+	if f.Status != todo.Status {
+		req.Status = f.Status
+	}
+	if f.Description == "" {
+		req.Clear = append(req.Clear, models.TodoFieldDescription)
+	}
+	if f.Priority == 0 {
+		req.Clear = append(req.Clear, models.TodoFieldPriority)
+	}
+	// Occurrences of a series always have a due date
+	if f.Due == nil && !todo.IsRecurring() {
+		req.Clear = append(req.Clear, models.TodoFieldDueDate)
+	}
+
+	switch {
+	case parentID != nil && (todo.ParentID == nil || *todo.ParentID != *parentID):
+		req.ParentID = parentID
+	case parentID == nil && f.ParentUID == "" && todo.ParentID != nil:
+		req.ParentID = &uuid.Nil
+	}
+	return req
+}
+
+// truncateRunes shortens s to at most max runes
+func truncateRunes(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max])
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"todo-backend/internal/models"
+	"todo-backend/internal/repository"
+	"todo-backend/pkg/ical"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeCalendar(t *testing.T, lines ...string) *ical.Component {
+	t.Helper()
+	cal, err := ical.Decode(strings.NewReader(strings.Join(lines, "\r\n") + "\r\n"))
+	require.NoError(t, err)
+	return cal
+}
+
+func TestParseVTodo(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	cal := decodeCalendar(t,
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"BEGIN:VTODO",
+		"UID:ABC-123@example.com",
+		`SUMMARY:  Pay rent\, utilities  `,
+		`DESCRIPTION:Bank transfer\nReference 42`,
+		"STATUS:IN-PROCESS",
+		"PRIORITY:1",
+		"DUE;VALUE=DATE:20240305",
+		"CATEGORIES:home,Bills",
+		"CATEGORIES:bills,,urgent",
+		"RELATED-TO;RELTYPE=SIBLING:sibling-uid",
+		"RELATED-TO:parent-uid",
+		"RRULE:FREQ=WEEKLY",
+		"END:VTODO",
+		"END:VCALENDAR",
+	)
+
+	fields, err := parseVTodo(cal, loc)
+	require.NoError(t, err)
+
+	due := time.Date(2024, 3, 5, 7, 0, 0, 0, time.UTC)
+	assert.Equal(t, &vtodoFields{
+		UID:         "ABC-123@example.com",
+		Title:       "Pay rent, utilities",
+		Description: "Bank transfer\nReference 42",
+		Status:      models.TodoStatusInProgress,
+		Priority:    5,
+		Due:         &due,
+		Tags:        []string{"home", "Bills", "urgent"},
+		ParentUID:   "parent-uid",
+	}, fields)
+}
+
+func TestParseVTodoDefaults(t *testing.T) {
+	cal := decodeCalendar(t,
+		"BEGIN:VCALENDAR",
+		"BEGIN:VTODO",
+		"UID:override",
+		"RECURRENCE-ID:20240305T090000Z",
+		"SUMMARY:Override",
+		"END:VTODO",
+		"BEGIN:VTODO",
+		"UID:master",
+		"COMPLETED:20240301T120000Z",
+		"PRIORITY:0",
+		"DUE:20240305T090000Z",
+		"END:VTODO",
+		"END:VCALENDAR",
+	)
+
+	fields, err := parseVTodo(cal, time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, "master", fields.UID)
+	assert.Equal(t, "Untitled", fields.Title)
+	assert.Equal(t, models.TodoStatusCompleted, fields.Status)
+	assert.Equal(t, 0, fields.Priority)
+	assert.Equal(t, time.Date(2024, 3, 5, 9, 0, 0, 0, time.UTC), *fields.Due)
+	assert.Equal(t, []string{}, fields.Tags)
+}
+
+func TestParseVTodoErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  string
+	}{
+		{"not a calendar", []string{"BEGIN:VTODO", "UID:a", "END:VTODO"}, "invalid calendar object"},
+		{"event", []string{"BEGIN:VCALENDAR", "BEGIN:VEVENT", "UID:a", "END:VEVENT", "END:VCALENDAR"}, "unsupported calendar component"},
+		{"no uid", []string{"BEGIN:VCALENDAR", "BEGIN:VTODO", "SUMMARY:a", "END:VTODO", "END:VCALENDAR"}, "invalid calendar object"},
+		{"bad due", []string{"BEGIN:VCALENDAR", "BEGIN:VTODO", "UID:a", "DUE:soon", "END:VTODO", "END:VCALENDAR"}, "invalid calendar object"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseVTodo(decodeCalendar(t, tt.lines...), time.UTC)
+			require.Error(t, err)
+			assert.True(t, strings.HasPrefix(err.Error(), tt.want), err.Error())
+		})
+	}
+}
+
+func TestCaldavPrioritiesRoundTrip(t *testing.T) {
+	for priority := 0; priority <= 5; priority++ {
+		assert.Equal(t, priority, caldavPriorities[calendarPriority(priority)])
+	}
+}
+
+func TestVTodoUpdateRequest(t *testing.T) {
+	parentID := uuid.New()
+	due := time.Date(2024, 3, 5, 9, 0, 0, 0, time.UTC)
+
+	t.Run("clears left out fields", func(t *testing.T) {
+		todo := &models.Todo{Status: models.TodoStatusPending, Description: "old", Priority: 3, DueDate: &due, ParentID: &parentID}
+		fields := &vtodoFields{Title: "New", Status: models.TodoStatusPending, Tags: []string{}}
+
+		req := fields.updateRequest(todo, nil)
+		assert.Equal(t, "New", req.Title)
+		assert.Empty(t, req.Status)
+		assert.Equal(t, []string{models.TodoFieldDescription, models.TodoFieldPriority, models.TodoFieldDueDate}, req.Clear)
+		assert.Equal(t, []string{}, req.Tags)
+		require.NotNil(t, req.ParentID)
+		assert.Equal(t, uuid.Nil, *req.ParentID)
+	})
+
+	t.Run("keeps the due date of recurring todos and unknown parents", func(t *testing.T) {
+		todo := &models.Todo{Status: models.TodoStatusPending, DueDate: &due, RecurrenceRule: "FREQ=DAILY", ParentID: &parentID}
+		fields := &vtodoFields{Title: "Daily", Status: models.TodoStatusCompleted, Priority: 2, Description: "d", ParentUID: "not-synced-yet"}
+
+		req := fields.updateRequest(todo, nil)
+		assert.Equal(t, models.TodoStatusCompleted, req.Status)
+		assert.Empty(t, req.Clear)
+		assert.Nil(t, req.ParentID)
+	})
+
+	t.Run("moves to a new parent", func(t *testing.T) {
+		newParentID := uuid.New()
+		todo := &models.Todo{ParentID: &parentID}
+		fields := &vtodoFields{Title: "Sub", ParentUID: "p"}
+
+		assert.Equal(t, &newParentID, fields.updateRequest(todo, &newParentID).ParentID)
+		assert.Nil(t, fields.updateRequest(todo, &parentID).ParentID)
+	})
+}
+
+func TestCheckConditions(t *testing.T) {
+	todo := &models.Todo{UpdatedAt: time.Date(2024, 3, 1, 12, 0, 0, 123456789, time.UTC)}
+	etag := caldavETag(todo)
+	assert.Equal(t, `"`+todo.Version()+`"`, etag)
+
+	tests := []struct {
+		name       string
+		todo       *models.Todo
+		conditions models.CalDAVConditions
+		wantErr    bool
+	}{
+		{name: "none", todo: todo},
+		{name: "if-match", todo: todo, conditions: models.CalDAVConditions{IfMatch: etag}},
+		{name: "if-match in list", todo: todo, conditions: models.CalDAVConditions{IfMatch: `"other", W/` + etag}},
+		{name: "if-match stale", todo: todo, conditions: models.CalDAVConditions{IfMatch: `"other"`}, wantErr: true},
+		{name: "if-match any", todo: todo, conditions: models.CalDAVConditions{IfMatch: "*"}},
+		{name: "if-match missing", conditions: models.CalDAVConditions{IfMatch: "*"}, wantErr: true},
+		{name: "if-none-match new", conditions: models.CalDAVConditions{IfNoneMatch: "*"}},
+		{name: "if-none-match existing", todo: todo, conditions: models.CalDAVConditions{IfNoneMatch: "*"}, wantErr: true},
+		{name: "if-none-match current", todo: todo, conditions: models.CalDAVConditions{IfNoneMatch: etag}, wantErr: true},
+		{name: "if-none-match other", todo: todo, conditions: models.CalDAVConditions{IfNoneMatch: `"other"`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkConditions(tt.todo, tt.conditions)
+			if tt.wantErr {
+				assert.EqualError(t, err, "precondition failed")
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCaldavConflict(t *testing.T) {
+	duplicate := func(index string) error {
+		return fmt.Errorf("create todo: %w", &pgconn.PgError{Code: "23505", ConstraintName: index})
+	}
+
+	assert.EqualError(t, caldavConflict(duplicate(repository.TodoCalDAVNameIndex)), "precondition failed")
+	assert.EqualError(t, caldavConflict(duplicate(repository.TodoICalUIDIndex)), "calendar object UID conflict")
+	other := duplicate("idx_other")
+	assert.Equal(t, other, caldavConflict(other))
+	assert.NoError(t, caldavConflict(nil))
+}
+
+func TestCaldavObject(t *testing.T) {
+	parent := &models.Todo{ID: uuid.New(), ICalUID: strPtr("parent@example.com")}
+	name := "client-name.ics"
+	todo := &models.Todo{
+		ID:         uuid.New(),
+		Title:      "Sub",
+		Status:     models.TodoStatusCompleted,
+		ICalUID:    strPtr("sub@example.com"),
+		CalDAVName: &name,
+		ParentID:   &parent.ID,
+		Parent:     parent,
+		UpdatedAt:  time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	object, err := caldavObject(todo)
+	require.NoError(t, err)
+	assert.Equal(t, name, object.Name)
+	assert.Equal(t, caldavETag(todo), object.ETag)
+
+	cal, err := ical.Decode(strings.NewReader(object.Data))
+	require.NoError(t, err)
+	vtodo := cal.Children("VTODO")[0]
+	assert.Equal(t, "sub@example.com", vtodo.Get("UID").Value)
+	assert.Equal(t, "parent@example.com", vtodo.Get("RELATED-TO").Value)
+	assert.Equal(t, "COMPLETED", vtodo.Get("STATUS").Value)
+
+	// Todos created through the API are named after their ID
+	object, err = caldavObject(&models.Todo{ID: todo.ID, Title: "API"})
+	require.NoError(t, err)
+	assert.Equal(t, todo.ID.String()+".ics", object.Name)
+}
+
+func strPtr(s string) *string {
+	return &s
+}
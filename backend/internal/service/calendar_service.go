@@ -0,0 +1,320 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"todo-backend/internal/config"
+	"todo-backend/internal/models"
+	"todo-backend/internal/repository"
+	"todo-backend/pkg/ical"
+	"todo-backend/pkg/rrule"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const calendarProductID = "-//todo-backend//Todo Calendar//EN"
+
+// calendarZoneYears is how far past today VTIMEZONE definitions list the
+// transitions of recurring todos' timezones
+const calendarZoneYears = 5
+
+type CalendarService interface {
+	// Feed URLs; GetFeed turns the feed on, RegenerateFeed replaces its secret
+	// so that old URLs stop working
+	GetFeed(userID uuid.UUID) (*models.CalendarFeedResponse, error)
+	RegenerateFeed(userID uuid.UUID) (*models.CalendarFeedResponse, error)
+	DisableFeed(userID uuid.UUID) error
+
+	// Calendar builds the feed of the user owning token
+	Calendar(token string, query *models.CalendarFeedQuery) (*ical.Component, error)
+}
+
+type calendarService struct {
+	userRepo repository.UserRepository
+	todoRepo repository.TodoRepository
+	listRepo repository.ListRepository
+	config   *config.Config
+	now      func() time.Time
+}
+
+func NewCalendarService(userRepo repository.UserRepository, todoRepo repository.TodoRepository, listRepo repository.ListRepository, cfg *config.Config) CalendarService {
+	return &calendarService{
+		userRepo: userRepo,
+		todoRepo: todoRepo,
+		listRepo: listRepo,
+		config:   cfg,
+		now:      time.Now,
+	}
+}
+
+func (s *calendarService) GetFeed(userID uuid.UUID) (*models.CalendarFeedResponse, error) {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.CalendarToken == nil {
+		if err := s.setToken(user); err != nil {
+			return nil, err
+		}
+	}
+	return s.feed(user)
+}
+
+func (s *calendarService) RegenerateFeed(userID uuid.UUID) (*models.CalendarFeedResponse, error) {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.setToken(user); err != nil {
+		return nil, err
+	}
+	return s.feed(user)
+}
+
+func (s *calendarService) DisableFeed(userID uuid.UUID) error {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return err
+	}
+	user.CalendarToken = nil
+	return s.userRepo.Update(user)
+}
+
+func (s *calendarService) Calendar(token string, query *models.CalendarFeedQuery) (*ical.Component, error) {
+	user, err := s.userRepo.GetByCalendarToken(token)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("calendar not found")
+		}
+		return nil, err
+	}
+
+	name := "Todos"
+	if query.ListID != nil {
+		list, err := s.listRepo.GetByID(*query.ListID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		if err != nil || list.UserID != user.ID {
+			return nil, errors.New("list not found")
+		}
+		name = list.Name
+	}
+
+	todos, err := s.todoRepo.FindDue(user.ID, query.ListID, query.IncludeCompleted)
+	if err != nil {
+		return nil, err
+	}
+	return buildCalendar(name, todos, query.Component, s.now()), nil
+}
+
+func (s *calendarService) getUser(userID uuid.UUID) (*models.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *calendarService) setToken(user *models.User) error {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return err
+	}
+	token := hex.EncodeToString(bytes)
+	user.CalendarToken = &token
+	return s.userRepo.Update(user)
+}
+
+func (s *calendarService) feed(user *models.User) (*models.CalendarFeedResponse, error) {
+	lists, err := s.listRepo.GetByUserID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/calendar/%s.ics", strings.TrimRight(s.config.PublicBaseURL, "/"), *user.CalendarToken)
+	response := &models.CalendarFeedResponse{
+		URL:       url,
+		WebcalURL: "webcal://" + strings.TrimPrefix(strings.TrimPrefix(url, "https://"), "http://"),
+		Lists:     make([]models.CalendarListFeed, 0, len(lists)),
+	}
+	for _, list := range lists {
+		response.Lists = append(response.Lists, models.CalendarListFeed{
+			ListID: list.ID,
+			Name:   list.Name,
+			URL:    fmt.Sprintf("%s?list_id=%s", url, list.ID),
+		})
+	}
+	return response, nil
+}
+
+// buildCalendar turns todos into a VCALENDAR with a VEVENT at each due date
+// and a VTODO per todo, or only one of them when component is set
+func buildCalendar(name string, todos []models.Todo, component string, now time.Time) *ical.Component {
+	cal := ical.NewComponent("VCALENDAR").
+		Set("VERSION", "2.0").
+		Set("PRODID", calendarProductID).
+		Set("CALSCALE", "GREGORIAN").
+		Set("METHOD", "PUBLISH").
+		SetText("X-WR-CALNAME", name).
+		Set("X-PUBLISHED-TTL", "PT1H")
+
+	// Earliest start in each timezone used by a recurring event
+	zones := make(map[string]time.Time)
+	locations := make(map[string]*time.Location)
+	var components []*ical.Component
+	for i := range todos {
+		todo := &todos[i]
+		if todo.DueDate == nil {
+			continue
+		}
+		if component != models.CalendarComponentTodo {
+			event, loc := todoEvent(todo)
+			if loc != nil {
+				start, ok := zones[loc.String()]
+				if !ok || todo.DueDate.Before(start) {
+					zones[loc.String()] = *todo.DueDate
+				}
+				locations[loc.String()] = loc
+			}
+			components = append(components, event)
+		}
+		if component != models.CalendarComponentEvent {
+			components = append(components, todoVTodo(todo))
+		}
+	}
+
+	names := make([]string, 0, len(zones))
+	for name := range zones {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		loc := locations[name]
+		start := zones[name].In(loc)
+		from := time.Date(start.Year(), time.January, 1, 0, 0, 0, 0, loc)
+		to := now.AddDate(calendarZoneYears, 0, 0)
+		if start.After(now) {
+			to = start.AddDate(calendarZoneYears, 0, 0)
+		}
+		cal.Add(ical.Timezone(loc, from, to))
+	}
+	return cal.Add(components...)
+}
+
+// todoEvent returns a zero-length VEVENT at the todo's due date. Recurring
+// todos repeat from their current occurrence in their own timezone, which is
+// returned so the calendar can define it.
+func todoEvent(todo *models.Todo) (*ical.Component, *time.Location) {
+	event := ical.NewComponent("VEVENT").
+		Set("UID", todo.ID.String()+"-due").
+		Set("DTSTAMP", ical.DateTime(todo.UpdatedAt))
+	setCalendarFields(event, todo)
+
+	loc, rule := calendarRecurrence(todo)
+	if loc != nil && loc != time.UTC {
+		event.Set("DTSTART", ical.LocalDateTime(todo.DueDate.In(loc)), ical.Param{Name: "TZID", Value: loc.String()})
+	} else {
+		event.Set("DTSTART", ical.DateTime(*todo.DueDate))
+		loc = nil
+	}
+	if rule != nil {
+		event.Set("RRULE", rule.String())
+	}
+	event.Set("TRANSP", "TRANSPARENT")
+	return event, loc
+}
+
+// todoVTodo returns the todo as a VTODO. It has no RRULE, which would need a
+// DTSTART before the due date.
+func todoVTodo(todo *models.Todo) *ical.Component {
+	vtodo := ical.NewComponent("VTODO").
+		Set("UID", todo.UID()).
+		Set("DTSTAMP", ical.DateTime(todo.UpdatedAt))
+	setCalendarFields(vtodo, todo)
+
+	if todo.DueDate != nil {
+		vtodo.Set("DUE", ical.DateTime(*todo.DueDate))
+	}
+	switch todo.Status {
+	case models.TodoStatusCompleted:
+		vtodo.Set("STATUS", "COMPLETED").Set("PERCENT-COMPLETE", "100")
+	case models.TodoStatusInProgress:
+		vtodo.Set("STATUS", "IN-PROCESS")
+	default:
+		vtodo.Set("STATUS", "NEEDS-ACTION")
+	}
+	if todo.Parent != nil {
+		vtodo.Set("RELATED-TO", todo.Parent.UID())
+	} else if todo.ParentID != nil {
+		vtodo.Set("RELATED-TO", todo.ParentID.String())
+	}
+	return vtodo
+}
+
+func setCalendarFields(c *ical.Component, todo *models.Todo) {
+	if !todo.CreatedAt.IsZero() {
+		c.Set("CREATED", ical.DateTime(todo.CreatedAt))
+	}
+	if !todo.UpdatedAt.IsZero() {
+		c.Set("LAST-MODIFIED", ical.DateTime(todo.UpdatedAt))
+	}
+	c.SetText("SUMMARY", todo.Title)
+	if todo.Description != "" {
+		c.SetText("DESCRIPTION", todo.Description)
+	}
+	if priority := calendarPriority(todo.Priority); priority > 0 {
+		c.Set("PRIORITY", fmt.Sprint(priority))
+	}
+	if len(todo.Tags) > 0 {
+		c.Set("CATEGORIES", ical.TextList(todo.TagNames()))
+	}
+}
+
+// calendarPriority maps priorities 1 (low) to 5 (high) onto iCalendar's 9
+// (low) to 1 (high); 0 is undefined in both
+func calendarPriority(priority int) int {
+	if priority <= 0 {
+		return 0
+	}
+	if priority > 5 {
+		priority = 5
+	}
+	return 11 - 2*priority
+}
+
+// calendarRecurrence returns the rule and timezone of an open recurring todo,
+// with a COUNT reduced by the occurrences before the current one
+func calendarRecurrence(todo *models.Todo) (*time.Location, *rrule.Rule) {
+	if !todo.IsRecurring() || todo.Status == models.TodoStatusCompleted || todo.DueDate == nil {
+		return nil, nil
+	}
+	rule, err := rrule.Parse(todo.RecurrenceRule)
+	if err != nil {
+		return nil, nil
+	}
+	loc := time.UTC
+	if todo.Timezone != "" {
+		if l, err := time.LoadLocation(todo.Timezone); err == nil {
+			loc = l
+		}
+	}
+
+	if rule.Count > 0 && todo.RecurrenceStart != nil {
+		start := todo.RecurrenceStart.In(loc)
+		rule.Count -= len(rule.Between(start, start, *todo.DueDate, rule.Count))
+		if rule.Count < 1 {
+			return nil, nil
+		}
+	}
+	return loc, rule
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"todo-backend/internal/models"
+	"todo-backend/pkg/ical"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildCalendar(t *testing.T) {
+	id := uuid.MustParse("7d7ee3a3-7f6b-4f0e-9a57-1d0c3a9b1f11")
+	due := time.Date(2024, 3, 5, 8, 0, 0, 0, time.UTC)
+	updated := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	todos := []models.Todo{{
+		ID:          id,
+		Title:       "Pay rent; utilities, too",
+		Description: "Bank transfer\nReference 42",
+		Status:      models.TodoStatusPending,
+		Priority:    5,
+		DueDate:     &due,
+		CreatedAt:   updated,
+		UpdatedAt:   updated,
+		Tags:        []models.Tag{{Name: "home"}, {Name: "bills"}},
+	}}
+
+	var buf bytes.Buffer
+	require.NoError(t, buildCalendar("Todos", todos, "", updated).Encode(&buf))
+
+	assert.Equal(t, strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//todo-backend//Todo Calendar//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:Todos",
+		"X-PUBLISHED-TTL:PT1H",
+		"BEGIN:VEVENT",
+		"UID:7d7ee3a3-7f6b-4f0e-9a57-1d0c3a9b1f11-due",
+		"DTSTAMP:20240301T120000Z",
+		"CREATED:20240301T120000Z",
+		"LAST-MODIFIED:20240301T120000Z",
+		`SUMMARY:Pay rent\; utilities\, too`,
+		`DESCRIPTION:Bank transfer\nReference 42`,
+		"PRIORITY:1",
+		"CATEGORIES:home,bills",
+		"DTSTART:20240305T080000Z",
+		"TRANSP:TRANSPARENT",
+		"END:VEVENT",
+		"BEGIN:VTODO",
+		"UID:7d7ee3a3-7f6b-4f0e-9a57-1d0c3a9b1f11",
+		"DTSTAMP:20240301T120000Z",
+		"CREATED:20240301T120000Z",
+		"LAST-MODIFIED:20240301T120000Z",
+		`SUMMARY:Pay rent\; utilities\, too`,
+		`DESCRIPTION:Bank transfer\nReference 42`,
+		"PRIORITY:1",
+		"CATEGORIES:home,bills",
+		"DUE:20240305T080000Z",
+		"STATUS:NEEDS-ACTION",
+		"END:VTODO",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n"), buf.String())
+}
+
+func TestBuildCalendarComponents(t *testing.T) {
+	due := time.Date(2024, 3, 5, 8, 0, 0, 0, time.UTC)
+	todos := []models.Todo{
+		{ID: uuid.New(), Title: "Due", DueDate: &due, Status: models.TodoStatusCompleted},
+		{ID: uuid.New(), Title: "No due date"},
+	}
+
+	events := buildCalendar("Todos", todos, models.CalendarComponentEvent, due)
+	require.Len(t, events.Components, 1)
+	assert.Equal(t, "VEVENT", events.Components[0].Name)
+
+	vtodos := buildCalendar("Todos", todos, models.CalendarComponentTodo, due)
+	require.Len(t, vtodos.Components, 1)
+	assert.Equal(t, "VTODO", vtodos.Components[0].Name)
+	assert.Contains(t, vtodos.Components[0].Properties, propertyOf("STATUS", "COMPLETED"))
+}
+
+func TestBuildCalendarRecurring(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("timezone data not available")
+	}
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, loc)
+	due := time.Date(2024, 3, 4, 9, 0, 0, 0, loc)
+	todos := []models.Todo{{
+		ID:              uuid.New(),
+		Title:           "Standup",
+		Status:          models.TodoStatusPending,
+		DueDate:         &due,
+		RecurrenceRule:  "FREQ=DAILY;COUNT=10",
+		Timezone:        "Europe/Berlin",
+		RecurrenceStart: &start,
+	}}
+
+	cal := buildCalendar("Todos", todos, models.CalendarComponentEvent, due)
+
+	require.Len(t, cal.Components, 2)
+	assert.Equal(t, "VTIMEZONE", cal.Components[0].Name)
+	event := cal.Components[1]
+	assert.Contains(t, event.Properties, ical.Property{Name: "DTSTART", Params: []ical.Param{{Name: "TZID", Value: "Europe/Berlin"}}, Value: "20240304T090000"})
+	// Three of the ten occurrences have passed
+	assert.Contains(t, event.Properties, propertyOf("RRULE", "FREQ=DAILY;COUNT=7"))
+}
+
+func TestCalendarRecurrence(t *testing.T) {
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	due := time.Date(2024, 3, 3, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		todo models.Todo
+		want string // empty for no RRULE
+	}{
+		{name: "not recurring", todo: models.Todo{DueDate: &due}},
+		{name: "no count", todo: models.Todo{DueDate: &due, RecurrenceRule: "FREQ=WEEKLY;BYDAY=MO", RecurrenceStart: &start}, want: "FREQ=WEEKLY;BYDAY=MO"},
+		{name: "count", todo: models.Todo{DueDate: &due, RecurrenceRule: "FREQ=DAILY;COUNT=5", RecurrenceStart: &start}, want: "FREQ=DAILY;COUNT=3"},
+		{name: "last occurrence", todo: models.Todo{DueDate: &due, RecurrenceRule: "FREQ=DAILY;COUNT=3", RecurrenceStart: &start}, want: "FREQ=DAILY;COUNT=1"},
+		{name: "completed", todo: models.Todo{DueDate: &due, RecurrenceRule: "FREQ=DAILY", Status: models.TodoStatusCompleted}},
+		{name: "invalid rule", todo: models.Todo{DueDate: &due, RecurrenceRule: "FREQ=HOURLY"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, rule := calendarRecurrence(&tt.todo)
+			if tt.want == "" {
+				assert.Nil(t, rule)
+				return
+			}
+			require.NotNil(t, rule)
+			assert.Equal(t, tt.want, rule.String())
+		})
+	}
+}
+
+func TestCalendarPriority(t *testing.T) {
+	for priority, want := range map[int]int{0: 0, 1: 9, 3: 5, 5: 1, 7: 1} {
+		assert.Equal(t, want, calendarPriority(priority), "priority %d", priority)
+	}
+}
+
+func propertyOf(name, value string) ical.Property {
+	return ical.Property{Name: name, Value: value}
+}
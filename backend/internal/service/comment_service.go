@@ -0,0 +1,208 @@
+package service
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+	"todo-backend/internal/models"
+	"todo-backend/internal/repository"
+	"todo-backend/pkg/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// mentionPattern matches @-mentions of users by email address, e.g. "@ana@example.com"
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@.])@([A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,})`)
+
+type CommentService interface {
+	Create(todoID uuid.UUID, userID uuid.UUID, req *models.CommentCreateRequest) (*models.Comment, error)
+	List(todoID uuid.UUID, userID uuid.UUID, cursor string, limit int) ([]models.Comment, string, error)
+	Update(id uuid.UUID, todoID uuid.UUID, userID uuid.UUID, req *models.CommentUpdateRequest) (*models.Comment, error)
+	Delete(id uuid.UUID, todoID uuid.UUID, userID uuid.UUID) error
+}
+
+type commentService struct {
+	commentRepo repository.CommentRepository
+	todoRepo    repository.TodoRepository
+	userRepo    repository.UserRepository
+}
+
+func NewCommentService(commentRepo repository.CommentRepository, todoRepo repository.TodoRepository, userRepo repository.UserRepository) CommentService {
+	return &commentService{
+		commentRepo: commentRepo,
+		todoRepo:    todoRepo,
+		userRepo:    userRepo,
+	}
+}
+
+func (s *commentService) Create(todoID uuid.UUID, userID uuid.UUID, req *models.CommentCreateRequest) (*models.Comment, error) {
+	todo, err := s.getReadableTodo(todoID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	comment := &models.Comment{
+		TodoID:   todoID,
+		AuthorID: userID,
+		Body:     req.Body,
+		Mentions: s.resolveMentions(todo, req.Body),
+	}
+
+	if err := s.commentRepo.Create(comment); err != nil {
+		return nil, err
+	}
+	return s.commentRepo.GetByID(comment.ID)
+}
+
+// List returns one page of a todo's thread, oldest first, and the cursor of
+// the next page (empty when this is the last one)
+func (s *commentService) List(todoID uuid.UUID, userID uuid.UUID, cursor string, limit int) ([]models.Comment, string, error) {
+	if _, err := s.getReadableTodo(todoID, userID); err != nil {
+		return nil, "", err
+	}
+
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	var afterCreatedAt *time.Time
+	afterID := uuid.Nil
+	if cursor != "" {
+		values, err := utils.DecodeCursor(cursor)
+		if err != nil || len(values) != 2 {
+			return nil, "", errors.New("invalid cursor")
+		}
+		createdAt, err := time.Parse(time.RFC3339Nano, values[0])
+		if err != nil {
+			return nil, "", errors.New("invalid cursor")
+		}
+		if afterID, err = uuid.Parse(values[1]); err != nil {
+			return nil, "", errors.New("invalid cursor")
+		}
+		afterCreatedAt = &createdAt
+	}
+
+	// Fetch one extra row to know whether another page follows
+	comments, err := s.commentRepo.ListByTodoID(todoID, afterCreatedAt, afterID, limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+
+	nextCursor := ""
+	if len(comments) > limit {
+		comments = comments[:limit]
+		last := comments[len(comments)-1]
+		nextCursor = utils.EncodeCursor(last.CreatedAt.Format(time.RFC3339Nano), last.ID.String())
+	}
+	return comments, nextCursor, nil
+}
+
+func (s *commentService) Update(id uuid.UUID, todoID uuid.UUID, userID uuid.UUID, req *models.CommentUpdateRequest) (*models.Comment, error) {
+	comment, err := s.getComment(id, todoID)
+	if err != nil {
+		return nil, err
+	}
+
+	if comment.AuthorID != userID {
+		return nil, errors.New("only the author can edit this comment")
+	}
+
+	todo, err := s.getReadableTodo(todoID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	comment.Body = req.Body
+	comment.EditedAt = &now
+	comment.Mentions = s.resolveMentions(todo, req.Body)
+
+	if err := s.commentRepo.Update(comment); err != nil {
+		return nil, err
+	}
+	return s.commentRepo.GetByID(comment.ID)
+}
+
+func (s *commentService) Delete(id uuid.UUID, todoID uuid.UUID, userID uuid.UUID) error {
+	comment, err := s.getComment(id, todoID)
+	if err != nil {
+		return err
+	}
+
+	if comment.AuthorID != userID {
+		return errors.New("only the author can delete this comment")
+	}
+
+	return s.commentRepo.Delete(id)
+}
+
+func (s *commentService) getComment(id uuid.UUID, todoID uuid.UUID) (*models.Comment, error) {
+	comment, err := s.commentRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("comment not found")
+		}
+		return nil, err
+	}
+	if comment.TodoID != todoID {
+		return nil, errors.New("comment not found")
+	}
+	return comment, nil
+}
+
+// getReadableTodo loads a todo whose thread the user is allowed to read and post to
+func (s *commentService) getReadableTodo(todoID uuid.UUID, userID uuid.UUID) (*models.Todo, error) {
+	todo, err := s.todoRepo.GetByID(todoID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("todo not found")
+		}
+		return nil, err
+	}
+	if !canAccessThread(todo, userID) {
+		return nil, errors.New("unauthorized to access this todo")
+	}
+	return todo, nil
+}
+
+// canAccessThread reports whether the user may read and post to the todo's
+// thread. Todos are private to their owner, so that is the owner alone.
+func canAccessThread(todo *models.Todo, userID uuid.UUID) bool {
+	return todo.UserID == userID
+}
+
+// resolveMentions maps the @-mentions in body to the users who can read the
+// todo's thread. Other handles are ignored the same way whether or not they
+// belong to anyone, so mentions can't be used to probe for accounts.
+func (s *commentService) resolveMentions(todo *models.Todo, body string) []models.CommentMention {
+	var mentions []models.CommentMention
+	seen := make(map[uuid.UUID]bool)
+
+	for _, handle := range parseMentions(body) {
+		user, err := s.userRepo.GetByEmail(handle)
+		if err != nil || seen[user.ID] || !canAccessThread(todo, user.ID) {
+			continue
+		}
+		seen[user.ID] = true
+		mentions = append(mentions, models.CommentMention{UserID: user.ID, Handle: handle})
+	}
+	return mentions
+}
+
+// parseMentions extracts the distinct lower-cased handles mentioned in body
+func parseMentions(body string) []string {
+	var handles []string
+	seen := make(map[string]bool)
+
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		handle := strings.ToLower(strings.TrimRight(match[1], "."))
+		if seen[handle] {
+			continue
+		}
+		seen[handle] = true
+		handles = append(handles, handle)
+	}
+	return handles
+}
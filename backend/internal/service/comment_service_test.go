@@ -0,0 +1,139 @@
+package service
+
+import (
+	"testing"
+	"time"
+	"todo-backend/internal/models"
+	"todo-backend/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestParseMentions(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{"none", "no mentions here", nil},
+		{"single", "@ana@example.com can you check?", []string{"ana@example.com"}},
+		{"multiple and dedup", "cc @Ana@Example.com and @bob@example.org, @ana@example.com", []string{"ana@example.com", "bob@example.org"}},
+		{"trailing period", "thanks @ana@example.com.", []string{"ana@example.com"}},
+		{"plain email is not a mention", "mail ana@example.com", nil},
+		{"bare handle is not resolved", "hey @ana", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, parseMentions(tt.body))
+		})
+	}
+}
+
+// commentTodos serves todos by ID
+type commentTodos struct {
+	repository.TodoRepository
+	todos map[uuid.UUID]*models.Todo
+}
+
+func (r *commentTodos) GetByID(id uuid.UUID) (*models.Todo, error) {
+	if todo, ok := r.todos[id]; ok {
+		return todo, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// commentUsers serves users by email
+type commentUsers struct {
+	repository.UserRepository
+	users []models.User
+}
+
+func (r *commentUsers) GetByEmail(email string) (*models.User, error) {
+	for i := range r.users {
+		if r.users[i].Email == email {
+			return &r.users[i], nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// commentStore keeps comments in memory
+type commentStore struct {
+	repository.CommentRepository
+	comments map[uuid.UUID]*models.Comment
+}
+
+func (r *commentStore) Create(comment *models.Comment) error {
+	comment.ID = uuid.New()
+	comment.CreatedAt = time.Now()
+	r.comments[comment.ID] = comment
+	return nil
+}
+
+func (r *commentStore) GetByID(id uuid.UUID) (*models.Comment, error) {
+	if comment, ok := r.comments[id]; ok {
+		return comment, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *commentStore) Update(comment *models.Comment) error {
+	r.comments[comment.ID] = comment
+	return nil
+}
+
+func (r *commentStore) ListByTodoID(todoID uuid.UUID, afterCreatedAt *time.Time, afterID uuid.UUID, limit int) ([]models.Comment, error) {
+	var comments []models.Comment
+	for _, comment := range r.comments {
+		if comment.TodoID == todoID {
+			comments = append(comments, *comment)
+		}
+	}
+	return comments, nil
+}
+
+func TestCommentAccess(t *testing.T) {
+	owner := models.User{ID: uuid.New(), Email: "owner@example.com"}
+	stranger := models.User{ID: uuid.New(), Email: "stranger@example.com"}
+	todo := &models.Todo{ID: uuid.New(), UserID: owner.ID}
+
+	s := NewCommentService(
+		&commentStore{comments: make(map[uuid.UUID]*models.Comment)},
+		&commentTodos{todos: map[uuid.UUID]*models.Todo{todo.ID: todo}},
+		&commentUsers{users: []models.User{owner, stranger}},
+	)
+
+	t.Run("the owner reads and posts to the thread", func(t *testing.T) {
+		comment, err := s.Create(todo.ID, owner.ID, &models.CommentCreateRequest{Body: "Started"})
+		require.NoError(t, err)
+		assert.Equal(t, owner.ID, comment.AuthorID)
+
+		comments, _, err := s.List(todo.ID, owner.ID, "", 20)
+		require.NoError(t, err)
+		assert.Len(t, comments, 1)
+	})
+
+	t.Run("others can't", func(t *testing.T) {
+		_, err := s.Create(todo.ID, stranger.ID, &models.CommentCreateRequest{Body: "Hi"})
+		assert.EqualError(t, err, "unauthorized to access this todo")
+
+		_, _, err = s.List(todo.ID, stranger.ID, "", 20)
+		assert.EqualError(t, err, "unauthorized to access this todo")
+	})
+
+	t.Run("mentions resolve only users who can read the thread", func(t *testing.T) {
+		body := "@owner@example.com @stranger@example.com @nobody@example.com"
+		comment, err := s.Create(todo.ID, owner.ID, &models.CommentCreateRequest{Body: body})
+		require.NoError(t, err)
+		require.Len(t, comment.Mentions, 1)
+		assert.Equal(t, owner.ID, comment.Mentions[0].UserID)
+
+		comment, err = s.Update(comment.ID, todo.ID, owner.ID, &models.CommentUpdateRequest{Body: "@stranger@example.com"})
+		require.NoError(t, err)
+		assert.Empty(t, comment.Mentions)
+	})
+}
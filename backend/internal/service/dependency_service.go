@@ -0,0 +1,138 @@
+package service
+
+import (
+	"errors"
+	"todo-backend/internal/models"
+	"todo-backend/internal/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type DependencyService interface {
+	Add(todoID uuid.UUID, userID uuid.UUID, dependsOnID uuid.UUID) ([]models.Todo, error)
+	Remove(todoID uuid.UUID, userID uuid.UUID, dependsOnID uuid.UUID) error
+	List(todoID uuid.UUID, userID uuid.UUID) ([]models.Todo, error)
+}
+
+type dependencyService struct {
+	dependencyRepo repository.DependencyRepository
+	todoRepo       repository.TodoRepository
+}
+
+func NewDependencyService(dependencyRepo repository.DependencyRepository, todoRepo repository.TodoRepository) DependencyService {
+	return &dependencyService{
+		dependencyRepo: dependencyRepo,
+		todoRepo:       todoRepo,
+	}
+}
+
+// Add makes todoID depend on dependsOnID and returns the todo's dependencies
+func (s *dependencyService) Add(todoID uuid.UUID, userID uuid.UUID, dependsOnID uuid.UUID) ([]models.Todo, error) {
+	if todoID == dependsOnID {
+		return nil, errors.New("todo cannot depend on itself")
+	}
+
+	if _, err := s.getOwnedTodo(todoID, userID); err != nil {
+		return nil, err
+	}
+	blocker, err := s.todoRepo.GetByID(dependsOnID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("dependency todo not found")
+		}
+		return nil, err
+	}
+	if blocker.UserID != userID {
+		return nil, errors.New("dependencies must belong to the same user")
+	}
+
+	// The check and the insert share a transaction holding the user's graph
+	// lock; otherwise a->b and b->a added at the same time could both pass
+	err = s.dependencyRepo.WithGraphLock(userID, func(repo repository.DependencyRepository) error {
+		exists, err := repo.Exists(todoID, dependsOnID)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return errors.New("dependency already exists")
+		}
+
+		// The new edge closes a cycle if todoID is already reachable from dependsOnID
+		cycle, err := reachable(dependsOnID, todoID, repo.GetDependsOnIDs)
+		if err != nil {
+			return err
+		}
+		if cycle {
+			return errors.New("dependency would create a cycle")
+		}
+
+		return repo.Create(&models.TodoDependency{TodoID: todoID, DependsOnID: dependsOnID})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.dependencyRepo.GetDependsOn(todoID)
+}
+
+func (s *dependencyService) Remove(todoID uuid.UUID, userID uuid.UUID, dependsOnID uuid.UUID) error {
+	if _, err := s.getOwnedTodo(todoID, userID); err != nil {
+		return err
+	}
+
+	deleted, err := s.dependencyRepo.Delete(todoID, dependsOnID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return errors.New("dependency not found")
+	}
+	return nil
+}
+
+func (s *dependencyService) List(todoID uuid.UUID, userID uuid.UUID) ([]models.Todo, error) {
+	if _, err := s.getOwnedTodo(todoID, userID); err != nil {
+		return nil, err
+	}
+	return s.dependencyRepo.GetDependsOn(todoID)
+}
+
+func (s *dependencyService) getOwnedTodo(todoID uuid.UUID, userID uuid.UUID) (*models.Todo, error) {
+	todo, err := s.todoRepo.GetByID(todoID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("todo not found")
+		}
+		return nil, err
+	}
+	if todo.UserID != userID {
+		return nil, errors.New("unauthorized to access this todo")
+	}
+	return todo, nil
+}
+
+// reachable walks the dependency graph breadth-first from start, one query
+// per level, and reports whether target can be reached
+func reachable(start, target uuid.UUID, next func([]uuid.UUID) ([]uuid.UUID, error)) (bool, error) {
+	visited := map[uuid.UUID]bool{start: true}
+	frontier := []uuid.UUID{start}
+
+	for len(frontier) > 0 {
+		neighbors, err := next(frontier)
+		if err != nil {
+			return false, err
+		}
+
+		frontier = frontier[:0]
+		for _, id := range neighbors {
+			if id == target {
+				return true, nil
+			}
+			if !visited[id] {
+				visited[id] = true
+				frontier = append(frontier, id)
+			}
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func graphLookup(edges map[uuid.UUID][]uuid.UUID) func([]uuid.UUID) ([]uuid.UUID, error) {
+	return func(ids []uuid.UUID) ([]uuid.UUID, error) {
+		var out []uuid.UUID
+		for _, id := range ids {
+			out = append(out, edges[id]...)
+		}
+		return out, nil
+	}
+}
+
+func TestReachable(t *testing.T) {
+	a, b, c, d, e := uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	// a -> b -> c, d is isolated; a diamond back into b is harmless
+	edges := map[uuid.UUID][]uuid.UUID{
+		a: {b, e},
+		e: {b},
+		b: {c},
+		c: {},
+	}
+
+	tests := []struct {
+		name          string
+		start, target uuid.UUID
+		want          bool
+	}{
+		{"direct edge", a, b, true},
+		{"transitive", a, c, true},
+		{"reverse direction", c, a, false},
+		{"isolated", d, a, false},
+		{"through the diamond", e, c, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := reachable(tt.start, tt.target, graphLookup(edges))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestReachable_TerminatesOnExistingCycle(t *testing.T) {
+	a, b, c := uuid.New(), uuid.New(), uuid.New()
+	edges := map[uuid.UUID][]uuid.UUID{a: {b}, b: {a}}
+
+	got, err := reachable(a, c, graphLookup(edges))
+	assert.NoError(t, err)
+	assert.False(t, got)
+}
+
+func TestReachable_PropagatesErrors(t *testing.T) {
+	_, err := reachable(uuid.New(), uuid.New(), func([]uuid.UUID) ([]uuid.UUID, error) {
+		return nil, errors.New("db down")
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,287 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"todo-backend/internal/models"
+	"todo-backend/internal/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type EffortService interface {
+	Report(userID uuid.UUID, groupBy string) (*models.EffortReport, error)
+	Subtree(todoID uuid.UUID, userID uuid.UUID) ([]models.EffortRollup, error)
+	Burndown(userID uuid.UUID, query *BurndownQuery) (*models.Burndown, error)
+}
+
+// BurndownQuery selects the days and todos of a burndown. From and To are
+// inclusive dates (YYYY-MM-DD) in the user's timezone. With a list the list's
+// estimate unit is used, otherwise Unit (default minutes).
+type BurndownQuery struct {
+	From   string
+	To     string
+	ListID *uuid.UUID
+	Tag    string
+	Unit   string
+}
+
+type effortService struct {
+	todoRepo   repository.TodoRepository
+	listRepo   repository.ListRepository
+	changeRepo repository.TodoChangeRepository
+	userRepo   repository.UserRepository
+	now        func() time.Time
+}
+
+func NewEffortService(todoRepo repository.TodoRepository, listRepo repository.ListRepository, changeRepo repository.TodoChangeRepository, userRepo repository.UserRepository) EffortService {
+	return &effortService{
+		todoRepo:   todoRepo,
+		listRepo:   listRepo,
+		changeRepo: changeRepo,
+		userRepo:   userRepo,
+		now:        time.Now,
+	}
+}
+
+// Report rolls up the estimates of the user's todos by list (the default) or
+// by tag
+func (s *effortService) Report(userID uuid.UUID, groupBy string) (*models.EffortReport, error) {
+	if groupBy == "" {
+		groupBy = models.EffortByList
+	}
+	if groupBy != models.EffortByList && groupBy != models.EffortByTag {
+		return nil, fmt.Errorf("invalid group_by: %s", groupBy)
+	}
+
+	rows, err := s.todoRepo.Effort(userID, groupBy)
+	if err != nil {
+		return nil, err
+	}
+	if rows == nil {
+		rows = []models.EffortRollup{}
+	}
+	return &models.EffortReport{GroupBy: groupBy, Rows: rows}, nil
+}
+
+// Subtree rolls up the estimates of a todo and all of its subtasks, one row
+// per unit in use
+func (s *effortService) Subtree(todoID uuid.UUID, userID uuid.UUID) ([]models.EffortRollup, error) {
+	todo, err := s.todoRepo.GetByID(todoID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("todo not found")
+		}
+		return nil, err
+	}
+	if todo.UserID != userID {
+		return nil, errors.New("unauthorized to access this todo")
+	}
+
+	rows, err := s.todoRepo.SubtreeEffort(todoID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		rows[i].Label = todo.Title
+	}
+	if rows == nil {
+		rows = []models.EffortRollup{}
+	}
+	return rows, nil
+}
+
+// Burndown rebuilds the remaining and completed effort at the end of each day
+// of the range from the todos' change history. Days after today are left out.
+func (s *effortService) Burndown(userID uuid.UUID, query *BurndownQuery) (*models.Burndown, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+	loc := user.Location()
+	now := s.now().In(loc)
+
+	start, end, err := reportRange(query.From, query.To, now)
+	if err != nil {
+		return nil, err
+	}
+
+	burndown := &models.Burndown{
+		From:     start.Format("2006-01-02"),
+		To:       end.AddDate(0, 0, -1).Format("2006-01-02"),
+		Timezone: loc.String(),
+		Unit:     models.EstimateUnitMinutes,
+		ListID:   query.ListID,
+		Tag:      models.NormalizeTagName(query.Tag),
+	}
+	switch models.EstimateUnit(query.Unit) {
+	case "", models.EstimateUnitMinutes:
+	case models.EstimateUnitPoints:
+		burndown.Unit = models.EstimateUnitPoints
+	default:
+		return nil, fmt.Errorf("invalid unit: %s", query.Unit)
+	}
+
+	lists, err := s.listRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	units := make(map[string]models.EstimateUnit, len(lists))
+	for _, list := range lists {
+		units[list.ID.String()] = list.EstimateUnit
+	}
+	if query.ListID != nil {
+		unit, ok := units[query.ListID.String()]
+		if !ok {
+			return nil, errors.New("list not found")
+		}
+		burndown.Unit = unit
+	}
+
+	todos, err := s.todoRepo.FindAllWithDeleted(userID)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := s.changeRepo.GetByUserSince(userID, start.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	states := make(map[uuid.UUID]*effortState, len(todos))
+	for i := range todos {
+		states[todos[i].ID] = newEffortState(&todos[i])
+	}
+
+	listID := ""
+	if query.ListID != nil {
+		listID = query.ListID.String()
+	}
+	match := func(state *effortState) bool {
+		if !state.exists || state.deleted {
+			return false
+		}
+		if listID != "" && state.listID != listID {
+			return false
+		}
+		if burndown.Tag != "" && !state.hasTag(burndown.Tag) {
+			return false
+		}
+		unit, ok := units[state.listID]
+		if !ok {
+			unit = models.EstimateUnitMinutes
+		}
+		return unit == burndown.Unit
+	}
+
+	var days []time.Time
+	for day := start; day.Before(end) && !day.After(now); day = day.AddDate(0, 0, 1) {
+		days = append(days, day)
+	}
+	burndown.Points = burndownSeries(states, changes, days, now, match)
+	return burndown, nil
+}
+
+// effortState is the part of a todo a burndown looks at
+type effortState struct {
+	exists   bool
+	deleted  bool
+	status   string
+	estimate float64 // 0 when unset
+	listID   string
+	tags     []string
+}
+
+func newEffortState(todo *models.Todo) *effortState {
+	state := &effortState{
+		exists:  true,
+		deleted: todo.DeletedAt.Valid,
+		status:  string(todo.Status),
+		tags:    todo.TagNames(),
+	}
+	if todo.Estimate != nil {
+		state.estimate = *todo.Estimate
+	}
+	if todo.ListID != nil {
+		state.listID = todo.ListID.String()
+	}
+	return state
+}
+
+func (e *effortState) hasTag(name string) bool {
+	for _, tag := range e.tags {
+		if tag == name {
+			return true
+		}
+	}
+	return false
+}
+
+// revert takes a change back, leaving the todo as it was before it
+func (e *effortState) revert(change *models.TodoChange) {
+	switch change.Action {
+	case models.TodoChangeCreated:
+		e.exists = false
+		return
+	case models.TodoChangeDeleted:
+		e.deleted = false
+	case models.TodoChangeRestored:
+		e.deleted = true
+	}
+
+	for _, field := range change.Changes {
+		switch field.Field {
+		case "status":
+			e.status, _ = field.Old.(string)
+		case "estimate":
+			e.estimate, _ = field.Old.(float64)
+		case "list_id":
+			e.listID, _ = field.Old.(string)
+		case "tags":
+			names, _ := field.Old.([]interface{})
+			e.tags = e.tags[:0:0]
+			for _, name := range names {
+				if name, ok := name.(string); ok {
+					e.tags = append(e.tags, name)
+				}
+			}
+		}
+	}
+}
+
+// burndownSeries walks back from the todos' current states through their
+// changes, latest first, and sums the effort of the matching todos at the end
+// of each day (or at now for today). days are the starts of the days in order.
+func burndownSeries(states map[uuid.UUID]*effortState, changes []models.TodoChange, days []time.Time, now time.Time, match func(*effortState) bool) []models.BurndownPoint {
+	points := make([]models.BurndownPoint, len(days))
+	next := 0
+	for i := len(days) - 1; i >= 0; i-- {
+		end := days[i].AddDate(0, 0, 1)
+		if end.After(now) {
+			end = now
+		}
+		for ; next < len(changes) && !changes[next].CreatedAt.Before(end); next++ {
+			if state, ok := states[changes[next].TodoID]; ok {
+				state.revert(&changes[next])
+			}
+		}
+
+		point := models.BurndownPoint{Date: days[i].Format("2006-01-02")}
+		for _, state := range states {
+			if !match(state) {
+				continue
+			}
+			if state.status == string(models.TodoStatusCompleted) {
+				point.Completed += state.estimate
+			} else {
+				point.Remaining += state.estimate
+			}
+		}
+		points[i] = point
+	}
+	return points
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"testing"
+	"time"
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBurndownSeries(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC)
+	}
+	at := func(d, hour int) time.Time {
+		return day(d).Add(time.Duration(hour) * time.Hour)
+	}
+	estimate := func(v float64) *float64 { return &v }
+
+	write, review, extra := uuid.New(), uuid.New(), uuid.New()
+	listID := uuid.New()
+	todos := []models.Todo{
+		{ID: write, Status: models.TodoStatusCompleted, Estimate: estimate(5)},
+		{ID: review, Status: models.TodoStatusPending, Estimate: estimate(3), ListID: &listID},
+		{ID: extra, Status: models.TodoStatusPending, Estimate: estimate(2)},
+	}
+	// Latest first, as the repository returns them
+	changes := []models.TodoChange{
+		{TodoID: review, Action: models.TodoChangeUpdated, CreatedAt: at(4, 9), Changes: models.FieldChanges{
+			{Field: "list_id", Old: nil, New: listID.String()},
+		}},
+		{TodoID: extra, Action: models.TodoChangeCreated, CreatedAt: at(3, 15)},
+		{TodoID: write, Action: models.TodoChangeUpdated, CreatedAt: at(3, 10), Changes: models.FieldChanges{
+			{Field: "status", Old: "in_progress", New: "completed"},
+		}},
+		{TodoID: write, Action: models.TodoChangeUpdated, CreatedAt: at(2, 12), Changes: models.FieldChanges{
+			{Field: "estimate", Old: float64(8), New: float64(5)},
+		}},
+	}
+
+	tests := []struct {
+		name  string
+		match func(*effortState) bool
+		want  []models.BurndownPoint
+	}{
+		{
+			name:  "all todos",
+			match: func(s *effortState) bool { return s.exists && !s.deleted },
+			want: []models.BurndownPoint{
+				{Date: "2024-03-01", Remaining: 11},
+				{Date: "2024-03-02", Remaining: 8},
+				{Date: "2024-03-03", Remaining: 5, Completed: 5},
+				{Date: "2024-03-04", Remaining: 5, Completed: 5},
+			},
+		},
+		{
+			name:  "one list",
+			match: func(s *effortState) bool { return s.exists && s.listID == listID.String() },
+			want: []models.BurndownPoint{
+				{Date: "2024-03-01"},
+				{Date: "2024-03-02"},
+				{Date: "2024-03-03"},
+				{Date: "2024-03-04", Remaining: 3},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			states := make(map[uuid.UUID]*effortState, len(todos))
+			for i := range todos {
+				states[todos[i].ID] = newEffortState(&todos[i])
+			}
+			days := []time.Time{day(1), day(2), day(3), day(4)}
+
+			// Today ends at now, after the last change
+			points := burndownSeries(states, changes, days, at(4, 12), tt.match)
+			assert.Equal(t, tt.want, points)
+		})
+	}
+}
+
+func TestEffortStateRevertDeletion(t *testing.T) {
+	state := &effortState{exists: true, deleted: true, tags: []string{"work"}}
+
+	state.revert(&models.TodoChange{Action: models.TodoChangeDeleted})
+	assert.False(t, state.deleted)
+
+	state.revert(&models.TodoChange{Action: models.TodoChangeUpdated, Changes: models.FieldChanges{
+		{Field: "tags", Old: []interface{}{"home"}, New: []interface{}{"work"}},
+	}})
+	assert.True(t, state.hasTag("home"))
+	assert.False(t, state.hasTag("work"))
+
+	state.revert(&models.TodoChange{Action: models.TodoChangeCreated})
+	assert.False(t, state.exists)
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"todo-backend/internal/models"
+	"todo-backend/internal/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// exportBatchSize is the number of todos read from the database at a time
+const exportBatchSize = 500
+
+// exportSort walks todos in the order they were created
+var exportSort = []models.TodoSort{{Field: "created_at"}}
+
+// errExportTooLarge stops an export that outgrows what an import reads
+var errExportTooLarge = fmt.Errorf("invalid export: larger than %d bytes", models.ImportMaxBytes)
+
+type ExportService interface {
+	// Export writes the user's todos to w, reading them in batches and
+	// flushing w, when it is a flusher, after every batch. Errors before
+	// anything is written leave w untouched. Exports are held to the limits
+	// of imports, so that every export can be imported again: more than
+	// models.ImportMaxItems todos are refused up front, and the export stops
+	// with errExportTooLarge after models.ImportMaxBytes, unless the request
+	// is complete.
+	Export(userID uuid.UUID, req *models.ExportRequest, w io.Writer) error
+}
+
+type exportService struct {
+	todoRepo     repository.TodoRepository
+	listRepo     repository.ListRepository
+	workflowRepo repository.WorkflowRepository
+	userRepo     repository.UserRepository
+	now          func() time.Time
+}
+
+func NewExportService(todoRepo repository.TodoRepository, listRepo repository.ListRepository, workflowRepo repository.WorkflowRepository, userRepo repository.UserRepository) ExportService {
+	return &exportService{
+		todoRepo:     todoRepo,
+		listRepo:     listRepo,
+		workflowRepo: workflowRepo,
+		userRepo:     userRepo,
+		now:          time.Now,
+	}
+}
+
+// flusher is implemented by writers that pass buffered output on, such as
+// HTTP response writers
+type flusher interface {
+	Flush()
+}
+
+func (s *exportService) Export(userID uuid.UUID, req *models.ExportRequest, w io.Writer) error {
+	if _, ok := models.ExportFiles[req.Format]; !ok {
+		return errors.New("invalid export format: must be json, csv, markdown or todotxt")
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("user not found")
+		}
+		return err
+	}
+	lists, err := s.listRepo.GetByUserID(userID)
+	if err != nil {
+		return err
+	}
+
+	filter := models.TodoFilter{}
+	if req.Filter != nil {
+		filter = *req.Filter
+	}
+	filter.Sort = exportSort
+	filter.Location = user.Location()
+
+	// Only lists the filter asks for are exported
+	if len(filter.ListIDs) > 0 {
+		wanted := make(map[uuid.UUID]bool, len(filter.ListIDs))
+		for _, id := range filter.ListIDs {
+			wanted[id] = true
+		}
+		selected := lists[:0:0]
+		for _, list := range lists {
+			if wanted[list.ID] {
+				selected = append(selected, list)
+			}
+		}
+		lists = selected
+	}
+
+	out := w
+	if !req.Complete {
+		count, err := s.todoRepo.Count(userID, &filter)
+		if err != nil {
+			return err
+		}
+		if count > models.ImportMaxItems {
+			return fmt.Errorf("invalid export: more than %d todos, export a list, tag or filter at a time", models.ImportMaxItems)
+		}
+		out = &limitedWriter{w: w, left: models.ImportMaxBytes}
+	}
+
+	// Only JSON keeps the lists' boards
+	workflows := make(map[uuid.UUID]models.Workflow)
+	if req.Format == models.ExportFormatJSON {
+		for _, list := range lists {
+			states, err := s.workflowRepo.GetByListID(list.ID)
+			if err != nil {
+				return err
+			}
+			if len(states) > 0 {
+				workflows[list.ID] = states
+			}
+		}
+	}
+
+	buffered := bufio.NewWriter(out)
+	flush := func() error {
+		if err := buffered.Flush(); err != nil {
+			return err
+		}
+		if f, ok := w.(flusher); ok {
+			f.Flush()
+		}
+		return nil
+	}
+
+	writer := newExportWriter(req.Format, buffered, lists, workflows, filter.Location)
+	if err := writer.begin(s.now()); err != nil {
+		return err
+	}
+
+	if req.Format == models.ExportFormatMarkdown {
+		// Markdown groups the todos by list, followed by those outside a list
+		sort.SliceStable(lists, func(i, j int) bool {
+			return strings.ToLower(lists[i].Name) < strings.ToLower(lists[j].Name)
+		})
+		for i := range lists {
+			section := filter
+			section.ListIDs = []uuid.UUID{lists[i].ID}
+			if err := writer.section(&lists[i]); err != nil {
+				return err
+			}
+			if err := s.each(userID, &section, writer, flush); err != nil {
+				return err
+			}
+		}
+		if len(filter.ListIDs) == 0 {
+			section := filter
+			section.WithoutList = true
+			if err := writer.section(nil); err != nil {
+				return err
+			}
+			if err := s.each(userID, &section, writer, flush); err != nil {
+				return err
+			}
+		}
+	} else if err := s.each(userID, &filter, writer, flush); err != nil {
+		return err
+	}
+
+	if err := writer.end(); err != nil {
+		return err
+	}
+	return flush()
+}
+
+// limitedWriter passes at most left bytes on to w
+type limitedWriter struct {
+	w    io.Writer
+	left int64
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if int64(len(p)) > l.left {
+		return 0, errExportTooLarge
+	}
+	n, err := l.w.Write(p)
+	l.left -= int64(n)
+	return n, err
+}
+
+// each writes the todos matching filter, one batch at a time
+func (s *exportService) each(userID uuid.UUID, filter *models.TodoFilter, writer exportWriter, flush func() error) error {
+	var cursor *models.TodoCursor
+	for {
+		todos, err := s.todoRepo.FindAfter(userID, filter, cursor, exportBatchSize)
+		if err != nil {
+			return err
+		}
+		for i := range todos {
+			if err := writer.write(&todos[i]); err != nil {
+				return err
+			}
+		}
+		if err := flush(); err != nil {
+			return err
+		}
+		if len(todos) < exportBatchSize {
+			return nil
+		}
+		cursor = models.NewTodoCursor(&todos[len(todos)-1], filter.Sort, false)
+	}
+}
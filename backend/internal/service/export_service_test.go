@@ -0,0 +1,229 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+	"todo-backend/internal/models"
+	"todo-backend/internal/repository"
+	"todo-backend/pkg/todoimport"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func exportFixture() ([]models.List, []models.Todo) {
+	home := models.List{ID: uuid.New(), Name: "Home", EstimateUnit: models.EstimateUnitMinutes}
+	sprint := models.List{ID: uuid.New(), Name: "Sprint", EstimateUnit: models.EstimateUnitPoints}
+
+	created := time.Date(2024, 2, 20, 8, 0, 0, 0, time.UTC)
+	due := time.Date(2024, 3, 5, 8, 0, 0, 0, time.UTC)
+	minutes, points := 30.0, 3.0
+	first, second, third, review := "c", "m", "a", "review"
+	parent := models.Todo{
+		ID: uuid.New(), Title: "Groceries", Description: "Weekly shop\nBring bags", Status: models.TodoStatusInProgress,
+		Priority: 4, DueDate: &due, RecurrenceRule: "FREQ=WEEKLY", Timezone: "Europe/Berlin", Estimate: &minutes,
+		ListID: &home.ID, Tags: []models.Tag{{Name: "errands"}, {Name: "side project"}}, Position: &second,
+		CreatedAt: created, UpdatedAt: created,
+	}
+	child := models.Todo{
+		ID: uuid.New(), Title: "Milk", Status: models.TodoStatusCompleted, Priority: 1, ParentID: &parent.ID,
+		ListID: &home.ID, Position: &third, CreatedAt: created, UpdatedAt: created.Add(24 * time.Hour),
+	}
+	story := models.Todo{
+		ID: uuid.New(), Title: "Login page", Status: models.TodoStatusInProgress, State: &review, Estimate: &points,
+		ListID: &sprint.ID, Position: &first, CreatedAt: created, UpdatedAt: created,
+	}
+	inbox := models.Todo{ID: uuid.New(), Title: "Call mom", Status: models.TodoStatusPending, CreatedAt: created, UpdatedAt: created}
+	return []models.List{home, sprint}, []models.Todo{child, parent, story, inbox}
+}
+
+// sprintWorkflow is the board of the fixture's Sprint list
+func sprintWorkflow(listID uuid.UUID) models.Workflow {
+	return models.Workflow{
+		{ListID: listID, Key: "backlog", Name: "Backlog", Category: models.StateCategoryTodo, Position: 0, Transitions: models.StateKeys{}},
+		{ListID: listID, Key: "review", Name: "Review", Category: models.StateCategoryDoing, Position: 1, WIPLimit: 3, Transitions: models.StateKeys{"shipped"}},
+		{ListID: listID, Key: "shipped", Name: "Shipped", Category: models.StateCategoryDone, Position: 2, Transitions: models.StateKeys{}},
+	}
+}
+
+func writeExport(t *testing.T, format string, lists []models.List, todos []models.Todo, sections bool) string {
+	t.Helper()
+	var buf bytes.Buffer
+	loc := time.FixedZone("CET", 60*60)
+	workflows := map[uuid.UUID]models.Workflow{lists[1].ID: sprintWorkflow(lists[1].ID)}
+	writer := newExportWriter(format, &buf, lists, workflows, loc)
+	require.NoError(t, writer.begin(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)))
+	// Sections start where the list changes, as the service groups by list
+	for i := range todos {
+		if sections && (i == 0 || !sameListID(todos[i-1].ListID, todos[i].ListID)) {
+			var list *models.List
+			for j := range lists {
+				if todos[i].ListID != nil && lists[j].ID == *todos[i].ListID {
+					list = &lists[j]
+				}
+			}
+			require.NoError(t, writer.section(list))
+		}
+		require.NoError(t, writer.write(&todos[i]))
+	}
+	require.NoError(t, writer.end())
+	return buf.String()
+}
+
+func TestJSONExportRoundTrip(t *testing.T) {
+	lists, todos := exportFixture()
+	data := writeExport(t, models.ExportFormatJSON, lists, todos, false)
+
+	result, err := todoimport.Parse("", []byte(data), todoimport.Options{})
+	require.NoError(t, err)
+	assert.Equal(t, todoimport.FormatJSON, result.Format)
+	assert.Empty(t, result.Skipped)
+	require.Len(t, result.Items, 4)
+
+	plan := newImportPlan(result.Items...)
+	for _, list := range result.Lists {
+		if list.EstimateUnit == string(models.EstimateUnitPoints) {
+			plan.units[strings.ToLower(list.Name)] = models.EstimateUnitPoints
+		}
+	}
+	plan.lists["sprint"] = &lists[1]
+	byTitle := make(map[string]models.TodoCreateRequest)
+	for i := range plan.items {
+		byTitle[plan.items[i].Title] = plan.request(&plan.items[i])
+	}
+
+	// Todos come in their manual order, those without a position last, and
+	// the subtask, exported first and placed before its parent, after it
+	titles := make([]string, len(result.Items))
+	for i, item := range result.Items {
+		titles[i] = item.Title
+	}
+	assert.Equal(t, []string{"Login page", "Groceries", "Milk", "Call mom"}, titles)
+	assert.Equal(t, 1, result.Items[2].Parent)
+	assert.Equal(t, "Home", result.Items[2].List)
+
+	// The Sprint list keeps its board and the story its state on it
+	assert.Empty(t, result.Lists[0].Workflow)
+	workflow, err := importWorkflow(result.Lists[1].Workflow)
+	require.NoError(t, err)
+	assert.Equal(t, sprintWorkflow(lists[1].ID), workflow.Workflow(lists[1].ID))
+	assert.Equal(t, "review", byTitle["Login page"].State)
+	assert.Equal(t, models.TodoStatusInProgress, byTitle["Login page"].Status)
+
+	parent := todos[1]
+	assert.Equal(t, models.TodoCreateRequest{
+		Title:          parent.Title,
+		Description:    parent.Description,
+		Status:         parent.Status,
+		Priority:       parent.Priority,
+		DueDate:        parent.DueDate,
+		RecurrenceRule: parent.RecurrenceRule,
+		Timezone:       parent.Timezone,
+		Estimate:       parent.Estimate,
+		Tags:           []string{"errands", "side project"},
+	}, byTitle["Groceries"])
+	assert.Equal(t, models.TodoStatusCompleted, byTitle["Milk"].Status)
+	// Points stay points in an existing list estimating in points
+	assert.Equal(t, todos[2].Estimate, byTitle["Login page"].Estimate)
+	assert.Empty(t, plan.report.Skipped)
+}
+
+type exportTodos struct {
+	repository.TodoRepository
+	count int64
+}
+
+func (r *exportTodos) Count(userID uuid.UUID, filter *models.TodoFilter) (int64, error) {
+	return r.count, nil
+}
+
+func (r *exportTodos) FindAfter(userID uuid.UUID, filter *models.TodoFilter, cursor *models.TodoCursor, limit int) ([]models.Todo, error) {
+	return nil, nil
+}
+
+type exportLists struct {
+	repository.ListRepository
+}
+
+func (exportLists) GetByUserID(userID uuid.UUID) ([]models.List, error) {
+	return nil, nil
+}
+
+func TestExportKeepsToImportLimits(t *testing.T) {
+	s := NewExportService(&exportTodos{count: models.ImportMaxItems + 1}, exportLists{}, nil, &bulkUsers{})
+
+	var buf bytes.Buffer
+	err := s.Export(uuid.New(), &models.ExportRequest{Format: models.ExportFormatTodoTxt}, &buf)
+	require.Error(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), "invalid export: more than 10000 todos"), err.Error())
+	assert.Zero(t, buf.Len())
+
+	// Account archives hold every todo
+	require.NoError(t, s.Export(uuid.New(), &models.ExportRequest{Format: models.ExportFormatTodoTxt, Complete: true}, &buf))
+	buf.Reset()
+
+	limited := &limitedWriter{w: &buf, left: 5}
+	_, err = limited.Write([]byte("abc"))
+	require.NoError(t, err)
+	_, err = limited.Write([]byte("def"))
+	assert.Equal(t, errExportTooLarge, err)
+	assert.Equal(t, "abc", buf.String())
+}
+
+func TestCSVExportWriter(t *testing.T) {
+	lists, todos := exportFixture()
+	data := writeExport(t, models.ExportFormatCSV, lists, todos[1:2], false)
+
+	records, err := csv.NewReader(bytes.NewBufferString(data)).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, csvExportHeader, records[0])
+	assert.Equal(t, []string{
+		todos[1].ID.String(), "", "Groceries", "Weekly shop\nBring bags", "in_progress", "4", "2024-03-05T08:00:00Z",
+		"Home", "errands, side project", "30", "FREQ=WEEKLY", "Europe/Berlin", "2024-02-20T08:00:00Z", "2024-02-20T08:00:00Z",
+	}, records[1])
+}
+
+func TestMarkdownExportWriter(t *testing.T) {
+	lists, todos := exportFixture()
+	data := writeExport(t, models.ExportFormatMarkdown, lists, []models.Todo{todos[1], todos[0], todos[3]}, true)
+
+	assert.Equal(t, "# Todos\n"+
+		"\n## Home\n\n"+
+		"- [ ] Groceries (in progress, due 2024-03-05 09:00, priority 4) #errands #side-project\n"+
+		"  Weekly shop\n"+
+		"  Bring bags\n"+
+		"- [x] Milk (priority 1)\n"+
+		"\n## Inbox\n\n"+
+		"- [ ] Call mom\n", data)
+}
+
+func TestTodoTxtExportWriter(t *testing.T) {
+	lists, todos := exportFixture()
+	data := writeExport(t, models.ExportFormatTodoTxt, lists, []models.Todo{todos[1], todos[0], todos[3]}, false)
+
+	assert.Equal(t, "(B) 2024-02-20 Groceries +Home @errands @side-project due:2024-03-05\n"+
+		"x 2024-02-21 2024-02-20 Milk +Home pri:E\n"+
+		"2024-02-20 Call mom\n", data)
+
+	// Reading the export back keeps status, priority, list, tags and due day
+	result, err := todoimport.Parse(todoimport.FormatTodoTxt, []byte(data), todoimport.Options{Location: time.FixedZone("CET", 60*60)})
+	require.NoError(t, err)
+	require.Len(t, result.Items, 3)
+	assert.Equal(t, 4, result.Items[0].Priority)
+	assert.Equal(t, "Home", result.Items[0].List)
+	assert.Equal(t, []string{"errands", "side-project"}, result.Items[0].Tags)
+	assert.Equal(t, "2024-03-05", result.Items[0].Due.Format("2006-01-02"))
+	assert.Equal(t, todoimport.StatusCompleted, result.Items[1].Status)
+	assert.Equal(t, 1, result.Items[1].Priority)
+}
+
+func TestTodoTxtExportPriority(t *testing.T) {
+	for priority, want := range map[int]string{0: "", 1: "E", 2: "D", 3: "C", 4: "B", 5: "A", 6: ""} {
+		assert.Equal(t, want, todoTxtExportPriority(priority), priority)
+	}
+}
@@ -0,0 +1,312 @@
+package service
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"todo-backend/internal/models"
+	"todo-backend/pkg/todoimport"
+
+	"github.com/google/uuid"
+)
+
+// exportWriter writes one export format. Sections group the following todos
+// under a list, or under no list for nil; only Markdown shows them.
+type exportWriter interface {
+	begin(now time.Time) error
+	section(list *models.List) error
+	write(todo *models.Todo) error
+	end() error
+}
+
+// newExportWriter returns the writer of a format. workflows holds the
+// workflows of the lists that have their own; only JSON exports them.
+func newExportWriter(format string, w io.Writer, lists []models.List, workflows map[uuid.UUID]models.Workflow, loc *time.Location) exportWriter {
+	names := make(map[uuid.UUID]string, len(lists))
+	for _, list := range lists {
+		names[list.ID] = list.Name
+	}
+
+	switch format {
+	case models.ExportFormatCSV:
+		return &csvExportWriter{w: csv.NewWriter(w), lists: names}
+	case models.ExportFormatMarkdown:
+		return &markdownExportWriter{w: w, loc: loc}
+	case models.ExportFormatTodoTxt:
+		return &todoTxtExportWriter{w: w, lists: names, loc: loc}
+	}
+	return &jsonExportWriter{w: w, lists: lists, workflows: workflows}
+}
+
+// jsonExportWriter writes a todoimport.Document, one todo per line
+type jsonExportWriter struct {
+	w         io.Writer
+	lists     []models.List
+	workflows map[uuid.UUID]models.Workflow
+	count     int
+}
+
+func (e *jsonExportWriter) begin(now time.Time) error {
+	lists := make([]todoimport.JSONList, len(e.lists))
+	for i, list := range e.lists {
+		lists[i] = todoimport.JSONList{ID: list.ID.String(), Name: list.Name, EstimateUnit: string(list.EstimateUnit)}
+		for _, state := range e.workflows[list.ID] {
+			lists[i].Workflow = append(lists[i].Workflow, todoimport.JSONState{
+				Key:         state.Key,
+				Name:        state.Name,
+				Category:    string(state.Category),
+				WIPLimit:    state.WIPLimit,
+				Transitions: []string(state.Transitions),
+			})
+		}
+	}
+	data, err := json.Marshal(lists)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(e.w, "{\"version\":%d,\"exported_at\":%q,\"lists\":%s,\"todos\":[",
+		todoimport.SchemaVersion, now.UTC().Format(time.RFC3339), data)
+	return err
+}
+
+func (e *jsonExportWriter) section(*models.List) error {
+	return nil
+}
+
+func (e *jsonExportWriter) write(todo *models.Todo) error {
+	data, err := json.Marshal(jsonExportTodo(todo))
+	if err != nil {
+		return err
+	}
+	separator := ",\n"
+	if e.count == 0 {
+		separator = "\n"
+	}
+	e.count++
+	if _, err := io.WriteString(e.w, separator); err != nil {
+		return err
+	}
+	_, err = e.w.Write(data)
+	return err
+}
+
+func (e *jsonExportWriter) end() error {
+	_, err := io.WriteString(e.w, "\n]}\n")
+	return err
+}
+
+func jsonExportTodo(todo *models.Todo) todoimport.JSONTodo {
+	out := todoimport.JSONTodo{
+		ID:             todo.ID.String(),
+		Title:          todo.Title,
+		Description:    todo.Description,
+		Status:         todoimport.Status(todo.Status),
+		Priority:       todo.Priority,
+		DueDate:        todo.DueDate,
+		RecurrenceRule: todo.RecurrenceRule,
+		Timezone:       todo.Timezone,
+		Estimate:       todo.Estimate,
+		Tags:           todo.TagNames(),
+		CreatedAt:      todo.CreatedAt,
+		UpdatedAt:      todo.UpdatedAt,
+	}
+	if todo.ParentID != nil {
+		out.ParentID = todo.ParentID.String()
+	}
+	if todo.ListID != nil {
+		out.ListID = todo.ListID.String()
+	}
+	if todo.State != nil {
+		out.State = *todo.State
+	}
+	if todo.Position != nil {
+		out.Position = *todo.Position
+	}
+	return out
+}
+
+// csvExportHeader names the columns of a CSV export
+var csvExportHeader = []string{
+	"id", "parent_id", "title", "description", "status", "priority", "due_date",
+	"list", "tags", "estimate", "recurrence_rule", "timezone", "created_at", "updated_at",
+}
+
+type csvExportWriter struct {
+	w     *csv.Writer
+	lists map[uuid.UUID]string
+}
+
+func (e *csvExportWriter) begin(time.Time) error {
+	return e.w.Write(csvExportHeader)
+}
+
+func (e *csvExportWriter) section(*models.List) error {
+	return nil
+}
+
+func (e *csvExportWriter) write(todo *models.Todo) error {
+	var parentID, due, list, estimate string
+	if todo.ParentID != nil {
+		parentID = todo.ParentID.String()
+	}
+	if todo.DueDate != nil {
+		due = todo.DueDate.UTC().Format(time.RFC3339)
+	}
+	if todo.ListID != nil {
+		list = e.lists[*todo.ListID]
+	}
+	if todo.Estimate != nil {
+		estimate = strconv.FormatFloat(*todo.Estimate, 'f', -1, 64)
+	}
+
+	if err := e.w.Write([]string{
+		todo.ID.String(), parentID, todo.Title, todo.Description, string(todo.Status),
+		strconv.Itoa(todo.Priority), due, list, strings.Join(todo.TagNames(), ", "), estimate,
+		todo.RecurrenceRule, todo.Timezone,
+		todo.CreatedAt.UTC().Format(time.RFC3339), todo.UpdatedAt.UTC().Format(time.RFC3339),
+	}); err != nil {
+		return err
+	}
+	// Pass the row on to the buffered writer flushed after every batch
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvExportWriter) end() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// markdownExportWriter writes a checklist per list; descriptions follow their
+// todo as indented lines
+type markdownExportWriter struct {
+	w   io.Writer
+	loc *time.Location
+	// heading of the current section, written before its first todo
+	heading string
+}
+
+func (e *markdownExportWriter) begin(time.Time) error {
+	_, err := io.WriteString(e.w, "# Todos\n")
+	return err
+}
+
+func (e *markdownExportWriter) section(list *models.List) error {
+	e.heading = "Inbox"
+	if list != nil {
+		e.heading = singleLine(list.Name)
+	}
+	return nil
+}
+
+func (e *markdownExportWriter) write(todo *models.Todo) error {
+	var b strings.Builder
+	if e.heading != "" {
+		b.WriteString("\n## " + e.heading + "\n\n")
+		e.heading = ""
+	}
+
+	check := " "
+	if todo.Status == models.TodoStatusCompleted {
+		check = "x"
+	}
+	b.WriteString("- [" + check + "] " + singleLine(todo.Title))
+
+	var details []string
+	if todo.Status == models.TodoStatusInProgress {
+		details = append(details, "in progress")
+	}
+	if todo.DueDate != nil {
+		details = append(details, "due "+todo.DueDate.In(e.loc).Format("2006-01-02 15:04"))
+	}
+	if todo.Priority > 0 {
+		details = append(details, "priority "+strconv.Itoa(todo.Priority))
+	}
+	if len(details) > 0 {
+		b.WriteString(" (" + strings.Join(details, ", ") + ")")
+	}
+	for _, tag := range todo.TagNames() {
+		b.WriteString(" #" + strings.Join(strings.Fields(tag), "-"))
+	}
+	b.WriteString("\n")
+
+	if description := strings.TrimSpace(todo.Description); description != "" {
+		for _, line := range strings.Split(description, "\n") {
+			b.WriteString("  " + strings.TrimRight(line, "\r") + "\n")
+		}
+	}
+
+	_, err := io.WriteString(e.w, b.String())
+	return err
+}
+
+func (e *markdownExportWriter) end() error {
+	return nil
+}
+
+// todoTxtExportWriter writes one todo.txt line per todo. Completed todos keep
+// their priority in a pri: tag and take their last update as completion date.
+type todoTxtExportWriter struct {
+	w     io.Writer
+	lists map[uuid.UUID]string
+	loc   *time.Location
+}
+
+func (e *todoTxtExportWriter) begin(time.Time) error {
+	return nil
+}
+
+func (e *todoTxtExportWriter) section(*models.List) error {
+	return nil
+}
+
+func (e *todoTxtExportWriter) write(todo *models.Todo) error {
+	var parts []string
+	priority := todoTxtExportPriority(todo.Priority)
+	if todo.Status == models.TodoStatusCompleted {
+		parts = append(parts, "x", todo.UpdatedAt.In(e.loc).Format("2006-01-02"))
+	} else if priority != "" {
+		parts = append(parts, "("+priority+")")
+	}
+	parts = append(parts, todo.CreatedAt.In(e.loc).Format("2006-01-02"), singleLine(todo.Title))
+
+	if todo.ListID != nil {
+		if name := e.lists[*todo.ListID]; name != "" {
+			parts = append(parts, "+"+strings.Join(strings.Fields(name), "-"))
+		}
+	}
+	for _, tag := range todo.TagNames() {
+		parts = append(parts, "@"+strings.Join(strings.Fields(tag), "-"))
+	}
+	if todo.DueDate != nil {
+		parts = append(parts, "due:"+todo.DueDate.In(e.loc).Format("2006-01-02"))
+	}
+	if todo.Status == models.TodoStatusCompleted && priority != "" {
+		parts = append(parts, "pri:"+priority)
+	}
+
+	_, err := io.WriteString(e.w, strings.Join(parts, " ")+"\n")
+	return err
+}
+
+func (e *todoTxtExportWriter) end() error {
+	return nil
+}
+
+// todoTxtExportPriority maps priorities 5 to 1 onto (A) to (E), the reverse
+// of the todo.txt import
+func todoTxtExportPriority(priority int) string {
+	if priority < 1 || priority > 5 {
+		return ""
+	}
+	return string(rune('A' + 5 - priority))
+}
+
+// singleLine collapses whitespace, including line breaks, to single spaces
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
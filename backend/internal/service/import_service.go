@@ -0,0 +1,512 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"todo-backend/internal/models"
+	"todo-backend/internal/repository"
+	"todo-backend/pkg/todoimport"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+// importClientID is recorded in the history of imported todos
+const importClientID = "import"
+
+// Imports of up to importSyncTodos todos complete before the request returns,
+// larger ones run in the background in batches of about importBatchTodos
+const (
+	importSyncTodos  = 200
+	importBatchTodos = 100
+)
+
+var (
+	errImportInterrupted = errors.New("import interrupted by a server shutdown")
+	errImportStale       = errors.New("import stopped making progress")
+	errImportGivenUp     = errors.New("import was given up on")
+)
+
+type ImportService interface {
+	// Preview parses an import without creating anything
+	Preview(userID uuid.UUID, req *models.ImportRequest) (*models.ImportPreview, error)
+	// Import creates an import's todos and lists. The returned job is
+	// completed for small imports and pending for large ones, which run in
+	// the background.
+	Import(userID uuid.UUID, req *models.ImportRequest) (*models.ImportJob, error)
+	GetJob(id uuid.UUID, userID uuid.UUID) (*models.ImportJob, error)
+}
+
+type importService struct {
+	jobRepo     repository.ImportJobRepository
+	listService ListService
+	userRepo    repository.UserRepository
+	todoService TodoService
+	queue       BackgroundQueue
+	// Background jobs not updated for jobTimeout are reported as failed
+	jobTimeout time.Duration
+	now        func() time.Time
+}
+
+func NewImportService(jobRepo repository.ImportJobRepository, listService ListService, userRepo repository.UserRepository, todoService TodoService, queue BackgroundQueue, jobTimeout time.Duration) ImportService {
+	return &importService{
+		jobRepo:     jobRepo,
+		listService: listService,
+		userRepo:    userRepo,
+		todoService: todoService.ForClient(importClientID),
+		queue:       queue,
+		jobTimeout:  jobTimeout,
+		now:         time.Now,
+	}
+}
+
+// importPlan is a parsed import: the todos to create, without their list and
+// parent, and the lists they go to
+type importPlan struct {
+	format   todoimport.Format
+	items    []todoimport.Item
+	requests []models.TodoCreateRequest
+	// lists maps the lowercased names of the import's lists to existing
+	// lists, or to nil for lists the import creates
+	lists map[string]*models.List
+	// units maps the lowercased names of the import's lists to the unit their
+	// estimates are in; minutes unless the import says otherwise
+	units map[string]models.EstimateUnit
+	// workflows maps the lowercased names of the import's lists to the
+	// boards of those that have their own, applied to the lists it creates
+	workflows map[string]*models.WorkflowRequest
+	report    models.ImportReport
+}
+
+func (s *importService) Preview(userID uuid.UUID, req *models.ImportRequest) (*models.ImportPreview, error) {
+	plan, err := s.plan(userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &models.ImportPreview{
+		Format: string(plan.format),
+		Total:  len(plan.items),
+		Todos:  make([]models.ImportPreviewTodo, 0, models.ImportPreviewTodos),
+		Report: plan.report,
+	}
+	for i := 0; i < len(plan.items) && i < models.ImportPreviewTodos; i++ {
+		item, request := plan.items[i], plan.requests[i]
+		todo := models.ImportPreviewTodo{
+			Line:           item.Line,
+			Title:          request.Title,
+			Description:    request.Description,
+			Status:         request.Status,
+			Priority:       request.Priority,
+			DueDate:        request.DueDate,
+			RecurrenceRule: request.RecurrenceRule,
+			Estimate:       request.Estimate,
+			List:           item.List,
+			Tags:           request.Tags,
+		}
+		if item.Parent >= 0 {
+			todo.ParentLine = &plan.items[item.Parent].Line
+		}
+		preview.Todos = append(preview.Todos, todo)
+	}
+	return preview, nil
+}
+
+func (s *importService) Import(userID uuid.UUID, req *models.ImportRequest) (*models.ImportJob, error) {
+	plan, err := s.plan(userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &models.ImportJob{
+		UserID: userID,
+		Format: string(plan.format),
+		Status: models.ImportJobPending,
+		Total:  len(plan.items),
+		Report: plan.report,
+	}
+	if err := s.jobRepo.Create(job); err != nil {
+		return nil, err
+	}
+
+	if job.Total <= importSyncTodos {
+		if err := s.run(context.Background(), job, plan); err != nil {
+			s.fail(job, err)
+			return nil, err
+		}
+		return job, nil
+	}
+
+	background := *job
+	err = s.queue.Enqueue(func(ctx context.Context) {
+		defer func() {
+			if r := recover(); r != nil {
+				s.fail(&background, fmt.Errorf("panic: %v", r))
+			}
+		}()
+		if err := s.run(ctx, &background, plan); err != nil {
+			s.fail(&background, err)
+		}
+	})
+	if err != nil {
+		s.fail(job, err)
+		return nil, err
+	}
+	return job, nil
+}
+
+func (s *importService) GetJob(id uuid.UUID, userID uuid.UUID) (*models.ImportJob, error) {
+	job, err := s.jobRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("import job not found")
+		}
+		return nil, err
+	}
+	if job.UserID != userID {
+		return nil, errors.New("unauthorized to access this import job")
+	}
+
+	// A job that stopped updating was lost, e.g. to a crash or a restart
+	if (job.Status == models.ImportJobPending || job.Status == models.ImportJobRunning) && stale(job.UpdatedAt, s.jobTimeout, s.now()) {
+		s.fail(job, errImportStale)
+		if job.Status != models.ImportJobFailed {
+			// It finished after all
+			return s.jobRepo.GetByID(id)
+		}
+	}
+	return job, nil
+}
+
+// plan parses an import and maps its items onto todo create requests
+func (s *importService) plan(userID uuid.UUID, req *models.ImportRequest) (*importPlan, error) {
+	var format todoimport.Format
+	if req.Format != "" {
+		var err error
+		if format, err = todoimport.ParseFormat(req.Format); err != nil {
+			return nil, fmt.Errorf("invalid import: %w", err)
+		}
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+
+	result, err := todoimport.Parse(format, req.Data, todoimport.Options{Location: user.Location(), Name: req.FileName})
+	if err != nil {
+		return nil, fmt.Errorf("invalid import: %w", err)
+	}
+	if len(result.Items) > models.ImportMaxItems {
+		return nil, fmt.Errorf("invalid import: more than %d todos", models.ImportMaxItems)
+	}
+
+	existing, err := s.listService.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	plan := &importPlan{
+		format:    result.Format,
+		items:     result.Items,
+		lists:     make(map[string]*models.List),
+		units:     make(map[string]models.EstimateUnit),
+		workflows: make(map[string]*models.WorkflowRequest),
+		report:    models.ImportReport{Lists: []string{}, SkippedFields: map[string]int{}, Skipped: []todoimport.Skipped{}},
+	}
+	for _, list := range result.Lists {
+		key := strings.ToLower(strings.TrimSpace(list.Name))
+		if unit := models.EstimateUnit(list.EstimateUnit); unit == models.EstimateUnitPoints {
+			plan.units[key] = unit
+		}
+		if len(list.Workflow) > 0 {
+			workflow, err := importWorkflow(list.Workflow)
+			if err != nil {
+				plan.report.Skip(todoimport.Skipped{Field: "workflow", Value: list.Name, Reason: err.Error()})
+				continue
+			}
+			plan.workflows[key] = workflow
+		}
+	}
+	for _, skipped := range result.Skipped {
+		plan.report.Skip(skipped)
+	}
+	plan.planLists(existing)
+
+	plan.requests = make([]models.TodoCreateRequest, len(plan.items))
+	for i := range plan.items {
+		plan.requests[i] = plan.request(&plan.items[i])
+	}
+	return plan, nil
+}
+
+// planLists matches the import's list names to the user's lists, ignoring
+// case, and names the lists it has to create
+func (p *importPlan) planLists(existing []models.List) {
+	byName := make(map[string]*models.List, len(existing))
+	for i := range existing {
+		byName[strings.ToLower(existing[i].Name)] = &existing[i]
+	}
+
+	for i := range p.items {
+		item := &p.items[i]
+		item.List = truncateRunes(strings.TrimSpace(item.List), 100)
+		if item.List == "" {
+			continue
+		}
+		key := strings.ToLower(item.List)
+		if _, ok := p.lists[key]; ok {
+			continue
+		}
+		p.lists[key] = byName[key]
+		if byName[key] == nil {
+			p.report.Lists = append(p.report.Lists, item.List)
+		}
+	}
+}
+
+// importWorkflow maps a list's exported board onto a workflow request, within
+// the limits of the request's validation
+func importWorkflow(states []todoimport.JSONState) (*models.WorkflowRequest, error) {
+	req := &models.WorkflowRequest{States: make([]models.WorkflowStateRequest, len(states))}
+	for i, state := range states {
+		category := models.StateCategory(state.Category)
+		switch category {
+		case models.StateCategoryTodo, models.StateCategoryDoing, models.StateCategoryDone:
+		default:
+			return nil, fmt.Errorf("invalid workflow: state %q has an unknown category %q", state.Key, state.Category)
+		}
+		if state.WIPLimit < 0 || state.WIPLimit > 1000 || len(state.Transitions) > 20 {
+			return nil, fmt.Errorf("invalid workflow: state %q allows at most 1000 todos and 20 transitions", state.Key)
+		}
+		name := truncateRunes(strings.TrimSpace(state.Name), 100)
+		if name == "" {
+			name = state.Key
+		}
+		req.States[i] = models.WorkflowStateRequest{
+			Key:         state.Key,
+			Name:        name,
+			Category:    category,
+			WIPLimit:    state.WIPLimit,
+			Transitions: state.Transitions,
+		}
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// request maps an item onto a todo create request, within the limits of the
+// request's validation
+func (p *importPlan) request(item *todoimport.Item) models.TodoCreateRequest {
+	req := models.TodoCreateRequest{
+		Title:       strings.TrimSpace(item.Title),
+		Description: strings.TrimSpace(item.Description),
+		Status:      models.TodoStatus(item.Status),
+		Priority:    item.Priority,
+		DueDate:     item.Due,
+		Estimate:    item.Estimate,
+		Tags:        []string{},
+		State:       item.State,
+	}
+
+	if len(item.RecurrenceRule) > 500 || len(item.Timezone) > 64 {
+		p.report.Skip(todoimport.Skipped{Line: item.Line, Field: "recurrence_rule", Value: item.RecurrenceRule, Reason: "recurrence rules are at most 500 and timezones 64 characters"})
+	} else {
+		req.RecurrenceRule = item.RecurrenceRule
+		req.Timezone = item.Timezone
+	}
+
+	if len([]rune(req.Title)) > 255 {
+		req.Title = truncateRunes(req.Title, 255)
+		p.report.Skip(todoimport.Skipped{Line: item.Line, Field: "title", Reason: "shortened to 255 characters"})
+	}
+	if len([]rune(req.Description)) > 1000 {
+		req.Description = truncateRunes(req.Description, 1000)
+		p.report.Skip(todoimport.Skipped{Line: item.Line, Field: "description", Reason: "shortened to 1000 characters"})
+	}
+
+	for _, tag := range normalizeTagNames(item.Tags) {
+		switch {
+		case len([]rune(tag)) > 50:
+			p.report.Skip(todoimport.Skipped{Line: item.Line, Field: "tag", Value: tag, Reason: "tags are at most 50 characters"})
+		case len(req.Tags) == 20:
+			p.report.Skip(todoimport.Skipped{Line: item.Line, Field: "tag", Value: tag, Reason: "todos have at most 20 tags"})
+		default:
+			req.Tags = append(req.Tags, tag)
+		}
+	}
+
+	if req.Estimate != nil {
+		key := strings.ToLower(item.List)
+		unit := p.unit(key)
+		list := p.lists[key]
+		switch {
+		case list != nil && list.EstimateUnit != unit:
+			p.report.Skip(todoimport.Skipped{Line: item.Line, Field: "estimate", Reason: "the list estimates in " + string(list.EstimateUnit)})
+			req.Estimate = nil
+		case *req.Estimate > 100000:
+			p.report.Skip(todoimport.Skipped{Line: item.Line, Field: "estimate", Reason: "estimates are at most 100000"})
+			req.Estimate = nil
+		}
+	}
+	return req
+}
+
+// unit returns the unit the estimates of a list of the import are in
+func (p *importPlan) unit(key string) models.EstimateUnit {
+	if unit, ok := p.units[key]; ok {
+		return unit
+	}
+	return models.EstimateUnitMinutes
+}
+
+// run creates the lists and todos of a planned import, updating its job after
+// every batch. A batch that fails is retried one todo tree at a time, and the
+// trees that fail again are reported as skipped. A cancelled ctx stops it
+// between batches, and so does the job being failed by GetJob meanwhile.
+func (s *importService) run(ctx context.Context, job *models.ImportJob, plan *importPlan) error {
+	// Jobs given up on while they waited in the queue are not started
+	job.Status = models.ImportJobRunning
+	if err := s.save(job, models.ImportJobPending); err != nil {
+		return err
+	}
+
+	listIDs := make(map[string]uuid.UUID, len(plan.lists))
+	for key, list := range plan.lists {
+		if list != nil {
+			listIDs[key] = list.ID
+		}
+	}
+	for _, name := range plan.report.Lists {
+		list, err := s.listService.Create(job.UserID, &models.ListCreateRequest{Name: name, EstimateUnit: plan.unit(strings.ToLower(name))})
+		if err != nil {
+			return err
+		}
+		listIDs[strings.ToLower(name)] = list.ID
+		if workflow := plan.workflows[strings.ToLower(name)]; workflow != nil {
+			if _, err := s.listService.SetWorkflow(list.ID, job.UserID, workflow); err != nil {
+				return err
+			}
+		}
+	}
+
+	trees, roots, sizes := plan.trees(listIDs)
+	for start := 0; start < len(trees); {
+		if ctx.Err() != nil {
+			return errImportInterrupted
+		}
+
+		end, count := start, 0
+		for end < len(trees) && (count == 0 || count+sizes[end] <= importBatchTodos) {
+			count += sizes[end]
+			end++
+		}
+
+		created, err := s.todoService.CreateTree(job.UserID, trees[start:end])
+		if err == nil {
+			job.Created += len(created)
+		} else {
+			for i := start; i < end; i++ {
+				created, err := s.todoService.CreateTree(job.UserID, trees[i:i+1])
+				if err != nil {
+					root := plan.items[roots[i]]
+					job.Report.Skip(todoimport.Skipped{Line: root.Line, Field: "todo", Value: trees[i].Title, Reason: err.Error()})
+					continue
+				}
+				job.Created += len(created)
+			}
+		}
+
+		job.Processed += count
+		if err := s.save(job, models.ImportJobRunning); err != nil {
+			return err
+		}
+		start = end
+	}
+
+	finished := s.now()
+	job.Status = models.ImportJobCompleted
+	job.FinishedAt = &finished
+	return s.save(job, models.ImportJobRunning)
+}
+
+// save writes a job's progress as long as its stored status is still status
+func (s *importService) save(job *models.ImportJob, status models.ImportJobStatus) error {
+	saved, err := s.jobRepo.UpdateIfStatus(job, status)
+	if err != nil {
+		return err
+	}
+	if !saved {
+		return errImportGivenUp
+	}
+	return nil
+}
+
+// fail marks a pending or running job as failed; a job that ended meanwhile
+// keeps its outcome
+func (s *importService) fail(job *models.ImportJob, err error) {
+	if errors.Is(err, errImportGivenUp) {
+		return
+	}
+	log.Error().Err(err).Str("job_id", job.ID.String()).Msg("Import failed")
+
+	failed := *job
+	finished := s.now()
+	failed.Status = models.ImportJobFailed
+	failed.Error = err.Error()
+	failed.FinishedAt = &finished
+	saved, updateErr := s.jobRepo.UpdateIfStatus(&failed, models.ImportJobPending, models.ImportJobRunning)
+	if updateErr != nil {
+		log.Error().Err(updateErr).Str("job_id", job.ID.String()).Msg("Failed to record import failure")
+		return
+	}
+	if saved {
+		*job = failed
+	}
+}
+
+// trees nests the planned todos under their parents, returning the top-level
+// trees in import order with the index of each root item and the number of
+// todos in each tree
+func (p *importPlan) trees(listIDs map[string]uuid.UUID) ([]models.TodoTreeRequest, []int, []int) {
+	children := make(map[int][]int)
+	var roots []int
+	for i, item := range p.items {
+		if item.Parent >= 0 {
+			children[item.Parent] = append(children[item.Parent], i)
+		} else {
+			roots = append(roots, i)
+		}
+	}
+
+	var build func(i int) (models.TodoTreeRequest, int)
+	build = func(i int) (models.TodoTreeRequest, int) {
+		req := p.requests[i]
+		if id, ok := listIDs[strings.ToLower(p.items[i].List)]; ok {
+			req.ListID = &id
+		}
+		node := models.TodoTreeRequest{TodoCreateRequest: req}
+		size := 1
+		for _, child := range children[i] {
+			subtask, n := build(child)
+			node.Subtasks = append(node.Subtasks, subtask)
+			size += n
+		}
+		return node, size
+	}
+
+	trees := make([]models.TodoTreeRequest, len(roots))
+	sizes := make([]int, len(roots))
+	for i, root := range roots {
+		trees[i], sizes[i] = build(root)
+	}
+	return trees, roots, sizes
+}
@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+	"todo-backend/internal/models"
+	"todo-backend/internal/repository"
+	"todo-backend/pkg/todoimport"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newImportPlan(items ...todoimport.Item) *importPlan {
+	return &importPlan{
+		items:     items,
+		lists:     make(map[string]*models.List),
+		units:     make(map[string]models.EstimateUnit),
+		workflows: make(map[string]*models.WorkflowRequest),
+		report:    models.ImportReport{Lists: []string{}, SkippedFields: map[string]int{}, Skipped: []todoimport.Skipped{}},
+	}
+}
+
+func TestImportPlanLists(t *testing.T) {
+	work := models.List{ID: uuid.New(), Name: "Work"}
+	plan := newImportPlan(
+		todoimport.Item{Title: "a", List: "work", Parent: -1},
+		todoimport.Item{Title: "b", List: " Home ", Parent: -1},
+		todoimport.Item{Title: "c", List: "HOME", Parent: -1},
+		todoimport.Item{Title: "d", Parent: -1},
+	)
+
+	plan.planLists([]models.List{work})
+	assert.Equal(t, []string{"Home"}, plan.report.Lists)
+	assert.Equal(t, &work, plan.lists["work"])
+	assert.Nil(t, plan.lists["home"])
+	assert.Equal(t, "Home", plan.items[1].List)
+}
+
+func TestImportPlanRequest(t *testing.T) {
+	estimate := 120.0
+	points := &models.List{ID: uuid.New(), Name: "Sprint", EstimateUnit: models.EstimateUnitPoints}
+
+	tags := []string{" Home ", "home", strings.Repeat("x", 51)}
+	for i := 0; i < 21; i++ {
+		tags = append(tags, "t"+string(rune('a'+i)))
+	}
+	plan := newImportPlan()
+	plan.lists["sprint"] = points
+
+	req := plan.request(&todoimport.Item{
+		Line:        7,
+		Title:       "  " + strings.Repeat("é", 300),
+		Description: strings.Repeat("d", 1001),
+		Status:      todoimport.StatusInProgress,
+		Priority:    4,
+		Estimate:    &estimate,
+		List:        "Sprint",
+		Tags:        tags,
+	})
+
+	assert.Equal(t, strings.Repeat("é", 255), req.Title)
+	assert.Len(t, req.Description, 1000)
+	assert.Equal(t, models.TodoStatusInProgress, req.Status)
+	assert.Equal(t, 4, req.Priority)
+	assert.Nil(t, req.Estimate)
+	require.Len(t, req.Tags, 20)
+	assert.Equal(t, "home", req.Tags[0])
+	assert.Equal(t, map[string]int{"title": 1, "description": 1, "tag": 3, "estimate": 1}, plan.report.SkippedFields)
+	for _, skipped := range plan.report.Skipped {
+		assert.Equal(t, 7, skipped.Line)
+	}
+
+	// Outside a list estimates are in minutes
+	req = plan.request(&todoimport.Item{Title: "Call", Status: todoimport.StatusPending, Estimate: &estimate})
+	assert.Equal(t, &estimate, req.Estimate)
+	assert.Equal(t, []string{}, req.Tags)
+}
+
+func TestImportPlanTrees(t *testing.T) {
+	listID := uuid.New()
+	plan := newImportPlan(
+		todoimport.Item{Title: "Groceries", List: "Home", Parent: -1},
+		todoimport.Item{Title: "Milk", List: "Home", Parent: 0},
+		todoimport.Item{Title: "Oat", List: "Home", Parent: 1},
+		todoimport.Item{Title: "Call mom", Parent: -1},
+		todoimport.Item{Title: "Eggs", List: "Home", Parent: 0},
+	)
+	plan.requests = make([]models.TodoCreateRequest, len(plan.items))
+	for i := range plan.items {
+		plan.requests[i] = plan.request(&plan.items[i])
+	}
+
+	trees, roots, sizes := plan.trees(map[string]uuid.UUID{"home": listID})
+	require.Len(t, trees, 2)
+	assert.Equal(t, []int{0, 3}, roots)
+	assert.Equal(t, []int{4, 1}, sizes)
+
+	groceries := trees[0]
+	assert.Equal(t, &listID, groceries.ListID)
+	require.Len(t, groceries.Subtasks, 2)
+	assert.Equal(t, "Milk", groceries.Subtasks[0].Title)
+	assert.Equal(t, "Oat", groceries.Subtasks[0].Subtasks[0].Title)
+	assert.Equal(t, "Eggs", groceries.Subtasks[1].Title)
+	assert.Nil(t, trees[1].ListID)
+}
+
+func TestImportReportSkip(t *testing.T) {
+	var report models.ImportReport
+	for i := 0; i < models.ImportMaxSkipped+5; i++ {
+		report.Skip(todoimport.Skipped{Line: i + 1, Field: "due", Reason: "invalid date"})
+	}
+	assert.Len(t, report.Skipped, models.ImportMaxSkipped)
+	assert.Equal(t, models.ImportMaxSkipped+5, report.SkippedFields["due"])
+}
+
+// importJobs keeps import jobs in memory
+type importJobs struct {
+	repository.ImportJobRepository
+	jobs map[uuid.UUID]models.ImportJob
+}
+
+func (r *importJobs) GetByID(id uuid.UUID) (*models.ImportJob, error) {
+	job := r.jobs[id]
+	return &job, nil
+}
+
+func (r *importJobs) UpdateIfStatus(job *models.ImportJob, statuses ...models.ImportJobStatus) (bool, error) {
+	for _, status := range statuses {
+		if r.jobs[job.ID].Status == status {
+			r.jobs[job.ID] = *job
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestImportGetJobFailsStaleJobs(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	userID := uuid.New()
+	lost := models.ImportJob{ID: uuid.New(), UserID: userID, Status: models.ImportJobRunning, UpdatedAt: now.Add(-time.Hour)}
+	busy := models.ImportJob{ID: uuid.New(), UserID: userID, Status: models.ImportJobRunning, UpdatedAt: now.Add(-time.Minute)}
+	done := models.ImportJob{ID: uuid.New(), UserID: userID, Status: models.ImportJobCompleted, UpdatedAt: now.Add(-time.Hour)}
+	repo := &importJobs{jobs: map[uuid.UUID]models.ImportJob{lost.ID: lost, busy.ID: busy, done.ID: done}}
+	s := &importService{jobRepo: repo, jobTimeout: 30 * time.Minute, now: func() time.Time { return now }}
+
+	job, err := s.GetJob(lost.ID, userID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ImportJobFailed, job.Status)
+	assert.Equal(t, errImportStale.Error(), job.Error)
+	assert.Equal(t, models.ImportJobFailed, repo.jobs[lost.ID].Status)
+
+	job, err = s.GetJob(busy.ID, userID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ImportJobRunning, job.Status)
+
+	job, err = s.GetJob(done.ID, userID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ImportJobCompleted, job.Status)
+}
+
+func TestImportRunStopsWhenCancelled(t *testing.T) {
+	job := models.ImportJob{ID: uuid.New(), Status: models.ImportJobPending, Total: 1}
+	repo := &importJobs{jobs: map[uuid.UUID]models.ImportJob{job.ID: job}}
+	s := &importService{jobRepo: repo, now: time.Now}
+
+	plan := newImportPlan(todoimport.Item{Title: "Pay rent", Parent: -1})
+	plan.requests = []models.TodoCreateRequest{{Title: "Pay rent"}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, errImportInterrupted, s.run(ctx, &job, plan))
+
+	// Jobs given up on while queued are left alone
+	repo.jobs[job.ID] = models.ImportJob{ID: job.ID, Status: models.ImportJobFailed}
+	assert.Equal(t, errImportGivenUp, s.run(context.Background(), &job, plan))
+	assert.Equal(t, models.ImportJobFailed, repo.jobs[job.ID].Status)
+}
+
+func TestImportFailKeepsFinishedJobs(t *testing.T) {
+	userID := uuid.New()
+	now := time.Now()
+	done := models.ImportJob{ID: uuid.New(), UserID: userID, Status: models.ImportJobCompleted, Created: 3}
+	repo := &importJobs{jobs: map[uuid.UUID]models.ImportJob{done.ID: done}}
+	s := &importService{jobRepo: repo, jobTimeout: 30 * time.Minute, now: func() time.Time { return now }}
+
+	// GetJob read the job as running just before the import completed it
+	stale := done
+	stale.Status = models.ImportJobRunning
+	stale.UpdatedAt = now.Add(-time.Hour)
+	s.fail(&stale, errImportStale)
+	assert.Equal(t, models.ImportJobRunning, stale.Status)
+	assert.Equal(t, done, repo.jobs[done.ID])
+
+	// And a job failed as stale isn't completed by a run that resumes
+	failed := models.ImportJob{ID: uuid.New(), UserID: userID, Status: models.ImportJobFailed}
+	repo.jobs[failed.ID] = failed
+	running := failed
+	running.Status = models.ImportJobRunning
+	assert.Equal(t, errImportGivenUp, s.save(&running, models.ImportJobRunning))
+	s.fail(&running, errImportGivenUp)
+	assert.Equal(t, failed, repo.jobs[failed.ID])
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"errors"
+	"todo-backend/internal/models"
+	"todo-backend/internal/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ListService interface {
+	Create(userID uuid.UUID, req *models.ListCreateRequest) (*models.List, error)
+	GetByUserID(userID uuid.UUID) ([]models.List, error)
+	Update(id uuid.UUID, userID uuid.UUID, req *models.ListUpdateRequest) (*models.List, error)
+	Delete(id uuid.UUID, userID uuid.UUID) error
+	GetTags(userID uuid.UUID) ([]models.Tag, error)
+
+	// Boards
+	GetWorkflow(id uuid.UUID, userID uuid.UUID) (models.Workflow, bool, error)
+	SetWorkflow(id uuid.UUID, userID uuid.UUID, req *models.WorkflowRequest) (models.Workflow, error)
+	ResetWorkflow(id uuid.UUID, userID uuid.UUID) error
+	Board(id uuid.UUID, userID uuid.UUID, limit int) (*Board, error)
+}
+
+// Board is a list's workflow with the todos in each state
+type Board struct {
+	List     *models.List
+	Workflow models.Workflow
+	Custom   bool
+	Counts   map[string]int64
+	Cards    map[string][]models.Todo
+}
+
+type listService struct {
+	listRepo     repository.ListRepository
+	tagRepo      repository.TagRepository
+	workflowRepo repository.WorkflowRepository
+	todoRepo     repository.TodoRepository
+}
+
+func NewListService(listRepo repository.ListRepository, tagRepo repository.TagRepository, workflowRepo repository.WorkflowRepository, todoRepo repository.TodoRepository) ListService {
+	return &listService{
+		listRepo:     listRepo,
+		tagRepo:      tagRepo,
+		workflowRepo: workflowRepo,
+		todoRepo:     todoRepo,
+	}
+}
+
+func (s *listService) Create(userID uuid.UUID, req *models.ListCreateRequest) (*models.List, error) {
+	list := &models.List{
+		UserID:       userID,
+		Name:         req.Name,
+		EstimateUnit: req.EstimateUnit,
+	}
+	if list.EstimateUnit == "" {
+		list.EstimateUnit = models.EstimateUnitMinutes
+	}
+
+	if err := s.listRepo.Create(list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (s *listService) GetByUserID(userID uuid.UUID) ([]models.List, error) {
+	return s.listRepo.GetByUserID(userID)
+}
+
+func (s *listService) Update(id uuid.UUID, userID uuid.UUID, req *models.ListUpdateRequest) (*models.List, error) {
+	list, err := s.getOwnedList(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != "" {
+		list.Name = req.Name
+	}
+	if req.EstimateUnit != "" {
+		list.EstimateUnit = req.EstimateUnit
+	}
+
+	if err := s.listRepo.Update(list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Delete removes a list; its todos are kept and moved out of it
+func (s *listService) Delete(id uuid.UUID, userID uuid.UUID) error {
+	if _, err := s.getOwnedList(id, userID); err != nil {
+		return err
+	}
+	return s.listRepo.Delete(id)
+}
+
+func (s *listService) GetTags(userID uuid.UUID) ([]models.Tag, error) {
+	return s.tagRepo.GetByUserID(userID)
+}
+
+// GetWorkflow returns a list's workflow and whether it is the list's own
+// rather than the default one
+func (s *listService) GetWorkflow(id uuid.UUID, userID uuid.UUID) (models.Workflow, bool, error) {
+	if _, err := s.getOwnedList(id, userID); err != nil {
+		return nil, false, err
+	}
+	states, err := s.workflowRepo.GetByListID(id)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(states) == 0 {
+		return models.DefaultWorkflow(), false, nil
+	}
+	return states, true, nil
+}
+
+// SetWorkflow replaces a list's workflow. Todos in a state that is removed
+// move to the first state of their category.
+func (s *listService) SetWorkflow(id uuid.UUID, userID uuid.UUID, req *models.WorkflowRequest) (models.Workflow, error) {
+	if _, err := s.getOwnedList(id, userID); err != nil {
+		return nil, err
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	states := req.Workflow(id)
+	if err := s.workflowRepo.Replace(id, states); err != nil {
+		return nil, err
+	}
+	return states, nil
+}
+
+// ResetWorkflow puts a list back on the default workflow; its todos keep
+// their status
+func (s *listService) ResetWorkflow(id uuid.UUID, userID uuid.UUID) error {
+	if _, err := s.getOwnedList(id, userID); err != nil {
+		return err
+	}
+	return s.workflowRepo.Replace(id, nil)
+}
+
+// Board returns a list's workflow with the number of todos in each state and
+// up to limit of them per state in manual order
+func (s *listService) Board(id uuid.UUID, userID uuid.UUID, limit int) (*Board, error) {
+	list, err := s.getOwnedList(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if limit < 1 || limit > 100 {
+		limit = 50
+	}
+
+	board := &Board{List: list, Workflow: models.DefaultWorkflow(), Cards: make(map[string][]models.Todo)}
+	states, err := s.workflowRepo.GetByListID(id)
+	if err != nil {
+		return nil, err
+	}
+	if len(states) > 0 {
+		board.Workflow, board.Custom = states, true
+	}
+
+	if board.Counts, err = s.todoRepo.CountByState(id); err != nil {
+		return nil, err
+	}
+	for _, state := range board.Workflow {
+		if board.Counts[state.Key] == 0 {
+			continue
+		}
+		cards, err := s.todoRepo.FindByState(id, state.Key, limit)
+		if err != nil {
+			return nil, err
+		}
+		board.Cards[state.Key] = cards
+	}
+	return board, nil
+}
+
+func (s *listService) getOwnedList(id uuid.UUID, userID uuid.UUID) (*models.List, error) {
+	list, err := s.listRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("list not found")
+		}
+		return nil, err
+	}
+	if list.UserID != userID {
+		return nil, errors.New("unauthorized to access this list")
+	}
+	return list, nil
+}
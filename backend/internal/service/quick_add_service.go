@@ -0,0 +1,79 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"todo-backend/internal/models"
+	"todo-backend/internal/repository"
+	"todo-backend/pkg/quickadd"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type QuickAddService interface {
+	// Parse turns a quick-add text into a todo create request, with dates in
+	// the user's timezone and @mentions matched against the user's lists
+	Parse(userID uuid.UUID, req *models.QuickAddRequest) (*models.QuickAddResult, error)
+}
+
+type quickAddService struct {
+	listRepo repository.ListRepository
+	userRepo repository.UserRepository
+	now      func() time.Time
+}
+
+func NewQuickAddService(listRepo repository.ListRepository, userRepo repository.UserRepository) QuickAddService {
+	return &quickAddService{
+		listRepo: listRepo,
+		userRepo: userRepo,
+		now:      time.Now,
+	}
+}
+
+func (s *quickAddService) Parse(userID uuid.UUID, req *models.QuickAddRequest) (*models.QuickAddResult, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+	lists, err := s.listRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(lists))
+	for i := range lists {
+		names[i] = lists[i].Name
+	}
+
+	parsed := quickadd.Parse(req.Text, s.now().In(user.Location()), names)
+	if strings.TrimSpace(parsed.Title) == "" {
+		return nil, errors.New("invalid quick add: nothing is left for the title")
+	}
+
+	todo := models.TodoCreateRequest{
+		Title:   parsed.Title,
+		DueDate: parsed.Due,
+		Tags:    parsed.Tags,
+		ListID:  req.ListID,
+	}
+	if todo.ListID != nil && *todo.ListID == uuid.Nil {
+		todo.ListID = nil
+	}
+	if parsed.Priority != nil {
+		todo.Priority = *parsed.Priority
+	}
+	if parsed.List != "" {
+		for i := range lists {
+			if lists[i].Name == parsed.List {
+				todo.ListID = &lists[i].ID
+				break
+			}
+		}
+	}
+
+	return &models.QuickAddResult{Request: todo, Tokens: parsed.Tokens}, nil
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"errors"
+	"todo-backend/internal/config"
+	"todo-backend/internal/models"
+	"todo-backend/internal/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ReminderService interface {
+	Create(todoID uuid.UUID, userID uuid.UUID, req *models.ReminderCreateRequest) (*models.Reminder, error)
+	GetByTodoID(todoID uuid.UUID, userID uuid.UUID) ([]models.Reminder, error)
+	Delete(id uuid.UUID, todoID uuid.UUID, userID uuid.UUID) error
+}
+
+type reminderService struct {
+	reminderRepo repository.ReminderRepository
+	todoRepo     repository.TodoRepository
+	config       *config.Config
+}
+
+func NewReminderService(reminderRepo repository.ReminderRepository, todoRepo repository.TodoRepository, cfg *config.Config) ReminderService {
+	return &reminderService{
+		reminderRepo: reminderRepo,
+		todoRepo:     todoRepo,
+		config:       cfg,
+	}
+}
+
+func (s *reminderService) Create(todoID uuid.UUID, userID uuid.UUID, req *models.ReminderCreateRequest) (*models.Reminder, error) {
+	todo, err := s.getOwnedTodo(todoID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if (req.RemindAt == nil) == (req.OffsetMinutes == nil) {
+		return nil, errors.New("reminder requires exactly one of remind_at or offset_minutes")
+	}
+	if req.OffsetMinutes != nil && todo.DueDate == nil {
+		return nil, errors.New("offset reminders require the todo to have a due date")
+	}
+
+	reminder := &models.Reminder{
+		TodoID:        todo.ID,
+		UserID:        userID,
+		RemindAt:      req.RemindAt,
+		OffsetMinutes: req.OffsetMinutes,
+		Channel:       req.Channel,
+		Status:        models.ReminderStatusPending,
+	}
+	if reminder.Channel == "" {
+		reminder.Channel = models.ReminderChannelLog
+	}
+	if !s.channelConfigured(reminder.Channel) {
+		return nil, errors.New("reminder channel is not configured")
+	}
+	reminder.Resolve(todo.DueDate)
+
+	if err := s.reminderRepo.Create(reminder); err != nil {
+		return nil, err
+	}
+	return reminder, nil
+}
+
+func (s *reminderService) GetByTodoID(todoID uuid.UUID, userID uuid.UUID) ([]models.Reminder, error) {
+	if _, err := s.getOwnedTodo(todoID, userID); err != nil {
+		return nil, err
+	}
+	return s.reminderRepo.GetByTodoID(todoID)
+}
+
+func (s *reminderService) Delete(id uuid.UUID, todoID uuid.UUID, userID uuid.UUID) error {
+	if _, err := s.getOwnedTodo(todoID, userID); err != nil {
+		return err
+	}
+
+	reminder, err := s.reminderRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("reminder not found")
+		}
+		return err
+	}
+	if reminder.TodoID != todoID {
+		return errors.New("reminder not found")
+	}
+
+	return s.reminderRepo.Delete(id)
+}
+
+// channelConfigured reports whether the dispatcher will have a notifier for
+// channel; reminders on a channel without one would only ever fail.
+func (s *reminderService) channelConfigured(channel string) bool {
+	switch channel {
+	case models.ReminderChannelWebhook:
+		return s.config.WebhookURL != ""
+	case models.ReminderChannelEmail:
+		return s.config.SMTPHost != ""
+	}
+	return true
+}
+
+func (s *reminderService) getOwnedTodo(todoID uuid.UUID, userID uuid.UUID) (*models.Todo, error) {
+	todo, err := s.todoRepo.GetByID(todoID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("todo not found")
+		}
+		return nil, err
+	}
+	if todo.UserID != userID {
+		return nil, errors.New("unauthorized to access this todo")
+	}
+	return todo, nil
+}
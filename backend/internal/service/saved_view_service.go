@@ -0,0 +1,127 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"todo-backend/internal/models"
+	"todo-backend/internal/repository"
+	"todo-backend/pkg/tql"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type SavedViewService interface {
+	Create(userID uuid.UUID, req *models.SavedViewCreateRequest) (*models.SavedView, error)
+	GetByUserID(userID uuid.UUID) ([]models.SavedView, error)
+	GetByID(id uuid.UUID, userID uuid.UUID) (*models.SavedView, error)
+	Update(id uuid.UUID, userID uuid.UUID, req *models.SavedViewUpdateRequest) (*models.SavedView, error)
+	Delete(id uuid.UUID, userID uuid.UUID) error
+	Results(id uuid.UUID, userID uuid.UUID, page, limit int) ([]models.Todo, int64, error)
+}
+
+type savedViewService struct {
+	viewRepo    repository.SavedViewRepository
+	todoService TodoService
+}
+
+func NewSavedViewService(viewRepo repository.SavedViewRepository, todoService TodoService) SavedViewService {
+	return &savedViewService{
+		viewRepo:    viewRepo,
+		todoService: todoService,
+	}
+}
+
+func (s *savedViewService) Create(userID uuid.UUID, req *models.SavedViewCreateRequest) (*models.SavedView, error) {
+	if _, err := viewFilter(req.Query, req.Sort); err != nil {
+		return nil, err
+	}
+
+	view := &models.SavedView{
+		UserID: userID,
+		Name:   req.Name,
+		Query:  req.Query,
+		Sort:   req.Sort,
+	}
+	if err := s.viewRepo.Create(view); err != nil {
+		return nil, err
+	}
+	return view, nil
+}
+
+func (s *savedViewService) GetByUserID(userID uuid.UUID) ([]models.SavedView, error) {
+	return s.viewRepo.GetByUserID(userID)
+}
+
+func (s *savedViewService) GetByID(id uuid.UUID, userID uuid.UUID) (*models.SavedView, error) {
+	view, err := s.viewRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("view not found")
+		}
+		return nil, err
+	}
+	if view.UserID != userID {
+		return nil, errors.New("unauthorized to access this view")
+	}
+	return view, nil
+}
+
+func (s *savedViewService) Update(id uuid.UUID, userID uuid.UUID, req *models.SavedViewUpdateRequest) (*models.SavedView, error) {
+	view, err := s.GetByID(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != "" {
+		view.Name = req.Name
+	}
+	if req.Query != "" {
+		view.Query = req.Query
+	}
+	if req.Sort != "" {
+		view.Sort = req.Sort
+	}
+	if _, err := viewFilter(view.Query, view.Sort); err != nil {
+		return nil, err
+	}
+
+	if err := s.viewRepo.Update(view); err != nil {
+		return nil, err
+	}
+	return view, nil
+}
+
+func (s *savedViewService) Delete(id uuid.UUID, userID uuid.UUID) error {
+	if _, err := s.GetByID(id, userID); err != nil {
+		return err
+	}
+	return s.viewRepo.Delete(id)
+}
+
+// Results runs the view's query and returns one page of matching todos
+func (s *savedViewService) Results(id uuid.UUID, userID uuid.UUID, page, limit int) ([]models.Todo, int64, error) {
+	view, err := s.GetByID(id, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	filter, err := viewFilter(view.Query, view.Sort)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.todoService.List(userID, filter, page, limit)
+}
+
+// viewFilter parses a view's query and sort into a todo filter
+func viewFilter(query, sort string) (*models.TodoFilter, error) {
+	expr, err := tql.Parse(query)
+	if err != nil {
+		return nil, fmt.Errorf("invalid query: %w", err)
+	}
+	sorts, err := models.ParseTodoSort(sort)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sort: %w", err)
+	}
+	return &models.TodoFilter{Query: expr, Sort: sorts}, nil
+}
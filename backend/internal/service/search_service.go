@@ -0,0 +1,37 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"todo-backend/internal/search"
+
+	"github.com/google/uuid"
+)
+
+type SearchService interface {
+	Search(userID uuid.UUID, query string, limit int) ([]search.Result, error)
+}
+
+type searchService struct {
+	searcher search.Searcher
+}
+
+func NewSearchService(searcher search.Searcher) SearchService {
+	return &searchService{
+		searcher: searcher,
+	}
+}
+
+func (s *searchService) Search(userID uuid.UUID, query string, limit int) ([]search.Result, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, errors.New("search query is required")
+	}
+	if len(query) > 200 {
+		return nil, errors.New("search query is too long")
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	return s.searcher.Search(userID, query, limit)
+}
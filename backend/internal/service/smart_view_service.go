@@ -0,0 +1,161 @@
+package service
+
+import (
+	"errors"
+	"time"
+	"todo-backend/internal/models"
+	"todo-backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// maxUpcomingTodos bounds the todos returned by the upcoming view
+const maxUpcomingTodos = 500
+
+// TodoDay is the todos due on one calendar day of the upcoming view
+type TodoDay struct {
+	Date  time.Time // midnight in the user's timezone
+	Todos []models.Todo
+}
+
+// SmartViewService serves the built-in date buckets of open todos, computed
+// in the user's timezone
+type SmartViewService interface {
+	Today(userID uuid.UUID, page, limit int) ([]models.Todo, int64, error)
+	Overdue(userID uuid.UUID, page, limit int) ([]models.Todo, int64, error)
+	Someday(userID uuid.UUID, page, limit int) ([]models.Todo, int64, error)
+	Upcoming(userID uuid.UUID, days int) ([]TodoDay, bool, error)
+}
+
+type smartViewService struct {
+	todoRepo repository.TodoRepository
+	userRepo repository.UserRepository
+	now      func() time.Time
+}
+
+func NewSmartViewService(todoRepo repository.TodoRepository, userRepo repository.UserRepository) SmartViewService {
+	return &smartViewService{
+		todoRepo: todoRepo,
+		userRepo: userRepo,
+		now:      time.Now,
+	}
+}
+
+// openTodoStatuses are the statuses the smart views list
+var openTodoStatuses = []models.TodoStatus{models.TodoStatusPending, models.TodoStatusInProgress}
+
+// dueOrder sorts the smart views by due date, most important first
+var dueOrder = []models.TodoSort{{Field: "due_date"}, {Field: "priority", Desc: true}}
+
+// Today returns the open todos due today
+func (s *smartViewService) Today(userID uuid.UUID, page, limit int) ([]models.Todo, int64, error) {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	today := startOfDay(s.now().In(user.Location()))
+	tomorrow := today.AddDate(0, 0, 1)
+
+	return s.find(userID, &models.TodoFilter{DueAfter: &today, DueBefore: &tomorrow}, page, limit)
+}
+
+// Overdue returns the open todos due before today
+func (s *smartViewService) Overdue(userID uuid.UUID, page, limit int) ([]models.Todo, int64, error) {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	today := startOfDay(s.now().In(user.Location()))
+
+	return s.find(userID, &models.TodoFilter{DueBefore: &today}, page, limit)
+}
+
+// Someday returns the open todos without a due date
+func (s *smartViewService) Someday(userID uuid.UUID, page, limit int) ([]models.Todo, int64, error) {
+	noDueDate := false
+	return s.find(userID, &models.TodoFilter{
+		HasDueDate: &noDueDate,
+		Sort:       []models.TodoSort{{Field: "priority", Desc: true}, {Field: "created_at"}},
+	}, page, limit)
+}
+
+// Upcoming returns the open todos due from tomorrow on, grouped by day. With
+// days < 1 the view runs to the end of next week, following the user's week
+// start; every day of the range is present, even without todos. It also
+// reports whether todos past the first maxUpcomingTodos were left out.
+func (s *smartViewService) Upcoming(userID uuid.UUID, days int) ([]TodoDay, bool, error) {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return nil, false, err
+	}
+	loc := user.Location()
+	tomorrow, end := upcomingRange(s.now().In(loc), user.FirstDayOfWeek(), days)
+
+	todos, _, err := s.todoRepo.Find(userID, &models.TodoFilter{
+		Statuses:  openTodoStatuses,
+		DueAfter:  &tomorrow,
+		DueBefore: &end,
+		Sort:      dueOrder,
+	}, 0, maxUpcomingTodos+1)
+	if err != nil {
+		return nil, false, err
+	}
+	truncated := len(todos) > maxUpcomingTodos
+	if truncated {
+		todos = todos[:maxUpcomingTodos]
+	}
+
+	var groups []TodoDay
+	index := make(map[string]int)
+	for day := tomorrow; day.Before(end); day = day.AddDate(0, 0, 1) {
+		index[day.Format("2006-01-02")] = len(groups)
+		groups = append(groups, TodoDay{Date: day, Todos: []models.Todo{}})
+	}
+	for _, todo := range todos {
+		if i, ok := index[todo.DueDate.In(loc).Format("2006-01-02")]; ok {
+			groups[i].Todos = append(groups[i].Todos, todo)
+		}
+	}
+	return groups, truncated, nil
+}
+
+func (s *smartViewService) find(userID uuid.UUID, filter *models.TodoFilter, page, limit int) ([]models.Todo, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+
+	filter.Statuses = openTodoStatuses
+	if filter.Sort == nil {
+		filter.Sort = dueOrder
+	}
+	return s.todoRepo.Find(userID, filter, (page-1)*limit, limit)
+}
+
+func (s *smartViewService) getUser(userID uuid.UUID) (*models.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	return user, nil
+}
+
+// upcomingRange returns the first day after now and the (exclusive) end of
+// the upcoming view: days later, or the end of the week after the current one
+func upcomingRange(now time.Time, weekStart time.Weekday, days int) (time.Time, time.Time) {
+	today := startOfDay(now)
+	tomorrow := today.AddDate(0, 0, 1)
+	if days > 0 {
+		return tomorrow, tomorrow.AddDate(0, 0, days)
+	}
+
+	sinceWeekStart := (int(today.Weekday()) - int(weekStart) + 7) % 7
+	return tomorrow, today.AddDate(0, 0, 14-sinceWeekStart)
+}
+
+// startOfDay returns midnight of t's day in t's location
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
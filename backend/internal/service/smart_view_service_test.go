@@ -0,0 +1,102 @@
+package service
+
+import (
+	"testing"
+	"time"
+	"todo-backend/internal/models"
+	"todo-backend/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpcomingRange(t *testing.T) {
+	day := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name      string
+		now       time.Time
+		weekStart time.Weekday
+		days      int
+		wantEnd   time.Time
+	}{
+		// 2024-01-10 is a Wednesday
+		{"monday weeks", time.Date(2024, 1, 10, 15, 0, 0, 0, time.UTC), time.Monday, 0, day(2024, 1, 22)},
+		{"sunday weeks", time.Date(2024, 1, 10, 15, 0, 0, 0, time.UTC), time.Sunday, 0, day(2024, 1, 21)},
+		{"last day of the week", time.Date(2024, 1, 14, 8, 0, 0, 0, time.UTC), time.Monday, 0, day(2024, 1, 22)},
+		{"first day of the week", time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC), time.Monday, 0, day(2024, 1, 29)},
+		{"explicit days", time.Date(2024, 1, 10, 15, 0, 0, 0, time.UTC), time.Monday, 3, day(2024, 1, 14)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := upcomingRange(tt.now, tt.weekStart, tt.days)
+			assert.Equal(t, startOfDay(tt.now).AddDate(0, 0, 1), start)
+			assert.Equal(t, tt.wantEnd, end)
+		})
+	}
+}
+
+func TestUpcomingRangeUsesLocalDays(t *testing.T) {
+	loc, err := time.LoadLocation("Pacific/Auckland")
+	if err != nil {
+		t.Skip("timezone data not available")
+	}
+
+	// 22:00 UTC on Tuesday is already Wednesday morning in Auckland
+	now := time.Date(2024, 1, 9, 22, 0, 0, 0, time.UTC).In(loc)
+	start, _ := upcomingRange(now, time.Monday, 1)
+
+	assert.Equal(t, time.Date(2024, 1, 11, 0, 0, 0, 0, loc), start)
+}
+
+// dueTodos returns up to limit todos due one per hour from the start of a range
+type dueTodos struct {
+	repository.TodoRepository
+	count int
+}
+
+func (r *dueTodos) Find(userID uuid.UUID, filter *models.TodoFilter, offset, limit int) ([]models.Todo, int64, error) {
+	var todos []models.Todo
+	for i := 0; i < r.count && i < limit; i++ {
+		due := filter.DueAfter.Add(time.Duration(i) * time.Hour)
+		todos = append(todos, models.Todo{ID: uuid.New(), UserID: userID, DueDate: &due})
+	}
+	return todos, int64(r.count), nil
+}
+
+type utcUser struct {
+	repository.UserRepository
+}
+
+func (utcUser) GetByID(id uuid.UUID) (*models.User, error) {
+	return &models.User{ID: id}, nil
+}
+
+func TestUpcomingReportsTruncation(t *testing.T) {
+	now := time.Date(2024, 1, 10, 15, 0, 0, 0, time.UTC)
+
+	for _, tt := range []struct {
+		count     int
+		returned  int
+		truncated bool
+	}{
+		{maxUpcomingTodos, maxUpcomingTodos, false},
+		{maxUpcomingTodos + 1, maxUpcomingTodos, true},
+	} {
+		s := &smartViewService{todoRepo: &dueTodos{count: tt.count}, userRepo: utcUser{}, now: func() time.Time { return now }}
+
+		days, truncated, err := s.Upcoming(uuid.New(), 30)
+		require.NoError(t, err)
+		assert.Equal(t, tt.truncated, truncated)
+
+		returned := 0
+		for _, day := range days {
+			returned += len(day.Todos)
+		}
+		assert.Equal(t, tt.returned, returned)
+	}
+}
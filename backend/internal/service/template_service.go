@@ -0,0 +1,224 @@
+package service
+
+import (
+	"errors"
+	"time"
+	"todo-backend/internal/models"
+	"todo-backend/internal/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type TemplateService interface {
+	Create(userID uuid.UUID, req *models.TemplateCreateRequest) (*models.Template, error)
+	GetByUserID(userID uuid.UUID) ([]models.Template, error)
+	GetByID(id uuid.UUID, userID uuid.UUID) (*models.Template, error)
+	Update(id uuid.UUID, userID uuid.UUID, req *models.TemplateUpdateRequest) (*models.Template, error)
+	Delete(id uuid.UUID, userID uuid.UUID) error
+	Instantiate(id uuid.UUID, userID uuid.UUID, req *models.TemplateInstantiateRequest) ([]models.Todo, error)
+
+	// Saving existing todos
+	FromTodo(todoID uuid.UUID, userID uuid.UUID, req *models.TemplateSaveRequest) (*models.Template, error)
+	FromList(listID uuid.UUID, userID uuid.UUID, req *models.TemplateSaveRequest) (*models.Template, error)
+}
+
+type templateService struct {
+	templateRepo repository.TemplateRepository
+	todoRepo     repository.TodoRepository
+	listRepo     repository.ListRepository
+	userRepo     repository.UserRepository
+	todoService  TodoService
+	now          func() time.Time
+}
+
+func NewTemplateService(templateRepo repository.TemplateRepository, todoRepo repository.TodoRepository, listRepo repository.ListRepository, userRepo repository.UserRepository, todoService TodoService) TemplateService {
+	return &templateService{
+		templateRepo: templateRepo,
+		todoRepo:     todoRepo,
+		listRepo:     listRepo,
+		userRepo:     userRepo,
+		todoService:  todoService,
+		now:          time.Now,
+	}
+}
+
+func (s *templateService) Create(userID uuid.UUID, req *models.TemplateCreateRequest) (*models.Template, error) {
+	items := models.TemplateItems(req.Items)
+	if err := items.Validate(); err != nil {
+		return nil, err
+	}
+
+	template := &models.Template{
+		UserID:      userID,
+		Name:        req.Name,
+		Description: req.Description,
+		Items:       items,
+	}
+	if err := s.templateRepo.Create(template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+func (s *templateService) GetByUserID(userID uuid.UUID) ([]models.Template, error) {
+	return s.templateRepo.GetByUserID(userID)
+}
+
+func (s *templateService) GetByID(id uuid.UUID, userID uuid.UUID) (*models.Template, error) {
+	template, err := s.templateRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("template not found")
+		}
+		return nil, err
+	}
+	if template.UserID != userID {
+		return nil, errors.New("unauthorized to access this template")
+	}
+	return template, nil
+}
+
+func (s *templateService) Update(id uuid.UUID, userID uuid.UUID, req *models.TemplateUpdateRequest) (*models.Template, error) {
+	template, err := s.GetByID(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != "" {
+		template.Name = req.Name
+	}
+	if req.Description != "" {
+		template.Description = req.Description
+	}
+	if req.Items != nil {
+		items := models.TemplateItems(req.Items)
+		if err := items.Validate(); err != nil {
+			return nil, err
+		}
+		template.Items = items
+	}
+
+	if err := s.templateRepo.Update(template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+func (s *templateService) Delete(id uuid.UUID, userID uuid.UUID) error {
+	if _, err := s.GetByID(id, userID); err != nil {
+		return err
+	}
+	return s.templateRepo.Delete(id)
+}
+
+// Instantiate creates the template's todos as one operation, which a single
+// undo takes back
+func (s *templateService) Instantiate(id uuid.UUID, userID uuid.UUID, req *models.TemplateInstantiateRequest) ([]models.Todo, error) {
+	template, err := s.GetByID(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := s.location(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	anchor := startOfDay(s.now().In(loc))
+	if req.AnchorDate != "" {
+		anchor, err = time.ParseInLocation("2006-01-02", req.AnchorDate, loc)
+		if err != nil {
+			return nil, errors.New("invalid anchor_date: must be a date (YYYY-MM-DD)")
+		}
+	}
+
+	listID := req.ListID
+	if listID != nil && *listID == uuid.Nil {
+		listID = nil
+	}
+	return s.todoService.CreateTree(userID, template.Items.TodoTree(anchor, listID))
+}
+
+// FromTodo saves a todo and its subtasks as a template
+func (s *templateService) FromTodo(todoID uuid.UUID, userID uuid.UUID, req *models.TemplateSaveRequest) (*models.Template, error) {
+	todo, err := s.todoRepo.GetByID(todoID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("todo not found")
+		}
+		return nil, err
+	}
+	if todo.UserID != userID {
+		return nil, errors.New("unauthorized to access this todo")
+	}
+
+	todos, err := s.todoRepo.FindSubtree(todoID)
+	if err != nil {
+		return nil, err
+	}
+	return s.save(userID, todos, todo.Title, req)
+}
+
+// FromList saves the todos of a list, with their subtasks in the list, as a
+// template
+func (s *templateService) FromList(listID uuid.UUID, userID uuid.UUID, req *models.TemplateSaveRequest) (*models.Template, error) {
+	list, err := s.listRepo.GetByID(listID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("list not found")
+		}
+		return nil, err
+	}
+	if list.UserID != userID {
+		return nil, errors.New("unauthorized to access this list")
+	}
+
+	todos, err := s.todoRepo.FindByListID(listID)
+	if err != nil {
+		return nil, err
+	}
+	if len(todos) == 0 {
+		return nil, errors.New("invalid template: the list has no todos")
+	}
+	return s.save(userID, todos, list.Name, req)
+}
+
+func (s *templateService) save(userID uuid.UUID, todos []models.Todo, name string, req *models.TemplateSaveRequest) (*models.Template, error) {
+	loc, err := s.location(userID)
+	if err != nil {
+		return nil, err
+	}
+	items := models.NewTemplateItems(todos, loc)
+	if err := items.Validate(); err != nil {
+		return nil, err
+	}
+
+	if req.Name != "" {
+		name = req.Name
+	}
+	// Todo titles can be longer than template names
+	if runes := []rune(name); len(runes) > 100 {
+		name = string(runes[:100])
+	}
+	template := &models.Template{
+		UserID:      userID,
+		Name:        name,
+		Description: req.Description,
+		Items:       items,
+	}
+	if err := s.templateRepo.Create(template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+func (s *templateService) location(userID uuid.UUID) (*time.Location, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+	return user.Location(), nil
+}
@@ -0,0 +1,280 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"todo-backend/internal/models"
+	"todo-backend/internal/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// maxTimeReportDays bounds the date range of a time report
+const maxTimeReportDays = 366
+
+type TimeEntryService interface {
+	StartTimer(todoID uuid.UUID, userID uuid.UUID, req *models.TimerStartRequest) (*models.TimeEntry, *models.TimeEntry, error)
+	StopTimer(todoID uuid.UUID, userID uuid.UUID) (*models.TimeEntry, error)
+	RunningTimer(userID uuid.UUID) (*models.TimeEntry, error)
+
+	Create(todoID uuid.UUID, userID uuid.UUID, req *models.TimeEntryCreateRequest) (*models.TimeEntry, error)
+	List(todoID uuid.UUID, userID uuid.UUID, page, limit int) ([]models.TimeEntry, int64, error)
+	Update(id uuid.UUID, todoID uuid.UUID, userID uuid.UUID, req *models.TimeEntryUpdateRequest) (*models.TimeEntry, error)
+	Delete(id uuid.UUID, todoID uuid.UUID, userID uuid.UUID) error
+
+	Report(userID uuid.UUID, from, to string, groupBy string) (*models.TimeReport, error)
+}
+
+type timeEntryService struct {
+	timeEntryRepo repository.TimeEntryRepository
+	todoRepo      repository.TodoRepository
+	userRepo      repository.UserRepository
+	now           func() time.Time
+}
+
+func NewTimeEntryService(timeEntryRepo repository.TimeEntryRepository, todoRepo repository.TodoRepository, userRepo repository.UserRepository) TimeEntryService {
+	return &timeEntryService{
+		timeEntryRepo: timeEntryRepo,
+		todoRepo:      todoRepo,
+		userRepo:      userRepo,
+		now:           time.Now,
+	}
+}
+
+// StartTimer starts a timer on a todo. A timer running on another todo is
+// stopped and returned as the second value.
+func (s *timeEntryService) StartTimer(todoID uuid.UUID, userID uuid.UUID, req *models.TimerStartRequest) (*models.TimeEntry, *models.TimeEntry, error) {
+	if _, err := s.getOwnedTodo(todoID, userID); err != nil {
+		return nil, nil, err
+	}
+
+	running, err := s.getRunning(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if running != nil && running.TodoID == todoID {
+		return nil, nil, errors.New("timer is already running for this todo")
+	}
+
+	entry := &models.TimeEntry{
+		TodoID:    todoID,
+		UserID:    userID,
+		StartedAt: s.now(),
+		Note:      req.Note,
+	}
+	stopped, err := s.timeEntryRepo.Start(entry)
+	if err != nil {
+		return nil, nil, err
+	}
+	return entry, stopped, nil
+}
+
+// StopTimer stops the timer running on a todo
+func (s *timeEntryService) StopTimer(todoID uuid.UUID, userID uuid.UUID) (*models.TimeEntry, error) {
+	if _, err := s.getOwnedTodo(todoID, userID); err != nil {
+		return nil, err
+	}
+
+	running, err := s.getRunning(userID)
+	if err != nil {
+		return nil, err
+	}
+	if running == nil || running.TodoID != todoID {
+		return nil, errors.New("no timer is running for this todo")
+	}
+
+	now := s.now()
+	if err := running.SetTimes(running.StartedAt, &now, now); err != nil {
+		return nil, err
+	}
+	if err := s.timeEntryRepo.Update(running); err != nil {
+		return nil, err
+	}
+	return running, nil
+}
+
+// RunningTimer returns the user's running timer, or nil
+func (s *timeEntryService) RunningTimer(userID uuid.UUID) (*models.TimeEntry, error) {
+	return s.getRunning(userID)
+}
+
+// Create logs time spent on a todo after the fact
+func (s *timeEntryService) Create(todoID uuid.UUID, userID uuid.UUID, req *models.TimeEntryCreateRequest) (*models.TimeEntry, error) {
+	if _, err := s.getOwnedTodo(todoID, userID); err != nil {
+		return nil, err
+	}
+
+	entry := &models.TimeEntry{TodoID: todoID, UserID: userID, Note: req.Note}
+	end := req.EndedAt
+	if err := entry.SetTimes(req.StartedAt, &end, s.now()); err != nil {
+		return nil, err
+	}
+	if err := s.timeEntryRepo.Create(entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// List returns one page of a todo's time entries, latest first
+func (s *timeEntryService) List(todoID uuid.UUID, userID uuid.UUID, page, limit int) ([]models.TimeEntry, int64, error) {
+	if _, err := s.getOwnedTodo(todoID, userID); err != nil {
+		return nil, 0, err
+	}
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+	return s.timeEntryRepo.ListByTodoID(todoID, (page-1)*limit, limit)
+}
+
+// Update edits the times or note of an entry. Setting an end on a running
+// timer stops it; its start can be corrected while it runs.
+func (s *timeEntryService) Update(id uuid.UUID, todoID uuid.UUID, userID uuid.UUID, req *models.TimeEntryUpdateRequest) (*models.TimeEntry, error) {
+	entry, err := s.getEntry(id, todoID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	start, end := entry.StartedAt, entry.EndedAt
+	if req.StartedAt != nil {
+		start = *req.StartedAt
+	}
+	if req.EndedAt != nil {
+		end = req.EndedAt
+	}
+	if err := entry.SetTimes(start, end, s.now()); err != nil {
+		return nil, err
+	}
+	if req.Note != nil {
+		entry.Note = *req.Note
+	}
+
+	if err := s.timeEntryRepo.Update(entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (s *timeEntryService) Delete(id uuid.UUID, todoID uuid.UUID, userID uuid.UUID) error {
+	if _, err := s.getEntry(id, todoID, userID); err != nil {
+		return err
+	}
+	return s.timeEntryRepo.Delete(id)
+}
+
+// Report sums the user's finished time entries by day, list or tag. from and
+// to are inclusive dates (YYYY-MM-DD) in the user's timezone and default to
+// the last seven days.
+func (s *timeEntryService) Report(userID uuid.UUID, from, to string, groupBy string) (*models.TimeReport, error) {
+	if groupBy == "" {
+		groupBy = models.TimeReportByDay
+	}
+	if groupBy != models.TimeReportByDay && groupBy != models.TimeReportByList && groupBy != models.TimeReportByTag {
+		return nil, fmt.Errorf("invalid group_by: %s", groupBy)
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+	loc := user.Location()
+
+	start, end, err := reportRange(from, to, s.now().In(loc))
+	if err != nil {
+		return nil, err
+	}
+
+	rows, total, err := s.timeEntryRepo.Report(userID, start, end, groupBy, loc.String())
+	if err != nil {
+		return nil, err
+	}
+	if rows == nil {
+		rows = []models.TimeReportRow{}
+	}
+	return &models.TimeReport{
+		From:         start.Format("2006-01-02"),
+		To:           end.AddDate(0, 0, -1).Format("2006-01-02"),
+		Timezone:     loc.String(),
+		GroupBy:      groupBy,
+		TotalSeconds: total,
+		Rows:         rows,
+	}, nil
+}
+
+// reportRange turns inclusive report dates into the instants [start, end) in
+// now's location
+func reportRange(from, to string, now time.Time) (time.Time, time.Time, error) {
+	loc := now.Location()
+	end := startOfDay(now).AddDate(0, 0, 1)
+	if to != "" {
+		day, err := time.ParseInLocation("2006-01-02", to, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("invalid to: must be a date (YYYY-MM-DD)")
+		}
+		end = day.AddDate(0, 0, 1)
+	}
+
+	start := end.AddDate(0, 0, -7)
+	if from != "" {
+		day, err := time.ParseInLocation("2006-01-02", from, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("invalid from: must be a date (YYYY-MM-DD)")
+		}
+		start = day
+	}
+
+	if !start.Before(end) {
+		return time.Time{}, time.Time{}, errors.New("invalid range: from is after to")
+	}
+	if end.Sub(start) > maxTimeReportDays*24*time.Hour+time.Hour {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid range: at most %d days", maxTimeReportDays)
+	}
+	return start, end, nil
+}
+
+func (s *timeEntryService) getRunning(userID uuid.UUID) (*models.TimeEntry, error) {
+	running, err := s.timeEntryRepo.GetRunning(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return running, err
+}
+
+func (s *timeEntryService) getEntry(id uuid.UUID, todoID uuid.UUID, userID uuid.UUID) (*models.TimeEntry, error) {
+	if _, err := s.getOwnedTodo(todoID, userID); err != nil {
+		return nil, err
+	}
+	entry, err := s.timeEntryRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("time entry not found")
+		}
+		return nil, err
+	}
+	if entry.TodoID != todoID {
+		return nil, errors.New("time entry not found")
+	}
+	return entry, nil
+}
+
+// getOwnedTodo loads a todo the user may track time on
+func (s *timeEntryService) getOwnedTodo(todoID uuid.UUID, userID uuid.UUID) (*models.Todo, error) {
+	todo, err := s.todoRepo.GetByID(todoID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("todo not found")
+		}
+		return nil, err
+	}
+	if todo.UserID != userID {
+		return nil, errors.New("unauthorized to access this todo")
+	}
+	return todo, nil
+}
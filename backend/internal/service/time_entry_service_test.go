@@ -0,0 +1,61 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportRange(t *testing.T) {
+	day := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+	now := time.Date(2024, 3, 10, 15, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		from      string
+		to        string
+		wantStart time.Time
+		wantEnd   time.Time
+		wantErr   string
+	}{
+		{name: "last seven days", wantStart: day(2024, 3, 4), wantEnd: day(2024, 3, 11)},
+		{name: "seven days before to", to: "2024-02-29", wantStart: day(2024, 2, 23), wantEnd: day(2024, 3, 1)},
+		{name: "from until today", from: "2024-03-01", wantStart: day(2024, 3, 1), wantEnd: day(2024, 3, 11)},
+		{name: "single day", from: "2024-03-05", to: "2024-03-05", wantStart: day(2024, 3, 5), wantEnd: day(2024, 3, 6)},
+		{name: "full year", from: "2024-01-01", to: "2024-12-31", wantStart: day(2024, 1, 1), wantEnd: day(2025, 1, 1)},
+		{name: "bad from", from: "03/01/2024", wantErr: "invalid from: must be a date (YYYY-MM-DD)"},
+		{name: "bad to", to: "tomorrow", wantErr: "invalid to: must be a date (YYYY-MM-DD)"},
+		{name: "reversed", from: "2024-03-06", to: "2024-03-05", wantErr: "invalid range: from is after to"},
+		{name: "too long", from: "2023-01-01", to: "2024-03-05", wantErr: "invalid range: at most 366 days"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, err := reportRange(tt.from, tt.to, now)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantStart, start)
+			assert.Equal(t, tt.wantEnd, end)
+		})
+	}
+}
+
+func TestReportRangeUsesLocalDays(t *testing.T) {
+	loc, err := time.LoadLocation("Pacific/Auckland")
+	if err != nil {
+		t.Skip("timezone data not available")
+	}
+
+	// 22:00 UTC on Tuesday is already Wednesday in Auckland
+	now := time.Date(2024, 1, 9, 22, 0, 0, 0, time.UTC).In(loc)
+	_, end, err := reportRange("", "", now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 11, 0, 0, 0, 0, loc), end)
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"todo-backend/internal/models"
+	"todo-backend/internal/repository"
+	"todo-backend/pkg/tql"
+
+	"github.com/google/uuid"
+)
+
+// errBulkRolledBack aborts the transaction of an atomic bulk request with failures
+var errBulkRolledBack = errors.New("bulk request rolled back")
+
+// Bulk applies one action to many todos in a single transaction. Every todo
+// goes through the same ownership checks as Update and Delete and is applied
+// in its own savepoint, so a failing todo is reported without undoing the
+// others unless the request is atomic.
+func (s *todoService) Bulk(userID uuid.UUID, req *models.TodoBulkRequest) (*models.TodoBulkResponse, error) {
+	if err := validateBulkRequest(req); err != nil {
+		return nil, err
+	}
+
+	var filter *models.TodoFilter
+	if req.Filter != "" {
+		expr, err := tql.Parse(req.Filter)
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter: %w", err)
+		}
+		filter = s.localize(userID, &models.TodoFilter{Query: expr, Sort: models.DefaultTodoSort})
+	}
+
+	resp := &models.TodoBulkResponse{Action: req.Action, Results: []models.TodoBulkItemResult{}}
+	err := s.inOperation(userID, models.OperationBulk, func(tx *todoService) error {
+		ids := uniqueIDs(req.IDs)
+		if filter != nil {
+			todos, total, err := tx.todoRepo.Find(userID, filter, 0, models.TodoBulkMaxItems)
+			if err != nil {
+				return err
+			}
+			if total > models.TodoBulkMaxItems {
+				return fmt.Errorf("invalid bulk request: filter matches more than %d todos", models.TodoBulkMaxItems)
+			}
+			ids = make([]uuid.UUID, len(todos))
+			for i := range todos {
+				ids[i] = todos[i].ID
+			}
+		}
+
+		for _, id := range ids {
+			err := tx.uow.Do(func(item repository.Repositories) error {
+				return tx.withRepositories(item).applyBulk(id, userID, req)
+			})
+			result := models.TodoBulkItemResult{ID: id, OK: err == nil}
+			if err != nil {
+				result.Error = err.Error()
+				resp.Failed++
+			} else {
+				resp.Succeeded++
+			}
+			resp.Results = append(resp.Results, result)
+		}
+
+		if req.Atomic && resp.Failed > 0 {
+			return errBulkRolledBack
+		}
+		return nil
+	})
+	if errors.Is(err, errBulkRolledBack) {
+		resp.RolledBack = true
+		resp.Succeeded = 0
+		for i := range resp.Results {
+			resp.Results[i].OK = false
+		}
+		return resp, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// applyBulk applies the action of a bulk request to one todo
+func (s *todoService) applyBulk(id uuid.UUID, userID uuid.UUID, req *models.TodoBulkRequest) error {
+	switch req.Action {
+	case models.TodoBulkComplete:
+		_, err := s.update(id, userID, &models.TodoUpdateRequest{Status: models.TodoStatusCompleted})
+		return err
+	case models.TodoBulkSetStatus:
+		_, err := s.update(id, userID, &models.TodoUpdateRequest{Status: req.Status})
+		return err
+	case models.TodoBulkMove:
+		listID := uuid.Nil
+		if req.ListID != nil {
+			listID = *req.ListID
+		}
+		_, err := s.update(id, userID, &models.TodoUpdateRequest{ListID: &listID})
+		return err
+	case models.TodoBulkSetPriority:
+		update := &models.TodoUpdateRequest{Priority: *req.Priority}
+		if *req.Priority == 0 {
+			update.Clear = []string{models.TodoFieldPriority}
+		}
+		_, err := s.Update(id, userID, update)
+		return err
+	case models.TodoBulkAddTag, models.TodoBulkRemoveTag:
+		todo, err := s.getOwned(id, userID, "update")
+		if err != nil {
+			return err
+		}
+		tag := models.NormalizeTagName(req.Tag)
+		names := make([]string, 0, len(todo.Tags)+1)
+		for _, name := range todo.TagNames() {
+			if name != tag {
+				names = append(names, name)
+			}
+		}
+		if req.Action == models.TodoBulkAddTag {
+			names = append(names, tag)
+		}
+		tags, err := s.tagRepo.FindOrCreate(userID, names)
+		if err != nil {
+			return err
+		}
+		before := snapshot(todo)
+		if err := s.todoRepo.SetTags(todo, tags); err != nil {
+			return err
+		}
+		todo.Tags = tags
+		return s.record(models.TodoChangeUpdated, userID, before, todo)
+	case models.TodoBulkDelete:
+		return s.delete(id, userID)
+	}
+	return fmt.Errorf("invalid bulk request: unknown action %q", req.Action)
+}
+
+// validateBulkRequest checks that a bulk request names its todos one way and
+// carries the argument its action needs
+func validateBulkRequest(req *models.TodoBulkRequest) error {
+	if (len(req.IDs) == 0) == (req.Filter == "") {
+		return errors.New("invalid bulk request: exactly one of ids or filter is required")
+	}
+	if len(req.IDs) > models.TodoBulkMaxItems {
+		return fmt.Errorf("invalid bulk request: at most %d ids are allowed", models.TodoBulkMaxItems)
+	}
+
+	switch req.Action {
+	case models.TodoBulkSetStatus:
+		if req.Status == "" {
+			return errors.New("invalid bulk request: set_status requires status")
+		}
+	case models.TodoBulkSetPriority:
+		if req.Priority == nil {
+			return errors.New("invalid bulk request: set_priority requires priority")
+		}
+	case models.TodoBulkAddTag, models.TodoBulkRemoveTag:
+		if models.NormalizeTagName(req.Tag) == "" {
+			return fmt.Errorf("invalid bulk request: %s requires tag", req.Action)
+		}
+	}
+	return nil
+}
+
+// uniqueIDs drops repeated IDs, keeping order
+func uniqueIDs(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	out := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
@@ -0,0 +1,482 @@
+package service
+
+import (
+	"errors"
+	"sort"
+	"testing"
+	"time"
+	"todo-backend/internal/models"
+	"todo-backend/internal/repository"
+	"todo-backend/pkg/tql"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestValidateBulkRequest(t *testing.T) {
+	ids := []uuid.UUID{uuid.New()}
+	priority := 0
+
+	tests := []struct {
+		name    string
+		req     models.TodoBulkRequest
+		wantErr string
+	}{
+		{"ids", models.TodoBulkRequest{Action: models.TodoBulkComplete, IDs: ids}, ""},
+		{"filter", models.TodoBulkRequest{Action: models.TodoBulkDelete, Filter: "status:completed"}, ""},
+		{"no target", models.TodoBulkRequest{Action: models.TodoBulkComplete}, "invalid bulk request: exactly one of ids or filter is required"},
+		{"both targets", models.TodoBulkRequest{Action: models.TodoBulkComplete, IDs: ids, Filter: "tag:work"}, "invalid bulk request: exactly one of ids or filter is required"},
+		{"too many ids", models.TodoBulkRequest{Action: models.TodoBulkComplete, IDs: make([]uuid.UUID, models.TodoBulkMaxItems+1)}, "invalid bulk request: at most 500 ids are allowed"},
+		{"status missing", models.TodoBulkRequest{Action: models.TodoBulkSetStatus, IDs: ids}, "invalid bulk request: set_status requires status"},
+		{"priority missing", models.TodoBulkRequest{Action: models.TodoBulkSetPriority, IDs: ids}, "invalid bulk request: set_priority requires priority"},
+		{"zero priority", models.TodoBulkRequest{Action: models.TodoBulkSetPriority, IDs: ids, Priority: &priority}, ""},
+		{"blank tag", models.TodoBulkRequest{Action: models.TodoBulkAddTag, IDs: ids, Tag: "  "}, "invalid bulk request: add_tag requires tag"},
+		{"move out of list", models.TodoBulkRequest{Action: models.TodoBulkMove, IDs: ids}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateBulkRequest(&tt.req)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUniqueIDs(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+	assert.Equal(t, []uuid.UUID{a, b}, uniqueIDs([]uuid.UUID{a, b, a, b}))
+	assert.Empty(t, uniqueIDs(nil))
+}
+
+// bulkStore is an in-memory database for todo operations. Its unit of work
+// restores the state it started from when fn fails, so nested calls behave
+// like savepoints and the outermost one like the transaction.
+type bulkStore struct {
+	todos      map[uuid.UUID]models.Todo
+	trash      map[uuid.UUID]models.Todo
+	changes    []models.TodoChange
+	operations []models.Operation
+	lists      map[uuid.UUID]models.List
+	workflows  map[uuid.UUID]models.Workflow
+	locked     []uuid.UUID        // lists locked, in order
+	failUpdate map[uuid.UUID]bool // todos whose writes fail
+	filters    []*models.TodoFilter
+}
+
+func (db *bulkStore) Do(fn func(repos repository.Repositories) error) error {
+	todos, trash := copyTodos(db.todos), copyTodos(db.trash)
+	changes := len(db.changes)
+	operations := append([]models.Operation(nil), db.operations...)
+
+	err := fn(repository.Repositories{
+		Todos:      &bulkTodos{db: db},
+		Tags:       bulkTags{},
+		Lists:      &boardLists{db: db},
+		Workflows:  &boardWorkflows{db: db},
+		Reminders:  noReminders{},
+		Changes:    &bulkChanges{db: db},
+		Operations: &bulkOperations{db: db},
+		Tx:         db,
+	})
+	if err != nil {
+		db.todos, db.trash = todos, trash
+		db.changes = db.changes[:changes]
+		db.operations = operations
+	}
+	return err
+}
+
+func copyTodos(todos map[uuid.UUID]models.Todo) map[uuid.UUID]models.Todo {
+	out := make(map[uuid.UUID]models.Todo, len(todos))
+	for id, todo := range todos {
+		out[id] = todo
+	}
+	return out
+}
+
+type bulkTodos struct {
+	repository.TodoRepository
+	db *bulkStore
+}
+
+func (r *bulkTodos) Create(todo *models.Todo) error {
+	if todo.ID == uuid.Nil {
+		todo.ID = uuid.New()
+	}
+	r.db.todos[todo.ID] = *todo
+	return nil
+}
+
+func (r *bulkTodos) GetByID(id uuid.UUID) (*models.Todo, error) {
+	todo, ok := r.db.todos[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &todo, nil
+}
+
+// Update writes the todo, then fails for todos in failUpdate so the caller's
+// savepoint has something to undo
+func (r *bulkTodos) Update(todo *models.Todo) error {
+	r.db.todos[todo.ID] = *todo
+	if r.db.failUpdate[todo.ID] {
+		return errors.New("database unavailable")
+	}
+	return nil
+}
+
+func (r *bulkTodos) SetTags(todo *models.Todo, tags []models.Tag) error {
+	todo.Tags = tags
+	return r.Update(todo)
+}
+
+func (r *bulkTodos) Delete(id uuid.UUID) error {
+	r.db.trash[id] = r.db.todos[id]
+	delete(r.db.todos, id)
+	return nil
+}
+
+func (r *bulkTodos) GetDeletedByID(id uuid.UUID) (*models.Todo, error) {
+	todo, ok := r.db.trash[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &todo, nil
+}
+
+func (r *bulkTodos) Restore(todo *models.Todo) error {
+	delete(r.db.trash, todo.ID)
+	r.db.todos[todo.ID] = *todo
+	return nil
+}
+
+// Find understands "tag:<name>" queries, which is all the tests use
+func (r *bulkTodos) Find(userID uuid.UUID, filter *models.TodoFilter, offset, limit int) ([]models.Todo, int64, error) {
+	r.db.filters = append(r.db.filters, filter)
+	cond := filter.Query.(*tql.Condition)
+
+	var todos []models.Todo
+	for _, todo := range r.db.todos {
+		for _, name := range todo.TagNames() {
+			if todo.UserID == userID && cond.Field == "tag" && name == cond.Values[0] {
+				todos = append(todos, todo)
+			}
+		}
+	}
+	return todos, int64(len(todos)), nil
+}
+
+// LastPosition counts trashed todos, like the real repository
+func (r *bulkTodos) LastPosition(userID uuid.UUID) (string, error) {
+	last := ""
+	for _, todos := range []map[uuid.UUID]models.Todo{r.db.todos, r.db.trash} {
+		for _, todo := range todos {
+			if todo.UserID == userID && todo.Position != nil && *todo.Position > last {
+				last = *todo.Position
+			}
+		}
+	}
+	return last, nil
+}
+
+func (r *bulkTodos) AdjacentPosition(userID uuid.UUID, position string, after bool, excludeID uuid.UUID) (string, error) {
+	adjacent := ""
+	for _, todo := range r.db.todos {
+		if todo.UserID != userID || todo.ID == excludeID || todo.Position == nil {
+			continue
+		}
+		key := *todo.Position
+		if after && key > position && (adjacent == "" || key < adjacent) ||
+			!after && key < position && key > adjacent {
+			adjacent = key
+		}
+	}
+	return adjacent, nil
+}
+
+func (r *bulkTodos) FindInManualOrder(userID uuid.UUID) ([]models.Todo, error) {
+	var todos []models.Todo
+	for _, todo := range r.db.todos {
+		if todo.UserID == userID {
+			todos = append(todos, todo)
+		}
+	}
+	sort.Slice(todos, func(i, j int) bool {
+		a, b := todos[i], todos[j]
+		switch {
+		case a.Position != nil && b.Position != nil && *a.Position != *b.Position:
+			return *a.Position < *b.Position
+		case (a.Position == nil) != (b.Position == nil):
+			return a.Position != nil
+		case !a.CreatedAt.Equal(b.CreatedAt):
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return a.ID.String() < b.ID.String()
+	})
+	return todos, nil
+}
+
+func (r *bulkTodos) SetPositions(positions map[uuid.UUID]string, updatedAt time.Time) error {
+	for id, position := range positions {
+		todo, ok := r.db.todos[id]
+		if !ok {
+			continue
+		}
+		position := position
+		todo.Position = &position
+		todo.UpdatedAt = updatedAt
+		r.db.todos[id] = todo
+	}
+	return nil
+}
+
+type bulkTags struct {
+	repository.TagRepository
+}
+
+func (bulkTags) FindOrCreate(userID uuid.UUID, names []string) ([]models.Tag, error) {
+	tags := []models.Tag{}
+	for _, name := range names {
+		tags = append(tags, models.Tag{UserID: userID, Name: name})
+	}
+	return tags, nil
+}
+
+type bulkChanges struct {
+	repository.TodoChangeRepository
+	db *bulkStore
+}
+
+func (r *bulkChanges) Create(change *models.TodoChange) error {
+	change.ID = uuid.New()
+	change.Seq = int64(len(r.db.changes) + 1)
+	r.db.changes = append(r.db.changes, *change)
+	return nil
+}
+
+func (r *bulkChanges) GetByOperationID(operationID uuid.UUID) ([]models.TodoChange, error) {
+	var changes []models.TodoChange
+	for i := len(r.db.changes) - 1; i >= 0; i-- {
+		if op := r.db.changes[i].OperationID; op != nil && *op == operationID {
+			changes = append(changes, r.db.changes[i])
+		}
+	}
+	return changes, nil
+}
+
+// bulkOperations keeps the operation log in creation order
+type bulkOperations struct {
+	repository.OperationRepository
+	db *bulkStore
+}
+
+func (r *bulkOperations) Create(op *models.Operation) error {
+	op.ID = uuid.New()
+	op.CreatedAt = time.Now()
+	r.db.operations = append(r.db.operations, *op)
+	return nil
+}
+
+func (r *bulkOperations) GetByID(id uuid.UUID) (*models.Operation, error) {
+	for _, op := range r.db.operations {
+		if op.ID == id {
+			return &op, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *bulkOperations) Update(op *models.Operation) error {
+	for i := range r.db.operations {
+		if r.db.operations[i].ID == op.ID {
+			r.db.operations[i] = *op
+		}
+	}
+	return nil
+}
+
+func (r *bulkOperations) DeleteIfEmpty(id uuid.UUID) error {
+	if r.hasChanges(id) {
+		return nil
+	}
+	for i := range r.db.operations {
+		if r.db.operations[i].ID == id {
+			r.db.operations = append(r.db.operations[:i], r.db.operations[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (r *bulkOperations) LatestUndoable(userID uuid.UUID, since time.Time) (*models.Operation, error) {
+	for i := len(r.db.operations) - 1; i >= 0; i-- {
+		op := r.db.operations[i]
+		if op.UserID == userID && op.Kind != models.OperationUndo && op.UndoneAt == nil && !op.CreatedAt.Before(since) {
+			return &op, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *bulkOperations) LatestRedoable(userID uuid.UUID, since time.Time) (*models.Operation, error) {
+	for i := len(r.db.operations) - 1; i >= 0; i-- {
+		op := r.db.operations[i]
+		if op.UserID != userID {
+			continue
+		}
+		if op.Kind != models.OperationUndo && op.Kind != models.OperationRedo {
+			// A new change since the last undo ends redo
+			return nil, gorm.ErrRecordNotFound
+		}
+		if op.Kind == models.OperationUndo && op.UndoneAt == nil && !op.CreatedAt.Before(since) {
+			return &op, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *bulkOperations) hasChanges(id uuid.UUID) bool {
+	for _, change := range r.db.changes {
+		if change.OperationID != nil && *change.OperationID == id {
+			return true
+		}
+	}
+	return false
+}
+
+type bulkUsers struct {
+	repository.UserRepository
+}
+
+func (r *bulkUsers) GetByID(id uuid.UUID) (*models.User, error) {
+	return &models.User{ID: id, Timezone: "Europe/Berlin"}, nil
+}
+
+func newBulkFixture(todos ...models.Todo) (*todoService, *bulkStore) {
+	db := &bulkStore{
+		todos:      make(map[uuid.UUID]models.Todo),
+		trash:      make(map[uuid.UUID]models.Todo),
+		lists:      make(map[uuid.UUID]models.List),
+		workflows:  make(map[uuid.UUID]models.Workflow),
+		failUpdate: make(map[uuid.UUID]bool),
+	}
+	for _, todo := range todos {
+		db.todos[todo.ID] = todo
+	}
+	s := &todoService{todoRepo: &bulkTodos{db: db}, listRepo: &boardLists{db: db}, workflowRepo: &boardWorkflows{db: db}, operationRepo: &bulkOperations{db: db}, userRepo: &bulkUsers{}, uow: db}
+	return s, db
+}
+
+func TestBulk_IsolatesFailingItems(t *testing.T) {
+	userID := uuid.New()
+	mine := models.Todo{ID: uuid.New(), UserID: userID, Priority: 1}
+	broken := models.Todo{ID: uuid.New(), UserID: userID, Priority: 1}
+	theirs := models.Todo{ID: uuid.New(), UserID: uuid.New(), Priority: 1}
+	s, db := newBulkFixture(mine, broken, theirs)
+	db.failUpdate[broken.ID] = true
+
+	priority := 3
+	resp, err := s.Bulk(userID, &models.TodoBulkRequest{
+		Action:   models.TodoBulkSetPriority,
+		IDs:      []uuid.UUID{mine.ID, broken.ID, theirs.ID},
+		Priority: &priority,
+	})
+	require.NoError(t, err)
+
+	assert.False(t, resp.RolledBack)
+	assert.Equal(t, 1, resp.Succeeded)
+	assert.Equal(t, 2, resp.Failed)
+	assert.Equal(t, []models.TodoBulkItemResult{
+		{ID: mine.ID, OK: true},
+		{ID: broken.ID, Error: "database unavailable"},
+		{ID: theirs.ID, Error: "unauthorized to update this todo"},
+	}, resp.Results)
+
+	// The failing item's savepoint undid its write; the others were kept
+	assert.Equal(t, 3, db.todos[mine.ID].Priority)
+	assert.Equal(t, 1, db.todos[broken.ID].Priority)
+	assert.Equal(t, 1, db.todos[theirs.ID].Priority)
+	require.Len(t, db.changes, 1)
+	assert.Equal(t, mine.ID, db.changes[0].TodoID)
+}
+
+func TestBulk_AtomicRollsBackEverything(t *testing.T) {
+	userID := uuid.New()
+	first := models.Todo{ID: uuid.New(), UserID: userID, Priority: 1}
+	second := models.Todo{ID: uuid.New(), UserID: userID, Priority: 1}
+	theirs := models.Todo{ID: uuid.New(), UserID: uuid.New(), Priority: 1}
+	s, db := newBulkFixture(first, second, theirs)
+
+	priority := 3
+	resp, err := s.Bulk(userID, &models.TodoBulkRequest{
+		Action:   models.TodoBulkSetPriority,
+		IDs:      []uuid.UUID{first.ID, theirs.ID, second.ID},
+		Priority: &priority,
+		Atomic:   true,
+	})
+	require.NoError(t, err)
+
+	assert.True(t, resp.RolledBack)
+	assert.Equal(t, 0, resp.Succeeded)
+	assert.Equal(t, 1, resp.Failed)
+	for _, result := range resp.Results {
+		assert.False(t, result.OK)
+	}
+	assert.Equal(t, "unauthorized to update this todo", resp.Results[1].Error)
+
+	assert.Equal(t, 1, db.todos[first.ID].Priority)
+	assert.Equal(t, 1, db.todos[second.ID].Priority)
+	assert.Empty(t, db.changes)
+}
+
+func TestBulk_SetPriorityZeroClearsIt(t *testing.T) {
+	userID := uuid.New()
+	todo := models.Todo{ID: uuid.New(), UserID: userID, Priority: 4}
+	s, db := newBulkFixture(todo)
+
+	priority := 0
+	resp, err := s.Bulk(userID, &models.TodoBulkRequest{
+		Action:   models.TodoBulkSetPriority,
+		IDs:      []uuid.UUID{todo.ID},
+		Priority: &priority,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, resp.Succeeded)
+	assert.Equal(t, 0, db.todos[todo.ID].Priority)
+}
+
+func TestBulk_SelectsByFilter(t *testing.T) {
+	userID := uuid.New()
+	work := models.Todo{ID: uuid.New(), UserID: userID, Tags: []models.Tag{{Name: "work"}}}
+	home := models.Todo{ID: uuid.New(), UserID: userID, Tags: []models.Tag{{Name: "home"}}}
+	theirs := models.Todo{ID: uuid.New(), UserID: uuid.New(), Tags: []models.Tag{{Name: "work"}}}
+	s, db := newBulkFixture(work, home, theirs)
+
+	priority := 2
+	resp, err := s.Bulk(userID, &models.TodoBulkRequest{
+		Action:   models.TodoBulkSetPriority,
+		Filter:   "tag:work",
+		Priority: &priority,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, resp.Succeeded)
+	assert.Equal(t, []models.TodoBulkItemResult{{ID: work.ID, OK: true}}, resp.Results)
+	assert.Equal(t, 2, db.todos[work.ID].Priority)
+	assert.Equal(t, 0, db.todos[home.ID].Priority)
+	assert.Equal(t, 0, db.todos[theirs.ID].Priority)
+
+	// Relative dates in the filter follow the user's timezone
+	require.Len(t, db.filters, 1)
+	assert.Equal(t, "Europe/Berlin", db.filters[0].Location.String())
+
+	_, err = s.Bulk(userID, &models.TodoBulkRequest{Action: models.TodoBulkDelete, Filter: "tag:("})
+	assert.ErrorContains(t, err, "invalid filter")
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"errors"
+	"time"
+	"todo-backend/internal/models"
+	"todo-backend/pkg/rank"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// errRebalance asks for the user's position keys to be respread before a new
+// key is taken: a neighbour has no key yet or the new key would be too long
+var errRebalance = errors.New("position keys need rebalancing")
+
+// Move places a todo between two others in the user's manual order and/or
+// moves it to another state of its list's board. Only the moved todo gets a
+// new position key, unless the keys around it have to be respread first.
+func (s *todoService) Move(id uuid.UUID, userID uuid.UUID, req *models.TodoMoveRequest) (*models.Todo, error) {
+	return s.mutate(userID, models.OperationMove, func(tx *todoService) (*models.Todo, error) {
+		return tx.move(id, userID, req)
+	})
+}
+
+func (s *todoService) move(id uuid.UUID, userID uuid.UUID, req *models.TodoMoveRequest) (*models.Todo, error) {
+	if req.AfterID == nil && req.BeforeID == nil && req.State == "" {
+		return nil, errors.New("invalid move: after_id, before_id or state is required")
+	}
+	if (req.AfterID != nil && *req.AfterID == id) || (req.BeforeID != nil && *req.BeforeID == id) {
+		return nil, errors.New("invalid move: a todo can't be its own neighbour")
+	}
+
+	todo, err := s.getOwned(id, userID, "update")
+	if err != nil {
+		return nil, err
+	}
+
+	var key string
+	if req.AfterID != nil || req.BeforeID != nil {
+		key, err = s.positionBetween(id, userID, req)
+		if errors.Is(err, errRebalance) {
+			if err := s.rebalance(userID); err != nil {
+				return nil, err
+			}
+			// Reload so the move's history entry starts from the respread key
+			if todo, err = s.getOwned(id, userID, "update"); err != nil {
+				return nil, err
+			}
+			key, err = s.positionBetween(id, userID, req)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	before := snapshot(todo)
+
+	if req.State != "" {
+		if err := s.enterState(todo, req.State); err != nil {
+			return nil, err
+		}
+	}
+	if key != "" {
+		todo.Position = &key
+	}
+
+	if err := s.todoRepo.Update(todo); err != nil {
+		return nil, err
+	}
+	if err := s.record(models.TodoChangeUpdated, userID, before, todo); err != nil {
+		return nil, err
+	}
+
+	// Dropping an occurrence of a recurring todo on a done state schedules the next one
+	if before.Status != models.TodoStatusCompleted && todo.Status == models.TodoStatusCompleted && todo.IsRecurring() {
+		if err := s.createNextOccurrence(todo); err != nil {
+			return nil, err
+		}
+	}
+	return s.GetByID(id)
+}
+
+// positionBetween returns a key placing the todo id between the requested
+// neighbours. With a single neighbour the other bound is the key next to it,
+// so the todo lands right beside it in any filtered view as well.
+func (s *todoService) positionBetween(id uuid.UUID, userID uuid.UUID, req *models.TodoMoveRequest) (string, error) {
+	var lo, hi string
+	var err error
+	if req.AfterID != nil {
+		if lo, err = s.neighbourPosition(*req.AfterID, userID); err != nil {
+			return "", err
+		}
+	}
+	if req.BeforeID != nil {
+		if hi, err = s.neighbourPosition(*req.BeforeID, userID); err != nil {
+			return "", err
+		}
+	}
+
+	switch {
+	case req.BeforeID == nil:
+		hi, err = s.todoRepo.AdjacentPosition(userID, lo, true, id)
+	case req.AfterID == nil:
+		lo, err = s.todoRepo.AdjacentPosition(userID, hi, false, id)
+	case lo > hi:
+		return "", errors.New("invalid move: after_id must come before before_id")
+	}
+	if err != nil {
+		return "", err
+	}
+	if lo != "" && lo == hi {
+		// Equal keys come from concurrent inserts or from a todo restored after a
+		// rebalance; respreading separates them
+		return "", errRebalance
+	}
+
+	key, err := rank.Between(lo, hi)
+	if err != nil {
+		return "", err
+	}
+	if len(key) > rank.MaxLength {
+		return "", errRebalance
+	}
+	return key, nil
+}
+
+// neighbourPosition returns the position key of a todo the moved one goes
+// next to
+func (s *todoService) neighbourPosition(id uuid.UUID, userID uuid.UUID) (string, error) {
+	todo, err := s.todoRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", errors.New("invalid move: neighbour todo not found")
+		}
+		return "", err
+	}
+	if todo.UserID != userID {
+		return "", errors.New("invalid move: neighbour todo not found")
+	}
+	if todo.Position == nil {
+		return "", errRebalance
+	}
+	return *todo.Position, nil
+}
+
+// nextPosition returns a key placing a new todo at the end of the user's
+// manual order
+func (s *todoService) nextPosition(userID uuid.UUID) (string, error) {
+	last, err := s.todoRepo.LastPosition(userID)
+	if err != nil {
+		return "", err
+	}
+	key, err := rank.Between(last, "")
+	if err != nil || len(key) <= rank.MaxLength {
+		return key, err
+	}
+
+	if err := s.rebalance(userID); err != nil {
+		return "", err
+	}
+	if last, err = s.todoRepo.LastPosition(userID); err != nil {
+		return "", err
+	}
+	return rank.Between(last, "")
+}
+
+// rebalance gives the user's live todos fresh, evenly spaced position keys in
+// their current manual order. Every new key is recorded with the running
+// operation, so undoing it puts the old keys back as well.
+func (s *todoService) rebalance(userID uuid.UUID) error {
+	todos, err := s.todoRepo.FindInManualOrder(userID)
+	if err != nil {
+		return err
+	}
+
+	keys := rank.Spread(len(todos))
+	positions := make(map[uuid.UUID]string, len(todos))
+	for i := range todos {
+		if todos[i].Position == nil || *todos[i].Position != keys[i] {
+			positions[todos[i].ID] = keys[i]
+		}
+	}
+	now := time.Now()
+	if err := s.todoRepo.SetPositions(positions, now); err != nil {
+		return err
+	}
+
+	for i := range todos {
+		key, ok := positions[todos[i].ID]
+		if !ok {
+			continue
+		}
+		before := snapshot(&todos[i])
+		todos[i].Position = &key
+		todos[i].UpdatedAt = now
+		if err := s.record(models.TodoChangeUpdated, userID, before, &todos[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
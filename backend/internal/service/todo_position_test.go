@@ -0,0 +1,61 @@
+package service
+
+import (
+	"testing"
+	"time"
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func position(t *testing.T, db *bulkStore, id uuid.UUID) string {
+	todo, ok := db.todos[id]
+	require.True(t, ok)
+	require.NotNil(t, todo.Position)
+	return *todo.Position
+}
+
+func TestMove_PlacesTheTodoBetweenItsNeighbours(t *testing.T) {
+	userID := uuid.New()
+	s, db := newBulkFixture()
+	a := createTodo(t, s, userID, "a")
+	b := createTodo(t, s, userID, "b")
+	c := createTodo(t, s, userID, "c")
+
+	_, err := s.Move(c.ID, userID, &models.TodoMoveRequest{AfterID: &a.ID, BeforeID: &b.ID})
+	require.NoError(t, err)
+	assert.Less(t, position(t, db, a.ID), position(t, db, c.ID))
+	assert.Less(t, position(t, db, c.ID), position(t, db, b.ID))
+
+	// With one neighbour the todo lands right beside it
+	_, err = s.Move(a.ID, userID, &models.TodoMoveRequest{BeforeID: &b.ID})
+	require.NoError(t, err)
+	assert.Less(t, position(t, db, c.ID), position(t, db, a.ID))
+	assert.Less(t, position(t, db, a.ID), position(t, db, b.ID))
+}
+
+func TestMove_RebalanceIsUndoneWithTheMove(t *testing.T) {
+	userID := uuid.New()
+	now := time.Now()
+	// Todos from before manual ordering have no position yet
+	older := models.Todo{ID: uuid.New(), UserID: userID, Title: "older", CreatedAt: now.Add(-time.Hour)}
+	newer := models.Todo{ID: uuid.New(), UserID: userID, Title: "newer", CreatedAt: now}
+	s, db := newBulkFixture(older, newer)
+	trashedKey := "t"
+	trashed := models.Todo{ID: uuid.New(), UserID: userID, Title: "trashed", Position: &trashedKey}
+	db.trash[trashed.ID] = trashed
+
+	_, err := s.Move(newer.ID, userID, &models.TodoMoveRequest{AfterID: &older.ID})
+	require.NoError(t, err)
+	assert.Less(t, position(t, db, older.ID), position(t, db, newer.ID))
+	assert.Equal(t, "t", *db.trash[trashed.ID].Position, "trashed todos are not respread")
+
+	result, err := s.Undo(userID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, models.OperationMove, result.Reverted.Kind)
+	assert.ElementsMatch(t, []uuid.UUID{older.ID, newer.ID}, result.TodoIDs)
+	assert.Nil(t, db.todos[older.ID].Position)
+	assert.Nil(t, db.todos[newer.ID].Position)
+}
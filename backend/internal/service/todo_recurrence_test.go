@@ -0,0 +1,197 @@
+package service
+
+import (
+	"testing"
+	"time"
+	"todo-backend/internal/models"
+	"todo-backend/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// seriesTodos keeps todos in memory. It is its own unit of work, without
+// rollback.
+type seriesTodos struct {
+	repository.TodoRepository
+	todos map[uuid.UUID]models.Todo
+}
+
+func (r *seriesTodos) Do(fn func(repos repository.Repositories) error) error {
+	return fn(repository.Repositories{
+		Todos:      r,
+		Reminders:  noReminders{},
+		Changes:    noChanges{},
+		Operations: noOperations{},
+		Tx:         r,
+	})
+}
+
+func (r *seriesTodos) Create(todo *models.Todo) error {
+	if todo.ID == uuid.Nil {
+		todo.ID = uuid.New()
+	}
+	r.todos[todo.ID] = *todo
+	return nil
+}
+
+func (r *seriesTodos) GetByID(id uuid.UUID) (*models.Todo, error) {
+	todo, ok := r.todos[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &todo, nil
+}
+
+func (r *seriesTodos) Update(todo *models.Todo) error {
+	r.todos[todo.ID] = *todo
+	return nil
+}
+
+func (r *seriesTodos) GetOpenBySeriesID(seriesID uuid.UUID) ([]models.Todo, error) {
+	var open []models.Todo
+	for _, todo := range r.todos {
+		if todo.SeriesID != nil && *todo.SeriesID == seriesID && todo.Status != models.TodoStatusCompleted {
+			open = append(open, todo)
+		}
+	}
+	return open, nil
+}
+
+// noReminders is a reminder store that never has any reminders
+func (r *seriesTodos) LastPosition(userID uuid.UUID) (string, error) {
+	return "", nil
+}
+
+type noReminders struct {
+	repository.ReminderRepository
+}
+
+func (noReminders) GetByTodoID(todoID uuid.UUID) ([]models.Reminder, error) { return nil, nil }
+
+func (noReminders) RescheduleForTodo(todoID uuid.UUID, dueDate *time.Time) error { return nil }
+
+// noChanges discards history entries
+type noChanges struct {
+	repository.TodoChangeRepository
+}
+
+func (noChanges) Create(change *models.TodoChange) error { return nil }
+
+// noOperations logs operations nowhere
+type noOperations struct {
+	repository.OperationRepository
+}
+
+func (noOperations) Create(op *models.Operation) error { return nil }
+
+func (noOperations) DeleteIfEmpty(id uuid.UUID) error { return nil }
+
+// openOccurrence returns the single open occurrence of a series
+func (r *seriesTodos) openOccurrence(t *testing.T, seriesID uuid.UUID) models.Todo {
+	open, err := r.GetOpenBySeriesID(seriesID)
+	require.NoError(t, err)
+	require.Len(t, open, 1)
+	return open[0]
+}
+
+func newSeries(t *testing.T, rule string) (*todoService, *seriesTodos, *models.Todo, uuid.UUID) {
+	repo := &seriesTodos{todos: make(map[uuid.UUID]models.Todo)}
+	s := &todoService{todoRepo: repo, reminderRepo: noReminders{}, changeRepo: noChanges{}, uow: repo}
+	userID := uuid.New()
+	due := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	todo, err := s.Create(userID, &models.TodoCreateRequest{Title: "Water plants", DueDate: &due, RecurrenceRule: rule})
+	require.NoError(t, err)
+	return s, repo, todo, userID
+}
+
+func complete(t *testing.T, s *todoService, id, userID uuid.UUID) {
+	_, err := s.Update(id, userID, &models.TodoUpdateRequest{Status: models.TodoStatusCompleted})
+	require.NoError(t, err)
+}
+
+func TestRecurrence_CompletingCreatesTheNextOccurrence(t *testing.T) {
+	s, repo, first, userID := newSeries(t, "FREQ=DAILY;COUNT=3")
+
+	complete(t, s, first.ID, userID)
+	second := repo.openOccurrence(t, first.ID)
+	assert.Equal(t, first.DueDate.AddDate(0, 0, 1), *second.DueDate)
+	assert.Equal(t, *first.RecurrenceStart, *second.RecurrenceStart)
+	assert.Equal(t, first.Title, second.Title)
+
+	complete(t, s, second.ID, userID)
+	third := repo.openOccurrence(t, first.ID)
+	assert.Equal(t, first.DueDate.AddDate(0, 0, 2), *third.DueDate)
+
+	// COUNT=3 ends the series with the third occurrence
+	complete(t, s, third.ID, userID)
+	open, err := repo.GetOpenBySeriesID(first.ID)
+	require.NoError(t, err)
+	assert.Empty(t, open)
+}
+
+func TestRecurrence_EditingALaterOccurrenceKeepsTheAnchor(t *testing.T) {
+	s, repo, first, userID := newSeries(t, "FREQ=DAILY;COUNT=3")
+	complete(t, s, first.ID, userID)
+	second := repo.openOccurrence(t, first.ID)
+
+	// Changing only the timezone doesn't move the anchor
+	updated, err := s.Update(second.ID, userID, &models.TodoUpdateRequest{Timezone: "Europe/Berlin"})
+	require.NoError(t, err)
+	assert.Equal(t, *first.RecurrenceStart, *updated.RecurrenceStart)
+
+	// Nor does a new rule on a later occurrence: COUNT still counts from the
+	// first one, so the second occurrence is the last of two
+	updated, err = s.Update(second.ID, userID, &models.TodoUpdateRequest{RecurrenceRule: "FREQ=DAILY;COUNT=2"})
+	require.NoError(t, err)
+	assert.Equal(t, *first.RecurrenceStart, *updated.RecurrenceStart)
+
+	complete(t, s, second.ID, userID)
+	open, err := repo.GetOpenBySeriesID(first.ID)
+	require.NoError(t, err)
+	assert.Empty(t, open)
+}
+
+func TestRecurrence_RuleChangeOnTheFirstOccurrenceReanchors(t *testing.T) {
+	s, _, first, userID := newSeries(t, "FREQ=DAILY")
+
+	due := first.DueDate.AddDate(0, 0, 3)
+	updated, err := s.Update(first.ID, userID, &models.TodoUpdateRequest{DueDate: &due, RecurrenceRule: "FREQ=WEEKLY"})
+	require.NoError(t, err)
+	assert.Equal(t, due, *updated.RecurrenceStart)
+	assert.Equal(t, "FREQ=WEEKLY", updated.RecurrenceRule)
+}
+
+func TestRecurrence_SkipOccurrence(t *testing.T) {
+	s, repo, first, userID := newSeries(t, "FREQ=WEEKLY;COUNT=2")
+
+	skipped, err := s.SkipOccurrence(first.ID, userID)
+	require.NoError(t, err)
+	assert.Equal(t, first.DueDate.AddDate(0, 0, 7), *skipped.DueDate)
+	assert.Equal(t, models.TodoStatusPending, repo.todos[first.ID].Status)
+
+	_, err = s.SkipOccurrence(first.ID, userID)
+	assert.EqualError(t, err, "no more occurrences in this series")
+}
+
+func TestRecurrence_EndSeries(t *testing.T) {
+	s, repo, first, userID := newSeries(t, "FREQ=DAILY")
+	complete(t, s, first.ID, userID)
+	second := repo.openOccurrence(t, first.ID)
+
+	ended, err := s.EndSeries(second.ID, userID)
+	require.NoError(t, err)
+	assert.False(t, ended.IsRecurring())
+
+	// Completing the last occurrence no longer schedules another one
+	complete(t, s, second.ID, userID)
+	open, err := repo.GetOpenBySeriesID(first.ID)
+	require.NoError(t, err)
+	assert.Empty(t, open)
+
+	_, err = s.SkipOccurrence(second.ID, userID)
+	assert.EqualError(t, err, "todo is not recurring")
+}
@@ -2,8 +2,12 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"time"
 	"todo-backend/internal/models"
 	"todo-backend/internal/repository"
+	"todo-backend/pkg/rrule"
+	"todo-backend/pkg/utils"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -12,30 +16,192 @@ import (
 type TodoService interface {
 	Create(userID uuid.UUID, req *models.TodoCreateRequest) (*models.Todo, error)
 	GetByID(id uuid.UUID) (*models.Todo, error)
-	GetByUserID(userID uuid.UUID, page, limit int) ([]models.Todo, int64, error)
+	List(userID uuid.UUID, filter *models.TodoFilter, page, limit int) ([]models.Todo, int64, error)
+	ListPage(userID uuid.UUID, filter *models.TodoFilter, cursor string, limit int, includeTotal bool) (*TodoPage, error)
 	Update(id uuid.UUID, userID uuid.UUID, req *models.TodoUpdateRequest) (*models.Todo, error)
 	Delete(id uuid.UUID, userID uuid.UUID) error
-	GetByStatus(userID uuid.UUID, status models.TodoStatus) ([]models.Todo, error)
+	History(id uuid.UUID, userID uuid.UUID, page, limit int) ([]models.TodoChange, int64, error)
+	Bulk(userID uuid.UUID, req *models.TodoBulkRequest) (*models.TodoBulkResponse, error)
+	CreateTree(userID uuid.UUID, tree []models.TodoTreeRequest) ([]models.Todo, error)
+
+	// Trash
+	GetTrash(userID uuid.UUID, page, limit int) ([]models.Todo, int64, error)
+	Restore(id uuid.UUID, userID uuid.UUID) (*models.Todo, error)
+	Purge(id uuid.UUID, userID uuid.UUID) error
+
+	// Recurring series
+	SkipOccurrence(id uuid.UUID, userID uuid.UUID) (*models.Todo, error)
+	EndSeries(id uuid.UUID, userID uuid.UUID) (*models.Todo, error)
+
+	// Manual order
+	Move(id uuid.UUID, userID uuid.UUID, req *models.TodoMoveRequest) (*models.Todo, error)
+
+	// Operation log
+	Operations(userID uuid.UUID, limit int) ([]models.Operation, error)
+	Undo(userID uuid.UUID, opID *uuid.UUID) (*UndoResult, error)
+	Redo(userID uuid.UUID, opID *uuid.UUID) (*UndoResult, error)
+
+	// ForClient returns the service recording clientID (a device or app
+	// identifier) in the history of the todos it changes
+	ForClient(clientID string) TodoService
+
+	// Transaction runs fn with a copy of the service whose changes share one
+	// database transaction, and the todo repository bound to it for reads
+	// that must see or lock the same rows
+	Transaction(fn func(tx TodoService, todos repository.TodoRepository) error) error
+}
+
+// TodoPage is one keyset page of a todo listing. Cursors are empty when there
+// is no page in that direction; Total is only set when requested.
+type TodoPage struct {
+	Todos      []models.Todo
+	NextCursor string
+	PrevCursor string
+	Total      *int64
 }
 
 type todoService struct {
-	todoRepo repository.TodoRepository
+	todoRepo          repository.TodoRepository
+	reminderRepo      repository.ReminderRepository
+	listRepo          repository.ListRepository
+	tagRepo           repository.TagRepository
+	userRepo          repository.UserRepository
+	changeRepo        repository.TodoChangeRepository
+	operationRepo     repository.OperationRepository
+	workflowRepo      repository.WorkflowRepository
+	attachmentService AttachmentService
+	uow               repository.UnitOfWork
+	clientID          string
+
+	// Operation log entry the changes are recorded under, set on copies bound
+	// to a transaction
+	operation *models.Operation
 }
 
-func NewTodoService(todoRepo repository.TodoRepository) TodoService {
+func NewTodoService(todoRepo repository.TodoRepository, reminderRepo repository.ReminderRepository, listRepo repository.ListRepository, tagRepo repository.TagRepository, userRepo repository.UserRepository, changeRepo repository.TodoChangeRepository, operationRepo repository.OperationRepository, workflowRepo repository.WorkflowRepository, attachmentService AttachmentService, uow repository.UnitOfWork) TodoService {
 	return &todoService{
-		todoRepo: todoRepo,
+		todoRepo:          todoRepo,
+		reminderRepo:      reminderRepo,
+		listRepo:          listRepo,
+		tagRepo:           tagRepo,
+		userRepo:          userRepo,
+		changeRepo:        changeRepo,
+		operationRepo:     operationRepo,
+		workflowRepo:      workflowRepo,
+		attachmentService: attachmentService,
+		uow:               uow,
+	}
+}
+
+func (s *todoService) ForClient(clientID string) TodoService {
+	client := *s
+	client.clientID = clientID
+	return &client
+}
+
+func (s *todoService) Transaction(fn func(tx TodoService, todos repository.TodoRepository) error) error {
+	return s.uow.Do(func(repos repository.Repositories) error {
+		return fn(s.withRepositories(repos), repos.Todos)
+	})
+}
+
+// withRepositories returns a copy of the service working on repos, typically
+// bound to a transaction
+func (s *todoService) withRepositories(repos repository.Repositories) *todoService {
+	tx := *s
+	tx.todoRepo = repos.Todos
+	tx.reminderRepo = repos.Reminders
+	tx.listRepo = repos.Lists
+	tx.tagRepo = repos.Tags
+	tx.changeRepo = repos.Changes
+	tx.operationRepo = repos.Operations
+	tx.workflowRepo = repos.Workflows
+	tx.uow = repos.Tx
+	return &tx
+}
+
+// mutate runs fn as one operation of the user's operation log; see inOperation
+func (s *todoService) mutate(userID uuid.UUID, kind models.OperationKind, fn func(tx *todoService) (*models.Todo, error)) (*models.Todo, error) {
+	var todo *models.Todo
+	err := s.inOperation(userID, kind, func(tx *todoService) error {
+		var err error
+		todo, err = fn(tx)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
+	return todo, nil
+}
+
+// inOperation runs fn with a copy of the service bound to one transaction and
+// logs it as a single operation, so a change, its history entries and its
+// undo information are committed together. Calls nested in an operation join
+// it; operations that end up changing nothing are not kept.
+func (s *todoService) inOperation(userID uuid.UUID, kind models.OperationKind, fn func(tx *todoService) error) error {
+	return s.uow.Do(func(repos repository.Repositories) error {
+		tx := s.withRepositories(repos)
+		if tx.operation != nil {
+			return fn(tx)
+		}
+
+		tx.operation = &models.Operation{UserID: userID, Kind: kind, ClientID: s.clientID}
+		if err := tx.operationRepo.Create(tx.operation); err != nil {
+			return err
+		}
+		if err := fn(tx); err != nil {
+			return err
+		}
+		return tx.operationRepo.DeleteIfEmpty(tx.operation.ID)
+	})
+}
+
+// record writes a history entry for a change to a todo; updates that leave
+// every recorded field as it was are skipped
+func (s *todoService) record(action models.TodoChangeAction, actorID uuid.UUID, before, after *models.Todo) error {
+	changes := models.DiffTodo(before, after)
+	if action == models.TodoChangeUpdated && len(changes) == 0 {
+		return nil
+	}
+	change := &models.TodoChange{
+		TodoID:   after.ID,
+		UserID:   after.UserID,
+		ActorID:  actorID,
+		Action:   action,
+		Changes:  changes,
+		ClientID: s.clientID,
+		Before:   models.NewTodoImage(before),
+	}
+	if s.operation != nil {
+		change.OperationID = &s.operation.ID
+	}
+	return s.changeRepo.Create(change)
+}
+
+// snapshot copies a todo before it is changed, for its history entry
+func snapshot(todo *models.Todo) *models.Todo {
+	before := *todo
+	before.Tags = append([]models.Tag(nil), todo.Tags...)
+	return &before
 }
 
 func (s *todoService) Create(userID uuid.UUID, req *models.TodoCreateRequest) (*models.Todo, error) {
+	return s.mutate(userID, models.OperationCreate, func(tx *todoService) (*models.Todo, error) {
+		return tx.create(userID, req)
+	})
+}
+
+func (s *todoService) create(userID uuid.UUID, req *models.TodoCreateRequest) (*models.Todo, error) {
 	todo := &models.Todo{
 		Title:       req.Title,
 		Description: req.Description,
 		Status:      req.Status,
 		Priority:    req.Priority,
 		DueDate:     req.DueDate,
+		Estimate:    req.Estimate,
 		UserID:      userID,
+		ICalUID:     req.ICalUID,
+		CalDAVName:  req.CalDAVName,
 	}
 
 	// Set default status if not provided
@@ -43,13 +209,81 @@ func (s *todoService) Create(userID uuid.UUID, req *models.TodoCreateRequest) (*
 		todo.Status = models.TodoStatusPending
 	}
 
+	if req.RecurrenceRule != "" {
+		if err := s.setRecurrence(todo, req.RecurrenceRule, req.Timezone); err != nil {
+			return nil, err
+		}
+		todo.ID = uuid.New()
+		todo.SeriesID = &todo.ID
+	}
+
+	if req.ListID != nil {
+		if err := s.checkListOwner(*req.ListID, userID); err != nil {
+			return nil, err
+		}
+		todo.ListID = req.ListID
+		if err := s.placeInList(todo, req.State); err != nil {
+			return nil, err
+		}
+	}
+	if len(req.Tags) > 0 {
+		tags, err := s.tagRepo.FindOrCreate(userID, normalizeTagNames(req.Tags))
+		if err != nil {
+			return nil, err
+		}
+		todo.Tags = tags
+	}
+	if req.ParentID != nil {
+		if err := s.setParent(todo, *req.ParentID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	position, err := s.nextPosition(userID)
+	if err != nil {
+		return nil, err
+	}
+	todo.Position = &position
+
 	if err := s.todoRepo.Create(todo); err != nil {
 		return nil, err
 	}
+	if err := s.record(models.TodoChangeCreated, userID, nil, todo); err != nil {
+		return nil, err
+	}
 
 	return todo, nil
 }
 
+// CreateTree creates todos with their subtasks as one operation, parents
+// before their subtasks
+func (s *todoService) CreateTree(userID uuid.UUID, tree []models.TodoTreeRequest) ([]models.Todo, error) {
+	var created []models.Todo
+	err := s.inOperation(userID, models.OperationInstantiate, func(tx *todoService) error {
+		var walk func(nodes []models.TodoTreeRequest, parentID *uuid.UUID) error
+		walk = func(nodes []models.TodoTreeRequest, parentID *uuid.UUID) error {
+			for i := range nodes {
+				req := nodes[i].TodoCreateRequest
+				req.ParentID = parentID
+				todo, err := tx.create(userID, &req)
+				if err != nil {
+					return err
+				}
+				created = append(created, *todo)
+				if err := walk(nodes[i].Subtasks, &todo.ID); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		return walk(tree, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
 func (s *todoService) GetByID(id uuid.UUID) (*models.Todo, error) {
 	todo, err := s.todoRepo.GetByID(id)
 	if err != nil {
@@ -61,7 +295,8 @@ func (s *todoService) GetByID(id uuid.UUID) (*models.Todo, error) {
 	return todo, nil
 }
 
-func (s *todoService) GetByUserID(userID uuid.UUID, page, limit int) ([]models.Todo, int64, error) {
+// List returns one page of the user's todos matching filter
+func (s *todoService) List(userID uuid.UUID, filter *models.TodoFilter, page, limit int) ([]models.Todo, int64, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -70,64 +305,595 @@ func (s *todoService) GetByUserID(userID uuid.UUID, page, limit int) ([]models.T
 	}
 
 	offset := (page - 1) * limit
-	return s.todoRepo.GetByUserID(userID, offset, limit)
+	return s.todoRepo.Find(userID, s.localize(userID, filter), offset, limit)
+}
+
+// localize makes relative dates in a filter's query follow the user's timezone
+func (s *todoService) localize(userID uuid.UUID, filter *models.TodoFilter) *models.TodoFilter {
+	if filter == nil || filter.Query == nil || filter.Location != nil {
+		return filter
+	}
+	if user, err := s.userRepo.GetByID(userID); err == nil {
+		filter.Location = user.Location()
+	}
+	return filter
+}
+
+// ListPage returns the page of the user's todos following (or, for a cursor
+// taken from PrevCursor, preceding) cursor. An empty cursor starts at the top.
+func (s *todoService) ListPage(userID uuid.UUID, filter *models.TodoFilter, cursor string, limit int, includeTotal bool) (*TodoPage, error) {
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	var position *models.TodoCursor
+	if cursor != "" {
+		position = &models.TodoCursor{}
+		if err := utils.DecodeCursorValue(cursor, position); err != nil {
+			return nil, err
+		}
+		if _, err := position.KeyValues(filter.Sort); err != nil {
+			return nil, err
+		}
+	}
+
+	// Fetch one extra row to know whether the walk continues
+	todos, err := s.todoRepo.FindAfter(userID, s.localize(userID, filter), position, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	more := len(todos) > limit
+	if more {
+		todos = todos[:limit]
+	}
+
+	page := &TodoPage{Todos: todos}
+	backward := position != nil && position.Backward
+	if backward {
+		for i, j := 0, len(todos)-1; i < j; i, j = i+1, j-1 {
+			todos[i], todos[j] = todos[j], todos[i]
+		}
+	}
+
+	if len(todos) > 0 {
+		first, last := &todos[0], &todos[len(todos)-1]
+		if (backward && more) || (!backward && position != nil) {
+			page.PrevCursor = utils.EncodeCursorValue(models.NewTodoCursor(first, filter.Sort, true))
+		}
+		if (!backward && more) || backward {
+			page.NextCursor = utils.EncodeCursorValue(models.NewTodoCursor(last, filter.Sort, false))
+		}
+	}
+
+	if includeTotal {
+		total, err := s.todoRepo.Count(userID, filter)
+		if err != nil {
+			return nil, err
+		}
+		page.Total = &total
+	}
+
+	return page, nil
 }
 
 func (s *todoService) Update(id uuid.UUID, userID uuid.UUID, req *models.TodoUpdateRequest) (*models.Todo, error) {
-	todo, err := s.todoRepo.GetByID(id)
+	return s.mutate(userID, models.OperationUpdate, func(tx *todoService) (*models.Todo, error) {
+		return tx.update(id, userID, req)
+	})
+}
+
+func (s *todoService) update(id uuid.UUID, userID uuid.UUID, req *models.TodoUpdateRequest) (*models.Todo, error) {
+	todo, err := s.getOwned(id, userID, "update")
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("todo not found")
+		return nil, err
+	}
+	before := snapshot(todo)
+
+	wasCompleted := todo.Status == models.TodoStatusCompleted
+	dueDateChanged := false
+
+	// Update fields if provided; status changes follow the list's workflow
+	if req.Status != "" {
+		if err := s.setStatus(todo, req.Status); err != nil {
+			return nil, err
+		}
+	}
+	if req.DueDate != nil {
+		dueDateChanged = todo.DueDate == nil || !todo.DueDate.Equal(*req.DueDate)
+		todo.DueDate = req.DueDate
+	} else if req.Clears(models.TodoFieldDueDate) && todo.DueDate != nil {
+		if todo.IsRecurring() {
+			return nil, errors.New("invalid recurrence rule: recurring todos require a due date")
 		}
+		dueDateChanged = true
+		todo.DueDate = nil
+	}
+	if err := s.applySharedFields(todo, req); err != nil {
 		return nil, err
 	}
+	if req.ListID != nil {
+		if *req.ListID == uuid.Nil {
+			todo.ListID = nil
+		} else {
+			if err := s.checkListOwner(*req.ListID, userID); err != nil {
+				return nil, err
+			}
+			todo.ListID = req.ListID
+		}
+		// A todo that stays in its list keeps its place on the board
+		if !sameListID(before.ListID, todo.ListID) {
+			if err := s.placeInList(todo, ""); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if req.ParentID != nil {
+		if err := s.setParent(todo, *req.ParentID, userID); err != nil {
+			return nil, err
+		}
+	}
 
-	// Check if the todo belongs to the user
-	if todo.UserID != userID {
-		return nil, errors.New("unauthorized to update this todo")
+	if err := s.todoRepo.Update(todo); err != nil {
+		return nil, err
 	}
 
-	// Update fields if provided
+	if req.Tags != nil {
+		tags, err := s.tagRepo.FindOrCreate(userID, normalizeTagNames(req.Tags))
+		if err != nil {
+			return nil, err
+		}
+		if err := s.todoRepo.SetTags(todo, tags); err != nil {
+			return nil, err
+		}
+		todo.Tags = tags
+	}
+
+	if dueDateChanged {
+		if err := s.reminderRepo.RescheduleForTodo(todo.ID, todo.DueDate); err != nil {
+			return nil, err
+		}
+	}
+
+	// Series edits carry the shared fields over to the other open occurrences
+	if req.Scope == models.TodoScopeSeries && todo.SeriesID != nil {
+		siblings, err := s.todoRepo.GetOpenBySeriesID(*todo.SeriesID)
+		if err != nil {
+			return nil, err
+		}
+		for i := range siblings {
+			if siblings[i].ID == todo.ID {
+				continue
+			}
+			previous := snapshot(&siblings[i])
+			if err := s.applySharedFields(&siblings[i], req); err != nil {
+				return nil, err
+			}
+			if err := s.todoRepo.Update(&siblings[i]); err != nil {
+				return nil, err
+			}
+			if err := s.record(models.TodoChangeUpdated, userID, previous, &siblings[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := s.record(models.TodoChangeUpdated, userID, before, todo); err != nil {
+		return nil, err
+	}
+
+	// Completing an occurrence of a recurring todo schedules the next one
+	if !wasCompleted && todo.Status == models.TodoStatusCompleted && todo.IsRecurring() {
+		if err := s.createNextOccurrence(todo); err != nil {
+			return nil, err
+		}
+	}
+
+	return todo, nil
+}
+
+// applySharedFields applies the fields that a series edit propagates to every open occurrence
+func (s *todoService) applySharedFields(todo *models.Todo, req *models.TodoUpdateRequest) error {
 	if req.Title != "" {
 		todo.Title = req.Title
 	}
 	if req.Description != "" {
 		todo.Description = req.Description
-	}
-	if req.Status != "" {
-		todo.Status = req.Status
+	} else if req.Clears(models.TodoFieldDescription) {
+		todo.Description = ""
 	}
 	if req.Priority > 0 {
 		todo.Priority = req.Priority
+	} else if req.Clears(models.TodoFieldPriority) {
+		todo.Priority = 0
 	}
-	if req.DueDate != nil {
-		todo.DueDate = req.DueDate
+	if req.Estimate != nil {
+		if *req.Estimate < 0 {
+			todo.Estimate = nil
+		} else {
+			estimate := *req.Estimate
+			todo.Estimate = &estimate
+		}
+	}
+	if req.RecurrenceRule != "" || req.Timezone != "" {
+		rule := req.RecurrenceRule
+		if rule == "" {
+			rule = todo.RecurrenceRule
+		}
+		if rule == "" {
+			return errors.New("invalid recurrence rule: timezone requires a recurrence rule")
+		}
+		if err := s.setRecurrence(todo, rule, req.Timezone); err != nil {
+			return err
+		}
+		if todo.SeriesID == nil {
+			todo.SeriesID = &todo.ID
+		}
 	}
+	return nil
+}
+
+// Delete moves a todo to the trash together with its reminders, comments and
+// attachments; see Restore and Purge
+func (s *todoService) Delete(id uuid.UUID, userID uuid.UUID) error {
+	_, err := s.mutate(userID, models.OperationDelete, func(tx *todoService) (*models.Todo, error) {
+		return nil, tx.delete(id, userID)
+	})
+	return err
+}
 
+func (s *todoService) delete(id uuid.UUID, userID uuid.UUID) error {
+	todo, err := s.getOwned(id, userID, "delete")
+	if err != nil {
+		return err
+	}
+	if err := s.todoRepo.Delete(id); err != nil {
+		return err
+	}
+	return s.record(models.TodoChangeDeleted, userID, todo, todo)
+}
+
+// History returns one page of a todo's change history, newest first. The
+// history of a todo in the trash stays readable until it is purged.
+func (s *todoService) History(id uuid.UUID, userID uuid.UUID, page, limit int) ([]models.TodoChange, int64, error) {
+	todo, err := s.todoRepo.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		todo, err = s.todoRepo.GetDeletedByID(id)
+	}
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, 0, errors.New("todo not found")
+		}
+		return nil, 0, err
+	}
+	if todo.UserID != userID {
+		return nil, 0, errors.New("unauthorized to access this todo")
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+
+	offset := (page - 1) * limit
+	return s.changeRepo.GetByTodoID(id, offset, limit)
+}
+
+// getOwned loads a todo the user is about to change; action names the change
+// in the error returned for someone else's todo
+func (s *todoService) getOwned(id uuid.UUID, userID uuid.UUID, action string) (*models.Todo, error) {
+	todo, err := s.todoRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("todo not found")
+		}
+		return nil, err
+	}
+
+	// Check if the todo belongs to the user
+	if todo.UserID != userID {
+		return nil, fmt.Errorf("unauthorized to %s this todo", action)
+	}
+	return todo, nil
+}
+
+// SkipOccurrence moves a recurring todo to its next occurrence without completing it
+func (s *todoService) SkipOccurrence(id uuid.UUID, userID uuid.UUID) (*models.Todo, error) {
+	return s.mutate(userID, models.OperationSkip, func(tx *todoService) (*models.Todo, error) {
+		return tx.skipOccurrence(id, userID)
+	})
+}
+
+func (s *todoService) skipOccurrence(id uuid.UUID, userID uuid.UUID) (*models.Todo, error) {
+	todo, err := s.getRecurringForUpdate(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	before := snapshot(todo)
+
+	next, found, err := nextOccurrence(todo)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errors.New("no more occurrences in this series")
+	}
+
+	todo.DueDate = &next
 	if err := s.todoRepo.Update(todo); err != nil {
 		return nil, err
 	}
+	if err := s.reminderRepo.RescheduleForTodo(todo.ID, todo.DueDate); err != nil {
+		return nil, err
+	}
+	if err := s.record(models.TodoChangeUpdated, userID, before, todo); err != nil {
+		return nil, err
+	}
+	return todo, nil
+}
+
+// EndSeries stops a recurring todo from generating further occurrences
+func (s *todoService) EndSeries(id uuid.UUID, userID uuid.UUID) (*models.Todo, error) {
+	return s.mutate(userID, models.OperationEndSeries, func(tx *todoService) (*models.Todo, error) {
+		return tx.endSeries(id, userID)
+	})
+}
+
+func (s *todoService) endSeries(id uuid.UUID, userID uuid.UUID) (*models.Todo, error) {
+	todo, err := s.getRecurringForUpdate(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	before := snapshot(todo)
+	todo.RecurrenceRule = ""
+	if err := s.todoRepo.Update(todo); err != nil {
+		return nil, err
+	}
+	if err := s.record(models.TodoChangeUpdated, userID, before, todo); err != nil {
+		return nil, err
+	}
+
+	if todo.SeriesID != nil {
+		open, err := s.todoRepo.GetOpenBySeriesID(*todo.SeriesID)
+		if err != nil {
+			return nil, err
+		}
+		for i := range open {
+			if open[i].ID == todo.ID || !open[i].IsRecurring() {
+				continue
+			}
+			previous := snapshot(&open[i])
+			open[i].RecurrenceRule = ""
+			if err := s.todoRepo.Update(&open[i]); err != nil {
+				return nil, err
+			}
+			if err := s.record(models.TodoChangeUpdated, userID, previous, &open[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
 
 	return todo, nil
 }
 
-func (s *todoService) Delete(id uuid.UUID, userID uuid.UUID) error {
-	todo, err := s.todoRepo.GetByID(id)
+// checkListOwner makes sure a todo is only filed into one of its owner's lists
+func (s *todoService) checkListOwner(listID uuid.UUID, userID uuid.UUID) error {
+	list, err := s.listRepo.GetByID(listID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("todo not found")
+			return errors.New("list not found")
 		}
 		return err
 	}
+	if list.UserID != userID {
+		return errors.New("list not found")
+	}
+	return nil
+}
+
+// setParent makes todo a subtask of parentID, which must be another of the
+// user's todos and not one of todo's own subtasks; the nil UUID makes todo a
+// top-level todo again
+func (s *todoService) setParent(todo *models.Todo, parentID uuid.UUID, userID uuid.UUID) error {
+	if parentID == uuid.Nil {
+		todo.ParentID = nil
+		return nil
+	}
+	if parentID == todo.ID {
+		return errors.New("invalid parent: a todo can't be its own subtask")
+	}
+
+	parent, err := s.todoRepo.GetByID(parentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("parent todo not found")
+		}
+		return err
+	}
+	if parent.UserID != userID {
+		return errors.New("parent todo not found")
+	}
+
+	// Walk up from the new parent; meeting todo would close a cycle. Ancestors
+	// in the trash end the walk.
+	seen := map[uuid.UUID]bool{parent.ID: true}
+	for ancestor := parent; ancestor.ParentID != nil && !seen[*ancestor.ParentID]; {
+		if *ancestor.ParentID == todo.ID {
+			return errors.New("invalid parent: a todo can't be a subtask of its own subtask")
+		}
+		seen[*ancestor.ParentID] = true
+		ancestor, err = s.todoRepo.GetByID(*ancestor.ParentID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	todo.ParentID = &parentID
+	return nil
+}
+
+// normalizeTagNames normalizes tag names and drops duplicates, keeping order
+func normalizeTagNames(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	out := make([]string, 0, len(names))
+	for _, name := range names {
+		name = models.NormalizeTagName(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		out = append(out, name)
+	}
+	return out
+}
+
+func (s *todoService) getRecurringForUpdate(id uuid.UUID, userID uuid.UUID) (*models.Todo, error) {
+	todo, err := s.todoRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("todo not found")
+		}
+		return nil, err
+	}
 
-	// Check if the todo belongs to the user
 	if todo.UserID != userID {
-		return errors.New("unauthorized to delete this todo")
+		return nil, errors.New("unauthorized to update this todo")
+	}
+	if !todo.IsRecurring() {
+		return nil, errors.New("todo is not recurring")
 	}
+	return todo, nil
+}
 
-	return s.todoRepo.Delete(id)
+// setRecurrence validates and stores a recurrence rule and timezone. A new
+// series is anchored at the todo's current due date; an existing one keeps
+// its anchor, so editing a later occurrence or only the timezone doesn't
+// restart COUNT or shift INTERVAL. Changing the rule of the series' first
+// occurrence re-anchors it.
+func (s *todoService) setRecurrence(todo *models.Todo, rule, timezone string) error {
+	parsed, err := rrule.Parse(rule)
+	if err != nil {
+		return fmt.Errorf("invalid recurrence rule: %w", err)
+	}
+	if timezone == "" {
+		timezone = todo.Timezone
+	}
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return fmt.Errorf("invalid timezone: %w", err)
+	}
+	if todo.DueDate == nil {
+		return errors.New("invalid recurrence rule: recurring todos require a due date")
+	}
+
+	changed := todo.RecurrenceRule != parsed.String()
+	firstOccurrence := todo.SeriesID == nil || *todo.SeriesID == todo.ID
+	if todo.RecurrenceStart == nil || todo.RecurrenceRule == "" || (changed && firstOccurrence) {
+		start := *todo.DueDate
+		todo.RecurrenceStart = &start
+	}
+	todo.RecurrenceRule = parsed.String()
+	todo.Timezone = timezone
+	return nil
 }
 
-func (s *todoService) GetByStatus(userID uuid.UUID, status models.TodoStatus) ([]models.Todo, error) {
-	return s.todoRepo.GetByStatus(userID, status)
-} 
\ No newline at end of file
+// createNextOccurrence creates the occurrence following a completed one,
+// unless the series has ended or an open occurrence already exists
+func (s *todoService) createNextOccurrence(completed *models.Todo) error {
+	next, found, err := nextOccurrence(completed)
+	if err != nil || !found {
+		return err
+	}
+
+	if completed.SeriesID != nil {
+		open, err := s.todoRepo.GetOpenBySeriesID(*completed.SeriesID)
+		if err != nil {
+			return err
+		}
+		if len(open) > 0 {
+			return nil
+		}
+	}
+
+	occurrence := &models.Todo{
+		Title:           completed.Title,
+		Description:     completed.Description,
+		Status:          models.TodoStatusPending,
+		Priority:        completed.Priority,
+		Estimate:        completed.Estimate,
+		DueDate:         &next,
+		UserID:          completed.UserID,
+		RecurrenceRule:  completed.RecurrenceRule,
+		Timezone:        completed.Timezone,
+		SeriesID:        completed.SeriesID,
+		RecurrenceStart: completed.RecurrenceStart,
+		ListID:          completed.ListID,
+		ParentID:        completed.ParentID,
+		Tags:            completed.Tags,
+		Position:        completed.Position, // takes the completed occurrence's place
+	}
+	if err := s.placeInList(occurrence, ""); err != nil {
+		return err
+	}
+	if err := s.todoRepo.Create(occurrence); err != nil {
+		return err
+	}
+	if err := s.record(models.TodoChangeCreated, completed.UserID, nil, occurrence); err != nil {
+		return err
+	}
+
+	// Offset reminders follow the series; absolute ones belong to a single occurrence
+	reminders, err := s.reminderRepo.GetByTodoID(completed.ID)
+	if err != nil {
+		return err
+	}
+	for _, r := range reminders {
+		if r.OffsetMinutes == nil {
+			continue
+		}
+		reminder := &models.Reminder{
+			TodoID:        occurrence.ID,
+			UserID:        occurrence.UserID,
+			OffsetMinutes: r.OffsetMinutes,
+			Channel:       r.Channel,
+			Status:        models.ReminderStatusPending,
+		}
+		reminder.Resolve(occurrence.DueDate)
+		if err := s.reminderRepo.Create(reminder); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nextOccurrence computes the due date following todo's current one in the
+// todo's timezone, so wall-clock times survive DST transitions
+func nextOccurrence(todo *models.Todo) (time.Time, bool, error) {
+	if todo.DueDate == nil {
+		return time.Time{}, false, nil
+	}
+
+	rule, err := rrule.Parse(todo.RecurrenceRule)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid recurrence rule: %w", err)
+	}
+	loc, err := time.LoadLocation(todo.Timezone)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid timezone: %w", err)
+	}
+
+	anchor := *todo.DueDate
+	if todo.RecurrenceStart != nil {
+		anchor = *todo.RecurrenceStart
+	}
+
+	next, found := rule.After(anchor.In(loc), todo.DueDate.In(loc))
+	return next.UTC(), found, nil
+}
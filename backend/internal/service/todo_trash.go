@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GetTrash returns one page of the user's deleted todos, most recently deleted first
+func (s *todoService) GetTrash(userID uuid.UUID, page, limit int) ([]models.Todo, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+
+	offset := (page - 1) * limit
+	return s.todoRepo.FindDeleted(userID, offset, limit)
+}
+
+// Restore takes a todo out of the trash together with the reminders, comments
+// and attachments that were deleted with it. A todo whose list is gone by now
+// comes back without a list.
+func (s *todoService) Restore(id uuid.UUID, userID uuid.UUID) (*models.Todo, error) {
+	return s.mutate(userID, models.OperationRestore, func(tx *todoService) (*models.Todo, error) {
+		return tx.restore(id, userID)
+	})
+}
+
+func (s *todoService) restore(id uuid.UUID, userID uuid.UUID) (*models.Todo, error) {
+	todo, err := s.getTrashed(id, userID, "restore")
+	if err != nil {
+		return nil, err
+	}
+	before := snapshot(todo)
+
+	if todo.ListID != nil {
+		if err := s.checkListOwner(*todo.ListID, userID); err != nil {
+			if err.Error() != "list not found" {
+				return nil, err
+			}
+			todo.ListID = nil
+			todo.State = nil
+		}
+	}
+
+	if err := s.releaseCalDAVIdentity(todo); err != nil {
+		return nil, err
+	}
+
+	if err := s.todoRepo.Restore(todo); err != nil {
+		return nil, err
+	}
+	if err := s.record(models.TodoChangeRestored, userID, before, todo); err != nil {
+		return nil, err
+	}
+	return s.GetByID(id)
+}
+
+// releaseCalDAVIdentity drops the CalDAV name and UID of a trashed todo when a
+// CalDAV client has reused them since; the todo then goes by its ID again
+func (s *todoService) releaseCalDAVIdentity(todo *models.Todo) error {
+	if todo.CalDAVName != nil {
+		if _, err := s.todoRepo.GetByCalDAVName(todo.UserID, *todo.CalDAVName); err == nil {
+			todo.CalDAVName = nil
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+	}
+	if todo.ICalUID != nil {
+		if _, err := s.todoRepo.GetByICalUID(todo.UserID, *todo.ICalUID); err == nil {
+			todo.ICalUID = nil
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Purge deletes a trashed todo and everything attached to it for good
+func (s *todoService) Purge(id uuid.UUID, userID uuid.UUID) error {
+	if _, err := s.getTrashed(id, userID, "purge"); err != nil {
+		return err
+	}
+
+	// Files go first: if that fails the todo stays in the trash to be retried
+	if err := s.attachmentService.DeleteBlobsForTodo(context.Background(), id); err != nil {
+		return err
+	}
+	return s.todoRepo.Purge(id)
+}
+
+// getTrashed loads a deleted todo of the user; action names the change in the
+// error returned for someone else's todo
+func (s *todoService) getTrashed(id uuid.UUID, userID uuid.UUID, action string) (*models.Todo, error) {
+	todo, err := s.todoRepo.GetDeletedByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("todo not found in trash")
+		}
+		return nil, err
+	}
+
+	if todo.UserID != userID {
+		return nil, fmt.Errorf("unauthorized to %s this todo", action)
+	}
+	return todo, nil
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// undoWindow is how long an operation can still be undone or redone
+const undoWindow = time.Hour
+
+// UndoResult is the operation logged for an undo or redo, the operation it
+// reverted and the todos it touched
+type UndoResult struct {
+	Operation *models.Operation
+	Reverted  *models.Operation
+	TodoIDs   []uuid.UUID
+}
+
+// Operations returns the user's latest operations, newest first
+func (s *todoService) Operations(userID uuid.UUID, limit int) ([]models.Operation, error) {
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	return s.operationRepo.GetRecent(userID, limit)
+}
+
+// Undo reverts the user's most recent operation, or the one given, by putting
+// back the before-images recorded with its changes. The undo is an operation
+// of its own and can be redone.
+func (s *todoService) Undo(userID uuid.UUID, opID *uuid.UUID) (*UndoResult, error) {
+	return s.revert(userID, opID, models.OperationUndo)
+}
+
+// Redo reverts the user's most recent undo, or the one given
+func (s *todoService) Redo(userID uuid.UUID, opID *uuid.UUID) (*UndoResult, error) {
+	return s.revert(userID, opID, models.OperationRedo)
+}
+
+func (s *todoService) revert(userID uuid.UUID, opID *uuid.UUID, kind models.OperationKind) (*UndoResult, error) {
+	var result *UndoResult
+	err := s.inOperation(userID, kind, func(tx *todoService) error {
+		target, err := tx.revertTarget(userID, opID, kind)
+		if err != nil {
+			return err
+		}
+
+		changes, err := tx.changeRepo.GetByOperationID(target.ID)
+		if err != nil {
+			return err
+		}
+		result = &UndoResult{Operation: tx.operation, Reverted: target, TodoIDs: []uuid.UUID{}}
+		seen := make(map[uuid.UUID]bool)
+		for i := range changes {
+			if err := tx.revertChange(userID, &changes[i], kind); err != nil {
+				return err
+			}
+			if !seen[changes[i].TodoID] {
+				seen[changes[i].TodoID] = true
+				result.TodoIDs = append(result.TodoIDs, changes[i].TodoID)
+			}
+		}
+
+		now := time.Now()
+		target.UndoneAt = &now
+		if err := tx.operationRepo.Update(target); err != nil {
+			return err
+		}
+		tx.operation.RevertsID = &target.ID
+		return tx.operationRepo.Update(tx.operation)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// revertTarget finds the operation an undo or redo applies to
+func (s *todoService) revertTarget(userID uuid.UUID, opID *uuid.UUID, kind models.OperationKind) (*models.Operation, error) {
+	since := time.Now().Add(-undoWindow)
+
+	if opID == nil {
+		var op *models.Operation
+		var err error
+		if kind == models.OperationUndo {
+			op, err = s.operationRepo.LatestUndoable(userID, since)
+		} else {
+			op, err = s.operationRepo.LatestRedoable(userID, since)
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("nothing to %s", kind)
+		}
+		return op, err
+	}
+
+	op, err := s.operationRepo.GetByID(*opID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("operation not found")
+		}
+		return nil, err
+	}
+	if op.UserID != userID {
+		return nil, errors.New("operation not found")
+	}
+
+	switch {
+	case kind == models.OperationUndo && op.Kind == models.OperationUndo:
+		return nil, errors.New("operation is an undo; redo it instead")
+	case kind == models.OperationRedo && op.Kind != models.OperationUndo:
+		return nil, errors.New("only undo operations can be redone")
+	case op.UndoneAt != nil:
+		return nil, errors.New("operation was already reverted")
+	case op.CreatedAt.Before(since):
+		return nil, fmt.Errorf("operation is too old to %s", kind)
+	}
+	return op, nil
+}
+
+// revertChange reverses one recorded change, refusing when the todo was
+// changed again since so later edits are never silently overwritten
+func (s *todoService) revertChange(userID uuid.UUID, change *models.TodoChange, kind models.OperationKind) error {
+	conflict := fmt.Errorf("cannot %s: todo %s was changed since", kind, change.TodoID)
+
+	if change.Action == models.TodoChangeDeleted {
+		if _, err := s.restore(change.TodoID, userID); err != nil {
+			if err.Error() == "todo not found in trash" {
+				return conflict
+			}
+			return err
+		}
+		return nil
+	}
+
+	todo, err := s.getOwned(change.TodoID, userID, "update")
+	if err != nil {
+		if err.Error() == "todo not found" {
+			return conflict
+		}
+		return err
+	}
+	if !change.Changes.Matches(todo) {
+		return conflict
+	}
+
+	switch change.Action {
+	case models.TodoChangeCreated, models.TodoChangeRestored:
+		return s.delete(todo.ID, userID)
+	case models.TodoChangeUpdated:
+		if change.Before == nil {
+			return fmt.Errorf("cannot %s: no before-image recorded for todo %s", kind, change.TodoID)
+		}
+		return s.applyImage(userID, todo, change.Before)
+	}
+	return fmt.Errorf("cannot %s: unknown change %q", kind, change.Action)
+}
+
+// applyImage puts a todo back into a recorded state
+func (s *todoService) applyImage(userID uuid.UUID, todo *models.Todo, image *models.TodoImage) error {
+	before := snapshot(todo)
+	image.ApplyTo(todo)
+
+	if todo.ListID != nil {
+		if err := s.checkListOwner(*todo.ListID, userID); err != nil {
+			if err.Error() != "list not found" {
+				return err
+			}
+			todo.ListID = nil
+			todo.State = nil
+		}
+	}
+	if todo.ParentID != nil {
+		// A purged parent leaves the todo at the top level
+		if _, err := s.todoRepo.GetByID(*todo.ParentID); errors.Is(err, gorm.ErrRecordNotFound) {
+			if _, err := s.todoRepo.GetDeletedByID(*todo.ParentID); errors.Is(err, gorm.ErrRecordNotFound) {
+				todo.ParentID = nil
+			}
+		}
+	}
+	if err := s.todoRepo.Update(todo); err != nil {
+		return err
+	}
+
+	tags, err := s.tagRepo.FindOrCreate(userID, image.Tags)
+	if err != nil {
+		return err
+	}
+	if err := s.todoRepo.SetTags(todo, tags); err != nil {
+		return err
+	}
+	todo.Tags = tags
+
+	if !sameTime(before.DueDate, todo.DueDate) {
+		if err := s.reminderRepo.RescheduleForTodo(todo.ID, todo.DueDate); err != nil {
+			return err
+		}
+	}
+	return s.record(models.TodoChangeUpdated, userID, before, todo)
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+	"time"
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createTodo(t *testing.T, s *todoService, userID uuid.UUID, title string) *models.Todo {
+	todo, err := s.Create(userID, &models.TodoCreateRequest{Title: title, Priority: 1})
+	require.NoError(t, err)
+	return todo
+}
+
+func rename(t *testing.T, s *todoService, id, userID uuid.UUID, title string) {
+	_, err := s.Update(id, userID, &models.TodoUpdateRequest{Title: title})
+	require.NoError(t, err)
+}
+
+func TestUndo_Update(t *testing.T) {
+	userID := uuid.New()
+	s, db := newBulkFixture()
+	todo := createTodo(t, s, userID, "Draft")
+	rename(t, s, todo.ID, userID, "Final")
+
+	result, err := s.Undo(userID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, models.OperationUpdate, result.Reverted.Kind)
+	assert.Equal(t, []uuid.UUID{todo.ID}, result.TodoIDs)
+	assert.Equal(t, "Draft", db.todos[todo.ID].Title)
+
+	// The undo is logged as an operation of its own
+	assert.Equal(t, models.OperationUndo, result.Operation.Kind)
+	assert.Equal(t, result.Reverted.ID, *result.Operation.RevertsID)
+}
+
+func TestUndo_Create(t *testing.T) {
+	userID := uuid.New()
+	s, db := newBulkFixture()
+	todo := createTodo(t, s, userID, "Mistake")
+
+	_, err := s.Undo(userID, nil)
+	require.NoError(t, err)
+	assert.NotContains(t, db.todos, todo.ID)
+	assert.Contains(t, db.trash, todo.ID)
+}
+
+func TestUndo_Delete(t *testing.T) {
+	userID := uuid.New()
+	s, db := newBulkFixture()
+	todo := createTodo(t, s, userID, "Keep me")
+	require.NoError(t, s.Delete(todo.ID, userID))
+	require.Contains(t, db.trash, todo.ID)
+
+	result, err := s.Undo(userID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, models.OperationDelete, result.Reverted.Kind)
+	assert.Equal(t, "Keep me", db.todos[todo.ID].Title)
+	assert.NotContains(t, db.trash, todo.ID)
+}
+
+func TestUndo_Bulk(t *testing.T) {
+	userID := uuid.New()
+	s, db := newBulkFixture()
+	first := createTodo(t, s, userID, "First")
+	second := createTodo(t, s, userID, "Second")
+
+	priority := 5
+	_, err := s.Bulk(userID, &models.TodoBulkRequest{
+		Action:   models.TodoBulkSetPriority,
+		IDs:      []uuid.UUID{first.ID, second.ID},
+		Priority: &priority,
+	})
+	require.NoError(t, err)
+
+	// The whole bulk request is one operation and is undone as one
+	result, err := s.Undo(userID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, models.OperationBulk, result.Reverted.Kind)
+	assert.ElementsMatch(t, []uuid.UUID{first.ID, second.ID}, result.TodoIDs)
+	assert.Equal(t, 1, db.todos[first.ID].Priority)
+	assert.Equal(t, 1, db.todos[second.ID].Priority)
+}
+
+func TestUndo_ConflictsWithLaterChanges(t *testing.T) {
+	userID := uuid.New()
+	s, db := newBulkFixture()
+	todo := createTodo(t, s, userID, "One")
+	rename(t, s, todo.ID, userID, "Two")
+	renamed := db.operations[len(db.operations)-1]
+	rename(t, s, todo.ID, userID, "Three")
+
+	_, err := s.Undo(userID, &renamed.ID)
+	assert.EqualError(t, err, fmt.Sprintf("cannot undo: todo %s was changed since", todo.ID))
+	assert.Equal(t, "Three", db.todos[todo.ID].Title)
+
+	// Nothing of the failed undo was kept
+	last := db.operations[len(db.operations)-1]
+	assert.Equal(t, models.OperationUpdate, last.Kind)
+	assert.Nil(t, last.UndoneAt)
+}
+
+func TestRedo_AfterUndo(t *testing.T) {
+	userID := uuid.New()
+	s, db := newBulkFixture()
+	todo := createTodo(t, s, userID, "Draft")
+	rename(t, s, todo.ID, userID, "Final")
+
+	undo, err := s.Undo(userID, nil)
+	require.NoError(t, err)
+
+	result, err := s.Redo(userID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, undo.Operation.ID, result.Reverted.ID)
+	assert.Equal(t, models.OperationRedo, result.Operation.Kind)
+	assert.Equal(t, "Final", db.todos[todo.ID].Title)
+
+	_, err = s.Redo(userID, nil)
+	assert.EqualError(t, err, "nothing to redo")
+}
+
+func TestRedo_BlockedByNewChange(t *testing.T) {
+	userID := uuid.New()
+	s, db := newBulkFixture()
+	todo := createTodo(t, s, userID, "Draft")
+	rename(t, s, todo.ID, userID, "Final")
+
+	_, err := s.Undo(userID, nil)
+	require.NoError(t, err)
+	rename(t, s, todo.ID, userID, "Different")
+
+	_, err = s.Redo(userID, nil)
+	assert.EqualError(t, err, "nothing to redo")
+	assert.Equal(t, "Different", db.todos[todo.ID].Title)
+}
+
+func TestUndo_WindowExpires(t *testing.T) {
+	userID := uuid.New()
+	s, db := newBulkFixture()
+	todo := createTodo(t, s, userID, "Draft")
+	rename(t, s, todo.ID, userID, "Final")
+
+	for i := range db.operations {
+		db.operations[i].CreatedAt = time.Now().Add(-undoWindow - time.Minute)
+	}
+	renamed := db.operations[len(db.operations)-1]
+
+	_, err := s.Undo(userID, nil)
+	assert.EqualError(t, err, "nothing to undo")
+
+	_, err = s.Undo(userID, &renamed.ID)
+	assert.EqualError(t, err, "operation is too old to undo")
+	assert.Equal(t, "Final", db.todos[todo.ID].Title)
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// workflowFor returns the workflow of a list and whether it is the list's
+// own. Todos without a list and lists without states use the default one.
+func (s *todoService) workflowFor(listID *uuid.UUID) (models.Workflow, bool, error) {
+	if listID == nil {
+		return models.DefaultWorkflow(), false, nil
+	}
+	states, err := s.workflowRepo.GetByListID(*listID)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(states) == 0 {
+		return models.DefaultWorkflow(), false, nil
+	}
+	return states, true, nil
+}
+
+// enterState moves a todo to a state of its list's workflow, enforcing the
+// workflow's transitions and the state's WIP limit, and sets the todo's
+// status from the state's category
+func (s *todoService) enterState(todo *models.Todo, key string) error {
+	workflow, custom, err := s.workflowFor(todo.ListID)
+	if err != nil {
+		return err
+	}
+	target := workflow.Find(key)
+	if target == nil {
+		return fmt.Errorf("invalid move: unknown state %q", key)
+	}
+
+	current := workflow.StateOf(todo)
+	if current == nil || current.Key != target.Key {
+		if current != nil && !current.CanMove(target.Key) {
+			return fmt.Errorf("transition not allowed: %s to %s", current.Key, target.Key)
+		}
+		if err := s.checkWIPLimit(todo.ListID, target); err != nil {
+			return err
+		}
+	}
+
+	// A todo can't be started while it waits on unfinished dependencies
+	status := target.Category.Status()
+	if status == models.TodoStatusInProgress && todo.Status != models.TodoStatusInProgress && todo.IsBlocked() {
+		return errors.New("todo is blocked by unfinished dependencies")
+	}
+
+	todo.Status = status
+	todo.State = nil
+	if custom {
+		todo.State = &target.Key
+	}
+	return nil
+}
+
+// setStatus changes a todo's status through its list's workflow: the todo
+// moves to the first state of the status' category unless it is in one of
+// that category already
+func (s *todoService) setStatus(todo *models.Todo, status models.TodoStatus) error {
+	workflow, _, err := s.workflowFor(todo.ListID)
+	if err != nil {
+		return err
+	}
+	category := models.CategoryOf(status)
+	if current := workflow.StateOf(todo); current != nil && current.Category == category {
+		return nil
+	}
+
+	target := workflow.First(category)
+	if target == nil {
+		return fmt.Errorf("transition not allowed: the list's workflow has no %s state", category)
+	}
+	return s.enterState(todo, target.Key)
+}
+
+// placeInList puts a todo that joins a list into the state keyed preferred,
+// when the list's board has it, or else into the first state of its status'
+// category, or the board's first state when the workflow has none of that
+// category
+func (s *todoService) placeInList(todo *models.Todo, preferred string) error {
+	workflow, custom, err := s.workflowFor(todo.ListID)
+	if err != nil {
+		return err
+	}
+	if !custom {
+		todo.State = nil
+		return nil
+	}
+
+	state := workflow.Find(preferred)
+	if state == nil {
+		state = workflow.First(models.CategoryOf(todo.Status))
+	}
+	if state == nil {
+		state = &workflow[0]
+	}
+	if err := s.checkWIPLimit(todo.ListID, state); err != nil {
+		return err
+	}
+	todo.State = &state.Key
+	todo.Status = state.Category.Status()
+	return nil
+}
+
+// checkWIPLimit refuses a todo entering a state that is already full. The
+// list stays locked until the transaction ends, so two todos can't both take
+// the last free place.
+func (s *todoService) checkWIPLimit(listID *uuid.UUID, state *models.WorkflowState) error {
+	if listID == nil || state.WIPLimit == 0 {
+		return nil
+	}
+	if err := s.listRepo.Lock(*listID); err != nil {
+		return err
+	}
+	counts, err := s.todoRepo.CountByState(*listID)
+	if err != nil {
+		return err
+	}
+	if counts[state.Key] >= int64(state.WIPLimit) {
+		return fmt.Errorf("wip limit reached: %s allows %d todos", state.Key, state.WIPLimit)
+	}
+	return nil
+}
+
+func sameListID(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
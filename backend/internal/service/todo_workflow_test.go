@@ -0,0 +1,136 @@
+package service
+
+import (
+	"testing"
+	"todo-backend/internal/models"
+	"todo-backend/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type boardLists struct {
+	repository.ListRepository
+	db *bulkStore
+}
+
+func (r *boardLists) GetByID(id uuid.UUID) (*models.List, error) {
+	list, ok := r.db.lists[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &list, nil
+}
+
+func (r *boardLists) Lock(id uuid.UUID) error {
+	r.db.locked = append(r.db.locked, id)
+	return nil
+}
+
+type boardWorkflows struct {
+	repository.WorkflowRepository
+	db *bulkStore
+}
+
+func (r *boardWorkflows) GetByListID(listID uuid.UUID) (models.Workflow, error) {
+	return r.db.workflows[listID], nil
+}
+
+func (r *bulkTodos) CountByState(listID uuid.UUID) (map[string]int64, error) {
+	counts := make(map[string]int64)
+	for _, todo := range r.db.todos {
+		if todo.ListID == nil || *todo.ListID != listID {
+			continue
+		}
+		if todo.State != nil {
+			counts[*todo.State]++
+		} else {
+			counts[string(todo.Status)]++
+		}
+	}
+	return counts, nil
+}
+
+// newBoard adds a list of the user with the given workflow
+func newBoard(db *bulkStore, userID uuid.UUID, states ...models.WorkflowState) uuid.UUID {
+	list := models.List{ID: uuid.New(), UserID: userID, Name: "Board"}
+	db.lists[list.ID] = list
+	for i := range states {
+		states[i].ListID = list.ID
+		states[i].Position = i
+	}
+	db.workflows[list.ID] = states
+	return list.ID
+}
+
+func TestWIPLimit_MoveIntoAFullState(t *testing.T) {
+	userID := uuid.New()
+	s, db := newBulkFixture()
+	listID := newBoard(db, userID,
+		models.WorkflowState{Key: "todo", Category: models.StateCategoryTodo},
+		models.WorkflowState{Key: "doing", Category: models.StateCategoryDoing, WIPLimit: 1},
+		models.WorkflowState{Key: "done", Category: models.StateCategoryDone},
+	)
+	first, err := s.Create(userID, &models.TodoCreateRequest{Title: "first", ListID: &listID})
+	require.NoError(t, err)
+	second, err := s.Create(userID, &models.TodoCreateRequest{Title: "second", ListID: &listID})
+	require.NoError(t, err)
+
+	_, err = s.Move(first.ID, userID, &models.TodoMoveRequest{State: "doing"})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{listID}, db.locked, "the count is taken under the list's lock")
+
+	_, err = s.Move(second.ID, userID, &models.TodoMoveRequest{State: "doing"})
+	assert.EqualError(t, err, "wip limit reached: doing allows 1 todos")
+	assert.Equal(t, "todo", *db.todos[second.ID].State)
+
+	// Staying in a full state is fine
+	_, err = s.Move(first.ID, userID, &models.TodoMoveRequest{State: "doing"})
+	assert.NoError(t, err)
+}
+
+func TestWIPLimit_JoiningAList(t *testing.T) {
+	userID := uuid.New()
+	s, db := newBulkFixture()
+	listID := newBoard(db, userID,
+		models.WorkflowState{Key: "inbox", Category: models.StateCategoryTodo, WIPLimit: 1},
+		models.WorkflowState{Key: "done", Category: models.StateCategoryDone},
+	)
+	first, err := s.Create(userID, &models.TodoCreateRequest{Title: "first", ListID: &listID})
+	require.NoError(t, err)
+	assert.Equal(t, "inbox", *db.todos[first.ID].State)
+
+	_, err = s.Create(userID, &models.TodoCreateRequest{Title: "second", ListID: &listID})
+	assert.EqualError(t, err, "wip limit reached: inbox allows 1 todos")
+
+	loose := createTodo(t, s, userID, "loose")
+	_, err = s.Update(loose.ID, userID, &models.TodoUpdateRequest{ListID: &listID})
+	assert.EqualError(t, err, "wip limit reached: inbox allows 1 todos")
+	assert.Nil(t, db.todos[loose.ID].ListID)
+
+	// A todo already in the list keeps its place
+	_, err = s.Update(first.ID, userID, &models.TodoUpdateRequest{ListID: &listID, Title: "renamed"})
+	assert.NoError(t, err)
+}
+
+func TestCreate_ImportedStateIsKept(t *testing.T) {
+	userID := uuid.New()
+	s, db := newBulkFixture()
+	listID := newBoard(db, userID,
+		models.WorkflowState{Key: "todo", Category: models.StateCategoryTodo},
+		models.WorkflowState{Key: "review", Category: models.StateCategoryDoing},
+		models.WorkflowState{Key: "done", Category: models.StateCategoryDone},
+	)
+
+	todo, err := s.Create(userID, &models.TodoCreateRequest{Title: "imported", ListID: &listID, State: "review"})
+	require.NoError(t, err)
+	assert.Equal(t, "review", *todo.State)
+	assert.Equal(t, models.TodoStatusInProgress, todo.Status)
+
+	// A state the board doesn't have falls back to the status' first one
+	todo, err = s.Create(userID, &models.TodoCreateRequest{Title: "stale", ListID: &listID, State: "gone", Status: models.TodoStatusCompleted})
+	require.NoError(t, err)
+	assert.Equal(t, "done", *todo.State)
+}
@@ -0,0 +1,283 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	s3Algorithm       = "AWS4-HMAC-SHA256"
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+	s3TimeFormat      = "20060102T150405Z"
+	s3DateFormat      = "20060102"
+)
+
+// S3Config configures an S3-compatible store (AWS S3, MinIO, R2, ...)
+type S3Config struct {
+	Endpoint     string // e.g. https://s3.eu-west-1.amazonaws.com or http://localhost:9000
+	Region       string
+	Bucket       string
+	AccessKey    string
+	SecretKey    string
+	UsePathStyle bool // address buckets as endpoint/bucket instead of bucket.endpoint
+}
+
+// S3Store talks to an S3-compatible object store using Signature Version 4
+type S3Store struct {
+	cfg        S3Config
+	endpoint   *url.URL
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+func NewS3Store(cfg S3Config) (*S3Store, error) {
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", cfg.Endpoint)
+	}
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("S3 bucket is required")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	return &S3Store{
+		cfg:        cfg,
+		endpoint:   endpoint,
+		httpClient: &http.Client{Timeout: 5 * time.Minute},
+		now:        time.Now,
+	}, nil
+}
+
+func (s *S3Store) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key).String(), r)
+	if err != nil {
+		return fmt.Errorf("failed to create S3 request: %w", err)
+	}
+	req.ContentLength = size
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return checkS3Response(resp, "upload")
+}
+
+func (s *S3Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.objectURL(key).String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 request: %w", err)
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	if err := checkS3Response(resp, "download"); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.objectURL(key).String(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create S3 request: %w", err)
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return checkS3Response(resp, "delete")
+}
+
+// PresignGet returns a query-signed GET URL valid for expires
+func (s *S3Store) PresignGet(key, fileName string, expires time.Duration) (string, error) {
+	now := s.now().UTC()
+	u := s.objectURL(key)
+
+	query := url.Values{}
+	query.Set("X-Amz-Algorithm", s3Algorithm)
+	query.Set("X-Amz-Credential", s.cfg.AccessKey+"/"+s.scope(now))
+	query.Set("X-Amz-Date", now.Format(s3TimeFormat))
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expires.Seconds())))
+	query.Set("X-Amz-SignedHeaders", "host")
+	if fileName != "" {
+		query.Set("response-content-disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	}
+
+	canonical := strings.Join([]string{
+		http.MethodGet,
+		u.EscapedPath(),
+		canonicalQuery(query),
+		"host:" + u.Host + "\n",
+		"host",
+		s3UnsignedPayload,
+	}, "\n")
+
+	query.Set("X-Amz-Signature", s.signature(now, canonical))
+	u.RawQuery = canonicalQuery(query)
+	return u.String(), nil
+}
+
+func (s *S3Store) do(req *http.Request) (*http.Response, error) {
+	s.sign(req)
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("S3 request failed: %w", err)
+	}
+	return resp, nil
+}
+
+// sign adds SigV4 headers to req. The payload is not hashed so uploads can be
+// streamed; transport integrity is left to TLS.
+func (s *S3Store) sign(req *http.Request) {
+	now := s.now().UTC()
+	req.Header.Set("X-Amz-Date", now.Format(s3TimeFormat))
+	req.Header.Set("X-Amz-Content-Sha256", s3UnsignedPayload)
+
+	headers := map[string]string{
+		"host":                 req.URL.Host,
+		"x-amz-content-sha256": s3UnsignedPayload,
+		"x-amz-date":           now.Format(s3TimeFormat),
+	}
+	if ct := req.Header.Get("Content-Type"); ct != "" {
+		headers["content-type"] = ct
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonical := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		s3UnsignedPayload,
+	}, "\n")
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, s.cfg.AccessKey, s.scope(now), signedHeaders, s.signature(now, canonical)))
+}
+
+func (s *S3Store) scope(now time.Time) string {
+	return now.Format(s3DateFormat) + "/" + s.cfg.Region + "/s3/aws4_request"
+}
+
+func (s *S3Store) signature(now time.Time, canonicalRequest string) string {
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		s3Algorithm,
+		now.Format(s3TimeFormat),
+		s.scope(now),
+		hex.EncodeToString(hash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.cfg.SecretKey), now.Format(s3DateFormat))
+	key = hmacSHA256(key, s.cfg.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func (s *S3Store) objectURL(key string) *url.URL {
+	u := *s.endpoint
+	escapedKey := escapeKey(key)
+	if s.cfg.UsePathStyle {
+		u.Path = strings.TrimRight(s.endpoint.Path, "/") + "/" + s.cfg.Bucket + "/" + key
+		u.RawPath = strings.TrimRight(s.endpoint.EscapedPath(), "/") + "/" + escapeSegment(s.cfg.Bucket) + "/" + escapedKey
+	} else {
+		u.Host = s.cfg.Bucket + "." + u.Host
+		u.Path = "/" + key
+		u.RawPath = "/" + escapedKey
+	}
+	return &u
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// canonicalQuery encodes query parameters sorted by key as SigV4 requires
+func canonicalQuery(values url.Values) string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, k := range keys {
+		for _, v := range values[k] {
+			parts = append(parts, escapeSegment(k)+"="+escapeSegment(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// escapeKey URI-encodes each path segment of an object key
+func escapeKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = escapeSegment(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// escapeSegment applies the RFC 3986 encoding SigV4 expects
+func escapeSegment(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func checkS3Response(resp *http.Response, action string) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("S3 %s failed with status %d: %s", action, resp.StatusCode, strings.TrimSpace(string(body)))
+}
@@ -0,0 +1,24 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrNotFound is returned when a blob does not exist
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStore stores opaque binary objects by key
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// Presigner is implemented by stores that can hand out time-limited download
+// URLs themselves; other stores are served through the API's signed download route
+type Presigner interface {
+	PresignGet(key, fileName string, expires time.Duration) (string, error)
+}
@@ -0,0 +1,260 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// maxDecodeDepth bounds how deeply components may nest
+const maxDecodeDepth = 10
+
+// Decode parses an iCalendar object, unfolding lines, and returns its
+// top-level component. Both CRLF and bare LF line endings are accepted.
+func Decode(r io.Reader) (*Component, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var root *Component
+	var stack []*Component
+	for i, line := range lines {
+		if line == "" {
+			continue
+		}
+		prop, err := parseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		switch prop.Name {
+		case "BEGIN":
+			if root != nil && len(stack) == 0 {
+				return nil, fmt.Errorf("line %d: content after END:%s", i+1, root.Name)
+			}
+			if len(stack) == maxDecodeDepth {
+				return nil, fmt.Errorf("line %d: components nest too deeply", i+1)
+			}
+			c := NewComponent(strings.ToUpper(prop.Value))
+			if len(stack) == 0 {
+				root = c
+			} else {
+				stack[len(stack)-1].Add(c)
+			}
+			stack = append(stack, c)
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1].Name != strings.ToUpper(prop.Value) {
+				return nil, fmt.Errorf("line %d: unexpected END:%s", i+1, prop.Value)
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("line %d: property %s outside a component", i+1, prop.Name)
+			}
+			c := stack[len(stack)-1]
+			c.Properties = append(c.Properties, *prop)
+		}
+	}
+
+	if root == nil {
+		return nil, errors.New("no component")
+	}
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("missing END:%s", stack[len(stack)-1].Name)
+	}
+	return root, nil
+}
+
+// unfold joins continuation lines, which start with a space or tab
+func unfold(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if len(lines) > 0 && line != "" && (line[0] == ' ' || line[0] == '\t') {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// parseLine parses a content line: name *(";" param) ":" value
+func parseLine(line string) (*Property, error) {
+	end := strings.IndexAny(line, ";:")
+	if end <= 0 {
+		return nil, errors.New("invalid content line")
+	}
+	prop := &Property{Name: strings.ToUpper(line[:end])}
+
+	rest := line[end:]
+	for rest[0] == ';' {
+		rest = rest[1:]
+		eq := strings.IndexByte(rest, '=')
+		if eq <= 0 {
+			return nil, fmt.Errorf("invalid parameter of %s", prop.Name)
+		}
+		name := strings.ToUpper(rest[:eq])
+		rest = rest[eq+1:]
+
+		// Values are separated by commas; quoted ones may contain ; : and ,
+		var value strings.Builder
+		for {
+			if strings.HasPrefix(rest, `"`) {
+				closing := strings.IndexByte(rest[1:], '"')
+				if closing < 0 {
+					return nil, fmt.Errorf("unterminated quote in parameter %s", name)
+				}
+				value.WriteString(rest[1 : closing+1])
+				rest = rest[closing+2:]
+			} else {
+				stop := strings.IndexAny(rest, ";:,")
+				if stop < 0 {
+					return nil, fmt.Errorf("missing value of %s", prop.Name)
+				}
+				value.WriteString(rest[:stop])
+				rest = rest[stop:]
+			}
+			if rest == "" || rest[0] != ',' {
+				break
+			}
+			value.WriteByte(',')
+			rest = rest[1:]
+		}
+		prop.Params = append(prop.Params, Param{Name: name, Value: value.String()})
+		if rest == "" {
+			return nil, fmt.Errorf("missing value of %s", prop.Name)
+		}
+	}
+	if rest[0] != ':' {
+		return nil, fmt.Errorf("invalid content line %s", prop.Name)
+	}
+	prop.Value = rest[1:]
+	return prop, nil
+}
+
+// Get returns the first property called name, or nil
+func (c *Component) Get(name string) *Property {
+	for i := range c.Properties {
+		if c.Properties[i].Name == name {
+			return &c.Properties[i]
+		}
+	}
+	return nil
+}
+
+// GetAll returns the properties called name
+func (c *Component) GetAll(name string) []*Property {
+	var props []*Property
+	for i := range c.Properties {
+		if c.Properties[i].Name == name {
+			props = append(props, &c.Properties[i])
+		}
+	}
+	return props
+}
+
+// Children returns the subcomponents called name
+func (c *Component) Children(name string) []*Component {
+	var children []*Component
+	for _, child := range c.Components {
+		if child.Name == name {
+			children = append(children, child)
+		}
+	}
+	return children
+}
+
+// Param returns the value of a parameter, or "" without it
+func (p *Property) Param(name string) string {
+	for _, param := range p.Params {
+		if param.Name == name {
+			return param.Value
+		}
+	}
+	return ""
+}
+
+// Text returns the unescaped value of a TEXT property
+func (p *Property) Text() string {
+	return unescape(p.Value)
+}
+
+// TextList returns the unescaped values of a multi-valued TEXT property such
+// as CATEGORIES
+func (p *Property) TextList() []string {
+	var values []string
+	var current strings.Builder
+	escaped := false
+	for _, r := range p.Value {
+		switch {
+		case escaped:
+			current.WriteByte('\\')
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ',':
+			values = append(values, unescape(current.String()))
+			current.Reset()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	return append(values, unescape(current.String()))
+}
+
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	escaped := false
+	for _, r := range s {
+		if !escaped {
+			if r == '\\' {
+				escaped = true
+			} else {
+				b.WriteRune(r)
+			}
+			continue
+		}
+		escaped = false
+		switch r {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Time parses a DATE or DATE-TIME value. UTC values end in Z; others are in
+// the TZID parameter's zone or, when that is missing or unknown, in loc.
+// dateOnly reports a DATE value, returned at midnight in loc.
+func (p *Property) Time(loc *time.Location) (t time.Time, dateOnly bool, err error) {
+	if tzid := p.Param("TZID"); tzid != "" {
+		if l, err := time.LoadLocation(strings.TrimPrefix(tzid, "/")); err == nil {
+			loc = l
+		}
+	}
+
+	value := p.Value
+	switch {
+	case p.Param("VALUE") == "DATE" || len(value) == len("20060102"):
+		t, err = time.ParseInLocation("20060102", value, loc)
+		return t, true, err
+	case strings.HasSuffix(value, "Z"):
+		t, err = time.Parse("20060102T150405Z", value)
+		return t, false, err
+	}
+	t, err = time.ParseInLocation("20060102T150405", value, loc)
+	return t, false, err
+}
@@ -0,0 +1,135 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecode(t *testing.T) {
+	input := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"BEGIN:VTODO",
+		"UID:abc-123",
+		"SUMMARY:Pay rent\\; utilities\\, too",
+		"DESCRIPTION:A long description that was folded by the client at seventy-f",
+		" ive octets",
+		`X-NOTE;X-LABEL="a:b;c",plain:value:with:colons`,
+		"due;tzid=Europe/Berlin:20240305T090000",
+		"END:VTODO",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n")
+
+	cal, err := Decode(strings.NewReader(input))
+	require.NoError(t, err)
+
+	assert.Equal(t, "VCALENDAR", cal.Name)
+	require.Len(t, cal.Children("VTODO"), 1)
+	todo := cal.Children("VTODO")[0]
+	assert.Equal(t, "abc-123", todo.Get("UID").Value)
+	assert.Equal(t, "Pay rent; utilities, too", todo.Get("SUMMARY").Text())
+	assert.Equal(t, "A long description that was folded by the client at seventy-five octets", todo.Get("DESCRIPTION").Text())
+
+	note := todo.Get("X-NOTE")
+	assert.Equal(t, "a:b;c,plain", note.Param("X-LABEL"))
+	assert.Equal(t, "value:with:colons", note.Value)
+
+	due := todo.Get("DUE")
+	require.NotNil(t, due, "names are case-insensitive")
+	assert.Equal(t, "Europe/Berlin", due.Param("TZID"))
+	assert.Nil(t, todo.Get("LOCATION"))
+}
+
+func TestDecodeBareLineFeeds(t *testing.T) {
+	cal, err := Decode(strings.NewReader("BEGIN:VTODO\nSUMMARY:Folded\n  with a space\nEND:VTODO\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "Folded with a space", cal.Get("SUMMARY").Text())
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"empty", ""},
+		{"no component", "SUMMARY:x\r\n"},
+		{"missing end", "BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nEND:VTODO\r\n"},
+		{"mismatched end", "BEGIN:VCALENDAR\r\nEND:VTODO\r\n"},
+		{"no colon", "BEGIN:VTODO\r\nSUMMARY\r\nEND:VTODO\r\n"},
+		{"unterminated quote", "BEGIN:VTODO\r\nX;A=\"b:c\r\nEND:VTODO\r\n"},
+		{"two roots", "BEGIN:VTODO\r\nEND:VTODO\r\nBEGIN:VTODO\r\nEND:VTODO\r\n"},
+		{"too deep", strings.Repeat("BEGIN:X\r\n", maxDecodeDepth+1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode(strings.NewReader(tt.input))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestDecodeRoundTrip(t *testing.T) {
+	text := strings.Repeat("Ünïcödé, ; \\ and newlines\n", 10)
+	original := NewComponent("VCALENDAR").Add(
+		NewComponent("VTODO").
+			SetText("SUMMARY", text).
+			Set("CATEGORIES", TextList([]string{"home", "a,b"})),
+	)
+
+	var buf bytes.Buffer
+	require.NoError(t, original.Encode(&buf))
+	decoded, err := Decode(&buf)
+	require.NoError(t, err)
+
+	todo := decoded.Children("VTODO")[0]
+	assert.Equal(t, text, todo.Get("SUMMARY").Text())
+	assert.Equal(t, []string{"home", "a,b"}, todo.Get("CATEGORIES").TextList())
+}
+
+func TestPropertyTime(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("timezone data not available")
+	}
+	fallback := time.FixedZone("fallback", -5*60*60)
+
+	tests := []struct {
+		name     string
+		prop     Property
+		want     time.Time
+		dateOnly bool
+		wantErr  bool
+	}{
+		{name: "utc", prop: Property{Value: "20240305T090000Z"}, want: time.Date(2024, 3, 5, 9, 0, 0, 0, time.UTC)},
+		{name: "tzid", prop: Property{Params: []Param{{"TZID", "Europe/Berlin"}}, Value: "20240305T090000"}, want: time.Date(2024, 3, 5, 9, 0, 0, 0, berlin)},
+		{name: "unknown tzid", prop: Property{Params: []Param{{"TZID", "W. Europe Standard Time"}}, Value: "20240305T090000"}, want: time.Date(2024, 3, 5, 9, 0, 0, 0, fallback)},
+		{name: "floating", prop: Property{Value: "20240305T090000"}, want: time.Date(2024, 3, 5, 9, 0, 0, 0, fallback)},
+		{name: "date", prop: Property{Params: []Param{{"VALUE", "DATE"}}, Value: "20240305"}, want: time.Date(2024, 3, 5, 0, 0, 0, 0, fallback), dateOnly: true},
+		{name: "date without value type", prop: Property{Value: "20240305"}, want: time.Date(2024, 3, 5, 0, 0, 0, 0, fallback), dateOnly: true},
+		{name: "invalid", prop: Property{Value: "tomorrow"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, dateOnly, err := tt.prop.Time(fallback)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, tt.want.Equal(got), "got %s, want %s", got, tt.want)
+			assert.Equal(t, tt.dateOnly, dateOnly)
+		})
+	}
+}
+
+func TestPropertyTextList(t *testing.T) {
+	assert.Equal(t, []string{"a", "b,c", `d\e`}, (&Property{Value: `a,b\,c,d\\e`}).TextList())
+}
@@ -0,0 +1,159 @@
+// Package ical reads and writes iCalendar data (RFC 5545): components with
+// properties, text escaping, line folding and VTIMEZONE definitions.
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxLineOctets is the longest content line before folding, excluding CRLF
+const maxLineOctets = 75
+
+// Param is a property parameter such as TZID=Europe/Berlin
+type Param struct {
+	Name  string
+	Value string
+}
+
+// Property is a content line. Value is written as is; use Text for values
+// of type TEXT.
+type Property struct {
+	Name   string
+	Params []Param
+	Value  string
+}
+
+// Component is a calendar component such as VCALENDAR, VEVENT or VTODO
+type Component struct {
+	Name       string
+	Properties []Property
+	Components []*Component
+}
+
+func NewComponent(name string) *Component {
+	return &Component{Name: name}
+}
+
+// Set adds a property with a raw value
+func (c *Component) Set(name, value string, params ...Param) *Component {
+	c.Properties = append(c.Properties, Property{Name: name, Params: params, Value: value})
+	return c
+}
+
+// SetText adds a property of type TEXT, escaping the value
+func (c *Component) SetText(name, text string, params ...Param) *Component {
+	return c.Set(name, Text(text), params...)
+}
+
+// Add adds a subcomponent
+func (c *Component) Add(children ...*Component) *Component {
+	c.Components = append(c.Components, children...)
+	return c
+}
+
+// Encode writes the component with CRLF line endings, folding long lines
+func (c *Component) Encode(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	c.encode(bw)
+	return bw.Flush()
+}
+
+func (c *Component) encode(w *bufio.Writer) {
+	writeLine(w, "BEGIN:"+c.Name)
+	for _, p := range c.Properties {
+		writeLine(w, p.String())
+	}
+	for _, child := range c.Components {
+		child.encode(w)
+	}
+	writeLine(w, "END:"+c.Name)
+}
+
+// String returns the unfolded content line
+func (p Property) String() string {
+	var b strings.Builder
+	b.WriteString(p.Name)
+	for _, param := range p.Params {
+		b.WriteByte(';')
+		b.WriteString(param.Name)
+		b.WriteByte('=')
+		b.WriteString(paramValue(param.Value))
+	}
+	b.WriteByte(':')
+	b.WriteString(p.Value)
+	return b.String()
+}
+
+// paramValue quotes values containing separators; double quotes can't be
+// escaped and are dropped
+func paramValue(value string) string {
+	value = strings.ReplaceAll(value, `"`, "")
+	if strings.ContainsAny(value, ":;,") {
+		return `"` + value + `"`
+	}
+	return value
+}
+
+// writeLine writes a content line folded at 75 octets, never inside a UTF-8
+// sequence. Continuation lines start with a space.
+func writeLine(w *bufio.Writer, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		w.WriteString(line[:cut])
+		w.WriteString("\r\n ")
+		line = line[cut:]
+		limit = maxLineOctets - 1
+	}
+	w.WriteString(line)
+	w.WriteString("\r\n")
+}
+
+// Text escapes a TEXT value: backslashes, semicolons, commas and newlines.
+// Other control characters are not allowed and are dropped.
+func Text(s string) string {
+	var b strings.Builder
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	for _, r := range s {
+		switch {
+		case r == '\\', r == ';', r == ',':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n', r == '\r':
+			b.WriteString(`\n`)
+		case r == '\t':
+			b.WriteRune(r)
+		case r < 0x20 || r == 0x7f:
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// TextList escapes a list of TEXT values such as CATEGORIES
+func TextList(values []string) string {
+	escaped := make([]string, len(values))
+	for i, v := range values {
+		escaped[i] = Text(v)
+	}
+	return strings.Join(escaped, ",")
+}
+
+// DateTime formats t as a UTC DATE-TIME
+func DateTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// LocalDateTime formats t as a DATE-TIME in its own location, for use with a
+// TZID parameter
+func LocalDateTime(t time.Time) string {
+	return t.Format("20060102T150405")
+}
@@ -0,0 +1,149 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"plain", "Pay rent", "Pay rent"},
+		{"separators", "a;b,c", `a\;b\,c`},
+		{"backslash", `C:\temp`, `C:\\temp`},
+		{"newlines", "line 1\nline 2\r\nline 3", `line 1\nline 2\nline 3`},
+		{"colon is fine", "Note: call", "Note: call"},
+		{"control characters", "bell\a tab\t", "bell tab\t"},
+		{"unicode", "Café ☕", "Café ☕"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Text(tt.input))
+		})
+	}
+}
+
+func TestTextList(t *testing.T) {
+	assert.Equal(t, `home,a\,b`, TextList([]string{"home", "a,b"}))
+}
+
+func TestPropertyString(t *testing.T) {
+	tests := []struct {
+		name string
+		prop Property
+		want string
+	}{
+		{"no params", Property{Name: "SUMMARY", Value: "Pay rent"}, "SUMMARY:Pay rent"},
+		{"param", Property{Name: "DTSTART", Params: []Param{{"TZID", "Europe/Berlin"}}, Value: "20240305T090000"}, "DTSTART;TZID=Europe/Berlin:20240305T090000"},
+		{"quoted param", Property{Name: "X-TEST", Params: []Param{{"X-NAME", `a:b "c"`}}, Value: "v"}, `X-TEST;X-NAME="a:b c":v`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.prop.String())
+		})
+	}
+}
+
+func TestEncodeFolding(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"short", "Pay rent"},
+		{"exactly one line", strings.Repeat("a", maxLineOctets-len("SUMMARY:"))},
+		{"ascii", strings.Repeat("abcdefghij", 30)},
+		{"multi-byte", strings.Repeat("€ü☕", 60)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, NewComponent("VTODO").SetText("SUMMARY", tt.value).Encode(&buf))
+			out := buf.String()
+
+			require.True(t, strings.HasSuffix(out, "\r\n"))
+			lines := strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n")
+			for i, line := range lines {
+				assert.LessOrEqual(t, len(line), maxLineOctets, "line %d is too long", i)
+				assert.True(t, utf8.ValidString(line), "line %d splits a character", i)
+				assert.NotContains(t, line, "\n")
+			}
+
+			// Unfolding restores the content line
+			unfolded := strings.ReplaceAll(out, "\r\n ", "")
+			assert.Equal(t, "BEGIN:VTODO\r\nSUMMARY:"+tt.value+"\r\nEND:VTODO\r\n", unfolded)
+		})
+	}
+}
+
+func TestEncodeNested(t *testing.T) {
+	cal := NewComponent("VCALENDAR").Set("VERSION", "2.0").Add(
+		NewComponent("VEVENT").Set("UID", "1"),
+	)
+
+	var buf bytes.Buffer
+	require.NoError(t, cal.Encode(&buf))
+	assert.Equal(t, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VEVENT\r\nUID:1\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n", buf.String())
+}
+
+func TestDateTime(t *testing.T) {
+	loc := time.FixedZone("", 2*60*60)
+	at := time.Date(2024, 3, 5, 9, 30, 15, 0, loc)
+
+	assert.Equal(t, "20240305T073015Z", DateTime(at))
+	assert.Equal(t, "20240305T093015", LocalDateTime(at))
+}
+
+func TestTimezone(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("timezone data not available")
+	}
+
+	tz := Timezone(loc, time.Date(2024, 1, 1, 0, 0, 0, 0, loc), time.Date(2025, 1, 1, 0, 0, 0, 0, loc))
+
+	var buf bytes.Buffer
+	require.NoError(t, tz.Encode(&buf))
+	assert.Equal(t, strings.Join([]string{
+		"BEGIN:VTIMEZONE",
+		"TZID:Europe/Berlin",
+		"BEGIN:STANDARD",
+		"DTSTART:20240101T000000",
+		"TZOFFSETFROM:+0100",
+		"TZOFFSETTO:+0100",
+		"TZNAME:CET",
+		"END:STANDARD",
+		"BEGIN:DAYLIGHT",
+		"DTSTART:20240331T020000",
+		"TZOFFSETFROM:+0100",
+		"TZOFFSETTO:+0200",
+		"TZNAME:CEST",
+		"END:DAYLIGHT",
+		"BEGIN:STANDARD",
+		"DTSTART:20241027T030000",
+		"TZOFFSETFROM:+0200",
+		"TZOFFSETTO:+0100",
+		"TZNAME:CET",
+		"END:STANDARD",
+		"END:VTIMEZONE",
+		"",
+	}, "\r\n"), buf.String())
+}
+
+func TestUTCOffset(t *testing.T) {
+	assert.Equal(t, "+0000", utcOffset(0))
+	assert.Equal(t, "+0530", utcOffset(5*3600+30*60))
+	assert.Equal(t, "-0800", utcOffset(-8*3600))
+	assert.Equal(t, "-001915", utcOffset(-(19*60 + 15)))
+}
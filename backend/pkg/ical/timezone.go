@@ -0,0 +1,75 @@
+package ical
+
+import (
+	"fmt"
+	"time"
+)
+
+// Timezone returns a VTIMEZONE for loc listing its UTC offset at from and
+// every transition up to to. Past the last listed transition clients keep
+// the last offset, so to should cover the dates the calendar uses.
+func Timezone(loc *time.Location, from, to time.Time) *Component {
+	tz := NewComponent("VTIMEZONE").Set("TZID", loc.String())
+
+	t := from.In(loc)
+	name, offset := t.Zone()
+	tz.Add(observance(t, t.IsDST(), name, offset, offset))
+
+	for t.Before(to) {
+		next := t.Add(24 * time.Hour)
+		nextName, nextOffset := next.Zone()
+		if nextName != name || nextOffset != offset {
+			at := transition(t, next)
+			tz.Add(observance(at.In(time.FixedZone("", offset)), at.IsDST(), nextName, offset, nextOffset))
+			name, offset = nextName, nextOffset
+		}
+		t = next
+	}
+	return tz
+}
+
+// transition finds the first instant after before whose zone differs from
+// before's, to the second
+func transition(before, after time.Time) time.Time {
+	name, offset := before.Zone()
+	for after.Sub(before) > time.Second {
+		mid := before.Add(after.Sub(before) / 2)
+		if midName, midOffset := mid.Zone(); midName == name && midOffset == offset {
+			before = mid
+		} else {
+			after = mid
+		}
+	}
+	return after.Truncate(time.Second)
+}
+
+// observance is a STANDARD or DAYLIGHT subcomponent starting at the local
+// time start, given in the offset in effect before it
+func observance(start time.Time, dst bool, name string, from, to int) *Component {
+	kind := "STANDARD"
+	if dst {
+		kind = "DAYLIGHT"
+	}
+	c := NewComponent(kind).
+		Set("DTSTART", LocalDateTime(start)).
+		Set("TZOFFSETFROM", utcOffset(from)).
+		Set("TZOFFSETTO", utcOffset(to))
+	if name != "" {
+		c.SetText("TZNAME", name)
+	}
+	return c
+}
+
+// utcOffset formats seconds east of UTC as +HHMM, or +HHMMSS when needed
+func utcOffset(seconds int) string {
+	sign := '+'
+	if seconds < 0 {
+		sign = '-'
+		seconds = -seconds
+	}
+	hours, minutes, secs := seconds/3600, seconds/60%60, seconds%60
+	if secs != 0 {
+		return fmt.Sprintf("%c%02d%02d%02d", sign, hours, minutes, secs)
+	}
+	return fmt.Sprintf("%c%02d%02d", sign, hours, minutes)
+}
@@ -0,0 +1,674 @@
+// Package quickadd parses the free text of a quick-add entry into the fields
+// of a todo, e.g.
+//
+//	Pay rent tomorrow 9am !3 #home @Personal
+//
+// is the todo "Pay rent" due tomorrow at 09:00 with priority 3, tagged home,
+// in the list Personal. The parser recognizes
+//
+//   - dates: today, tonight, tomorrow (tmr, tmrw), weekdays (fri, friday, this
+//     friday, next friday), weekend, next week, next month, next year,
+//     "in 3 days", "in 2 weeks", "in 2 hours", 2024-03-05, "Mar 5",
+//     "March 5th, 2025" and "5 March"
+//   - times: 9am, 9:30pm, 9 pm, 21:00, noon and "at 9"
+//   - priorities: !0 to !5
+//   - tags: #home; digits alone, as in "issue #12", are not a tag
+//   - lists: @Personal, or @Side projects for a list named "Side projects"
+//
+// A date or time may be preceded by on, at, by or due, which then belong to
+// it. When a date, time, priority or list appears twice the last one wins and
+// the earlier one stays in the title; every tag is taken. Anything else,
+// including @names that are not lists, stays in the title.
+package quickadd
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Kind is the kind of a recognized token
+type Kind string
+
+const (
+	KindDate     Kind = "date"
+	KindTime     Kind = "time"
+	KindPriority Kind = "priority"
+	KindTag      Kind = "tag"
+	KindList     Kind = "list"
+)
+
+// Default times of day of a date given without a time
+const (
+	DefaultHour = 9
+	tonightHour = 20
+)
+
+// MaxTagLength is the longest tag recognized
+const MaxTagLength = 50
+
+// Token is a recognized part of the text. Start and End are 0-based character
+// offsets, End exclusive. Value is the normalized value: a date as
+// YYYY-MM-DD, a time as HH:MM, a priority, a tag without the # or the list's
+// name as given to Parse.
+type Token struct {
+	Kind  Kind   `json:"kind"`
+	Text  string `json:"text"`
+	Value string `json:"value"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
+
+// Result is a parsed entry
+type Result struct {
+	Title    string
+	Due      *time.Time
+	Priority *int
+	Tags     []string
+	List     string
+	Tokens   []Token // in text order
+}
+
+// Parse parses text. Dates are relative to now and in its location; a date
+// without a time is due at DefaultHour, and a time without a date is today or,
+// once past, tomorrow. lists are the names @mentions are matched against,
+// ignoring case.
+func Parse(text string, now time.Time, lists []string) *Result {
+	p := &parser{words: split(text), now: now, lists: lists}
+	for _, list := range lists {
+		if n := len(strings.Fields(list)); n > p.maxListWords {
+			p.maxListWords = n
+		}
+	}
+	return p.parse()
+}
+
+type word struct {
+	text       string // as typed
+	norm       string // lower case, without trailing punctuation
+	start, end int
+}
+
+// split splits text at whitespace, keeping character offsets
+func split(text string) []word {
+	var words []word
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+		start := i
+		for i < len(runes) && !unicode.IsSpace(runes[i]) {
+			i++
+		}
+		w := string(runes[start:i])
+		words = append(words, word{
+			text:  w,
+			norm:  strings.ToLower(strings.TrimRight(w, ",.;:!?")),
+			start: start,
+			end:   i,
+		})
+	}
+	return words
+}
+
+type clock struct {
+	hour, minute int
+}
+
+func (c clock) String() string {
+	return time.Date(0, 1, 1, c.hour, c.minute, 0, 0, time.UTC).Format("15:04")
+}
+
+// match is a recognized run of n words
+type match struct {
+	kind     Kind
+	n        int
+	value    string
+	day      time.Time // dates, at the start of the day
+	clock    *clock    // times, and dates that imply one such as tonight
+	priority int
+}
+
+type parser struct {
+	words        []word
+	now          time.Time
+	lists        []string
+	maxListWords int
+}
+
+func (p *parser) parse() *Result {
+	result := &Result{}
+	used := make([]bool, len(p.words))
+	var tokens []Token
+	last := make(map[Kind]int) // index into tokens
+	matches := make([]*match, 0)
+
+	for i := 0; i < len(p.words); {
+		m := p.matchAt(i)
+		if m == nil {
+			i++
+			continue
+		}
+		token := Token{
+			Kind:  m.kind,
+			Text:  p.text(i, m.n),
+			Value: m.value,
+			Start: p.words[i].start,
+			End:   p.words[i+m.n-1].end,
+		}
+		if m.kind == KindTag {
+			result.Tags = append(result.Tags, m.value)
+		} else if prev, ok := last[m.kind]; ok {
+			// The earlier one goes back to the title
+			tokens[prev].Kind = ""
+		}
+		last[m.kind] = len(tokens)
+		tokens = append(tokens, token)
+		matches = append(matches, m)
+		i += m.n
+	}
+
+	var date, at *match
+	for i, token := range tokens {
+		if token.Kind == "" {
+			continue
+		}
+		for j := range p.words {
+			if p.words[j].start >= token.Start && p.words[j].end <= token.End {
+				used[j] = true
+			}
+		}
+		result.Tokens = append(result.Tokens, token)
+
+		m := matches[i]
+		switch m.kind {
+		case KindDate:
+			date = m
+		case KindTime:
+			at = m
+		case KindPriority:
+			priority := m.priority
+			result.Priority = &priority
+		case KindList:
+			result.List = m.value
+		}
+	}
+	result.Due = p.due(date, at)
+
+	title := make([]string, 0, len(p.words))
+	for i, w := range p.words {
+		if !used[i] {
+			title = append(title, w.text)
+		}
+	}
+	result.Title = strings.Join(title, " ")
+	return result
+}
+
+// due combines a date and a time into a due date
+func (p *parser) due(date, at *match) *time.Time {
+	if date == nil && at == nil {
+		return nil
+	}
+
+	var day time.Time
+	c := clock{hour: DefaultHour}
+	if date != nil {
+		day = date.day
+		if date.clock != nil {
+			c = *date.clock
+		}
+	}
+	if at != nil {
+		c = *at.clock
+	}
+	if date == nil {
+		day = p.today()
+		if !p.at(day, c).After(p.now) {
+			day = day.AddDate(0, 0, 1)
+		}
+	}
+
+	due := p.at(day, c)
+	return &due
+}
+
+func (p *parser) at(day time.Time, c clock) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), c.hour, c.minute, 0, 0, p.now.Location())
+}
+
+func (p *parser) today() time.Time {
+	return time.Date(p.now.Year(), p.now.Month(), p.now.Day(), 0, 0, 0, 0, p.now.Location())
+}
+
+func (p *parser) text(i, n int) string {
+	parts := make([]string, n)
+	for j := range parts {
+		parts[j] = p.words[i+j].text
+	}
+	return strings.Join(parts, " ")
+}
+
+func (p *parser) norm(i int) string {
+	if i < len(p.words) {
+		return p.words[i].norm
+	}
+	return ""
+}
+
+func (p *parser) matchAt(i int) *match {
+	w := p.words[i].text
+	switch {
+	case strings.HasPrefix(w, "#"):
+		return matchTag(p.words[i].norm[1:])
+	case strings.HasPrefix(w, "!"):
+		return matchPriority(p.words[i].norm)
+	case strings.HasPrefix(w, "@"):
+		return p.matchList(i)
+	}
+	return p.matchWhen(i)
+}
+
+func matchTag(name string) *match {
+	if name == "" || len([]rune(name)) > MaxTagLength {
+		return nil
+	}
+	digits := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("-_/", r) {
+			return nil
+		}
+		if !unicode.IsDigit(r) {
+			digits = false
+		}
+	}
+	if digits {
+		return nil
+	}
+	return &match{kind: KindTag, n: 1, value: name}
+}
+
+func matchPriority(norm string) *match {
+	if len(norm) != 2 || norm[1] < '0' || norm[1] > '5' {
+		return nil
+	}
+	priority := int(norm[1] - '0')
+	return &match{kind: KindPriority, n: 1, value: norm[1:], priority: priority}
+}
+
+// matchList matches the longest run of words naming a list
+func (p *parser) matchList(i int) *match {
+	for n := min(p.maxListWords, len(p.words)-i); n >= 1; n-- {
+		parts := make([]string, n)
+		for j := range parts {
+			parts[j] = p.words[i+j].text
+		}
+		parts[0] = parts[0][1:]
+		parts[n-1] = strings.TrimRight(parts[n-1], ",.;:!?")
+		name := strings.Join(parts, " ")
+		for _, list := range p.lists {
+			if strings.EqualFold(name, strings.Join(strings.Fields(list), " ")) {
+				return &match{kind: KindList, n: n, value: list}
+			}
+		}
+	}
+	return nil
+}
+
+// matchWhen matches a date or time, with the words that may precede one
+func (p *parser) matchWhen(i int) *match {
+	switch p.norm(i) {
+	case "on", "by", "due", "at":
+		if i+1 >= len(p.words) {
+			return nil
+		}
+		if p.norm(i) == "at" {
+			if hour, ok := number(p.norm(i+1), 0, 23); ok {
+				return &match{kind: KindTime, n: 2, clock: &clock{hour: hour}, value: clock{hour: hour}.String()}
+			}
+		}
+		if m := p.matchWhen(i + 1); m != nil {
+			m.n++
+			return m
+		}
+		return nil
+	}
+	if m := p.matchDate(i); m != nil {
+		m.kind = KindDate
+		m.value = m.day.Format("2006-01-02")
+		return m
+	}
+	if m := p.matchTime(i); m != nil {
+		m.kind = KindTime
+		m.value = m.clock.String()
+		return m
+	}
+	return nil
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tues": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+var months = map[string]time.Month{
+	"jan": time.January, "january": time.January,
+	"feb": time.February, "february": time.February,
+	"mar": time.March, "march": time.March,
+	"apr": time.April, "april": time.April,
+	"may": time.May,
+	"jun": time.June, "june": time.June,
+	"jul": time.July, "july": time.July,
+	"aug": time.August, "august": time.August,
+	"sep": time.September, "sept": time.September, "september": time.September,
+	"oct": time.October, "october": time.October,
+	"nov": time.November, "november": time.November,
+	"dec": time.December, "december": time.December,
+}
+
+func (p *parser) matchDate(i int) *match {
+	today := p.today()
+	word := p.norm(i)
+
+	switch word {
+	case "today":
+		return &match{n: 1, day: today}
+	case "tonight":
+		return &match{n: 1, day: today, clock: &clock{hour: tonightHour}}
+	case "tomorrow", "tmr", "tmrw":
+		return &match{n: 1, day: today.AddDate(0, 0, 1)}
+	case "weekend":
+		return &match{n: 1, day: p.weekend()}
+	case "this":
+		if weekday, ok := weekdays[p.norm(i+1)]; ok {
+			return &match{n: 2, day: p.coming(weekday)}
+		}
+		if p.norm(i+1) == "weekend" {
+			return &match{n: 2, day: p.weekend()}
+		}
+		return nil
+	case "next":
+		return p.matchNext(i)
+	case "in":
+		return p.matchIn(i)
+	}
+
+	if weekday, ok := weekdays[word]; ok {
+		return &match{n: 1, day: p.coming(weekday)}
+	}
+	if day, err := time.ParseInLocation("2006-01-02", word, p.now.Location()); err == nil {
+		return &match{n: 1, day: day}
+	}
+	return p.matchMonthDay(i)
+}
+
+// coming returns the next given weekday after today
+func (p *parser) coming(weekday time.Weekday) time.Time {
+	today := p.today()
+	days := (int(weekday) - int(today.Weekday()) + 7) % 7
+	if days == 0 {
+		days = 7
+	}
+	return today.AddDate(0, 0, days)
+}
+
+// weekend returns the coming Saturday, or today during a weekend
+func (p *parser) weekend() time.Time {
+	today := p.today()
+	switch today.Weekday() {
+	case time.Saturday, time.Sunday:
+		return today
+	}
+	return p.coming(time.Saturday)
+}
+
+// nextWeek returns the Monday of next week
+func (p *parser) nextWeek() time.Time {
+	today := p.today()
+	return today.AddDate(0, 0, 7-(int(today.Weekday())+6)%7)
+}
+
+func (p *parser) matchNext(i int) *match {
+	next := p.norm(i + 1)
+	if weekday, ok := weekdays[next]; ok {
+		// The weekday of next week, Monday to Sunday
+		return &match{n: 2, day: p.nextWeek().AddDate(0, 0, (int(weekday)+6)%7)}
+	}
+	today := p.today()
+	switch next {
+	case "week":
+		return &match{n: 2, day: p.nextWeek()}
+	case "weekend":
+		return &match{n: 2, day: p.nextWeek().AddDate(0, 0, 5)}
+	case "month":
+		return &match{n: 2, day: time.Date(today.Year(), today.Month()+1, 1, 0, 0, 0, 0, today.Location())}
+	case "year":
+		return &match{n: 2, day: time.Date(today.Year()+1, time.January, 1, 0, 0, 0, 0, today.Location())}
+	}
+	return nil
+}
+
+type unit int
+
+const (
+	minutes unit = iota
+	hours
+	days
+	weeks
+	monthsUnit
+	years
+)
+
+var units = map[string]unit{
+	"m": minutes, "min": minutes, "mins": minutes, "minute": minutes, "minutes": minutes,
+	"h": hours, "hr": hours, "hrs": hours, "hour": hours, "hours": hours,
+	"d": days, "day": days, "days": days,
+	"w": weeks, "wk": weeks, "wks": weeks, "week": weeks, "weeks": weeks,
+	"mo": monthsUnit, "month": monthsUnit, "months": monthsUnit,
+	"y": years, "yr": years, "yrs": years, "year": years, "years": years,
+}
+
+// matchIn matches "in 3 days", "in 3d" and "in a week"
+func (p *parser) matchIn(i int) *match {
+	amount, n := 0, 0
+	var u unit
+	next := p.norm(i + 1)
+
+	if next == "a" || next == "an" {
+		amount, n = 1, 3
+		var ok bool
+		if u, ok = units[p.norm(i+2)]; !ok {
+			return nil
+		}
+	} else if value, ok := number(next, 1, 999); ok {
+		amount, n = value, 3
+		if u, ok = units[p.norm(i+2)]; !ok {
+			return nil
+		}
+	} else {
+		digits := strings.IndexFunc(next, func(r rune) bool { return r < '0' || r > '9' })
+		if digits <= 0 {
+			return nil
+		}
+		value, ok := number(next[:digits], 1, 999)
+		if !ok {
+			return nil
+		}
+		if u, ok = units[next[digits:]]; !ok {
+			return nil
+		}
+		amount, n = value, 2
+	}
+
+	today := p.today()
+	switch u {
+	case minutes, hours:
+		d := time.Duration(amount) * time.Minute
+		if u == hours {
+			d = time.Duration(amount) * time.Hour
+		}
+		t := p.now.Add(d).Truncate(time.Minute)
+		return &match{n: n, day: time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location()), clock: &clock{hour: t.Hour(), minute: t.Minute()}}
+	case days:
+		return &match{n: n, day: today.AddDate(0, 0, amount)}
+	case weeks:
+		return &match{n: n, day: today.AddDate(0, 0, 7*amount)}
+	case monthsUnit:
+		return &match{n: n, day: addMonths(today, amount)}
+	default:
+		return &match{n: n, day: addMonths(today, 12*amount)}
+	}
+}
+
+// addMonths adds months, ending on the last day of a shorter month rather
+// than overflowing into the next
+func addMonths(day time.Time, months int) time.Time {
+	first := time.Date(day.Year(), day.Month()+time.Month(months), 1, 0, 0, 0, 0, day.Location())
+	last := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(day.Day(), last)-1)
+}
+
+// matchMonthDay matches "Mar 5", "March 5th, 2025", "5 March" and "5th of
+// March 2025". Without a year the date is the next one from today.
+func (p *parser) matchMonthDay(i int) *match {
+	var month time.Month
+	var day, n int
+	if m, ok := months[p.norm(i)]; ok {
+		d, ok := dayOfMonth(p.norm(i + 1))
+		if !ok {
+			return nil
+		}
+		month, day, n = m, d, 2
+	} else if d, ok := dayOfMonth(p.norm(i)); ok {
+		n = 1
+		if p.norm(i+1) == "of" {
+			n++
+		}
+		if month, ok = months[p.norm(i+n)]; !ok {
+			return nil
+		}
+		day = d
+		n++
+	} else {
+		return nil
+	}
+
+	today := p.today()
+	year, explicitYear := number(p.norm(i+n), 1970, 9999)
+	if explicitYear {
+		n++
+	} else {
+		year = today.Year()
+	}
+
+	date := time.Date(year, month, day, 0, 0, 0, 0, today.Location())
+	if date.Day() != day && (explicitYear || month != time.February || day != 29) {
+		return nil
+	}
+	// Without a year, the next such day; February 29 is the next leap day
+	for !explicitYear && (date.Before(today) || date.Day() != day) {
+		year++
+		date = time.Date(year, month, day, 0, 0, 0, 0, today.Location())
+	}
+	return &match{n: n, day: date}
+}
+
+// dayOfMonth parses 5, 5th, 1st, 2nd and 3rd
+func dayOfMonth(s string) (int, bool) {
+	for _, suffix := range []string{"st", "nd", "rd", "th"} {
+		if strings.HasSuffix(s, suffix) {
+			s = strings.TrimSuffix(s, suffix)
+			break
+		}
+	}
+	return number(s, 1, 31)
+}
+
+func (p *parser) matchTime(i int) *match {
+	word := p.norm(i)
+	if word == "noon" {
+		return &match{n: 1, clock: &clock{hour: 12}}
+	}
+	if c, ok := parseClock(word); ok {
+		return &match{n: 1, clock: c}
+	}
+	// 9 am, 9:30 pm
+	if next := p.norm(i + 1); next == "am" || next == "pm" {
+		if c, ok := parseClock(word + next); ok {
+			return &match{n: 2, clock: c}
+		}
+	}
+	return nil
+}
+
+// parseClock parses 9am, 9:30pm, 9.30pm and 21:00. Without am or pm the
+// minutes are required, so that plain numbers stay in the title.
+func parseClock(s string) (*clock, bool) {
+	meridiem := ""
+	for _, suffix := range []string{"am", "pm"} {
+		if strings.HasSuffix(s, suffix) {
+			meridiem = suffix
+			s = strings.TrimSuffix(s, suffix)
+			break
+		}
+	}
+
+	hourPart, minutePart, hasMinutes := strings.Cut(s, ":")
+	if !hasMinutes && meridiem != "" {
+		hourPart, minutePart, hasMinutes = strings.Cut(s, ".")
+	}
+	if !hasMinutes && meridiem == "" {
+		return nil, false
+	}
+
+	minute := 0
+	if hasMinutes {
+		var ok bool
+		if len(minutePart) != 2 {
+			return nil, false
+		}
+		if minute, ok = number(minutePart, 0, 59); !ok {
+			return nil, false
+		}
+	}
+	if len(hourPart) > 2 {
+		return nil, false
+	}
+
+	if meridiem == "" {
+		hour, ok := number(hourPart, 0, 23)
+		if !ok {
+			return nil, false
+		}
+		return &clock{hour: hour, minute: minute}, true
+	}
+	hour, ok := number(hourPart, 1, 12)
+	if !ok {
+		return nil, false
+	}
+	hour %= 12
+	if meridiem == "pm" {
+		hour += 12
+	}
+	return &clock{hour: hour, minute: minute}, true
+}
+
+// number parses a plain decimal number within [lo, hi]
+func number(s string, lo, hi int) (int, bool) {
+	if s == "" || strings.IndexFunc(s, func(r rune) bool { return r < '0' || r > '9' }) >= 0 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < lo || n > hi {
+		return 0, false
+	}
+	return n, true
+}
@@ -0,0 +1,254 @@
+package quickadd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	loc := time.FixedZone("CET", 60*60)
+	// A Wednesday afternoon
+	now := time.Date(2024, 3, 6, 14, 30, 0, 0, loc)
+	lists := []string{"Personal", "Side projects", "Work"}
+
+	tests := []struct {
+		name     string
+		text     string
+		title    string
+		due      string // YYYY-MM-DD HH:MM in loc, empty for none
+		priority int    // -1 for none
+		tags     []string
+		list     string
+	}{
+		{name: "everything", text: "Pay rent tomorrow 9am !3 #home @Personal", title: "Pay rent", due: "2024-03-07 09:00", priority: 3, tags: []string{"home"}, list: "Personal"},
+		{name: "plain text", text: "Call mom", title: "Call mom", priority: -1},
+		{name: "extra spaces", text: "  Call   mom  ", title: "Call mom", priority: -1},
+		{name: "empty", text: "", title: "", priority: -1},
+
+		// Relative dates
+		{name: "today", text: "Water plants today", title: "Water plants", due: "2024-03-06 09:00", priority: -1},
+		{name: "tonight", text: "Take out trash tonight", title: "Take out trash", due: "2024-03-06 20:00", priority: -1},
+		{name: "tonight with a time", text: "Movie tonight 10pm", title: "Movie", due: "2024-03-06 22:00", priority: -1},
+		{name: "tomorrow", text: "Pay rent tomorrow", title: "Pay rent", due: "2024-03-07 09:00", priority: -1},
+		{name: "tmrw", text: "Pay rent tmrw", title: "Pay rent", due: "2024-03-07 09:00", priority: -1},
+		{name: "case", text: "Pay rent TOMORROW", title: "Pay rent", due: "2024-03-07 09:00", priority: -1},
+		{name: "trailing punctuation", text: "Submit taxes tomorrow!", title: "Submit taxes", due: "2024-03-07 09:00", priority: -1},
+		{name: "weekday", text: "Standup friday", title: "Standup", due: "2024-03-08 09:00", priority: -1},
+		{name: "short weekday", text: "Standup fri", title: "Standup", due: "2024-03-08 09:00", priority: -1},
+		{name: "today's weekday is next week", text: "Standup wednesday", title: "Standup", due: "2024-03-13 09:00", priority: -1},
+		{name: "this weekday", text: "Standup this friday", title: "Standup", due: "2024-03-08 09:00", priority: -1},
+		{name: "next weekday", text: "Standup next friday", title: "Standup", due: "2024-03-15 09:00", priority: -1},
+		{name: "next monday", text: "Standup next monday", title: "Standup", due: "2024-03-11 09:00", priority: -1},
+		{name: "next sunday", text: "Brunch next sunday", title: "Brunch", due: "2024-03-17 09:00", priority: -1},
+		{name: "weekend", text: "Hike weekend", title: "Hike", due: "2024-03-09 09:00", priority: -1},
+		{name: "this weekend", text: "Hike this weekend", title: "Hike", due: "2024-03-09 09:00", priority: -1},
+		{name: "next weekend", text: "Hike next weekend", title: "Hike", due: "2024-03-16 09:00", priority: -1},
+		{name: "next week", text: "Plan sprint next week", title: "Plan sprint", due: "2024-03-11 09:00", priority: -1},
+		{name: "next month", text: "Invoice next month", title: "Invoice", due: "2024-04-01 09:00", priority: -1},
+		{name: "next year", text: "Renew passport next year", title: "Renew passport", due: "2025-01-01 09:00", priority: -1},
+		{name: "next without a date", text: "Read the next chapter", title: "Read the next chapter", priority: -1},
+
+		// In ...
+		{name: "in days", text: "Follow up in 3 days", title: "Follow up", due: "2024-03-09 09:00", priority: -1},
+		{name: "in compact days", text: "Follow up in 3d", title: "Follow up", due: "2024-03-09 09:00", priority: -1},
+		{name: "in a week", text: "Follow up in a week", title: "Follow up", due: "2024-03-13 09:00", priority: -1},
+		{name: "in weeks", text: "Follow up in 2 weeks", title: "Follow up", due: "2024-03-20 09:00", priority: -1},
+		{name: "in hours", text: "Check oven in 2 hours", title: "Check oven", due: "2024-03-06 16:30", priority: -1},
+		{name: "in an hour", text: "Check oven in an hour", title: "Check oven", due: "2024-03-06 15:30", priority: -1},
+		{name: "in minutes", text: "Check oven in 45 min", title: "Check oven", due: "2024-03-06 15:15", priority: -1},
+		{name: "in hours at a time", text: "Check oven in 2 hours at 17:00", title: "Check oven", due: "2024-03-06 17:00", priority: -1},
+		{name: "in a month", text: "Dentist in a month", title: "Dentist", due: "2024-04-06 09:00", priority: -1},
+		{name: "in years", text: "Dentist in 2 years", title: "Dentist", due: "2026-03-06 09:00", priority: -1},
+		{name: "in a place", text: "Meet in Berlin", title: "Meet in Berlin", priority: -1},
+		{name: "in a number of things", text: "Cut in 3 pieces", title: "Cut in 3 pieces", priority: -1},
+
+		// Absolute dates
+		{name: "iso date", text: "Launch 2024-04-01", title: "Launch", due: "2024-04-01 09:00", priority: -1},
+		{name: "month day", text: "Launch Mar 10", title: "Launch", due: "2024-03-10 09:00", priority: -1},
+		{name: "month day passed", text: "Launch Mar 1", title: "Launch", due: "2025-03-01 09:00", priority: -1},
+		{name: "month ordinal year", text: "Launch March 5th, 2025", title: "Launch", due: "2025-03-05 09:00", priority: -1},
+		{name: "day month", text: "Launch 5 March", title: "Launch", due: "2025-03-05 09:00", priority: -1},
+		{name: "day of month", text: "Launch 10th of April", title: "Launch", due: "2024-04-10 09:00", priority: -1},
+		{name: "day month year", text: "Launch 1 jan 2026", title: "Launch", due: "2026-01-01 09:00", priority: -1},
+		{name: "leap day", text: "Party Feb 29", title: "Party", due: "2028-02-29 09:00", priority: -1},
+		{name: "no such day", text: "Party Feb 30", title: "Party Feb 30", priority: -1},
+		{name: "no such leap day", text: "Party Feb 29 2025", title: "Party Feb 29 2025", priority: -1},
+		{name: "month without a day", text: "Plan May trip", title: "Plan May trip", priority: -1},
+		{name: "date and time", text: "Launch 2024-04-01 17:45", title: "Launch", due: "2024-04-01 17:45", priority: -1},
+
+		// Times
+		{name: "time later today", text: "Call Sam 5pm", title: "Call Sam", due: "2024-03-06 17:00", priority: -1},
+		{name: "time passed today", text: "Call Sam 9am", title: "Call Sam", due: "2024-03-07 09:00", priority: -1},
+		{name: "time with minutes", text: "Call Sam 9:30pm", title: "Call Sam", due: "2024-03-06 21:30", priority: -1},
+		{name: "time with a dot", text: "Call Sam 9.30pm", title: "Call Sam", due: "2024-03-06 21:30", priority: -1},
+		{name: "separate meridiem", text: "Call Sam 9 pm", title: "Call Sam", due: "2024-03-06 21:00", priority: -1},
+		{name: "24 hour", text: "Call Sam 21:00", title: "Call Sam", due: "2024-03-06 21:00", priority: -1},
+		{name: "midnight", text: "Deploy 12am", title: "Deploy", due: "2024-03-07 00:00", priority: -1},
+		{name: "noon", text: "Lunch noon", title: "Lunch", due: "2024-03-07 12:00", priority: -1},
+		{name: "at hour", text: "Gym at 16", title: "Gym", due: "2024-03-06 16:00", priority: -1},
+		{name: "at time", text: "Gym at 6pm", title: "Gym", due: "2024-03-06 18:00", priority: -1},
+		{name: "bare number", text: "Buy 5 apples", title: "Buy 5 apples", priority: -1},
+		{name: "not a time", text: "1:1 with Sam", title: "1:1 with Sam", priority: -1},
+		{name: "bad hour", text: "Call 13pm", title: "Call 13pm", priority: -1},
+
+		// Connecting words
+		{name: "on and at", text: "Dentist on friday at 3pm", title: "Dentist", due: "2024-03-08 15:00", priority: -1},
+		{name: "due by", text: "Report due by friday", title: "Report", due: "2024-03-08 09:00", priority: -1},
+		{name: "at a place", text: "Work at home", title: "Work at home", priority: -1},
+		{name: "on its own", text: "Turn the heating on", title: "Turn the heating on", priority: -1},
+
+		// Last one wins
+		{name: "two dates", text: "Move meeting from friday to monday", title: "Move meeting from friday to", due: "2024-03-11 09:00", priority: -1},
+		{name: "two priorities", text: "Fix bug !1 !4", title: "Fix bug !1", priority: 4},
+
+		// Priorities
+		{name: "priority zero", text: "Fix bug !0", title: "Fix bug", priority: 0},
+		{name: "priority out of range", text: "Fix bug !6", title: "Fix bug !6", priority: -1},
+		{name: "exclamation", text: "Hurry !!", title: "Hurry !!", priority: -1},
+
+		// Tags
+		{name: "tags", text: "Buy milk #errands #Home", title: "Buy milk", priority: -1, tags: []string{"errands", "home"}},
+		{name: "tag with punctuation", text: "Buy milk #errands, now", title: "Buy milk now", priority: -1, tags: []string{"errands"}},
+		{name: "tag with dash", text: "Buy milk #to-buy", title: "Buy milk", priority: -1, tags: []string{"to-buy"}},
+		{name: "issue number", text: "Close issue #12", title: "Close issue #12", priority: -1},
+		{name: "lone hash", text: "Press # key", title: "Press # key", priority: -1},
+		{name: "unicode tag", text: "Kaffee #küche", title: "Kaffee", priority: -1, tags: []string{"küche"}},
+
+		// Lists
+		{name: "list", text: "Fix bug @work", title: "Fix bug", priority: -1, list: "Work"},
+		{name: "multi-word list", text: "Plan @side projects later", title: "Plan later", priority: -1, list: "Side projects"},
+		{name: "list prefix", text: "Plan @side quests", title: "Plan @side quests", priority: -1},
+		{name: "unknown list", text: "Email @bob", title: "Email @bob", priority: -1},
+		{name: "list with punctuation", text: "Taxes @personal, soon", title: "Taxes soon", priority: -1, list: "Personal"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Parse(tt.text, now, lists)
+
+			assert.Equal(t, tt.title, got.Title)
+			if tt.due == "" {
+				assert.Nil(t, got.Due)
+			} else if assert.NotNil(t, got.Due) {
+				assert.Equal(t, tt.due, got.Due.In(loc).Format("2006-01-02 15:04"))
+			}
+			if tt.priority < 0 {
+				assert.Nil(t, got.Priority)
+			} else if assert.NotNil(t, got.Priority) {
+				assert.Equal(t, tt.priority, *got.Priority)
+			}
+			assert.Equal(t, tt.tags, got.Tags)
+			assert.Equal(t, tt.list, got.List)
+		})
+	}
+}
+
+func TestParseTokens(t *testing.T) {
+	now := time.Date(2024, 3, 6, 14, 30, 0, 0, time.UTC)
+
+	got := Parse("Pay rent tomorrow at 9am !3 #home @Personal", now, []string{"Personal"})
+
+	assert.Equal(t, []Token{
+		{Kind: KindDate, Text: "tomorrow", Value: "2024-03-07", Start: 9, End: 17},
+		{Kind: KindTime, Text: "at 9am", Value: "09:00", Start: 18, End: 24},
+		{Kind: KindPriority, Text: "!3", Value: "3", Start: 25, End: 27},
+		{Kind: KindTag, Text: "#home", Value: "home", Start: 28, End: 33},
+		{Kind: KindList, Text: "@Personal", Value: "Personal", Start: 34, End: 43},
+	}, got.Tokens)
+}
+
+func TestParseTokenOffsetsAreCharacters(t *testing.T) {
+	now := time.Date(2024, 3, 6, 14, 30, 0, 0, time.UTC)
+
+	got := Parse("Café über tomorrow", now, nil)
+
+	require.Len(t, got.Tokens, 1)
+	assert.Equal(t, 10, got.Tokens[0].Start)
+	assert.Equal(t, 18, got.Tokens[0].End)
+	assert.Equal(t, "Café über", got.Title)
+}
+
+func TestParseSkipsReplacedTokens(t *testing.T) {
+	now := time.Date(2024, 3, 6, 14, 30, 0, 0, time.UTC)
+
+	got := Parse("Move from friday to monday", now, nil)
+
+	require.Len(t, got.Tokens, 1)
+	assert.Equal(t, "monday", got.Tokens[0].Text)
+}
+
+func TestParseAcrossDST(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("timezone data not available")
+	}
+	// Clocks go forward on 2024-03-31
+	now := time.Date(2024, 3, 30, 12, 0, 0, 0, loc)
+
+	got := Parse("Brunch tomorrow 10am", now, nil)
+
+	require.NotNil(t, got.Due)
+	assert.True(t, time.Date(2024, 3, 31, 10, 0, 0, 0, loc).Equal(*got.Due))
+	_, offset := got.Due.Zone()
+	assert.Equal(t, 2*60*60, offset)
+}
+
+func TestAddMonths(t *testing.T) {
+	tests := []struct {
+		from   string
+		months int
+		want   string
+	}{
+		{"2024-01-31", 1, "2024-02-29"},
+		{"2023-01-31", 1, "2023-02-28"},
+		{"2024-03-31", 1, "2024-04-30"},
+		{"2024-11-15", 3, "2025-02-15"},
+		{"2024-02-29", 12, "2025-02-28"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from, func(t *testing.T) {
+			from, err := time.Parse("2006-01-02", tt.from)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, addMonths(from, tt.months).Format("2006-01-02"))
+		})
+	}
+}
+
+func TestParseClock(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string // empty when not a time
+	}{
+		{"9am", "09:00"},
+		{"12am", "00:00"},
+		{"12pm", "12:00"},
+		{"11:59pm", "23:59"},
+		{"07:05", "07:05"},
+		{"0:00", "00:00"},
+		{"9.15am", "09:15"},
+		{"9", ""},
+		{"9.15", ""},
+		{"24:00", ""},
+		{"9:5", ""},
+		{"9:60", ""},
+		{"0am", ""},
+		{"123:00", ""},
+		{"pm", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, ok := parseClock(tt.input)
+			if tt.want == "" {
+				assert.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			assert.Equal(t, tt.want, got.String())
+		})
+	}
+}
@@ -0,0 +1,151 @@
+// Package rank generates lexicographic sort keys for manually ordered items.
+//
+// A key is a base-62 fraction written without its leading "0.", so keys
+// compare as byte strings in the same order as the fractions they stand for.
+// Between always finds a key strictly between two others, which lets an item
+// be moved by rewriting only its own key. Keys grow when the same gap is
+// split repeatedly; Spread hands out short, evenly spaced keys to start over.
+package rank
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Digits is the alphabet of keys in ascending byte order
+const Digits = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// MaxLength is the key length beyond which a collection should be rebalanced
+const MaxLength = 24
+
+const base = len(Digits)
+
+// Between returns a key sorting strictly between a and b. An empty a means
+// "before every key" and an empty b "after every key".
+func Between(a, b string) (string, error) {
+	if err := Validate(a); a != "" && err != nil {
+		return "", err
+	}
+	if err := Validate(b); b != "" && err != nil {
+		return "", err
+	}
+	if a != "" && b != "" && a >= b {
+		return "", fmt.Errorf("rank: %q does not sort before %q", a, b)
+	}
+	if b == "" && a != "" {
+		return after(a), nil
+	}
+	return midpoint(a, b), nil
+}
+
+// Validate checks that key is a non-empty string of key digits that does not
+// end in the smallest digit, which would leave no room before it
+func Validate(key string) error {
+	if key == "" {
+		return errors.New("rank: empty key")
+	}
+	for i := 0; i < len(key); i++ {
+		if strings.IndexByte(Digits, key[i]) < 0 {
+			return fmt.Errorf("rank: invalid character %q in key", key[i])
+		}
+	}
+	if key[len(key)-1] == Digits[0] {
+		return fmt.Errorf("rank: key %q ends in %q", key, Digits[0])
+	}
+	return nil
+}
+
+// midpoint returns a key between a and b, which must be valid with a < b; an
+// empty b is unbounded
+func midpoint(a, b string) string {
+	if b != "" {
+		// Keep the common prefix, reading missing digits of a as zeros
+		n := 0
+		for n < len(b) && digitAt(a, n) == b[n] {
+			n++
+		}
+		if n > 0 {
+			rest := ""
+			if n < len(a) {
+				rest = a[n:]
+			}
+			return b[:n] + midpoint(rest, b[n:])
+		}
+	}
+
+	lo := 0
+	if a != "" {
+		lo = strings.IndexByte(Digits, a[0])
+	}
+	hi := base
+	if b != "" {
+		hi = strings.IndexByte(Digits, b[0])
+	}
+	if hi-lo > 1 {
+		return string(Digits[(lo+hi+1)/2])
+	}
+
+	// The first digits are adjacent: b's first digit alone still sorts
+	// before b when b is longer, otherwise extend a
+	if len(b) > 1 {
+		return b[:1]
+	}
+	rest := ""
+	if len(a) > 1 {
+		rest = a[1:]
+	}
+	return string(Digits[lo]) + midpoint(rest, "")
+}
+
+// after returns a short key sorting after a. Appending steps the first digit
+// that can still grow instead of halving the open gap, so keys of items added
+// at the end grow by one digit per base appends rather than every few.
+func after(a string) string {
+	for i := 0; i < len(a); i++ {
+		if d := strings.IndexByte(Digits, a[i]); d < base-1 {
+			return a[:i] + string(Digits[d+1])
+		}
+	}
+	return a + string(Digits[1])
+}
+
+func digitAt(key string, i int) byte {
+	if i < len(key) {
+		return key[i]
+	}
+	return Digits[0]
+}
+
+// Spread returns n ascending keys spaced evenly over the key space, all of
+// the shortest length that leaves room for inserts between neighbours
+func Spread(n int) []string {
+	if n <= 0 {
+		return []string{}
+	}
+
+	// Pick a width whose key space has about base slots per key
+	width := 1
+	space := uint64(base)
+	for space/uint64(n+1) < uint64(base) && width < 10 {
+		width++
+		space *= uint64(base)
+	}
+	step := space / uint64(n+1)
+
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = format(uint64(i+1)*step, width)
+	}
+	return keys
+}
+
+// format writes v as width base-62 digits, dropping trailing zeros
+func format(v uint64, width int) string {
+	buf := make([]byte, width)
+	for i := width - 1; i >= 0; i-- {
+		buf[i] = Digits[v%uint64(base)]
+		v /= uint64(base)
+	}
+	return strings.TrimRight(string(buf), Digits[:1])
+}
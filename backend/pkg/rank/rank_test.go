@@ -0,0 +1,107 @@
+package rank
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBetween(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{name: "empty collection", a: "", b: "", want: "V"},
+		{name: "before first", a: "", b: "V", want: "G"},
+		{name: "after last", a: "V", b: "", want: "W"},
+		{name: "after a long key", a: "zVzz", b: "", want: "zW"},
+		{name: "wide gap", a: "1", b: "9", want: "5"},
+		{name: "adjacent digits", a: "1", b: "2", want: "1V"},
+		{name: "adjacent digits with longer upper bound", a: "1", b: "2V", want: "2"},
+		{name: "common prefix", a: "A1", b: "A3", want: "A2"},
+		{name: "shorter lower bound", a: "A", b: "A2", want: "A1"},
+		{name: "extend below the smallest gap", a: "", b: "01", want: "00V"},
+		{name: "after the largest digit", a: "z", b: "", want: "z1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Between(tt.a, tt.b)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, Validate(got))
+			if tt.a != "" {
+				assert.Less(t, tt.a, got)
+			}
+			if tt.b != "" {
+				assert.Less(t, got, tt.b)
+			}
+		})
+	}
+}
+
+func TestBetweenErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+	}{
+		{name: "equal keys", a: "V", b: "V"},
+		{name: "reversed keys", a: "k", b: "B"},
+		{name: "invalid character", a: "a-b", b: ""},
+		{name: "trailing zero", a: "", b: "V0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Between(tt.a, tt.b)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestBetweenRepeatedInserts(t *testing.T) {
+	// Always inserting right after the same key keeps the order and grows
+	// the key slowly
+	lo, hi := "V", "W"
+	for i := 0; i < 100; i++ {
+		key, err := Between(lo, hi)
+		require.NoError(t, err)
+		require.Less(t, lo, key)
+		require.Less(t, key, hi)
+		hi = key
+	}
+	assert.LessOrEqual(t, len(hi), 100)
+
+	// Appending stays short
+	last := ""
+	for i := 0; i < 1000; i++ {
+		key, err := Between(last, "")
+		require.NoError(t, err)
+		require.Less(t, last, key)
+		last = key
+	}
+	assert.LessOrEqual(t, len(last), 20)
+}
+
+func TestSpread(t *testing.T) {
+	assert.Empty(t, Spread(0))
+	assert.Equal(t, []string{"V"}, Spread(1))
+
+	for _, n := range []int{2, 10, 61, 62, 500, 10000} {
+		keys := Spread(n)
+		require.Len(t, keys, n)
+		assert.True(t, sort.StringsAreSorted(keys), "n=%d", n)
+		for i, key := range keys {
+			require.NoError(t, Validate(key), "n=%d", n)
+			if i > 0 {
+				require.NotEqual(t, keys[i-1], key, "n=%d", n)
+				_, err := Between(keys[i-1], key)
+				require.NoError(t, err)
+			}
+			assert.Less(t, len(key), MaxLength)
+		}
+	}
+}
@@ -0,0 +1,482 @@
+package rrule
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency is the FREQ part of an RFC 5545 recurrence rule
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// maxPeriods bounds how many periods are scanned when looking for an occurrence
+const maxPeriods = 20000
+
+// WeekdayNum is a BYDAY entry such as MO, 2TU or -1FR
+type WeekdayNum struct {
+	Weekday time.Weekday
+	N       int // 0 means every occurrence of the weekday in the period
+}
+
+// Rule is a parsed RRULE value
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	Count      int
+	Until      time.Time
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	ByMonth    []int
+	BySetPos   []int
+	WeekStart  time.Weekday
+}
+
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+var weekdayNames = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// Parse parses an RRULE value, with or without the leading "RRULE:" prefix
+func Parse(s string) (*Rule, error) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "RRULE:"), "rrule:")
+	if s == "" {
+		return nil, errors.New("empty recurrence rule")
+	}
+
+	rule := &Rule{Interval: 1, WeekStart: time.Monday}
+	for _, part := range strings.Split(s, ";") {
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 || kv[1] == "" {
+			return nil, fmt.Errorf("invalid rule part %q", part)
+		}
+		key, value := strings.ToUpper(kv[0]), strings.ToUpper(kv[1])
+
+		var err error
+		switch key {
+		case "FREQ":
+			switch Frequency(value) {
+			case Daily, Weekly, Monthly, Yearly:
+				rule.Freq = Frequency(value)
+			default:
+				err = fmt.Errorf("unsupported FREQ %q", value)
+			}
+		case "INTERVAL":
+			rule.Interval, err = strconv.Atoi(value)
+			if err == nil && rule.Interval < 1 {
+				err = errors.New("INTERVAL must be positive")
+			}
+		case "COUNT":
+			rule.Count, err = strconv.Atoi(value)
+			if err == nil && rule.Count < 1 {
+				err = errors.New("COUNT must be positive")
+			}
+		case "UNTIL":
+			rule.Until, err = parseUntil(value)
+		case "BYDAY":
+			rule.ByDay, err = parseByDay(value)
+		case "BYMONTHDAY":
+			rule.ByMonthDay, err = parseIntList(value, -31, 31)
+		case "BYMONTH":
+			rule.ByMonth, err = parseIntList(value, 1, 12)
+		case "BYSETPOS":
+			rule.BySetPos, err = parseIntList(value, -366, 366)
+		case "WKST":
+			wd, ok := weekdayCodes[value]
+			if !ok {
+				err = fmt.Errorf("invalid WKST %q", value)
+			}
+			rule.WeekStart = wd
+		default:
+			err = fmt.Errorf("unsupported rule part %s", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", key, err)
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, errors.New("FREQ is required")
+	}
+	if rule.Count > 0 && !rule.Until.IsZero() {
+		return nil, errors.New("COUNT and UNTIL cannot both be set")
+	}
+	return rule, nil
+}
+
+func parseUntil(value string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			if layout == "20060102" {
+				// A date-only UNTIL includes the whole day
+				t = t.Add(24*time.Hour - time.Second)
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", value)
+}
+
+func parseByDay(value string) ([]WeekdayNum, error) {
+	var days []WeekdayNum
+	for _, item := range strings.Split(value, ",") {
+		if len(item) < 2 {
+			return nil, fmt.Errorf("invalid weekday %q", item)
+		}
+		code := item[len(item)-2:]
+		wd, ok := weekdayCodes[code]
+		if !ok {
+			return nil, fmt.Errorf("invalid weekday %q", item)
+		}
+		n := 0
+		if prefix := item[:len(item)-2]; prefix != "" {
+			var err error
+			n, err = strconv.Atoi(prefix)
+			if err != nil || n == 0 || n < -53 || n > 53 {
+				return nil, fmt.Errorf("invalid weekday ordinal %q", item)
+			}
+		}
+		days = append(days, WeekdayNum{Weekday: wd, N: n})
+	}
+	return days, nil
+}
+
+func parseIntList(value string, min, max int) ([]int, error) {
+	var values []int
+	for _, item := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(item)
+		if err != nil || n == 0 || n < min || n > max {
+			return nil, fmt.Errorf("invalid value %q", item)
+		}
+		values = append(values, n)
+	}
+	return values, nil
+}
+
+// String formats the rule back into its RRULE value form
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, d := range r.ByDay {
+			days[i] = weekdayNames[d.Weekday]
+			if d.N != 0 {
+				days[i] = strconv.Itoa(d.N) + days[i]
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		parts = append(parts, "BYMONTHDAY="+joinInts(r.ByMonthDay))
+	}
+	if len(r.ByMonth) > 0 {
+		parts = append(parts, "BYMONTH="+joinInts(r.ByMonth))
+	}
+	if len(r.BySetPos) > 0 {
+		parts = append(parts, "BYSETPOS="+joinInts(r.BySetPos))
+	}
+	if r.WeekStart != time.Monday {
+		parts = append(parts, "WKST="+weekdayNames[r.WeekStart])
+	}
+	return strings.Join(parts, ";")
+}
+
+func joinInts(values []int) string {
+	items := make([]string, len(values))
+	for i, v := range values {
+		items[i] = strconv.Itoa(v)
+	}
+	return strings.Join(items, ",")
+}
+
+// After returns the first occurrence of the series starting at dtstart that is
+// strictly later than t. Occurrences keep dtstart's wall-clock time in
+// dtstart's location, so a 09:00 series stays at 09:00 across DST changes.
+func (r *Rule) After(dtstart, t time.Time) (time.Time, bool) {
+	var next time.Time
+	found := false
+	r.iterate(dtstart, func(occ time.Time) bool {
+		if occ.After(t) {
+			next, found = occ, true
+			return false
+		}
+		return true
+	})
+	return next, found
+}
+
+// Between returns the occurrences in [from, to), capped at limit entries
+func (r *Rule) Between(dtstart, from, to time.Time, limit int) []time.Time {
+	var occurrences []time.Time
+	r.iterate(dtstart, func(occ time.Time) bool {
+		if !occ.Before(to) {
+			return false
+		}
+		if !occ.Before(from) {
+			occurrences = append(occurrences, occ)
+		}
+		return limit <= 0 || len(occurrences) < limit
+	})
+	return occurrences
+}
+
+// iterate calls fn for each occurrence in order until fn returns false or the
+// series ends. The first occurrence is always dtstart itself.
+func (r *Rule) iterate(dtstart time.Time, fn func(time.Time) bool) {
+	interval := r.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	emitted := 0
+	emit := func(occ time.Time) bool {
+		if !r.Until.IsZero() && occ.After(r.Until) {
+			return false
+		}
+		if r.Count > 0 && emitted >= r.Count {
+			return false
+		}
+		emitted++
+		return fn(occ)
+	}
+
+	if !emit(dtstart) {
+		return
+	}
+
+	for period := 0; period < maxPeriods; period++ {
+		for _, occ := range r.expand(dtstart, period*interval) {
+			if !occ.After(dtstart) {
+				continue
+			}
+			if !emit(occ) {
+				return
+			}
+		}
+		if !r.Until.IsZero() && r.periodStart(dtstart, period*interval).After(r.Until) {
+			return
+		}
+	}
+}
+
+// periodStart returns the first day of the period offset steps after dtstart's period
+func (r *Rule) periodStart(dtstart time.Time, offset int) time.Time {
+	y, m, d := dtstart.Date()
+	loc := dtstart.Location()
+	switch r.Freq {
+	case Daily:
+		return time.Date(y, m, d+offset, 0, 0, 0, 0, loc)
+	case Weekly:
+		back := (int(dtstart.Weekday()) - int(r.WeekStart) + 7) % 7
+		return time.Date(y, m, d-back+7*offset, 0, 0, 0, 0, loc)
+	case Monthly:
+		return time.Date(y, m+time.Month(offset), 1, 0, 0, 0, 0, loc)
+	default:
+		return time.Date(y+offset, 1, 1, 0, 0, 0, 0, loc)
+	}
+}
+
+// expand returns the sorted candidate occurrences in one period
+func (r *Rule) expand(dtstart time.Time, offset int) []time.Time {
+	start := r.periodStart(dtstart, offset)
+	var days []time.Time
+
+	switch r.Freq {
+	case Daily:
+		days = []time.Time{start}
+	case Weekly:
+		for i := 0; i < 7; i++ {
+			day := start.AddDate(0, 0, i)
+			if len(r.ByDay) == 0 && day.Weekday() != dtstart.Weekday() {
+				continue
+			}
+			days = append(days, day)
+		}
+	case Monthly:
+		days = r.expandMonth(dtstart, start.Year(), start.Month())
+	case Yearly:
+		months := r.ByMonth
+		if len(months) == 0 && (len(r.ByMonthDay) > 0 || len(r.ByDay) == 0) {
+			months = []int{int(dtstart.Month())}
+		}
+		if len(months) == 0 {
+			// BYDAY without BYMONTH: ordinals count within the whole year
+			days = r.expandYearByDay(start.Year(), dtstart.Location())
+		}
+		for _, m := range months {
+			days = append(days, r.expandMonth(dtstart, start.Year(), time.Month(m))...)
+		}
+	}
+
+	var candidates []time.Time
+	for _, day := range days {
+		if !r.matches(day) {
+			continue
+		}
+		h, mi, s := dtstart.Clock()
+		y, mo, d := day.Date()
+		candidates = append(candidates, time.Date(y, mo, d, h, mi, s, dtstart.Nanosecond(), dtstart.Location()))
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].Before(candidates[j]) })
+
+	return r.applySetPos(candidates)
+}
+
+// expandMonth returns the days of a month selected by BYMONTHDAY/BYDAY
+func (r *Rule) expandMonth(dtstart time.Time, year int, month time.Month) []time.Time {
+	loc := dtstart.Location()
+	first := time.Date(year, month, 1, 0, 0, 0, 0, loc)
+	daysIn := first.AddDate(0, 1, -1).Day()
+
+	if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+		if dtstart.Day() > daysIn {
+			return nil
+		}
+		return []time.Time{time.Date(year, month, dtstart.Day(), 0, 0, 0, 0, loc)}
+	}
+
+	var days []time.Time
+	for d := 1; d <= daysIn; d++ {
+		day := time.Date(year, month, d, 0, 0, 0, 0, loc)
+		if len(r.ByMonthDay) > 0 && !containsMonthDay(r.ByMonthDay, d, daysIn) {
+			continue
+		}
+		if len(r.ByDay) > 0 && !matchesOrdinalWeekday(r.ByDay, day, d, daysIn) {
+			continue
+		}
+		days = append(days, day)
+	}
+	return days
+}
+
+func (r *Rule) expandYearByDay(year int, loc *time.Location) []time.Time {
+	first := time.Date(year, 1, 1, 0, 0, 0, 0, loc)
+	daysIn := first.AddDate(1, 0, -1).YearDay()
+
+	var days []time.Time
+	for d := 1; d <= daysIn; d++ {
+		day := first.AddDate(0, 0, d-1)
+		if matchesOrdinalWeekday(r.ByDay, day, d, daysIn) {
+			days = append(days, day)
+		}
+	}
+	return days
+}
+
+// matches applies the limiting BYxxx parts that are not used for expansion
+func (r *Rule) matches(day time.Time) bool {
+	if len(r.ByMonth) > 0 && !containsInt(r.ByMonth, int(day.Month())) {
+		return false
+	}
+	switch r.Freq {
+	case Daily:
+		if len(r.ByMonthDay) > 0 {
+			daysIn := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, day.Location()).Day()
+			if !containsMonthDay(r.ByMonthDay, day.Day(), daysIn) {
+				return false
+			}
+		}
+		if len(r.ByDay) > 0 && !matchesWeekday(r.ByDay, day.Weekday()) {
+			return false
+		}
+	case Weekly:
+		if len(r.ByDay) > 0 && !matchesWeekday(r.ByDay, day.Weekday()) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Rule) applySetPos(candidates []time.Time) []time.Time {
+	if len(r.BySetPos) == 0 || len(candidates) == 0 {
+		return candidates
+	}
+	var selected []time.Time
+	for _, pos := range r.BySetPos {
+		idx := pos - 1
+		if pos < 0 {
+			idx = len(candidates) + pos
+		}
+		if idx >= 0 && idx < len(candidates) {
+			selected = append(selected, candidates[idx])
+		}
+	}
+	sort.Slice(selected, func(i, j int) bool { return selected[i].Before(selected[j]) })
+	return selected
+}
+
+func containsInt(values []int, v int) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+func containsMonthDay(values []int, day, daysIn int) bool {
+	for _, x := range values {
+		if x == day || (x < 0 && daysIn+x+1 == day) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesWeekday(days []WeekdayNum, wd time.Weekday) bool {
+	for _, d := range days {
+		if d.Weekday == wd {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesOrdinalWeekday checks BYDAY entries where ordinals count within a
+// span of daysIn days and index is the 1-based position of day in that span
+func matchesOrdinalWeekday(days []WeekdayNum, day time.Time, index, daysIn int) bool {
+	for _, d := range days {
+		if d.Weekday != day.Weekday() {
+			continue
+		}
+		if d.N == 0 {
+			return true
+		}
+		if d.N > 0 && (index-1)/7+1 == d.N {
+			return true
+		}
+		if d.N < 0 && (daysIn-index)/7+1 == -d.N {
+			return true
+		}
+	}
+	return false
+}
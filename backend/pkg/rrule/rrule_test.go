@@ -0,0 +1,167 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"daily", "FREQ=DAILY", "FREQ=DAILY", false},
+		{"prefix and lowercase", "RRULE:freq=weekly;byday=mo,we", "FREQ=WEEKLY;BYDAY=MO,WE", false},
+		{"ordinal weekday", "FREQ=MONTHLY;BYDAY=-1FR", "FREQ=MONTHLY;BYDAY=-1FR", false},
+		{"interval and count", "FREQ=WEEKLY;INTERVAL=2;COUNT=5", "FREQ=WEEKLY;INTERVAL=2;COUNT=5", false},
+		{"until", "FREQ=DAILY;UNTIL=20240110T000000Z", "FREQ=DAILY;UNTIL=20240110T000000Z", false},
+		{"missing freq", "INTERVAL=2", "", true},
+		{"bad freq", "FREQ=HOURLY", "", true},
+		{"count and until", "FREQ=DAILY;COUNT=2;UNTIL=20240101", "", true},
+		{"bad weekday", "FREQ=WEEKLY;BYDAY=XX", "", true},
+		{"zero monthday", "FREQ=MONTHLY;BYMONTHDAY=0", "", true},
+		{"unknown part", "FREQ=DAILY;BYHOUR=9", "", true},
+		{"empty", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, rule.String())
+		})
+	}
+}
+
+func TestAfter(t *testing.T) {
+	utc := time.UTC
+	tests := []struct {
+		name    string
+		rule    string
+		dtstart time.Time
+		after   time.Time
+		want    time.Time
+		found   bool
+	}{
+		{
+			name:    "daily",
+			rule:    "FREQ=DAILY",
+			dtstart: time.Date(2024, 1, 1, 9, 0, 0, 0, utc),
+			after:   time.Date(2024, 1, 1, 9, 0, 0, 0, utc),
+			want:    time.Date(2024, 1, 2, 9, 0, 0, 0, utc),
+			found:   true,
+		},
+		{
+			name:    "every other week on monday and friday",
+			rule:    "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR",
+			dtstart: time.Date(2024, 1, 1, 9, 0, 0, 0, utc), // Monday
+			after:   time.Date(2024, 1, 5, 9, 0, 0, 0, utc),
+			want:    time.Date(2024, 1, 15, 9, 0, 0, 0, utc),
+			found:   true,
+		},
+		{
+			name:    "monthly on the 31st skips short months",
+			rule:    "FREQ=MONTHLY",
+			dtstart: time.Date(2024, 1, 31, 0, 0, 0, 0, utc),
+			after:   time.Date(2024, 1, 31, 0, 0, 0, 0, utc),
+			want:    time.Date(2024, 3, 31, 0, 0, 0, 0, utc),
+			found:   true,
+		},
+		{
+			name:    "last day of month",
+			rule:    "FREQ=MONTHLY;BYMONTHDAY=-1",
+			dtstart: time.Date(2024, 1, 31, 0, 0, 0, 0, utc),
+			after:   time.Date(2024, 1, 31, 0, 0, 0, 0, utc),
+			want:    time.Date(2024, 2, 29, 0, 0, 0, 0, utc),
+			found:   true,
+		},
+		{
+			name:    "last friday of month",
+			rule:    "FREQ=MONTHLY;BYDAY=-1FR",
+			dtstart: time.Date(2024, 1, 26, 12, 0, 0, 0, utc),
+			after:   time.Date(2024, 1, 26, 12, 0, 0, 0, utc),
+			want:    time.Date(2024, 2, 23, 12, 0, 0, 0, utc),
+			found:   true,
+		},
+		{
+			name:    "last weekday of month via setpos",
+			rule:    "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1",
+			dtstart: time.Date(2024, 2, 29, 0, 0, 0, 0, utc),
+			after:   time.Date(2024, 2, 29, 0, 0, 0, 0, utc),
+			want:    time.Date(2024, 3, 29, 0, 0, 0, 0, utc),
+			found:   true,
+		},
+		{
+			name:    "yearly thanksgiving",
+			rule:    "FREQ=YEARLY;BYMONTH=11;BYDAY=4TH",
+			dtstart: time.Date(2023, 11, 23, 0, 0, 0, 0, utc),
+			after:   time.Date(2023, 11, 23, 0, 0, 0, 0, utc),
+			want:    time.Date(2024, 11, 28, 0, 0, 0, 0, utc),
+			found:   true,
+		},
+		{
+			name:    "count exhausted",
+			rule:    "FREQ=DAILY;COUNT=3",
+			dtstart: time.Date(2024, 1, 1, 0, 0, 0, 0, utc),
+			after:   time.Date(2024, 1, 3, 0, 0, 0, 0, utc),
+			found:   false,
+		},
+		{
+			name:    "until reached",
+			rule:    "FREQ=WEEKLY;UNTIL=20240114T000000Z",
+			dtstart: time.Date(2024, 1, 1, 0, 0, 0, 0, utc),
+			after:   time.Date(2024, 1, 8, 0, 0, 0, 0, utc),
+			found:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.rule)
+			require.NoError(t, err)
+
+			got, found := rule.After(tt.dtstart, tt.after)
+			assert.Equal(t, tt.found, found)
+			if tt.found {
+				assert.True(t, tt.want.Equal(got), "want %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestAfterKeepsWallClockAcrossDST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	rule, err := Parse("FREQ=WEEKLY")
+	require.NoError(t, err)
+
+	dtstart := time.Date(2024, 3, 4, 9, 0, 0, 0, loc)
+	next, found := rule.After(dtstart, dtstart.AddDate(0, 0, 7))
+
+	assert.True(t, found)
+	assert.Equal(t, 9, next.Hour())
+	assert.Equal(t, 18, next.Day())
+}
+
+func TestBetween(t *testing.T) {
+	rule, err := Parse("FREQ=DAILY;INTERVAL=3")
+	require.NoError(t, err)
+
+	dtstart := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	got := rule.Between(dtstart, dtstart.AddDate(0, 0, 2), dtstart.AddDate(0, 0, 10), 0)
+
+	require.Len(t, got, 3)
+	assert.Equal(t, 4, got[0].Day())
+	assert.Equal(t, 7, got[1].Day())
+	assert.Equal(t, 10, got[2].Day())
+}
@@ -0,0 +1,201 @@
+package todoimport
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SchemaVersion is the version of the JSON export schema. Imports read
+// documents up to this version. Version 2 added positions, workflow states
+// and the workflows of lists.
+const SchemaVersion = 2
+
+// Document is a JSON export:
+//
+//	{
+//	  "version": 2,
+//	  "exported_at": "2024-03-05T09:00:00Z",
+//	  "lists": [{"id": "l1", "name": "Home", "estimate_unit": "minutes",
+//	    "workflow": [
+//	      {"key": "todo", "name": "To do", "category": "todo"},
+//	      {"key": "shop", "name": "Shopping", "category": "doing", "wip_limit": 2},
+//	      {"key": "done", "name": "Done", "category": "done"}
+//	    ]}],
+//	  "todos": [
+//	    {"id": "t1", "list_id": "l1", "title": "Groceries", "status": "in_progress",
+//	     "state": "shop", "position": "m", "priority": 3,
+//	     "due_date": "2024-03-05T09:00:00Z", "tags": ["errands"]},
+//	    {"id": "t2", "parent_id": "t1", "list_id": "l1", "title": "Milk",
+//	     "status": "completed", "state": "done", "position": "n", "priority": 0,
+//	     "tags": []}
+//	  ]
+//	}
+//
+// IDs only link todos to their list and parent within the document; imports
+// create new ones. Positions only order the document's todos among
+// themselves; imports append them to the manual order. Timestamps are
+// informational, imported todos are created anew.
+type Document struct {
+	Version    int        `json:"version"`
+	ExportedAt time.Time  `json:"exported_at"`
+	Lists      []JSONList `json:"lists"`
+	Todos      []JSONTodo `json:"todos"`
+}
+
+// JSONList is a list of a JSON export. Workflow is empty for lists on the
+// default workflow.
+type JSONList struct {
+	ID           string      `json:"id"`
+	Name         string      `json:"name"`
+	EstimateUnit string      `json:"estimate_unit,omitempty"`
+	Workflow     []JSONState `json:"workflow,omitempty"`
+}
+
+// JSONState is a state of a list's workflow, in board order
+type JSONState struct {
+	Key         string   `json:"key"`
+	Name        string   `json:"name"`
+	Category    string   `json:"category"`
+	WIPLimit    int      `json:"wip_limit,omitempty"`
+	Transitions []string `json:"transitions,omitempty"`
+}
+
+// JSONTodo is a todo of a JSON export
+type JSONTodo struct {
+	ID             string     `json:"id"`
+	ParentID       string     `json:"parent_id,omitempty"`
+	ListID         string     `json:"list_id,omitempty"`
+	Title          string     `json:"title"`
+	Description    string     `json:"description,omitempty"`
+	Status         Status     `json:"status"`
+	State          string     `json:"state,omitempty"`
+	Position       string     `json:"position,omitempty"`
+	Priority       int        `json:"priority"`
+	DueDate        *time.Time `json:"due_date,omitempty"`
+	RecurrenceRule string     `json:"recurrence_rule,omitempty"`
+	Timezone       string     `json:"timezone,omitempty"`
+	Estimate       *float64   `json:"estimate,omitempty"`
+	Tags           []string   `json:"tags"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// isDocument reports whether data is a JSON export rather than a Taskwarrior
+// task
+func isDocument(data []byte) bool {
+	var header struct {
+		Version *int            `json:"version"`
+		Todos   json.RawMessage `json:"todos"`
+	}
+	return json.Unmarshal(data, &header) == nil && header.Version != nil && header.Todos != nil
+}
+
+func parseJSON(result *Result, data []byte) error {
+	var doc Document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("invalid JSON export: %w", err)
+	}
+	if doc.Version < 1 || doc.Version > SchemaVersion {
+		return fmt.Errorf("invalid JSON export: unsupported version %d", doc.Version)
+	}
+
+	lists := make(map[string]string, len(doc.Lists))
+	for _, list := range doc.Lists {
+		lists[list.ID] = list.Name
+		result.Lists = append(result.Lists, List{Name: list.Name, EstimateUnit: list.EstimateUnit, Workflow: list.Workflow})
+	}
+
+	// Todos are emitted in their manual order, those without a position
+	// last, and subtasks after their parent, whatever the document's order
+	children := make(map[string][]int)
+	indexes := make(map[string]int, len(doc.Todos))
+	for i, todo := range doc.Todos {
+		if todo.ID != "" {
+			indexes[todo.ID] = i
+		}
+	}
+	var roots []int
+	for i, todo := range doc.Todos {
+		if _, ok := indexes[todo.ParentID]; ok && todo.ParentID != todo.ID {
+			children[todo.ParentID] = append(children[todo.ParentID], i)
+		} else {
+			roots = append(roots, i)
+		}
+	}
+	byPosition := func(indexes []int) {
+		sort.SliceStable(indexes, func(a, b int) bool {
+			pa, pb := doc.Todos[indexes[a]].Position, doc.Todos[indexes[b]].Position
+			return pa != "" && (pb == "" || pa < pb)
+		})
+	}
+	byPosition(roots)
+	for _, siblings := range children {
+		byPosition(siblings)
+	}
+
+	emitted := make([]bool, len(doc.Todos))
+	var emit func(i, parent int)
+	emit = func(i, parent int) {
+		if emitted[i] {
+			return
+		}
+		emitted[i] = true
+		todo := doc.Todos[i]
+
+		item := Item{
+			Line:           i + 1,
+			Title:          strings.TrimSpace(todo.Title),
+			Description:    todo.Description,
+			Status:         todo.Status,
+			State:          todo.State,
+			Priority:       todo.Priority,
+			Due:            todo.DueDate,
+			RecurrenceRule: todo.RecurrenceRule,
+			Timezone:       todo.Timezone,
+			Estimate:       todo.Estimate,
+			Tags:           todo.Tags,
+			Parent:         parent,
+		}
+		if todo.ListID != "" {
+			name, ok := lists[todo.ListID]
+			if ok {
+				item.List = name
+			} else {
+				result.skip(item.Line, "list_id", todo.ListID, "unknown list")
+			}
+		}
+		switch item.Status {
+		case StatusPending, StatusInProgress, StatusCompleted:
+		default:
+			result.skip(item.Line, "status", string(todo.Status), "invalid status")
+			item.Status = StatusPending
+		}
+		if item.Priority < 0 || item.Priority > 5 {
+			result.skip(item.Line, "priority", fmt.Sprint(todo.Priority), "invalid priority")
+			item.Priority = 0
+		}
+		if item.Title == "" {
+			item.Title = "Untitled"
+		}
+
+		index := len(result.Items)
+		result.Items = append(result.Items, item)
+		for _, child := range children[todo.ID] {
+			emit(child, index)
+		}
+	}
+	for _, i := range roots {
+		emit(i, -1)
+	}
+	// Todos on a parent cycle have no root to be reached from
+	for i := range doc.Todos {
+		if !emitted[i] {
+			result.skip(i+1, "parent_id", doc.Todos[i].ParentID, "circular parent")
+			emit(i, -1)
+		}
+	}
+	return nil
+}
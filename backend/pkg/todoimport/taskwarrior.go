@@ -0,0 +1,177 @@
+package todoimport
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// taskwarriorDate is the layout of Taskwarrior export dates, always in UTC
+const taskwarriorDate = "20060102T150405Z"
+
+var taskwarriorPriorities = map[string]int{"H": 5, "M": 3, "L": 1}
+
+// Taskwarrior fields that are bookkeeping or mapped elsewhere, not reported
+// as skipped
+var taskwarriorIgnored = map[string]bool{
+	"id": true, "uuid": true, "entry": true, "modified": true, "end": true,
+	"urgency": true, "mask": true, "imask": true, "last": true, "rtype": true,
+	"parent": true, "status": true, "description": true, "priority": true,
+	"project": true, "tags": true, "due": true, "start": true, "annotations": true,
+}
+
+type taskwarriorAnnotation struct {
+	Description string `json:"description"`
+}
+
+type taskwarriorTask struct {
+	Description string                  `json:"description"`
+	Status      string                  `json:"status"`
+	Priority    string                  `json:"priority"`
+	Project     string                  `json:"project"`
+	Tags        []string                `json:"tags"`
+	Due         string                  `json:"due"`
+	Start       string                  `json:"start"`
+	Annotations []taskwarriorAnnotation `json:"annotations"`
+}
+
+func parseTaskwarrior(result *Result, data []byte, opts Options) error {
+	raw, err := taskwarriorRecords(data)
+	if err != nil {
+		return err
+	}
+
+	for i, record := range raw {
+		number := i + 1
+		var task taskwarriorTask
+		if err := json.Unmarshal(record, &task); err != nil {
+			return fmt.Errorf("invalid Taskwarrior JSON: task %d: %w", number, err)
+		}
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(record, &fields); err != nil {
+			return fmt.Errorf("invalid Taskwarrior JSON: task %d: %w", number, err)
+		}
+
+		switch task.Status {
+		case "deleted":
+			result.skip(number, "status", task.Status, "deleted tasks are not imported")
+			continue
+		case "recurring":
+			result.skip(number, "status", task.Status, "recurring templates are not imported, their pending instances are")
+			continue
+		}
+
+		item := Item{
+			Line:   number,
+			Title:  strings.TrimSpace(task.Description),
+			Status: StatusPending,
+			List:   task.Project,
+			Tags:   task.Tags,
+			Parent: -1,
+		}
+		if task.Status == "completed" {
+			item.Status = StatusCompleted
+		} else if task.Start != "" {
+			item.Status = StatusInProgress
+		}
+
+		if task.Priority != "" {
+			if priority, ok := taskwarriorPriorities[task.Priority]; ok {
+				item.Priority = priority
+			} else {
+				result.skip(number, "priority", task.Priority, "invalid priority")
+			}
+		}
+		if task.Due != "" {
+			if due, err := time.Parse(taskwarriorDate, task.Due); err == nil {
+				item.Due = &due
+			} else {
+				result.skip(number, "due", task.Due, "invalid date")
+			}
+		}
+
+		notes := make([]string, 0, len(task.Annotations))
+		for _, annotation := range task.Annotations {
+			if text := strings.TrimSpace(annotation.Description); text != "" {
+				notes = append(notes, text)
+			}
+		}
+		item.Description = strings.Join(notes, "\n")
+
+		keys := make([]string, 0, len(fields))
+		for key := range fields {
+			if !taskwarriorIgnored[key] {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			result.skip(number, key, taskwarriorValue(fields[key]), taskwarriorReason(key))
+		}
+
+		if item.Title == "" {
+			result.skip(number, "description", "", "tasks without a description are not imported")
+			continue
+		}
+		result.Items = append(result.Items, item)
+	}
+	return nil
+}
+
+// taskwarriorRecords splits an export into its tasks: a JSON array, as
+// written by task export, or one object per line, as written by older
+// versions and hooks
+func taskwarriorRecords(data []byte) ([]json.RawMessage, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		var records []json.RawMessage
+		if err := json.Unmarshal(data, &records); err != nil {
+			return nil, fmt.Errorf("invalid Taskwarrior JSON: %w", err)
+		}
+		return records, nil
+	}
+
+	var records []json.RawMessage
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var record json.RawMessage
+		err := decoder.Decode(&record)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid Taskwarrior JSON: %w", err)
+		}
+		records = append(records, record)
+	}
+	if len(records) == 0 {
+		return nil, errors.New("invalid Taskwarrior JSON: no tasks")
+	}
+	return records, nil
+}
+
+func taskwarriorReason(key string) string {
+	switch key {
+	case "depends":
+		return "dependencies are not imported"
+	case "recur", "until":
+		return "recurrence is not imported"
+	case "wait", "scheduled":
+		return "wait and scheduled dates are not imported"
+	}
+	return "unknown field"
+}
+
+// taskwarriorValue returns a field's value for a report, unquoting strings
+func taskwarriorValue(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	return string(raw)
+}
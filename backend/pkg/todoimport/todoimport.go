@@ -0,0 +1,186 @@
+// Package todoimport reads task exports, of other todo apps and of this one,
+// into a flat list of items that callers turn into todos. It reads
+//
+//   - todo.txt: one task per line, with (A) priorities, +projects, @contexts,
+//     due:YYYY-MM-DD and a leading x for completed tasks
+//   - Todoist CSV: the project template export, with TYPE, CONTENT,
+//     DESCRIPTION, PRIORITY, INDENT, DATE and DURATION columns
+//   - Taskwarrior JSON: the output of task export, an array or one task per
+//     line
+//   - JSON: this app's own export, see Document, which imports without loss
+//
+// Priorities are mapped onto 0 (none) to 5 (highest), projects onto list
+// names and contexts, labels and tags onto tags. Everything the parsers read
+// but cannot map, e.g. Taskwarrior dependencies or Todoist sections, is
+// reported as skipped, so callers can show what an import leaves behind.
+package todoimport
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Format is an import format
+type Format string
+
+const (
+	FormatTodoTxt     Format = "todotxt"
+	FormatTodoist     Format = "todoist"
+	FormatTaskwarrior Format = "taskwarrior"
+	FormatJSON        Format = "json"
+)
+
+// Status is the status of an item
+type Status string
+
+const (
+	StatusPending    Status = "pending"
+	StatusInProgress Status = "in_progress"
+	StatusCompleted  Status = "completed"
+)
+
+// DefaultHour is the hour of a due date given without a time
+const DefaultHour = 9
+
+// Item is one task of an import
+type Item struct {
+	// Line is the line (todo.txt, Todoist) or task (Taskwarrior, JSON)
+	// number of the item, counting from 1
+	Line        int
+	Title       string
+	Description string
+	Status      Status
+	// State is the key of the item's state on its list's board, only read
+	// from JSON exports
+	State string
+	// Priority from 0 (none) to 5 (highest)
+	Priority int
+	Due      *time.Time
+	// Recurrence rule and its timezone, only read from JSON exports
+	RecurrenceRule string
+	Timezone       string
+	// Estimate in minutes, or in the unit of the item's list in JSON exports
+	Estimate *float64
+	// List is the name of the item's project, empty without one
+	List string
+	Tags []string
+	// Parent is the index of the item's parent in Result.Items, -1 for a
+	// top-level item. Parents always come before their subtasks, and JSON
+	// exports list their items in manual order otherwise.
+	Parent int
+}
+
+// Skipped is a field, or a whole task, an import leaves out
+type Skipped struct {
+	Line   int    `json:"line"`
+	Field  string `json:"field"`
+	Value  string `json:"value,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// List is a list an import describes, beyond the names of its items' lists
+type List struct {
+	Name         string
+	EstimateUnit string
+	// Workflow is the list's board, empty for the default one
+	Workflow []JSONState
+}
+
+// Result is a parsed import. Lists is only set by formats that describe their
+// lists.
+type Result struct {
+	Format  Format
+	Items   []Item
+	Lists   []List
+	Skipped []Skipped
+}
+
+func (r *Result) skip(line int, field, value, reason string) {
+	r.Skipped = append(r.Skipped, Skipped{Line: line, Field: field, Value: value, Reason: reason})
+}
+
+// Options of a parse
+type Options struct {
+	// Location of due dates given without a timezone, UTC when nil
+	Location *time.Location
+	// Name is the name of the imported file. Todoist exports one project per
+	// file and name the file after it, so its stem becomes the list name.
+	Name string
+}
+
+// Formats returns the supported formats
+func Formats() []Format {
+	return []Format{FormatTodoTxt, FormatTodoist, FormatTaskwarrior, FormatJSON}
+}
+
+// ParseFormat returns the format named s
+func ParseFormat(s string) (Format, error) {
+	for _, format := range Formats() {
+		if strings.EqualFold(s, string(format)) {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported import format %q", s)
+}
+
+// Detect guesses the format of data: a JSON object with a version and todos
+// is a JSON export, other JSON a Taskwarrior export, CSV with a TYPE,CONTENT
+// header a Todoist export and anything else todo.txt
+func Detect(data []byte) Format {
+	data = bytes.TrimSpace(bytes.TrimPrefix(data, utf8BOM))
+	if len(data) > 0 && data[0] == '{' && isDocument(data) {
+		return FormatJSON
+	}
+	if len(data) > 0 && (data[0] == '[' || data[0] == '{') {
+		return FormatTaskwarrior
+	}
+	header, _, _ := strings.Cut(string(data), "\n")
+	header = strings.ToUpper(strings.ReplaceAll(header, `"`, ""))
+	if strings.HasPrefix(header, "TYPE,CONTENT") {
+		return FormatTodoist
+	}
+	return FormatTodoTxt
+}
+
+// Parse parses data in the given format, detecting it when empty
+func Parse(format Format, data []byte, opts Options) (*Result, error) {
+	if opts.Location == nil {
+		opts.Location = time.UTC
+	}
+	data = bytes.TrimPrefix(data, utf8BOM)
+	if format == "" {
+		format = Detect(data)
+	}
+
+	result := &Result{Format: format}
+	var err error
+	switch format {
+	case FormatTodoTxt:
+		err = parseTodoTxt(result, data, opts)
+	case FormatTodoist:
+		err = parseTodoist(result, data, opts)
+	case FormatTaskwarrior:
+		err = parseTaskwarrior(result, data, opts)
+	case FormatJSON:
+		err = parseJSON(result, data)
+	default:
+		return nil, fmt.Errorf("unsupported import format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Items) == 0 {
+		return nil, errors.New("no tasks found")
+	}
+	return result, nil
+}
+
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// atDefaultHour returns a date at DefaultHour in loc
+func atDefaultHour(date time.Time, loc *time.Location) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), DefaultHour, 0, 0, 0, loc)
+}
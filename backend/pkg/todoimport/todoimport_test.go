@@ -0,0 +1,307 @@
+package todoimport
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want Format
+	}{
+		{"todo.txt", "(A) Call mom +Family\nx Pay rent", FormatTodoTxt},
+		{"todoist", "TYPE,CONTENT,DESCRIPTION,PRIORITY,INDENT\ntask,Call mom,,4,1", FormatTodoist},
+		{"todoist quoted with BOM", "\xef\xbb\xbf\"TYPE\",\"CONTENT\"\n", FormatTodoist},
+		{"taskwarrior array", "  [{\"description\":\"Call mom\"}]", FormatTaskwarrior},
+		{"taskwarrior lines", "{\"description\":\"Call mom\"}\n{\"description\":\"Pay rent\"}", FormatTaskwarrior},
+		{"json export", "{\"version\":1,\"lists\":[],\"todos\":[]}", FormatJSON},
+		{"taskwarrior with a version", "{\"description\":\"Upgrade\",\"version\":2}", FormatTaskwarrior},
+		{"empty", "", FormatTodoTxt},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Detect([]byte(tt.data)))
+		})
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat("Todoist")
+	require.NoError(t, err)
+	assert.Equal(t, FormatTodoist, format)
+
+	_, err = ParseFormat("omnifocus")
+	assert.EqualError(t, err, `unsupported import format "omnifocus"`)
+}
+
+func TestParseTodoTxt(t *testing.T) {
+	loc := time.FixedZone("CET", 60*60)
+	data := strings.Join([]string{
+		"(A) 2024-02-20 Call mom +Family @phone due:2024-03-05",
+		"",
+		"x 2024-03-01 2024-02-20 Pay rent +Home +Bills pri:B",
+		"(E) Read https://example.com/post @reading",
+		"Fix bike due:someday id:3",
+		"+Garden @outside",
+	}, "\n")
+
+	result, err := Parse(FormatTodoTxt, []byte(data), Options{Location: loc})
+	require.NoError(t, err)
+	require.Len(t, result.Items, 5)
+
+	due := time.Date(2024, 3, 5, 9, 0, 0, 0, loc)
+	assert.Equal(t, Item{
+		Line: 1, Title: "Call mom", Status: StatusPending, Priority: 5, Due: &due,
+		List: "Family", Tags: []string{"phone"}, Parent: -1,
+	}, result.Items[0])
+
+	paid := result.Items[1]
+	assert.Equal(t, 3, paid.Line)
+	assert.Equal(t, "Pay rent", paid.Title)
+	assert.Equal(t, StatusCompleted, paid.Status)
+	assert.Equal(t, 4, paid.Priority)
+	assert.Equal(t, "Home", paid.List)
+	assert.Equal(t, []string{"Bills"}, paid.Tags)
+
+	assert.Equal(t, "Read https://example.com/post", result.Items[2].Title)
+	assert.Equal(t, 1, result.Items[2].Priority)
+
+	assert.Equal(t, "Fix bike", result.Items[3].Title)
+	assert.Nil(t, result.Items[3].Due)
+
+	// A task of only a project and context keeps its text as the title
+	assert.Equal(t, "+Garden @outside", result.Items[4].Title)
+	assert.Equal(t, "Garden", result.Items[4].List)
+
+	assert.Equal(t, []Skipped{
+		{Line: 1, Field: "creation_date", Value: "2024-02-20", Reason: "creation dates are not imported"},
+		{Line: 3, Field: "completion_date", Value: "2024-03-01", Reason: "completion dates are not imported"},
+		{Line: 3, Field: "creation_date", Value: "2024-02-20", Reason: "creation dates are not imported"},
+		{Line: 5, Field: "due", Value: "someday", Reason: "invalid date"},
+		{Line: 5, Field: "id", Value: "3", Reason: "unknown field"},
+	}, result.Skipped)
+}
+
+func TestParseTodoist(t *testing.T) {
+	loc := time.FixedZone("CET", 60*60)
+	data := strings.Join([]string{
+		"TYPE,CONTENT,DESCRIPTION,PRIORITY,INDENT,AUTHOR,RESPONSIBLE,DATE,DATE_LANG,TIMEZONE,DURATION,DURATION_UNIT",
+		"note,Project notes,,,,,,,,,,",
+		"section,Errands,,,,,,,,,,",
+		"task,Buy groceries @errands @Shopping,Milk and eggs,4,1,Ann (1),,2024-03-05,en,,30,minute",
+		"task,Milk,,1,2,Ann (1),Bob (2),,en,,,",
+		"note,Oat milk,,,,,,,,,,",
+		"note,Or soy,,,,,,,,,,",
+		"task,Eggs,,2,3,Ann (1),,5 Mar 2024 18:30,en,UTC,,",
+		"task,Water plants,,3,1,Ann (1),,every day,en,,1,day",
+		"",
+		"meta,view_style=list,,,,,,,,,,",
+	}, "\n")
+
+	result, err := Parse("", []byte(data), Options{Location: loc, Name: "exports/Home Chores.csv"})
+	require.NoError(t, err)
+	assert.Equal(t, FormatTodoist, result.Format)
+	require.Len(t, result.Items, 4)
+
+	estimate := 30.0
+	due := time.Date(2024, 3, 5, 9, 0, 0, 0, loc)
+	assert.Equal(t, Item{
+		Line: 4, Title: "Buy groceries", Description: "Milk and eggs", Status: StatusPending,
+		Priority: 5, Due: &due, Estimate: &estimate, List: "Home Chores",
+		Tags: []string{"errands", "Shopping"}, Parent: -1,
+	}, result.Items[0])
+
+	milk := result.Items[1]
+	assert.Equal(t, 0, milk.Parent)
+	assert.Equal(t, 0, milk.Priority)
+	assert.Equal(t, "Oat milk\n\nOr soy", milk.Description)
+
+	eggs := result.Items[2]
+	assert.Equal(t, 1, eggs.Parent)
+	assert.Equal(t, 3, eggs.Priority)
+	assert.Equal(t, time.Date(2024, 3, 5, 18, 30, 0, 0, time.UTC), *eggs.Due)
+
+	water := result.Items[3]
+	assert.Equal(t, -1, water.Parent)
+	assert.Nil(t, water.Due)
+	assert.Nil(t, water.Estimate)
+
+	assert.Equal(t, []Skipped{
+		{Line: 2, Field: "note", Value: "Project notes", Reason: "project comments are not imported"},
+		{Line: 3, Field: "section", Value: "Errands", Reason: "sections are not imported"},
+		{Line: 5, Field: "responsible", Value: "Bob (2)", Reason: "assignees are not imported"},
+		{Line: 9, Field: "date", Value: "every day", Reason: "recurring dates are not imported"},
+		{Line: 9, Field: "duration", Value: "1 day", Reason: "only durations in minutes are imported"},
+	}, result.Skipped)
+}
+
+func TestParseTodoistIndentWithoutParent(t *testing.T) {
+	data := "TYPE,CONTENT,INDENT\ntask,Orphan,3\ntask,Child,2\n"
+
+	result, err := Parse(FormatTodoist, []byte(data), Options{})
+	require.NoError(t, err)
+	require.Len(t, result.Items, 2)
+	assert.Equal(t, -1, result.Items[0].Parent)
+	assert.Equal(t, 0, result.Items[1].Parent)
+	assert.Empty(t, result.Items[0].List)
+}
+
+func TestParseTaskwarrior(t *testing.T) {
+	data := `[
+		{"id":1,"uuid":"a","description":"Call mom","status":"pending","priority":"H","project":"Family","tags":["phone"],"due":"20240305T090000Z","entry":"20240301T080000Z","urgency":8.2},
+		{"id":0,"description":"Pay rent","status":"completed","priority":"M","end":"20240301T120000Z","annotations":[{"entry":"20240301T120000Z","description":"Paid by transfer"},{"description":"Ref 42"}]},
+		{"id":2,"description":"Write report","status":"waiting","start":"20240302T080000Z","wait":"20240310T000000Z","depends":"b,c","estimate":3},
+		{"description":"Old","status":"deleted"},
+		{"description":"Weekly review","status":"recurring","recur":"weekly"}
+	]`
+
+	result, err := Parse("", []byte(data), Options{})
+	require.NoError(t, err)
+	assert.Equal(t, FormatTaskwarrior, result.Format)
+	require.Len(t, result.Items, 3)
+
+	due := time.Date(2024, 3, 5, 9, 0, 0, 0, time.UTC)
+	assert.Equal(t, Item{
+		Line: 1, Title: "Call mom", Status: StatusPending, Priority: 5, Due: &due,
+		List: "Family", Tags: []string{"phone"}, Parent: -1,
+	}, result.Items[0])
+
+	assert.Equal(t, StatusCompleted, result.Items[1].Status)
+	assert.Equal(t, 3, result.Items[1].Priority)
+	assert.Equal(t, "Paid by transfer\nRef 42", result.Items[1].Description)
+
+	assert.Equal(t, StatusInProgress, result.Items[2].Status)
+
+	assert.Equal(t, []Skipped{
+		{Line: 3, Field: "depends", Value: "b,c", Reason: "dependencies are not imported"},
+		{Line: 3, Field: "estimate", Value: "3", Reason: "unknown field"},
+		{Line: 3, Field: "wait", Value: "20240310T000000Z", Reason: "wait and scheduled dates are not imported"},
+		{Line: 4, Field: "status", Value: "deleted", Reason: "deleted tasks are not imported"},
+		{Line: 5, Field: "status", Value: "recurring", Reason: "recurring templates are not imported, their pending instances are"},
+	}, result.Skipped)
+}
+
+func TestParseTaskwarriorLines(t *testing.T) {
+	data := "{\"description\":\"Call mom\",\"status\":\"pending\"}\n{\"description\":\"Pay rent\",\"status\":\"pending\"}\n"
+
+	result, err := Parse(FormatTaskwarrior, []byte(data), Options{})
+	require.NoError(t, err)
+	require.Len(t, result.Items, 2)
+	assert.Equal(t, "Pay rent", result.Items[1].Title)
+	assert.Equal(t, 2, result.Items[1].Line)
+}
+
+func TestParseJSON(t *testing.T) {
+	data := `{
+		"version": 1,
+		"exported_at": "2024-03-01T12:00:00Z",
+		"lists": [{"id": "l1", "name": "Sprint", "estimate_unit": "points"}],
+		"todos": [
+			{"id": "c", "parent_id": "b", "title": "Grandchild", "status": "pending", "priority": 0, "tags": []},
+			{"id": "b", "parent_id": "a", "list_id": "l1", "title": "Child", "status": "completed", "priority": 2, "tags": ["x"]},
+			{"id": "a", "list_id": "l1", "title": "Root", "status": "in_progress", "priority": 5, "estimate": 3,
+			 "due_date": "2024-03-05T08:00:00Z", "recurrence_rule": "FREQ=DAILY", "timezone": "Europe/Berlin", "tags": ["work"]},
+			{"id": "d", "parent_id": "gone", "list_id": "l9", "title": "  ", "status": "done", "priority": 9, "tags": []},
+			{"id": "e", "parent_id": "f", "title": "Loop 1", "status": "pending", "priority": 0, "tags": []},
+			{"id": "f", "parent_id": "e", "title": "Loop 2", "status": "pending", "priority": 0, "tags": []}
+		]
+	}`
+
+	result, err := Parse("", []byte(data), Options{})
+	require.NoError(t, err)
+	assert.Equal(t, FormatJSON, result.Format)
+	assert.Equal(t, []List{{Name: "Sprint", EstimateUnit: "points"}}, result.Lists)
+	require.Len(t, result.Items, 6)
+
+	due := time.Date(2024, 3, 5, 8, 0, 0, 0, time.UTC)
+	estimate := 3.0
+	assert.Equal(t, Item{
+		Line: 3, Title: "Root", Status: StatusInProgress, Priority: 5, Due: &due,
+		RecurrenceRule: "FREQ=DAILY", Timezone: "Europe/Berlin", Estimate: &estimate,
+		List: "Sprint", Tags: []string{"work"}, Parent: -1,
+	}, result.Items[0])
+
+	// Subtasks follow their parent whatever the document's order
+	titles := make([]string, len(result.Items))
+	parents := make([]int, len(result.Items))
+	for i, item := range result.Items {
+		titles[i], parents[i] = item.Title, item.Parent
+	}
+	assert.Equal(t, []string{"Root", "Child", "Grandchild", "Untitled", "Loop 1", "Loop 2"}, titles)
+	assert.Equal(t, []int{-1, 0, 1, -1, -1, 4}, parents)
+
+	invalid := result.Items[3]
+	assert.Equal(t, StatusPending, invalid.Status)
+	assert.Equal(t, 0, invalid.Priority)
+	assert.Empty(t, invalid.List)
+
+	assert.Equal(t, []Skipped{
+		{Line: 4, Field: "list_id", Value: "l9", Reason: "unknown list"},
+		{Line: 4, Field: "status", Value: "done", Reason: "invalid status"},
+		{Line: 4, Field: "priority", Value: "9", Reason: "invalid priority"},
+		{Line: 5, Field: "parent_id", Value: "f", Reason: "circular parent"},
+	}, result.Skipped)
+}
+
+func TestParseJSONManualOrder(t *testing.T) {
+	data := `{
+		"version": 2,
+		"lists": [{"id": "l1", "name": "Home", "workflow": [
+			{"key": "todo", "name": "To do", "category": "todo"},
+			{"key": "done", "name": "Done", "category": "done", "transitions": ["todo"]}
+		]}],
+		"todos": [
+			{"id": "a", "title": "Unplaced", "status": "pending", "priority": 0, "tags": []},
+			{"id": "b", "title": "Second", "status": "pending", "position": "n", "priority": 0, "tags": []},
+			{"id": "c", "parent_id": "b", "title": "Later subtask", "status": "pending", "position": "z", "priority": 0, "tags": []},
+			{"id": "d", "parent_id": "b", "list_id": "l1", "title": "Earlier subtask", "status": "completed", "state": "done", "position": "a", "priority": 0, "tags": []},
+			{"id": "e", "title": "First", "status": "pending", "position": "m", "priority": 0, "tags": []}
+		]
+	}`
+
+	result, err := Parse("", []byte(data), Options{})
+	require.NoError(t, err)
+	assert.Equal(t, []List{{Name: "Home", Workflow: []JSONState{
+		{Key: "todo", Name: "To do", Category: "todo"},
+		{Key: "done", Name: "Done", Category: "done", Transitions: []string{"todo"}},
+	}}}, result.Lists)
+
+	titles := make([]string, len(result.Items))
+	for i, item := range result.Items {
+		titles[i] = item.Title
+	}
+	assert.Equal(t, []string{"First", "Second", "Earlier subtask", "Later subtask", "Unplaced"}, titles)
+	assert.Equal(t, "done", result.Items[2].State)
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		data   string
+		want   string
+	}{
+		{"empty todo.txt", FormatTodoTxt, "\n  \n", "no tasks found"},
+		{"todoist without content", FormatTodoist, "TYPE,TITLE\ntask,a\n", "invalid Todoist CSV: missing CONTENT column"},
+		{"todoist without tasks", FormatTodoist, "TYPE,CONTENT\nsection,a\n", "no tasks found"},
+		{"bad taskwarrior", FormatTaskwarrior, "[{\"description\":", "invalid Taskwarrior JSON"},
+		{"future json version", FormatJSON, "{\"version\":3,\"todos\":[]}", "invalid JSON export: unsupported version 3"},
+		{"bad json", FormatJSON, "{\"version\":1,\"todos\":{}}", "invalid JSON export"},
+		{"unknown format", "omnifocus", "a", `unsupported import format "omnifocus"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.format, []byte(tt.data), Options{})
+			require.Error(t, err)
+			assert.True(t, strings.HasPrefix(err.Error(), tt.want), err.Error())
+		})
+	}
+}
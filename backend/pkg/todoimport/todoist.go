@@ -0,0 +1,210 @@
+package todoimport
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Todoist priorities run from 4 (p1, highest) down to 1 (p4, none)
+var todoistPriorities = map[string]int{"4": 5, "3": 4, "2": 3, "1": 0}
+
+// Layouts of Todoist DATE and DEADLINE values, with and without a time
+var (
+	todoistDateTimeLayouts = []string{
+		"2006-01-02T15:04:05",
+		"2006-01-02 15:04",
+		"2 Jan 2006 15:04",
+		"Jan 2 2006 15:04",
+		"2 January 2006 15:04",
+		"January 2 2006 15:04",
+	}
+	todoistDateLayouts = []string{
+		"2006-01-02",
+		"2 Jan 2006",
+		"Jan 2 2006",
+		"2 January 2006",
+		"January 2 2006",
+	}
+)
+
+func parseTodoist(result *Result, data []byte, opts Options) error {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("invalid Todoist CSV: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToUpper(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["TYPE"]; !ok {
+		return errors.New("invalid Todoist CSV: missing TYPE column")
+	}
+	if _, ok := columns["CONTENT"]; !ok {
+		return errors.New("invalid Todoist CSV: missing CONTENT column")
+	}
+
+	list := strings.TrimSpace(strings.TrimSuffix(filepath.Base(opts.Name), filepath.Ext(opts.Name)))
+	if list == "." {
+		list = ""
+	}
+
+	// parents[i] is the index of the last task at indent i+1
+	var parents []int
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("invalid Todoist CSV: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		switch strings.ToLower(field("TYPE")) {
+		case "task":
+			item := parseTodoistTask(result, line, field, opts.Location)
+			item.List = list
+
+			indent, err := strconv.Atoi(field("INDENT"))
+			if err != nil || indent < 1 {
+				indent = 1
+			}
+			if indent > len(parents)+1 {
+				indent = len(parents) + 1
+			}
+			if indent > 1 {
+				item.Parent = parents[indent-2]
+			}
+			parents = append(parents[:indent-1], len(result.Items))
+			result.Items = append(result.Items, item)
+		case "note":
+			note := field("CONTENT")
+			if len(result.Items) == 0 {
+				result.skip(line, "note", note, "project comments are not imported")
+				continue
+			}
+			last := &result.Items[len(result.Items)-1]
+			if last.Description == "" {
+				last.Description = note
+			} else {
+				last.Description += "\n\n" + note
+			}
+		case "section":
+			result.skip(line, "section", field("CONTENT"), "sections are not imported")
+		}
+	}
+	return nil
+}
+
+func parseTodoistTask(result *Result, line int, field func(string) string, loc *time.Location) Item {
+	title, tags := todoistLabels(field("CONTENT"))
+	item := Item{
+		Line:        line,
+		Title:       title,
+		Description: field("DESCRIPTION"),
+		Status:      StatusPending,
+		Tags:        tags,
+		Parent:      -1,
+	}
+
+	if value := field("PRIORITY"); value != "" {
+		priority, ok := todoistPriorities[value]
+		if ok {
+			item.Priority = priority
+		} else {
+			result.skip(line, "priority", value, "invalid priority")
+		}
+	}
+
+	if timezone := field("TIMEZONE"); timezone != "" {
+		if tz, err := time.LoadLocation(timezone); err == nil {
+			loc = tz
+		}
+	}
+	date, deadline := field("DATE"), field("DEADLINE")
+	if date == "" && deadline != "" {
+		date, deadline = deadline, ""
+	}
+	if date != "" {
+		if due, ok := parseTodoistDate(date, loc); ok {
+			item.Due = &due
+		} else if strings.HasPrefix(strings.ToLower(date), "every") {
+			result.skip(line, "date", date, "recurring dates are not imported")
+		} else {
+			result.skip(line, "date", date, "invalid date")
+		}
+	}
+	if deadline != "" {
+		result.skip(line, "deadline", deadline, "tasks with a date keep it as their due date")
+	}
+
+	if value := field("DURATION"); value != "" {
+		minutes, err := strconv.ParseFloat(value, 64)
+		unit := strings.ToLower(field("DURATION_UNIT"))
+		switch {
+		case err != nil || minutes < 0:
+			result.skip(line, "duration", value, "invalid duration")
+		case unit == "" || unit == "minute":
+			item.Estimate = &minutes
+		default:
+			result.skip(line, "duration", value+" "+unit, "only durations in minutes are imported")
+		}
+	}
+
+	if responsible := field("RESPONSIBLE"); responsible != "" {
+		result.skip(line, "responsible", responsible, "assignees are not imported")
+	}
+	return item
+}
+
+// todoistLabels splits the @labels Todoist appends to a task's content from
+// its title
+func todoistLabels(content string) (string, []string) {
+	var title, tags []string
+	for _, word := range strings.Fields(content) {
+		if len(word) > 1 && word[0] == '@' {
+			tags = append(tags, word[1:])
+		} else {
+			title = append(title, word)
+		}
+	}
+	if len(title) == 0 {
+		return content, tags
+	}
+	return strings.Join(title, " "), tags
+}
+
+func parseTodoistDate(value string, loc *time.Location) (time.Time, bool) {
+	value = strings.Join(strings.Fields(strings.ReplaceAll(value, ",", " ")), " ")
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, true
+	}
+	for _, layout := range todoistDateTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, true
+		}
+	}
+	for _, layout := range todoistDateLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return atDefaultHour(t, loc), true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,119 @@
+package todoimport
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"time"
+)
+
+// todo.txt priorities (A) to (D) map onto 5 to 2, every lower letter onto 1
+func todoTxtPriority(letter byte) int {
+	if letter <= 'D' {
+		return 5 - int(letter-'A')
+	}
+	return 1
+}
+
+func isTodoTxtPriority(token string) bool {
+	return len(token) == 3 && token[0] == '(' && token[2] == ')' && token[1] >= 'A' && token[1] <= 'Z'
+}
+
+func isTodoTxtDate(token string) bool {
+	_, err := time.Parse("2006-01-02", token)
+	return err == nil
+}
+
+func parseTodoTxt(result *Result, data []byte, opts Options) error {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for number := 1; scanner.Scan(); number++ {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			result.Items = append(result.Items, parseTodoTxtLine(result, number, line, opts.Location))
+		}
+	}
+	return scanner.Err()
+}
+
+// parseTodoTxtLine parses one task:
+//
+//	x (A) 2024-03-01 2024-02-20 Call mom +Family @phone due:2024-03-05
+//
+// is completed on 2024-03-01, created on 2024-02-20, has priority A, the
+// project Family and the context phone. Completed tasks keep their priority
+// in a pri: tag.
+func parseTodoTxtLine(result *Result, number int, line string, loc *time.Location) Item {
+	item := Item{Line: number, Status: StatusPending, Parent: -1}
+	tokens := strings.Fields(line)
+
+	if tokens[0] == "x" {
+		item.Status = StatusCompleted
+		tokens = tokens[1:]
+	}
+	if len(tokens) > 0 && isTodoTxtPriority(tokens[0]) {
+		item.Priority = todoTxtPriority(tokens[0][1])
+		tokens = tokens[1:]
+	}
+	// A completed task has its completion date before its creation date
+	if item.Status == StatusCompleted && len(tokens) > 1 && isTodoTxtDate(tokens[0]) && isTodoTxtDate(tokens[1]) {
+		result.skip(number, "completion_date", tokens[0], "completion dates are not imported")
+		tokens = tokens[1:]
+	}
+	if len(tokens) > 0 && isTodoTxtDate(tokens[0]) {
+		result.skip(number, "creation_date", tokens[0], "creation dates are not imported")
+		tokens = tokens[1:]
+	}
+
+	var title []string
+	for _, token := range tokens {
+		switch {
+		case len(token) > 1 && token[0] == '+':
+			if item.List == "" {
+				item.List = token[1:]
+			} else {
+				item.Tags = append(item.Tags, token[1:])
+			}
+		case len(token) > 1 && token[0] == '@':
+			item.Tags = append(item.Tags, token[1:])
+		case isTodoTxtTag(token):
+			key, value, _ := strings.Cut(token, ":")
+			parseTodoTxtTag(result, &item, key, value, loc)
+		default:
+			title = append(title, token)
+		}
+	}
+
+	item.Title = strings.Join(title, " ")
+	if item.Title == "" {
+		item.Title = line
+	}
+	return item
+}
+
+// isTodoTxtTag reports whether token is a key:value tag. URLs such as
+// https://example.com stay in the title.
+func isTodoTxtTag(token string) bool {
+	key, value, found := strings.Cut(token, ":")
+	return found && key != "" && value != "" && !strings.HasPrefix(value, "/") && !strings.ContainsAny(key, "/.")
+}
+
+func parseTodoTxtTag(result *Result, item *Item, key, value string, loc *time.Location) {
+	switch strings.ToLower(key) {
+	case "due":
+		date, err := time.ParseInLocation("2006-01-02", value, loc)
+		if err != nil {
+			result.skip(item.Line, "due", value, "invalid date")
+			return
+		}
+		due := atDefaultHour(date, loc)
+		item.Due = &due
+	case "pri":
+		if len(value) == 1 && value[0] >= 'A' && value[0] <= 'Z' {
+			item.Priority = todoTxtPriority(value[0])
+			return
+		}
+		result.skip(item.Line, "pri", value, "invalid priority")
+	default:
+		result.skip(item.Line, key, value, "unknown field")
+	}
+}
@@ -0,0 +1,151 @@
+package tql
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenLParen
+	tokenRParen
+	tokenOr
+	tokenNot
+	tokenTerm // field<op>value
+	tokenText
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int // 1-based character position
+
+	// Set for tokenTerm
+	field    string
+	op       string
+	opPos    int
+	value    string
+	valuePos int
+}
+
+// operators in match order, longest first
+var operators = []string{">=", "<=", "!=", ":", "=", ">", "<"}
+
+// lex splits a query into tokens, tracking character positions for errors
+func lex(query string) ([]token, error) {
+	runes := []rune(query)
+	var tokens []token
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		pos := i + 1
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "(", pos: pos})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")", pos: pos})
+			i++
+		case r == '-':
+			tokens = append(tokens, token{kind: tokenNot, text: "-", pos: pos})
+			i++
+		default:
+			word, quoted, next, err := readWord(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, classify(word, quoted, pos))
+			i = next
+		}
+	}
+
+	tokens = append(tokens, token{kind: tokenEOF, text: "end of query", pos: len(runes) + 1})
+	return tokens, nil
+}
+
+// readWord reads up to the next unquoted space or parenthesis. Quoted
+// sections may contain spaces; the quotes are dropped.
+func readWord(runes []rune, start int) (word []rune, quoted []bool, next int, err error) {
+	i := start
+	for i < len(runes) {
+		r := runes[i]
+		if unicode.IsSpace(r) || r == '(' || r == ')' {
+			break
+		}
+		if r == '"' {
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			if end == len(runes) {
+				return nil, nil, 0, &Error{Pos: i + 1, Msg: "unterminated quote"}
+			}
+			for _, q := range runes[i+1 : end] {
+				word = append(word, q)
+				quoted = append(quoted, true)
+			}
+			i = end + 1
+			continue
+		}
+		word = append(word, r)
+		quoted = append(quoted, false)
+		i++
+	}
+	return word, quoted, i, nil
+}
+
+// classify turns a word into a keyword, a field term or free text. Positions
+// inside the word are approximate when it contains quotes.
+func classify(word []rune, quoted []bool, pos int) token {
+	text := string(word)
+
+	allQuoted := len(word) > 0
+	for _, q := range quoted {
+		allQuoted = allQuoted && q
+	}
+	if allQuoted || len(word) == 0 {
+		return token{kind: tokenText, text: text, pos: pos}
+	}
+
+	switch text {
+	case "OR":
+		return token{kind: tokenOr, text: text, pos: pos}
+	case "NOT":
+		return token{kind: tokenNot, text: text, pos: pos}
+	}
+
+	// field<op>value, where field is an unquoted run of letters and underscores
+	end := 0
+	for end < len(word) && !quoted[end] && (unicode.IsLetter(word[end]) || word[end] == '_') {
+		end++
+	}
+	if end > 0 && end < len(word) && !quoted[end] {
+		rest := string(word[end:])
+		for _, op := range operators {
+			if len(rest) >= len(op) && rest[:len(op)] == op {
+				value := rest[len(op):]
+				normalized := op
+				if op == ":" {
+					normalized = "="
+				}
+				return token{
+					kind:     tokenTerm,
+					text:     text,
+					pos:      pos,
+					field:    string(word[:end]),
+					op:       normalized,
+					opPos:    pos + end,
+					value:    value,
+					valuePos: pos + end + utf8.RuneCountInString(op),
+				}
+			}
+		}
+	}
+
+	return token{kind: tokenText, text: text, pos: pos}
+}
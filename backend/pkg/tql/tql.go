@@ -0,0 +1,359 @@
+// Package tql parses the todo query language used by saved views and the
+// q parameter of todo listings, e.g.
+//
+//	status:pending priority>=3 due<7d tag:work -tag:someday
+//
+// Terms are separated by whitespace and must all match. OR (upper case)
+// separates alternatives, parentheses group and a leading "-" or NOT negates.
+// A term is either field<op>value or free text matched against the title and
+// description; quote values and text that contain spaces.
+package tql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Error is a parse error at a 1-based character position of the query
+type Error struct {
+	Pos int
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("at position %d: %s", e.Pos, e.Msg)
+}
+
+// Expr is a node of a parsed query
+type Expr interface {
+	Pos() int
+}
+
+// And matches when every operand matches
+type And struct {
+	Exprs []Expr
+	pos   int
+}
+
+// Or matches when any operand matches
+type Or struct {
+	Exprs []Expr
+	pos   int
+}
+
+// Not matches when its operand does not
+type Not struct {
+	Expr Expr
+	pos  int
+}
+
+// Text matches todos whose title or description contains the text
+type Text struct {
+	Text string
+	pos  int
+}
+
+// Condition compares a todo field with one or more values. Op is one of
+// "=", "!=", "<", "<=", ">" and ">="; "field:value" is parsed as "=".
+type Condition struct {
+	Field  string
+	Op     string
+	Values []string
+	Ints   []int  // priority values
+	Dates  []Date // due, created and updated values
+	pos    int
+}
+
+func (e *And) Pos() int       { return e.pos }
+func (e *Or) Pos() int        { return e.pos }
+func (e *Not) Pos() int       { return e.pos }
+func (e *Text) Pos() int      { return e.pos }
+func (e *Condition) Pos() int { return e.pos }
+
+// Date is a calendar day, either absolute or relative to today
+type Date struct {
+	Absolute *time.Time // year, month and day only
+	Days     int
+	Months   int
+}
+
+// Day returns the start of the day in now's location
+func (d Date) Day(now time.Time) time.Time {
+	if d.Absolute != nil {
+		return time.Date(d.Absolute.Year(), d.Absolute.Month(), d.Absolute.Day(), 0, 0, 0, 0, now.Location())
+	}
+	return time.Date(now.Year(), now.Month()+time.Month(d.Months), now.Day()+d.Days, 0, 0, 0, 0, now.Location())
+}
+
+// Fields and the operators and values they accept
+const (
+	FieldStatus   = "status"
+	FieldPriority = "priority"
+	FieldDue      = "due"
+	FieldCreated  = "created"
+	FieldUpdated  = "updated"
+	FieldTag      = "tag"
+	FieldList     = "list"
+	FieldIs       = "is"
+	FieldHas      = "has"
+)
+
+var (
+	equalityOps   = []string{"=", "!="}
+	comparisonOps = []string{"=", "!=", "<", "<=", ">", ">="}
+
+	fieldOps = map[string][]string{
+		FieldStatus:   equalityOps,
+		FieldPriority: comparisonOps,
+		FieldDue:      comparisonOps,
+		FieldCreated:  comparisonOps,
+		FieldUpdated:  comparisonOps,
+		FieldTag:      equalityOps,
+		FieldList:     equalityOps,
+		FieldIs:       {"="},
+		FieldHas:      {"="},
+	}
+
+	statusValues = []string{"pending", "in_progress", "completed"}
+	isValues     = []string{"overdue", "recurring", "blocked"}
+	hasValues    = []string{"due", "tags", "list"}
+)
+
+// Parse parses and validates a query. An empty query matches everything and
+// yields a nil Expr.
+func Parse(query string) (Expr, error) {
+	tokens, err := lex(query)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	if p.peek().kind == tokenEOF {
+		return nil, nil
+	}
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, &Error{Pos: tok.pos, Msg: fmt.Sprintf("unexpected %q", tok.text)}
+	}
+	return expr, nil
+}
+
+type parser struct {
+	tokens []token
+	i      int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.i]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.i]
+	if tok.kind != tokenEOF {
+		p.i++
+	}
+	return tok
+}
+
+// parseOr parses and-groups separated by OR
+func (p *parser) parseOr() (Expr, error) {
+	pos := p.peek().pos
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	exprs := []Expr{first}
+	for p.peek().kind == tokenOr {
+		tok := p.next()
+		if k := p.peek().kind; k == tokenEOF || k == tokenRParen || k == tokenOr {
+			return nil, &Error{Pos: tok.pos, Msg: "OR must be followed by a term"}
+		}
+		expr, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+	}
+
+	if len(exprs) == 1 {
+		return first, nil
+	}
+	return &Or{Exprs: exprs, pos: pos}, nil
+}
+
+// parseAnd parses a run of unary terms
+func (p *parser) parseAnd() (Expr, error) {
+	pos := p.peek().pos
+	var exprs []Expr
+	for {
+		switch p.peek().kind {
+		case tokenEOF, tokenRParen, tokenOr:
+			if len(exprs) == 0 {
+				tok := p.peek()
+				if tok.kind == tokenEOF {
+					return nil, &Error{Pos: tok.pos, Msg: "expected a term"}
+				}
+				return nil, &Error{Pos: tok.pos, Msg: fmt.Sprintf("unexpected %q", tok.text)}
+			}
+			if len(exprs) == 1 {
+				return exprs[0], nil
+			}
+			return &And{Exprs: exprs, pos: pos}, nil
+		}
+
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+	}
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenNot:
+		if k := p.peek().kind; k == tokenEOF || k == tokenRParen || k == tokenOr {
+			return nil, &Error{Pos: tok.pos, Msg: "negation must be followed by a term"}
+		}
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &Not{Expr: expr, pos: tok.pos}, nil
+	case tokenLParen:
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != tokenRParen {
+			return nil, &Error{Pos: tok.pos, Msg: "unclosed parenthesis"}
+		}
+		p.next()
+		return expr, nil
+	case tokenText:
+		return &Text{Text: tok.text, pos: tok.pos}, nil
+	case tokenTerm:
+		return parseCondition(tok)
+	default:
+		return nil, &Error{Pos: tok.pos, Msg: fmt.Sprintf("unexpected %q", tok.text)}
+	}
+}
+
+// parseCondition validates the field, operator and values of a term
+func parseCondition(tok token) (Expr, error) {
+	ops, ok := fieldOps[tok.field]
+	if !ok {
+		return nil, &Error{Pos: tok.pos, Msg: fmt.Sprintf("unknown field %q", tok.field)}
+	}
+	if !contains(ops, tok.op) {
+		return nil, &Error{Pos: tok.opPos, Msg: fmt.Sprintf("operator %q is not supported for %s", tok.op, tok.field)}
+	}
+	if tok.value == "" {
+		return nil, &Error{Pos: tok.valuePos, Msg: fmt.Sprintf("missing value for %s", tok.field)}
+	}
+
+	cond := &Condition{Field: tok.field, Op: tok.op, pos: tok.pos}
+
+	// Equality accepts a comma-separated list of alternatives
+	values := []string{tok.value}
+	if tok.op == "=" || tok.op == "!=" {
+		values = strings.Split(tok.value, ",")
+	} else if strings.Contains(tok.value, ",") {
+		return nil, &Error{Pos: tok.valuePos, Msg: fmt.Sprintf("operator %q takes a single value", tok.op)}
+	}
+
+	offset := 0
+	for _, value := range values {
+		pos := tok.valuePos + offset
+		offset += utf8.RuneCountInString(value) + 1
+		value = strings.ToLower(value)
+		if strings.TrimSpace(value) == "" {
+			return nil, &Error{Pos: pos, Msg: "empty value"}
+		}
+
+		switch tok.field {
+		case FieldStatus:
+			if !contains(statusValues, value) {
+				return nil, &Error{Pos: pos, Msg: fmt.Sprintf("invalid status %q, expected one of %s", value, strings.Join(statusValues, ", "))}
+			}
+		case FieldIs:
+			if !contains(isValues, value) {
+				return nil, &Error{Pos: pos, Msg: fmt.Sprintf("invalid value %q for is, expected one of %s", value, strings.Join(isValues, ", "))}
+			}
+		case FieldHas:
+			if !contains(hasValues, value) {
+				return nil, &Error{Pos: pos, Msg: fmt.Sprintf("invalid value %q for has, expected one of %s", value, strings.Join(hasValues, ", "))}
+			}
+		case FieldPriority:
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 || n > 5 {
+				return nil, &Error{Pos: pos, Msg: fmt.Sprintf("invalid priority %q, expected 0-5", value)}
+			}
+			cond.Ints = append(cond.Ints, n)
+		case FieldDue, FieldCreated, FieldUpdated:
+			date, err := parseDate(value)
+			if err != nil {
+				return nil, &Error{Pos: pos, Msg: err.Error()}
+			}
+			cond.Dates = append(cond.Dates, date)
+		}
+		cond.Values = append(cond.Values, value)
+	}
+
+	// Ranges only make sense for a single day
+	if len(cond.Dates) > 1 {
+		return nil, &Error{Pos: tok.valuePos, Msg: fmt.Sprintf("%s takes a single date", tok.field)}
+	}
+	return cond, nil
+}
+
+// parseDate accepts today, tomorrow, yesterday, YYYY-MM-DD and offsets from
+// today such as 7d, -2w, 3m or 1y
+func parseDate(value string) (Date, error) {
+	switch value {
+	case "today":
+		return Date{}, nil
+	case "tomorrow":
+		return Date{Days: 1}, nil
+	case "yesterday":
+		return Date{Days: -1}, nil
+	}
+
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return Date{Absolute: &t}, nil
+	}
+
+	if len(value) >= 2 {
+		n, err := strconv.Atoi(value[:len(value)-1])
+		if err == nil {
+			switch value[len(value)-1] {
+			case 'd':
+				return Date{Days: n}, nil
+			case 'w':
+				return Date{Days: 7 * n}, nil
+			case 'm':
+				return Date{Months: n}, nil
+			case 'y':
+				return Date{Months: 12 * n}, nil
+			}
+		}
+	}
+	return Date{}, fmt.Errorf("invalid date %q, expected today, tomorrow, yesterday, YYYY-MM-DD or an offset like 7d, 2w, 1m", value)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+package tql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	expr, err := Parse(`status:pending priority>=3 due<7d tag:work -tag:someday`)
+	require.NoError(t, err)
+
+	and, ok := expr.(*And)
+	require.True(t, ok)
+	require.Len(t, and.Exprs, 5)
+
+	status := and.Exprs[0].(*Condition)
+	assert.Equal(t, "status", status.Field)
+	assert.Equal(t, "=", status.Op)
+	assert.Equal(t, []string{"pending"}, status.Values)
+
+	priority := and.Exprs[1].(*Condition)
+	assert.Equal(t, ">=", priority.Op)
+	assert.Equal(t, []int{3}, priority.Ints)
+
+	due := and.Exprs[2].(*Condition)
+	assert.Equal(t, "<", due.Op)
+	assert.Equal(t, Date{Days: 7}, due.Dates[0])
+
+	not, ok := and.Exprs[4].(*Not)
+	require.True(t, ok)
+	assert.Equal(t, []string{"someday"}, not.Expr.(*Condition).Values)
+}
+
+func TestParseStructure(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		check func(t *testing.T, expr Expr)
+	}{
+		{
+			name:  "empty query",
+			query: "   ",
+			check: func(t *testing.T, expr Expr) { assert.Nil(t, expr) },
+		},
+		{
+			name:  "or binds looser than and",
+			query: "tag:a tag:b OR tag:c",
+			check: func(t *testing.T, expr Expr) {
+				or := expr.(*Or)
+				require.Len(t, or.Exprs, 2)
+				assert.Len(t, or.Exprs[0].(*And).Exprs, 2)
+			},
+		},
+		{
+			name:  "parentheses and NOT",
+			query: "NOT (is:overdue OR has:due) invoice",
+			check: func(t *testing.T, expr Expr) {
+				and := expr.(*And)
+				require.Len(t, and.Exprs, 2)
+				assert.IsType(t, &Or{}, and.Exprs[0].(*Not).Expr)
+				assert.Equal(t, "invoice", and.Exprs[1].(*Text).Text)
+			},
+		},
+		{
+			name:  "quoted text and values",
+			query: `"call mom" list:"Home Stuff"`,
+			check: func(t *testing.T, expr Expr) {
+				and := expr.(*And)
+				assert.Equal(t, "call mom", and.Exprs[0].(*Text).Text)
+				assert.Equal(t, []string{"home stuff"}, and.Exprs[1].(*Condition).Values)
+			},
+		},
+		{
+			name:  "comma separated alternatives",
+			query: "status:pending,in_progress",
+			check: func(t *testing.T, expr Expr) {
+				assert.Equal(t, []string{"pending", "in_progress"}, expr.(*Condition).Values)
+			},
+		},
+		{
+			name:  "absolute and named dates",
+			query: "due>=2024-03-01 created:yesterday",
+			check: func(t *testing.T, expr Expr) {
+				and := expr.(*And)
+				require.NotNil(t, and.Exprs[0].(*Condition).Dates[0].Absolute)
+				assert.Equal(t, Date{Days: -1}, and.Exprs[1].(*Condition).Dates[0])
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := Parse(tt.query)
+			require.NoError(t, err)
+			tt.check(t, expr)
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		pos   int
+		msg   string
+	}{
+		{"colour:red", 1, `unknown field "colour"`},
+		{"tag:work status:done", 17, `invalid status "done"`},
+		{"priority>=9", 11, `invalid priority "9"`},
+		{"tag>work", 4, `operator ">" is not supported for tag`},
+		{"due<soon", 5, `invalid date "soon"`},
+		{"status:", 8, "missing value for status"},
+		{"status:pending,,completed", 16, "empty value"},
+		{`"unterminated`, 1, "unterminated quote"},
+		{"(tag:a OR tag:b", 1, "unclosed parenthesis"},
+		{"tag:a )", 7, `unexpected ")"`},
+		{"tag:a OR", 7, "OR must be followed by a term"},
+		{"tag:a -", 7, "negation must be followed by a term"},
+		{"is:late", 4, `invalid value "late" for is`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := Parse(tt.query)
+			require.Error(t, err)
+
+			parseErr, ok := err.(*Error)
+			require.True(t, ok, "expected *Error, got %T", err)
+			assert.Equal(t, tt.pos, parseErr.Pos)
+			assert.Contains(t, parseErr.Msg, tt.msg)
+		})
+	}
+}
+
+func TestDateDay(t *testing.T) {
+	now := time.Date(2024, 1, 31, 15, 4, 5, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), Date{}.Day(now))
+	assert.Equal(t, time.Date(2024, 2, 7, 0, 0, 0, 0, time.UTC), Date{Days: 7}.Day(now))
+	assert.Equal(t, time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), Date{Months: 1}.Day(now))
+
+	abs := time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC)
+	loc := time.FixedZone("UTC+2", 2*3600)
+	assert.Equal(t, time.Date(2024, 5, 6, 0, 0, 0, 0, loc), Date{Absolute: &abs}.Day(now.In(loc)))
+}
@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// EncodeCursor packs the sort key values of the last row into an opaque token
+func EncodeCursor(values ...string) string {
+	return EncodeCursorValue(values)
+}
+
+// DecodeCursor unpacks a token produced by EncodeCursor
+func DecodeCursor(cursor string) ([]string, error) {
+	var values []string
+	if err := DecodeCursorValue(cursor, &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// EncodeCursorValue packs an arbitrary JSON-serialisable position into an opaque token
+func EncodeCursorValue(v interface{}) string {
+	raw, _ := json.Marshal(v)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursorValue unpacks a token produced by EncodeCursorValue into v
+func DecodeCursorValue(cursor string, v interface{}) error {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return errors.New("invalid cursor")
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return errors.New("invalid cursor")
+	}
+	return nil
+}
@@ -35,6 +35,23 @@ type Pagination struct {
 	HasPrev    bool `json:"has_prev"`
 }
 
+type CursorPagination struct {
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+	Total      *int64 `json:"total,omitempty"`
+	Next       string `json:"next,omitempty"` // link to the next page
+	Prev       string `json:"prev,omitempty"` // link to the previous page
+}
+
+type CursorPaginatedResponse struct {
+	Success    bool             `json:"success"`
+	Message    string           `json:"message"`
+	Data       interface{}      `json:"data"`
+	Pagination CursorPagination `json:"pagination"`
+}
+
 type ValidationError struct {
 	Field   string `json:"field"`
 	Message string `json:"message"`
@@ -73,6 +90,15 @@ func PaginatedSuccessResponse(c *gin.Context, statusCode int, message string, da
 	})
 }
 
+func CursorPaginatedSuccessResponse(c *gin.Context, statusCode int, message string, data interface{}, pagination CursorPagination) {
+	c.JSON(statusCode, CursorPaginatedResponse{
+		Success:    true,
+		Message:    message,
+		Data:       data,
+		Pagination: pagination,
+	})
+}
+
 func CalculatePagination(page, limit, total int) Pagination {
 	totalPages := (total + limit - 1) / limit
 	hasNext := page < totalPages
@@ -0,0 +1,224 @@
+// Package webdav reads the WebDAV (RFC 4918) and CalDAV (RFC 4791) request
+// bodies a task server needs, PROPFIND and the calendar-query and
+// calendar-multiget REPORTs, and writes multistatus responses.
+//
+// Property values are written as XML fragments. Every element declares its
+// own default namespace, so responses need no prefixes.
+package webdav
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Namespaces
+const (
+	NamespaceDAV      = "DAV:"
+	NamespaceCalDAV   = "urn:ietf:params:xml:ns:caldav"
+	NamespaceCalendar = "http://calendarserver.org/ns/"
+)
+
+// Report kinds
+const (
+	ReportCalendarQuery    = "calendar-query"
+	ReportCalendarMultiget = "calendar-multiget"
+)
+
+// Propfind is a parsed PROPFIND body. An empty body asks for all properties.
+type Propfind struct {
+	AllProp  bool
+	PropName bool
+	Props    []xml.Name
+}
+
+// Report is a parsed calendar-query or calendar-multiget REPORT body. Kind is
+// the local name of the root element, so callers can reject other reports.
+type Report struct {
+	Kind    string
+	AllProp bool
+	Props   []xml.Name
+	// Hrefs of a calendar-multiget
+	Hrefs []string
+	// Components is the comp-filter path of a calendar-query, e.g. VCALENDAR,
+	// VTODO; time ranges and property filters are not read
+	Components []string
+}
+
+type anyElement struct {
+	XMLName xml.Name
+}
+
+type propElement struct {
+	Names []anyElement `xml:",any"`
+}
+
+func (p *propElement) names() []xml.Name {
+	if p == nil {
+		return nil
+	}
+	names := make([]xml.Name, len(p.Names))
+	for i, e := range p.Names {
+		names[i] = e.XMLName
+	}
+	return names
+}
+
+type propfindElement struct {
+	XMLName  xml.Name     `xml:"DAV: propfind"`
+	AllProp  *struct{}    `xml:"DAV: allprop"`
+	PropName *struct{}    `xml:"DAV: propname"`
+	Prop     *propElement `xml:"DAV: prop"`
+}
+
+type compFilter struct {
+	Name    string       `xml:"name,attr"`
+	Filters []compFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+}
+
+type reportElement struct {
+	XMLName xml.Name
+	AllProp *struct{}    `xml:"DAV: allprop"`
+	Prop    *propElement `xml:"DAV: prop"`
+	Hrefs   []string     `xml:"DAV: href"`
+	Filter  *struct {
+		Comp *compFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+	} `xml:"urn:ietf:params:xml:ns:caldav filter"`
+}
+
+// ParsePropfind parses a PROPFIND request body
+func ParsePropfind(body io.Reader) (*Propfind, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return &Propfind{AllProp: true}, nil
+	}
+
+	var element propfindElement
+	if err := xml.Unmarshal(data, &element); err != nil {
+		return nil, fmt.Errorf("invalid propfind: %w", err)
+	}
+	propfind := &Propfind{
+		AllProp:  element.AllProp != nil,
+		PropName: element.PropName != nil,
+		Props:    element.Prop.names(),
+	}
+	if !propfind.AllProp && !propfind.PropName && len(propfind.Props) == 0 {
+		return nil, errors.New("invalid propfind: expected allprop, propname or prop")
+	}
+	return propfind, nil
+}
+
+// ParseReport parses a REPORT request body
+func ParseReport(body io.Reader) (*Report, error) {
+	var element reportElement
+	if err := xml.NewDecoder(body).Decode(&element); err != nil {
+		return nil, fmt.Errorf("invalid report: %w", err)
+	}
+
+	report := &Report{
+		Kind:    element.XMLName.Local,
+		AllProp: element.AllProp != nil,
+		Props:   element.Prop.names(),
+		Hrefs:   element.Hrefs,
+	}
+	if element.XMLName.Space != NamespaceCalDAV {
+		report.Kind = element.XMLName.Space + " " + element.XMLName.Local
+	}
+	if element.Filter != nil {
+		for filter := element.Filter.Comp; filter != nil; {
+			report.Components = append(report.Components, strings.ToUpper(filter.Name))
+			if len(filter.Filters) == 0 {
+				break
+			}
+			filter = &filter.Filters[0]
+		}
+	}
+	return report, nil
+}
+
+// Prop is a property with its value as an XML fragment
+type Prop struct {
+	Name  xml.Name
+	Value string
+}
+
+// Response is one resource of a multistatus. Status, when set, replaces the
+// property lists, e.g. for a multiget href that does not exist.
+type Response struct {
+	Href     string
+	Status   int
+	Found    []Prop
+	NotFound []xml.Name
+}
+
+// WriteMultistatus writes a 207 Multi-Status body
+func WriteMultistatus(w io.Writer, responses []Response) error {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<multistatus xmlns="DAV:">`)
+	for _, r := range responses {
+		b.WriteString("<response>")
+		b.WriteString(Element(xml.Name{Space: NamespaceDAV, Local: "href"}, Escape(r.Href)))
+		if r.Status != 0 {
+			b.WriteString(Element(xml.Name{Space: NamespaceDAV, Local: "status"}, statusLine(r.Status)))
+		} else {
+			if len(r.Found) > 0 || len(r.NotFound) == 0 {
+				var props strings.Builder
+				for _, p := range r.Found {
+					props.WriteString(Element(p.Name, p.Value))
+				}
+				writePropstat(&b, props.String(), http.StatusOK)
+			}
+			if len(r.NotFound) > 0 {
+				var props strings.Builder
+				for _, name := range r.NotFound {
+					props.WriteString(Element(name, ""))
+				}
+				writePropstat(&b, props.String(), http.StatusNotFound)
+			}
+		}
+		b.WriteString("</response>")
+	}
+	b.WriteString("</multistatus>")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writePropstat(b *strings.Builder, props string, status int) {
+	b.WriteString("<propstat><prop>")
+	b.WriteString(props)
+	b.WriteString("</prop>")
+	b.WriteString(Element(xml.Name{Space: NamespaceDAV, Local: "status"}, statusLine(status)))
+	b.WriteString("</propstat>")
+}
+
+func statusLine(status int) string {
+	return fmt.Sprintf("HTTP/1.1 %d %s", status, http.StatusText(status))
+}
+
+// Element returns <local xmlns="space">inner</local>, or an empty element
+// without inner content
+func Element(name xml.Name, inner string) string {
+	open := "<" + name.Local
+	if name.Space != "" {
+		open += ` xmlns="` + Escape(name.Space) + `"`
+	}
+	if inner == "" {
+		return open + "/>"
+	}
+	return open + ">" + inner + "</" + name.Local + ">"
+}
+
+// Escape escapes text for XML character data and attributes
+func Escape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
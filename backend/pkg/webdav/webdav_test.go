@@ -0,0 +1,128 @@
+package webdav
+
+import (
+	"encoding/xml"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	displayName = xml.Name{Space: NamespaceDAV, Local: "displayname"}
+	getETag     = xml.Name{Space: NamespaceDAV, Local: "getetag"}
+	getCTag     = xml.Name{Space: NamespaceCalendar, Local: "getctag"}
+	calData     = xml.Name{Space: NamespaceCalDAV, Local: "calendar-data"}
+)
+
+func TestParsePropfind(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    *Propfind
+		wantErr bool
+	}{
+		{name: "empty body", body: "", want: &Propfind{AllProp: true}},
+		{name: "allprop", body: `<propfind xmlns="DAV:"><allprop/></propfind>`, want: &Propfind{AllProp: true}},
+		{name: "propname", body: `<D:propfind xmlns:D="DAV:"><D:propname/></D:propfind>`, want: &Propfind{PropName: true}},
+		{
+			name: "props in several namespaces",
+			body: `<?xml version="1.0"?>
+<d:propfind xmlns:d="DAV:" xmlns:cs="http://calendarserver.org/ns/">
+  <d:prop><d:displayname/><cs:getctag/><x:color xmlns:x="urn:example"/></d:prop>
+</d:propfind>`,
+			want: &Propfind{Props: []xml.Name{displayName, getCTag, {Space: "urn:example", Local: "color"}}},
+		},
+		{name: "not xml", body: "propfind", wantErr: true},
+		{name: "wrong root", body: `<prop xmlns="DAV:"><displayname/></prop>`, wantErr: true},
+		{name: "nothing asked", body: `<propfind xmlns="DAV:"/>`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePropfind(strings.NewReader(tt.body))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseReport(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want *Report
+	}{
+		{
+			name: "calendar-query",
+			body: `<c:calendar-query xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">
+  <d:prop><d:getetag/><c:calendar-data/></d:prop>
+  <c:filter><c:comp-filter name="VCALENDAR"><c:comp-filter name="vtodo">
+    <c:time-range start="20240101T000000Z"/>
+  </c:comp-filter></c:comp-filter></c:filter>
+</c:calendar-query>`,
+			want: &Report{Kind: ReportCalendarQuery, Props: []xml.Name{getETag, calData}, Components: []string{"VCALENDAR", "VTODO"}},
+		},
+		{
+			name: "calendar-multiget",
+			body: `<C:calendar-multiget xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">
+  <D:prop><D:getetag/></D:prop>
+  <D:href>/dav/calendars/u/inbox/a.ics</D:href>
+  <D:href>/dav/calendars/u/inbox/b.ics</D:href>
+</C:calendar-multiget>`,
+			want: &Report{Kind: ReportCalendarMultiget, Props: []xml.Name{getETag}, Hrefs: []string{"/dav/calendars/u/inbox/a.ics", "/dav/calendars/u/inbox/b.ics"}},
+		},
+		{
+			name: "other report",
+			body: `<sync-collection xmlns="DAV:"><sync-token/></sync-collection>`,
+			want: &Report{Kind: "DAV: sync-collection"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseReport(strings.NewReader(tt.body))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := ParseReport(strings.NewReader("<unclosed"))
+	assert.Error(t, err)
+}
+
+func TestWriteMultistatus(t *testing.T) {
+	var b strings.Builder
+	err := WriteMultistatus(&b, []Response{
+		{
+			Href:     "/dav/calendars/u/work/",
+			Found:    []Prop{{Name: displayName, Value: Escape("R&D <team>")}, {Name: getCTag, Value: "42"}},
+			NotFound: []xml.Name{{Space: "urn:example", Local: "color"}},
+		},
+		{Href: "/dav/calendars/u/work/missing.ics", Status: http.StatusNotFound},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, xml.Header+`<multistatus xmlns="DAV:">`+
+		`<response><href xmlns="DAV:">/dav/calendars/u/work/</href>`+
+		`<propstat><prop><displayname xmlns="DAV:">R&amp;D &lt;team&gt;</displayname><getctag xmlns="http://calendarserver.org/ns/">42</getctag></prop>`+
+		`<status xmlns="DAV:">HTTP/1.1 200 OK</status></propstat>`+
+		`<propstat><prop><color xmlns="urn:example"/></prop><status xmlns="DAV:">HTTP/1.1 404 Not Found</status></propstat></response>`+
+		`<response><href xmlns="DAV:">/dav/calendars/u/work/missing.ics</href><status xmlns="DAV:">HTTP/1.1 404 Not Found</status></response>`+
+		`</multistatus>`, b.String())
+
+	// The output is well-formed
+	var parsed struct {
+		Responses []struct {
+			Href string `xml:"href"`
+		} `xml:"response"`
+	}
+	require.NoError(t, xml.Unmarshal([]byte(b.String()), &parsed))
+	assert.Len(t, parsed.Responses, 2)
+}